@@ -2,9 +2,12 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log/slog"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 
 	"github.com/csnewman/cathode/internal/mediaserver"
 )
@@ -34,12 +37,27 @@ func main() {
 func mainErr(logger *slog.Logger) error {
 	logger.Info("Cathode Media Server")
 
-	ms, err := mediaserver.New(logger)
+	configPath := flag.String("config", os.Getenv("CATHODE_CONFIG"), "path of the config file")
+	flag.Parse()
+
+	cfg, err := mediaserver.LoadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	ms, err := mediaserver.New(logger, cfg)
 	if err != nil {
 		return err
 	}
 
-	ctx := context.Background()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		// Restore the default handling, so that a second signal kills the server without waiting for shutdown
+		stop()
+	}()
 
 	return ms.Run(ctx)
 }
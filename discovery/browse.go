@@ -0,0 +1,280 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+const (
+	// BrowseTimeout is how long Browse waits for answers when the context has no deadline.
+	BrowseTimeout = 2 * time.Second
+
+	// queryInterval separates repeats of the query, in case it or an answer is lost.
+	queryInterval = 500 * time.Millisecond
+)
+
+// Browse queries the local network for media servers, returning those that answered before the context is done, or
+// BrowseTimeout has passed if it has no deadline. Servers are ordered by name, with one entry for each ID.
+func Browse(ctx context.Context) ([]Server, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, BrowseTimeout)
+		defer cancel()
+	}
+
+	query := new(dns.Msg)
+	query.SetQuestion(serviceName(), dns.TypePTR)
+
+	b, err := query.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		conns []*mdnsConn
+		errs  []error
+	)
+
+	for _, network := range []string{"udp4", "udp6"} {
+		c, err := dialMDNS(network)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%v: %w", network, err))
+
+			continue
+		}
+
+		defer c.Close()
+
+		conns = append(conns, c)
+	}
+
+	if len(conns) == 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	packets := make(chan packet)
+
+	for _, c := range conns {
+		go c.read(ctx, packets)
+	}
+
+	results := newBrowseResults()
+	ticker := time.NewTicker(queryInterval)
+
+	defer ticker.Stop()
+
+	sent := false
+
+	for {
+		for _, c := range conns {
+			if err := c.multicast(b); err == nil {
+				sent = true
+			}
+		}
+
+		if !sent {
+			return nil, errors.New("failed to send query on any interface")
+		}
+
+	wait:
+		for {
+			select {
+			case <-ctx.Done():
+				return results.servers(), nil
+			case p := <-packets:
+				results.add(p)
+			case <-ticker.C:
+				break wait
+			}
+		}
+	}
+}
+
+type packet struct {
+	msg  *dns.Msg
+	from netip.Addr
+}
+
+// read passes the answers received on the socket to packets until the context is done.
+func (c *mdnsConn) read(ctx context.Context, packets chan<- packet) {
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = c.conn.SetReadDeadline(deadline)
+	}
+
+	buf := make([]byte, 9000)
+
+	for {
+		n, from, err := c.conn.ReadFromUDPAddrPort(buf)
+		if err != nil {
+			return
+		}
+
+		msg := new(dns.Msg)
+		if err := msg.Unpack(buf[:n]); err != nil || !msg.Response {
+			continue
+		}
+
+		select {
+		case packets <- packet{msg: msg, from: from.Addr().Unmap()}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// browseResults collects records across answers, as responders may split them over several packets.
+type browseResults struct {
+	instances map[string]bool
+	srv       map[string]*dns.SRV
+	txt       map[string][]string
+	hosts     map[string][]netip.Addr
+	// from is the sender of each instance's answer, used when no addresses were given.
+	from map[string]netip.Addr
+}
+
+func newBrowseResults() *browseResults {
+	return &browseResults{
+		instances: make(map[string]bool),
+		srv:       make(map[string]*dns.SRV),
+		txt:       make(map[string][]string),
+		hosts:     make(map[string][]netip.Addr),
+		from:      make(map[string]netip.Addr),
+	}
+}
+
+func (b *browseResults) add(p packet) {
+	service := serviceName()
+
+	for _, rr := range append(p.msg.Answer, p.msg.Extra...) {
+		name := strings.ToLower(rr.Header().Name)
+
+		switch rr := rr.(type) {
+		case *dns.PTR:
+			if strings.EqualFold(rr.Hdr.Name, service) {
+				instance := strings.ToLower(rr.Ptr)
+
+				b.instances[instance] = true
+				b.from[instance] = p.from
+			}
+		case *dns.SRV:
+			b.srv[name] = rr
+		case *dns.TXT:
+			b.txt[name] = rr.Txt
+		case *dns.A:
+			b.addHost(name, rr.A)
+		case *dns.AAAA:
+			b.addHost(name, rr.AAAA)
+		}
+	}
+}
+
+func (b *browseResults) addHost(name string, ip net.IP) {
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return
+	}
+
+	addr = addr.Unmap()
+
+	if !slices.Contains(b.hosts[name], addr) {
+		b.hosts[name] = append(b.hosts[name], addr)
+	}
+}
+
+func (b *browseResults) servers() []Server {
+	byID := make(map[string]Server)
+
+	for instance := range b.instances {
+		txt, ok := b.txt[instance]
+		if !ok {
+			continue
+		}
+
+		var s Server
+
+		if err := s.ParseTXT(unescapeTXT(txt)); err != nil {
+			continue
+		}
+
+		if srv, ok := b.srv[instance]; ok {
+			s.Port = int(srv.Port)
+			s.Addrs = slices.Clone(b.hosts[strings.ToLower(srv.Target)])
+		}
+
+		if len(s.Addrs) == 0 {
+			s.Addrs = []netip.Addr{b.from[instance]}
+		}
+
+		// A server answering over both address families is merged into one
+		if prev, ok := byID[s.ID]; ok {
+			for _, addr := range prev.Addrs {
+				if !slices.Contains(s.Addrs, addr) {
+					s.Addrs = append(s.Addrs, addr)
+				}
+			}
+		}
+
+		byID[s.ID] = s
+	}
+
+	res := make([]Server, 0, len(byID))
+	for _, s := range byID {
+		res = append(res, s)
+	}
+
+	slices.SortFunc(res, func(a, b Server) int {
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	return res
+}
+
+// unescapeTXT reverses the escaping miekg/dns applies to unpacked TXT strings, where special characters are preceded
+// by a backslash and other bytes are written as \DDD.
+func unescapeTXT(txt []string) []string {
+	res := make([]string, len(txt))
+
+	for i, s := range txt {
+		var sb strings.Builder
+
+		for j := 0; j < len(s); j++ {
+			if s[j] != '\\' || j+1 >= len(s) {
+				sb.WriteByte(s[j])
+
+				continue
+			}
+
+			if j+3 < len(s) {
+				if v, err := strconv.ParseUint(s[j+1:j+4], 10, 8); err == nil {
+					sb.WriteByte(byte(v))
+
+					j += 3
+
+					continue
+				}
+			}
+
+			sb.WriteByte(s[j+1])
+
+			j++
+		}
+
+		res[i] = sb.String()
+	}
+
+	return res
+}
@@ -0,0 +1,144 @@
+package discovery
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	dsdm "github.com/csnewman/dyndirect/go"
+)
+
+var ErrInvalidService = errors.New("invalid service")
+
+const (
+	// Service is the DNS-SD service type media servers are advertised under.
+	Service = "_cathode._tcp"
+	// Domain is the mDNS domain.
+	Domain = "local."
+)
+
+// TXT record keys.
+const (
+	KeyID      = "id"
+	KeyName    = "name"
+	KeyVersion = "version"
+	KeyPort    = "port"
+	KeyDomain  = "domain"
+	// KeyTLS is "1" when the port serves HTTPS, and "0" when it serves plain HTTP.
+	KeyTLS = "tls"
+)
+
+var (
+	mdnsGroupV4 = &net.UDPAddr{IP: net.IPv4(224, 0, 0, 251), Port: 5353}
+	mdnsGroupV6 = &net.UDPAddr{IP: net.ParseIP("ff02::fb"), Port: 5353}
+)
+
+// Server is a media server advertised on the local network.
+type Server struct {
+	// ID stays the same across restarts and address changes, so identifies the server.
+	ID      string
+	Name    string
+	Version string
+	Port    int
+	// Domain is the DynDirect domain the server has a certificate for. Empty when it has none.
+	Domain string
+	TLS    bool
+	// Addrs are the addresses the server advertised for itself.
+	Addrs []netip.Addr
+}
+
+// URLs returns the URLs the server may be reached on, using DynDirect hostnames when it has a DynDirect domain.
+func (s Server) URLs() []string {
+	scheme := "http"
+	if s.TLS {
+		scheme = "https"
+	}
+
+	port := strconv.Itoa(s.Port)
+	res := make([]string, 0, len(s.Addrs))
+
+	for _, addr := range s.Addrs {
+		host := addr.String()
+
+		if s.TLS && s.Domain != "" {
+			host = dsdm.GetDomainForIP(s.Domain, addr.AsSlice())
+		}
+
+		res = append(res, fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(host, port)))
+	}
+
+	return res
+}
+
+// TXT returns the TXT record strings describing the server.
+func (s Server) TXT() []string {
+	txt := []string{
+		KeyID + "=" + s.ID,
+		KeyName + "=" + s.Name,
+		KeyVersion + "=" + s.Version,
+		KeyPort + "=" + strconv.Itoa(s.Port),
+		KeyTLS + "=" + boolTXT(s.TLS),
+	}
+
+	if s.Domain != "" {
+		txt = append(txt, KeyDomain+"="+s.Domain)
+	}
+
+	return txt
+}
+
+// ParseTXT fills the fields of the server held in TXT record strings. Unknown keys are ignored, so that later versions
+// can add to the record.
+func (s *Server) ParseTXT(txt []string) error {
+	for _, entry := range txt {
+		key, value, _ := strings.Cut(entry, "=")
+
+		switch strings.ToLower(key) {
+		case KeyID:
+			s.ID = value
+		case KeyName:
+			s.Name = value
+		case KeyVersion:
+			s.Version = value
+		case KeyPort:
+			port, err := strconv.Atoi(value)
+			if err != nil || port < 1 || port > 65535 {
+				return fmt.Errorf("%w: invalid port %q", ErrInvalidService, value)
+			}
+
+			s.Port = port
+		case KeyDomain:
+			s.Domain = value
+		case KeyTLS:
+			s.TLS = value == "1"
+		}
+	}
+
+	if s.ID == "" {
+		return fmt.Errorf("%w: missing id", ErrInvalidService)
+	}
+
+	return nil
+}
+
+func boolTXT(b bool) string {
+	if b {
+		return "1"
+	}
+
+	return "0"
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, ".", `\.`)
+
+// instanceName returns the service instance name of a server. The name is escaped so that it stays a single label.
+func instanceName(name string) string {
+	return labelEscaper.Replace(name) + "." + serviceName()
+}
+
+func serviceName() string {
+	return Service + "." + Domain
+}
@@ -0,0 +1,130 @@
+package discovery
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// mdnsConn is a UDP socket used for mDNS on every multicast interface.
+type mdnsConn struct {
+	conn  *net.UDPConn
+	group *net.UDPAddr
+	p4    *ipv4.PacketConn
+	p6    *ipv6.PacketConn
+}
+
+// listenMDNS listens on the mDNS port of a network, udp4 or udp6, joining the group on every multicast interface.
+func listenMDNS(network string) (*mdnsConn, error) {
+	group := mdnsGroupV4
+	if network == "udp6" {
+		group = mdnsGroupV6
+	}
+
+	// Address reuse is enabled, so that other responders on the host keep working
+	conn, err := net.ListenMulticastUDP(network, nil, group)
+	if err != nil {
+		return nil, err
+	}
+
+	c := newMDNSConn(conn, network)
+
+	for _, ifi := range multicastInterfaces() {
+		c.join(&ifi)
+	}
+
+	return c, nil
+}
+
+// dialMDNS opens a socket on an ephemeral port for sending queries, with answers unicast back to it.
+func dialMDNS(network string) (*mdnsConn, error) {
+	conn, err := net.ListenUDP(network, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return newMDNSConn(conn, network), nil
+}
+
+func newMDNSConn(conn *net.UDPConn, network string) *mdnsConn {
+	if network == "udp6" {
+		return &mdnsConn{conn: conn, group: mdnsGroupV6, p6: ipv6.NewPacketConn(conn)}
+	}
+
+	return &mdnsConn{conn: conn, group: mdnsGroupV4, p4: ipv4.NewPacketConn(conn)}
+}
+
+func (c *mdnsConn) join(ifi *net.Interface) {
+	// Interfaces without an address of the family cannot join, which is expected
+	if c.p6 != nil {
+		_ = c.p6.JoinGroup(ifi, c.group)
+	} else {
+		_ = c.p4.JoinGroup(ifi, c.group)
+	}
+}
+
+// multicast sends a packet to the group on every multicast interface, succeeding if any send does.
+func (c *mdnsConn) multicast(b []byte) error {
+	ifis := multicastInterfaces()
+	if len(ifis) == 0 {
+		_, err := c.conn.WriteToUDP(b, c.group)
+
+		return err
+	}
+
+	var (
+		errs []error
+		sent bool
+	)
+
+	for _, ifi := range ifis {
+		var err error
+
+		if c.p6 != nil {
+			err = c.p6.SetMulticastInterface(&ifi)
+		} else {
+			err = c.p4.SetMulticastInterface(&ifi)
+		}
+
+		if err == nil {
+			_, err = c.conn.WriteToUDP(b, c.group)
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%v: %w", ifi.Name, err))
+		} else {
+			sent = true
+		}
+	}
+
+	if sent {
+		return nil
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *mdnsConn) Close() error {
+	return c.conn.Close()
+}
+
+// multicastInterfaces returns the interfaces that are up and support multicast.
+func multicastInterfaces() []net.Interface {
+	ifis, err := net.Interfaces()
+	if err != nil {
+		return nil
+	}
+
+	var res []net.Interface
+
+	for _, ifi := range ifis {
+		if ifi.Flags&net.FlagUp != 0 && ifi.Flags&net.FlagMulticast != 0 {
+			res = append(res, ifi)
+		}
+	}
+
+	return res
+}
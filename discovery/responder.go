@@ -0,0 +1,317 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+const (
+	// hostTTL and serviceTTL are the record TTLs recommended by RFC 6762.
+	hostTTL    = 120
+	serviceTTL = 4500
+	// legacyTTL caps TTLs in answers to queries from ordinary resolvers, which do not track changes.
+	legacyTTL = 10
+
+	// cacheFlush marks records the responder is authoritative for, replacing any cached by clients.
+	cacheFlush = 1 << 15
+	// unicastResponse is set on questions that ask for a unicast answer.
+	unicastResponse = 1 << 15
+
+	mdnsPort = 5353
+
+	// announceInterval separates the announcements sent on start, as RFC 6762 requires at least two.
+	announceInterval = time.Second
+)
+
+// servicesName is queried by browsers enumerating every service type on the network.
+const servicesName = "_services._dns-sd._udp." + Domain
+
+// Responder answers mDNS queries for a server, advertising it as an instance of Service.
+type Responder struct {
+	info func() Server
+	host string
+}
+
+// NewResponder creates a responder advertising the server returned by info. Info is called for every answer, so
+// changes such as a new DynDirect domain are advertised as they happen. The ID must not change.
+func NewResponder(info func() Server) *Responder {
+	id := info().ID
+
+	// The host name is unique to the server, so that it cannot clash with the name of the machine
+	host := "cathode-" + strings.ToLower(id)
+	if len(host) > 16 {
+		host = host[:16]
+	}
+
+	return &Responder{
+		info: info,
+		host: host + "." + Domain,
+	}
+}
+
+// Run announces the server, then answers queries until the context is cancelled, when a goodbye is sent so that
+// browsers forget the server. An error is returned if mDNS cannot be used on any address family.
+func (r *Responder) Run(ctx context.Context) error {
+	var (
+		conns []*mdnsConn
+		errs  []error
+	)
+
+	for _, network := range []string{"udp4", "udp6"} {
+		c, err := listenMDNS(network)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%v: %w", network, err))
+
+			continue
+		}
+
+		conns = append(conns, c)
+	}
+
+	if len(conns) == 0 {
+		return errors.Join(errs...)
+	}
+
+	var wg sync.WaitGroup
+
+	for _, c := range conns {
+		wg.Add(1)
+
+		go func(c *mdnsConn) {
+			defer wg.Done()
+
+			r.serve(c)
+		}(c)
+	}
+
+	r.announce(conns, serviceTTL)
+
+	timer := time.NewTimer(announceInterval)
+
+	select {
+	case <-ctx.Done():
+		timer.Stop()
+	case <-timer.C:
+		r.announce(conns, serviceTTL)
+
+		<-ctx.Done()
+	}
+
+	r.announce(conns, 0)
+
+	for _, c := range conns {
+		_ = c.Close()
+	}
+
+	wg.Wait()
+
+	return nil
+}
+
+// announce multicasts every record of the server. A TTL of zero says goodbye.
+func (r *Responder) announce(conns []*mdnsConn, ttl uint32) {
+	s := r.info()
+
+	msg := new(dns.Msg)
+	msg.Response = true
+	msg.Authoritative = true
+	msg.Answer = append(msg.Answer, r.ptr(s), r.srv(s), r.txt(s))
+	msg.Answer = append(msg.Answer, r.addrs(s)...)
+
+	for _, rr := range msg.Answer {
+		rr.Header().Ttl = min(rr.Header().Ttl, ttl)
+	}
+
+	b, err := msg.Pack()
+	if err != nil {
+		return
+	}
+
+	for _, c := range conns {
+		_ = c.multicast(b)
+	}
+}
+
+func (r *Responder) serve(c *mdnsConn) {
+	buf := make([]byte, 9000)
+
+	for {
+		n, from, err := c.conn.ReadFromUDP(buf)
+		if errors.Is(err, net.ErrClosed) {
+			return
+		} else if err != nil {
+			continue
+		}
+
+		var query dns.Msg
+
+		if err := query.Unpack(buf[:n]); err != nil || query.Response || query.Opcode != dns.OpcodeQuery {
+			continue
+		}
+
+		// Queries not sent from the mDNS port come from ordinary resolvers, which expect a conventional reply
+		legacy := from.Port != mdnsPort
+
+		res, unicast := r.answer(&query, legacy)
+		if res == nil {
+			continue
+		}
+
+		b, err := res.Pack()
+		if err != nil {
+			continue
+		}
+
+		if legacy || unicast {
+			_, _ = c.conn.WriteToUDP(b, from)
+		} else {
+			_ = c.multicast(b)
+		}
+	}
+}
+
+// answer builds the response to a query, returning nil when no question is about the server. Unicast is true when
+// every answered question asked for a unicast response.
+func (r *Responder) answer(query *dns.Msg, legacy bool) (*dns.Msg, bool) {
+	s := r.info()
+
+	instance := instanceName(s.Name)
+	service := serviceName()
+
+	res := new(dns.Msg)
+	res.Response = true
+	res.Authoritative = true
+
+	var (
+		answer, extra []dns.RR
+		unicast       = true
+	)
+
+	for _, q := range query.Question {
+		var rrs []dns.RR
+
+		switch {
+		case strings.EqualFold(q.Name, service) && matchType(q.Qtype, dns.TypePTR):
+			rrs = append(rrs, r.ptr(s))
+			extra = append(extra, r.srv(s), r.txt(s))
+			extra = append(extra, r.addrs(s)...)
+		case strings.EqualFold(q.Name, servicesName) && matchType(q.Qtype, dns.TypePTR):
+			rrs = append(rrs, &dns.PTR{
+				Hdr: dns.RR_Header{Name: servicesName, Rrtype: dns.TypePTR, Class: dns.ClassINET, Ttl: serviceTTL},
+				Ptr: service,
+			})
+		case strings.EqualFold(q.Name, instance):
+			if matchType(q.Qtype, dns.TypeSRV) {
+				rrs = append(rrs, r.srv(s))
+				extra = append(extra, r.addrs(s)...)
+			}
+
+			if matchType(q.Qtype, dns.TypeTXT) {
+				rrs = append(rrs, r.txt(s))
+			}
+		case strings.EqualFold(q.Name, r.host):
+			for _, rr := range r.addrs(s) {
+				if matchType(q.Qtype, rr.Header().Rrtype) {
+					rrs = append(rrs, rr)
+				}
+			}
+		}
+
+		if len(rrs) > 0 {
+			answer = append(answer, rrs...)
+			unicast = unicast && q.Qclass&unicastResponse != 0
+		}
+	}
+
+	if len(answer) == 0 {
+		return nil, false
+	}
+
+	res.Answer = answer
+	res.Extra = extra
+
+	if legacy {
+		// Conventional replies echo the query, and must not carry the mDNS cache flush bit
+		res.Id = query.Id
+		res.Question = query.Question
+
+		for _, rr := range append(res.Answer, res.Extra...) {
+			rr.Header().Class &^= cacheFlush
+			rr.Header().Ttl = min(rr.Header().Ttl, legacyTTL)
+		}
+	}
+
+	return res, unicast
+}
+
+func matchType(qtype uint16, rrtype uint16) bool {
+	return qtype == rrtype || qtype == dns.TypeANY
+}
+
+func (r *Responder) ptr(s Server) dns.RR {
+	return &dns.PTR{
+		Hdr: dns.RR_Header{Name: serviceName(), Rrtype: dns.TypePTR, Class: dns.ClassINET, Ttl: serviceTTL},
+		Ptr: instanceName(s.Name),
+	}
+}
+
+func (r *Responder) srv(s Server) dns.RR {
+	return &dns.SRV{
+		Hdr: dns.RR_Header{
+			Name:   instanceName(s.Name),
+			Rrtype: dns.TypeSRV,
+			Class:  dns.ClassINET | cacheFlush,
+			Ttl:    hostTTL,
+		},
+		Port:   uint16(s.Port),
+		Target: r.host,
+	}
+}
+
+func (r *Responder) txt(s Server) dns.RR {
+	return &dns.TXT{
+		Hdr: dns.RR_Header{
+			Name:   instanceName(s.Name),
+			Rrtype: dns.TypeTXT,
+			Class:  dns.ClassINET | cacheFlush,
+			Ttl:    serviceTTL,
+		},
+		Txt: escapeTXT(s.TXT()),
+	}
+}
+
+func (r *Responder) addrs(s Server) []dns.RR {
+	res := make([]dns.RR, 0, len(s.Addrs))
+
+	for _, addr := range s.Addrs {
+		hdr := dns.RR_Header{Name: r.host, Class: dns.ClassINET | cacheFlush, Ttl: hostTTL}
+
+		if addr.Is4() {
+			hdr.Rrtype = dns.TypeA
+			res = append(res, &dns.A{Hdr: hdr, A: addr.AsSlice()})
+		} else {
+			hdr.Rrtype = dns.TypeAAAA
+			res = append(res, &dns.AAAA{Hdr: hdr, AAAA: addr.AsSlice()})
+		}
+	}
+
+	return res
+}
+
+// escapeTXT escapes TXT strings for miekg/dns, which parses escapes in them when packing.
+func escapeTXT(txt []string) []string {
+	res := make([]string, len(txt))
+
+	for i, s := range txt {
+		res[i] = strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+	}
+
+	return res
+}
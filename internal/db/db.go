@@ -33,7 +33,9 @@ func Open(path string) (*DB, error) {
 	pool, err := sqlitepool.NewPool(
 		path,
 		4,
-		func(_ sqliteh.DB) error { return nil },
+		func(db sqliteh.DB) error {
+			return sqlitepool.ExecScript(db, "PRAGMA foreign_keys = ON;")
+		},
 		nil,
 	)
 	if err != nil {
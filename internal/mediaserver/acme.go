@@ -0,0 +1,191 @@
+package mediaserver
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	dsdm "github.com/csnewman/dyndirect/go"
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/go-acme/lego/v4/certificate"
+	"github.com/go-acme/lego/v4/challenge/dns01"
+	"github.com/go-acme/lego/v4/lego"
+	llog "github.com/go-acme/lego/v4/log"
+	"github.com/go-acme/lego/v4/registration"
+)
+
+var errInvalidACMEOption = errors.New("invalid acme option")
+
+type acmeProvider string
+
+const (
+	acmeProviderZeroSSL            acmeProvider = dsdm.ProviderZeroSSL
+	acmeProviderLetsEncrypt        acmeProvider = "letsencrypt"
+	acmeProviderLetsEncryptStaging acmeProvider = "letsencrypt-staging"
+)
+
+// fallback returns the provider tried when issuance fails. Staging has none, as falling back to a production provider
+// would issue real certificates while testing.
+func (p acmeProvider) fallback() (acmeProvider, bool) {
+	switch p {
+	case acmeProviderZeroSSL:
+		return acmeProviderLetsEncrypt, true
+	case acmeProviderLetsEncrypt:
+		return acmeProviderZeroSSL, true
+	case acmeProviderLetsEncryptStaging:
+		return "", false
+	}
+
+	return "", false
+}
+
+func (p acmeProvider) validate() error {
+	switch p {
+	case acmeProviderZeroSSL, acmeProviderLetsEncrypt, acmeProviderLetsEncryptStaging:
+		return nil
+	}
+
+	return fmt.Errorf("%w: unknown provider %q", errInvalidACMEOption, p)
+}
+
+type acmeKeyType string
+
+const (
+	acmeKeyTypeEC256   acmeKeyType = "ec256"
+	acmeKeyTypeEC384   acmeKeyType = "ec384"
+	acmeKeyTypeRSA2048 acmeKeyType = "rsa2048"
+	acmeKeyTypeRSA4096 acmeKeyType = "rsa4096"
+)
+
+var acmeKeyTypes = map[acmeKeyType]certcrypto.KeyType{
+	acmeKeyTypeEC256:   certcrypto.EC256,
+	acmeKeyTypeEC384:   certcrypto.EC384,
+	acmeKeyTypeRSA2048: certcrypto.RSA2048,
+	acmeKeyTypeRSA4096: certcrypto.RSA4096,
+}
+
+func (k acmeKeyType) validate() error {
+	if _, ok := acmeKeyTypes[k]; !ok {
+		return fmt.Errorf("%w: unknown key type %q", errInvalidACMEOption, k)
+	}
+
+	return nil
+}
+
+// acmeIssueTimeout bounds a single certificate request, including account creation and the DNS challenge.
+const acmeIssueTimeout = 120 * time.Second
+
+type acmeUser struct {
+	email        string
+	registration *registration.Resource
+	key          crypto.PrivateKey
+}
+
+func (u *acmeUser) GetEmail() string {
+	return u.email
+}
+
+func (u *acmeUser) GetRegistration() *registration.Resource {
+	return u.registration
+}
+
+func (u *acmeUser) GetPrivateKey() crypto.PrivateKey {
+	return u.key
+}
+
+// acquireDSDMCertificate requests a wildcard certificate for a DSDM subdomain, returning the PEM encoded certificate
+// and key. ZeroSSL is handled by the DSDM client, while Let's Encrypt is requested directly, answering the DNS
+// challenge through the DSDM server.
+func acquireDSDMCertificate(
+	ctx context.Context,
+	dc *dsdm.Client,
+	sub *dsdm.SubdomainResponse,
+	provider acmeProvider,
+	keyType acmeKeyType,
+) ([]byte, []byte, error) {
+	kt, ok := acmeKeyTypes[keyType]
+	if !ok {
+		return nil, nil, keyType.validate()
+	}
+
+	var dirURL string
+
+	switch provider {
+	case acmeProviderZeroSSL:
+		cert, err := dc.AcquireCertificate(ctx, dsdm.AcquireCertificateRequest{
+			ID:         sub.Id,
+			Domain:     sub.Domain,
+			Token:      sub.Token,
+			Provider:   dsdm.ProviderZeroSSL,
+			KeyType:    kt,
+			Timeout:    acmeIssueTimeout,
+			SilenceLog: true,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return cert.Certificate, cert.PrivateKey, nil
+	case acmeProviderLetsEncrypt:
+		dirURL = lego.LEDirectoryProduction
+	case acmeProviderLetsEncryptStaging:
+		dirURL = lego.LEDirectoryStaging
+	default:
+		return nil, nil, provider.validate()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, acmeIssueTimeout)
+	defer cancel()
+
+	// Matches the DSDM client, which silences the global lego logger
+	llog.Logger = log.New(io.Discard, "", 0)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate account key: %w", err)
+	}
+
+	// Let's Encrypt does not require an email, and accounts are not reused
+	user := &acmeUser{key: key}
+
+	cfg := lego.NewConfig(user)
+	cfg.CADirURL = dirURL
+	cfg.Certificate.KeyType = kt
+
+	client, err := lego.NewClient(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create acme client: %w", err)
+	}
+
+	// The propagation check does not work against DSDM servers
+	err = client.Challenge.SetDNS01Provider(
+		dc.NewDNSChallengeProvider(ctx, sub.Id, sub.Token),
+		dns01.DisableCompletePropagationRequirement(),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user.registration, err = client.Registration.Register(registration.RegisterOptions{TermsOfServiceAgreed: true})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to register acme account: %w", err)
+	}
+
+	res, err := client.Certificate.Obtain(certificate.ObtainRequest{
+		Domains:                        []string{"*." + sub.Domain},
+		Bundle:                         true,
+		AlwaysDeactivateAuthorizations: true,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return res.Certificate, res.PrivateKey, nil
+}
@@ -0,0 +1,383 @@
+package mediaserver
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	dsdm "github.com/csnewman/dyndirect/go"
+)
+
+// addressScope describes where an address can be reached from.
+type addressScope string
+
+const (
+	addressScopeLoopback addressScope = "loopback"
+	// addressScopeLAN addresses are private, so can only be reached on the local network.
+	addressScopeLAN addressScope = "lan"
+	// addressScopePublic addresses are reachable from the internet, provided the port is forwarded.
+	addressScopePublic addressScope = "public"
+	// addressScopeDomain addresses use a configured ACME domain, which resolves however the user set it up.
+	addressScopeDomain addressScope = "domain"
+)
+
+// addressScopeOrder is the order addresses are listed in, from the most to the least direct.
+var addressScopeOrder = []addressScope{
+	addressScopeLoopback,
+	addressScopeLAN,
+	addressScopePublic,
+	addressScopeDomain,
+}
+
+// serverAddress is a URL the server may be reachable on.
+type serverAddress struct {
+	url   string
+	host  string
+	port  int
+	scope addressScope
+	// ip is zero for domain addresses.
+	ip    netip.Addr
+	tls   bool
+	http3 bool
+}
+
+// publicProbeInterval is how often the public addresses are rediscovered, as they change when ISPs reassign them.
+const publicProbeInterval = 30 * time.Minute
+
+// addressDiscovery tracks the public addresses of the server. Local addresses are read from the interfaces on each
+// lookup, so are always current.
+type addressDiscovery struct {
+	logger      *slog.Logger
+	stunServers []string
+
+	mu     sync.Mutex
+	public []netip.Addr
+	// mapped holds the external address of each listener port forwarded by the router.
+	mapped map[int]netip.AddrPort
+}
+
+func newAddressDiscovery(logger *slog.Logger, stunServers []string) *addressDiscovery {
+	return &addressDiscovery{
+		logger:      logger,
+		stunServers: stunServers,
+		mapped:      make(map[int]netip.AddrPort),
+	}
+}
+
+// Run probes the public addresses until the context is cancelled.
+func (d *addressDiscovery) Run(ctx context.Context) {
+	if len(d.stunServers) == 0 {
+		return
+	}
+
+	for {
+		d.refresh(ctx)
+
+		timer := time.NewTimer(publicProbeInterval)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// refresh probes the public address of each address family. The previous addresses are kept when every probe fails,
+// as that is more likely a transient outage than the addresses having gone.
+func (d *addressDiscovery) refresh(ctx context.Context) {
+	var (
+		found []netip.Addr
+		errs  []error
+	)
+
+	for _, network := range []string{"udp4", "udp6"} {
+		for _, server := range d.stunServers {
+			addr, err := stunProbe(ctx, network, server)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%v %v: %w", network, server, err))
+
+				continue
+			}
+
+			addr = addr.Unmap()
+
+			// A private address means the server is behind a NAT without a public address of this family
+			if addr.IsGlobalUnicast() && !addr.IsPrivate() {
+				found = append(found, addr)
+			}
+
+			break
+		}
+	}
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	if len(found) == 0 && len(errs) > 0 {
+		d.logger.Warn("Public address discovery failed", "err", errors.Join(errs...))
+
+		return
+	}
+
+	d.mu.Lock()
+	changed := !slices.Equal(d.public, found)
+	d.public = found
+	d.mu.Unlock()
+
+	if changed {
+		d.logger.Info("Public addresses discovered", "addresses", found)
+	}
+}
+
+// Public returns the last discovered public addresses.
+func (d *addressDiscovery) Public() []netip.Addr {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return slices.Clone(d.public)
+}
+
+// SetMapped records the external address a listener port is forwarded from, removing it when external is zero.
+func (d *addressDiscovery) SetMapped(port int, external netip.AddrPort) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if external.IsValid() {
+		d.mapped[port] = external
+	} else {
+		delete(d.mapped, port)
+	}
+}
+
+// Mapped returns the external address a listener port is forwarded from, if any.
+func (d *addressDiscovery) Mapped(port int) (netip.AddrPort, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	external, ok := d.mapped[port]
+
+	return external, ok
+}
+
+// localAddresses returns the addresses of the network interfaces. Link-local addresses are skipped, as IPv6 ones
+// need a zone that cannot be given in a hostname.
+func localAddresses() ([]netip.Addr, error) {
+	ifAddrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list interface addresses: %w", err)
+	}
+
+	var res []netip.Addr
+
+	for _, ifAddr := range ifAddrs {
+		prefix, err := netip.ParsePrefix(ifAddr.String())
+		if err != nil {
+			continue
+		}
+
+		addr := prefix.Addr().Unmap()
+
+		if addr.IsLinkLocalUnicast() || addr.IsMulticast() || addr.IsUnspecified() {
+			continue
+		}
+
+		res = append(res, addr)
+	}
+
+	return res, nil
+}
+
+func scopeOf(addr netip.Addr) addressScope {
+	switch {
+	case addr.IsLoopback():
+		return addressScopeLoopback
+	case addr.IsPrivate():
+		return addressScopeLAN
+	default:
+		return addressScopePublic
+	}
+}
+
+// Addresses returns the URLs each listener may be reached on, ordered from the most to the least direct. TLS
+// listeners use the DynDirect hostname of each address, or the address itself outside of dyndirect mode, along with
+// the configured ACME domains. Ports forwarded by the router are listed with their external address and port.
+func (m *NetworkManager) Addresses() ([]serverAddress, error) {
+	local, err := localAddresses()
+	if err != nil {
+		return nil, err
+	}
+
+	public := m.discovery.Public()
+	wildcards := m.certs.Wildcards(certSourceDSDM)
+
+	var res []serverAddress
+
+	seen := make(map[string]bool)
+
+	add := func(a serverAddress) {
+		if !seen[a.url] {
+			seen[a.url] = true
+
+			res = append(res, a)
+		}
+	}
+
+	for _, l := range m.listeners {
+		host, portStr, err := net.SplitHostPort(l.Address)
+		if err != nil {
+			continue
+		}
+
+		port, _ := strconv.Atoi(portStr)
+
+		var ips []netip.Addr
+
+		for _, ip := range local {
+			if listensOn(host, ip) {
+				ips = append(ips, ip)
+			}
+		}
+
+		// Public addresses reach the listener through port forwarding, whichever address of the family it is bound to
+		if !boundToLoopback(host) {
+			for _, ip := range public {
+				if forwardsTo(host, ip) {
+					ips = append(ips, ip)
+				}
+			}
+		}
+
+		addIP := func(ip netip.Addr, port int) {
+			a := serverAddress{
+				port:  port,
+				scope: scopeOf(ip),
+				ip:    ip,
+				tls:   !l.Plain,
+				http3: l.HTTP3,
+			}
+
+			if l.Plain || m.tls.Mode != TLSModeDynDirect {
+				a.host = ip.String()
+				add(a.withURL())
+			}
+
+			if l.Plain {
+				return
+			}
+
+			for _, domain := range wildcards {
+				a.host = dsdm.GetDomainForIP(domain, ip.AsSlice())
+				add(a.withURL())
+			}
+		}
+
+		for _, ip := range ips {
+			addIP(ip, port)
+		}
+
+		if external, ok := m.discovery.Mapped(port); ok && !l.Plain {
+			addIP(external.Addr(), int(external.Port()))
+		}
+
+		// ACME domains are assumed to reach every listener not bound to loopback
+		if l.Plain || !m.tls.ACME.Enabled() || boundToLoopback(host) {
+			continue
+		}
+
+		for _, domain := range m.tls.ACME.Domains {
+			if strings.HasPrefix(domain, "*.") {
+				continue
+			}
+
+			a := serverAddress{
+				host:  strings.ToLower(domain),
+				port:  port,
+				scope: addressScopeDomain,
+				tls:   true,
+				http3: l.HTTP3,
+			}
+
+			add(a.withURL())
+		}
+	}
+
+	slices.SortStableFunc(res, func(a, b serverAddress) int {
+		return cmp.Compare(slices.Index(addressScopeOrder, a.scope), slices.Index(addressScopeOrder, b.scope))
+	})
+
+	return res, nil
+}
+
+func (a serverAddress) withURL() serverAddress {
+	scheme := "http"
+	if a.tls {
+		scheme = "https"
+	}
+
+	a.url = fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(a.host, strconv.Itoa(a.port)))
+
+	return a
+}
+
+// listensOn reports whether a listener bound to host accepts connections on ip. IPv6 wildcards are assumed to be dual
+// stack, as they are by default on the supported platforms.
+func listensOn(host string, ip netip.Addr) bool {
+	switch host {
+	case "":
+		return true
+	case "localhost":
+		return ip.IsLoopback()
+	}
+
+	bound, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+
+	bound = bound.Unmap()
+
+	switch {
+	case bound == netip.IPv4Unspecified():
+		return ip.Is4()
+	case bound.IsUnspecified():
+		return true
+	default:
+		return bound == ip
+	}
+}
+
+func boundToLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+
+	ip, err := netip.ParseAddr(host)
+
+	return err == nil && ip.IsLoopback()
+}
+
+// forwardsTo reports whether a listener bound to host can receive connections forwarded from a public ip. Forwarding
+// keeps the address family, which only IPv6 wildcards accept both of.
+func forwardsTo(host string, ip netip.Addr) bool {
+	bound, err := netip.ParseAddr(host)
+	if err != nil {
+		return host == ""
+	}
+
+	bound = bound.Unmap()
+
+	return bound.Is4() == ip.Is4() || bound == netip.IPv6Unspecified()
+}
@@ -27,6 +27,14 @@ type v1API struct {
 	logger     *slog.Logger
 	router     *chi.Mux
 	wtUpgrader wtUpgrader
+	network    *NetworkManager
+	library    *LibraryManager
+	images     *ImageCache
+	auth       *AuthManager
+	watch      *WatchManager
+	playback   *PlaybackManager
+	plugins    *PluginManager
+	events     *EventBus
 }
 
 var errInvalidState = errors.New("invalid state")
@@ -40,39 +48,69 @@ func (a *v1API) ConnectPluginTransport(
 		return nil, fmt.Errorf("%w: rr missing", errInvalidState)
 	}
 
+	token, _ := bearerToken(r)
+
+	reg, err := a.plugins.Authenticate(ctx, token)
+	if errors.Is(err, errInvalidCredentials) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+
+		return v1.ConnectPluginTransport401JSONResponse{
+			Error:   "unauthorized",
+			Message: "A valid plugin token is required",
+		}, nil
+	} else if errors.Is(err, errPluginDisabled) {
+		return v1.ConnectPluginTransport403JSONResponse{
+			Error:   "plugin-disabled",
+			Message: "The plugin has been disabled",
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
 	s, err := a.wtUpgrader(w, r)
 	if err != nil {
 		return nil, fmt.Errorf("failed to upgrade session: %w", err)
 	}
 
-	a.logger.Info("upgraded!", "s", s.RemoteAddr())
-
-	go func() {
-		s, err := s.AcceptStream(context.Background())
-		a.logger.Info("accepted", "s", s, "err", err)
-
-		blob := make([]byte, 2)
-		s.Read(blob)
-
-		a.logger.Info("received", "b", string(blob))
-	}()
+	// The handshake completes before returning, after which the session outlives the request
+	a.plugins.Connect(w, r, s, reg)
 
 	return nil, nil
 }
 
-func newV1API(logger *slog.Logger, wtUpgrader wtUpgrader) (*v1API, error) {
+func newV1API(
+	logger *slog.Logger,
+	wtUpgrader wtUpgrader,
+	network *NetworkManager,
+	library *LibraryManager,
+	images *ImageCache,
+	auth *AuthManager,
+	watch *WatchManager,
+	playback *PlaybackManager,
+	plugins *PluginManager,
+	events *EventBus,
+) (*v1API, error) {
 	r := chi.NewRouter()
 
 	api := &v1API{
 		logger:     logger,
 		router:     r,
 		wtUpgrader: wtUpgrader,
+		network:    network,
+		library:    library,
+		images:     images,
+		auth:       auth,
+		watch:      watch,
+		playback:   playback,
+		plugins:    plugins,
+		events:     events,
 	}
 
 	r.Use(middleware.RequestID)
 	r.Use(api.httpLogger)
 	//r.Use(middleware.NoCache)
 	r.Use(api.httpRecoverer)
+	r.Use(api.authenticate)
 
 	spec, err := v1.GetSwagger()
 	if err != nil {
@@ -94,18 +132,29 @@ func newV1API(logger *slog.Logger, wtUpgrader wtUpgrader) (*v1API, error) {
 	r.Use(oapi.OapiRequestValidatorWithOptions(
 		spec,
 		&oapi.Options{
-			Options: openapi3filter.Options{},
-			ErrorHandler: func(w http.ResponseWriter, message string, _ int) {
-				api.logger.Error(
-					"API bad request",
-					"message", message,
-				)
-
+			Options: openapi3filter.Options{
+				AuthenticationFunc: authenticationFunc,
+			},
+			ErrorHandler: func(w http.ResponseWriter, message string, status int) {
 				v := &v1.ErrorResponse{
 					Error:   "bad-request",
 					Message: message,
 				}
 
+				if status == http.StatusUnauthorized {
+					v.Error = "unauthorized"
+					v.Message = "A valid session is required"
+
+					w.Header().Set("WWW-Authenticate", "Bearer")
+				} else {
+					status = http.StatusBadRequest
+
+					api.logger.Error(
+						"API bad request",
+						"message", message,
+					)
+				}
+
 				buf := &bytes.Buffer{}
 				enc := json.NewEncoder(buf)
 				enc.SetEscapeHTML(true)
@@ -114,7 +163,7 @@ func newV1API(logger *slog.Logger, wtUpgrader wtUpgrader) (*v1API, error) {
 				}
 
 				w.Header().Set("Content-Type", "application/json; charset=utf-8")
-				w.WriteHeader(http.StatusBadRequest)
+				w.WriteHeader(status)
 				_, _ = w.Write(buf.Bytes())
 			},
 			MultiErrorHandler: nil,
@@ -126,6 +175,7 @@ func newV1API(logger *slog.Logger, wtUpgrader wtUpgrader) (*v1API, error) {
 			api,
 			[]v1.StrictMiddlewareFunc{
 				requestResponseAccessorMiddleware,
+				authorizeMiddleware,
 			},
 			v1.StrictHTTPServerOptions{
 				RequestErrorHandlerFunc: func(w http.ResponseWriter, r *http.Request, err error) {
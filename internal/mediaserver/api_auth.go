@@ -0,0 +1,203 @@
+package mediaserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	v1 "github.com/csnewman/cathode/internal/v1"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/go-chi/chi/v5/middleware"
+	strictnethttp "github.com/oapi-codegen/runtime/strictmiddleware/nethttp"
+)
+
+var errUnauthenticated = errors.New("unauthenticated")
+
+type authKeyType string
+
+const authKey authKeyType = "ct-auth"
+
+const (
+	// accessTokenScheme is the security scheme of operations accepting the token in the URL, for browser APIs that
+	// cannot set headers.
+	accessTokenScheme = "accessToken"
+	accessTokenParam  = "access_token"
+)
+
+// authInfo is the session or device a request was made with.
+type authInfo struct {
+	token string
+	// fromQuery is set when the token was given in the URL rather than as a bearer token.
+	fromQuery bool
+	principal
+}
+
+// authenticate resolves the bearer token of a request, or failing that its access token parameter, if any. Requests
+// without a valid session are let through, as only the request validator knows which operations require one.
+func (a *v1API) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := bearerToken(r)
+		fromQuery := false
+
+		if !ok {
+			token = r.URL.Query().Get(accessTokenParam)
+			fromQuery = token != ""
+		}
+
+		if token == "" {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		p, err := a.auth.Authenticate(r.Context(), token)
+		if errors.Is(err, errInvalidCredentials) {
+			next.ServeHTTP(w, r)
+
+			return
+		} else if err != nil {
+			a.logger.Error(
+				"Failed to authenticate request",
+				"path", r.URL.Path,
+				"request_id", middleware.GetReqID(r.Context()),
+				"err", err,
+			)
+
+			writeResponse(w, r, http.StatusInternalServerError, "internal-error", "An internal server error has occurred")
+
+			return
+		}
+
+		info := &authInfo{
+			token:     token,
+			fromQuery: fromQuery,
+			principal: p,
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), authKey, info)))
+	})
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+
+	return token, true
+}
+
+func authFromCtx(ctx context.Context) (*authInfo, bool) {
+	info, ok := ctx.Value(authKey).(*authInfo)
+
+	return info, ok
+}
+
+// authenticationFunc rejects requests to operations requiring a session when none was provided. Tokens given in the
+// URL only satisfy the access token scheme, so are rejected by operations not listing it. Roles are checked later by
+// authorizeMiddleware, so that they can be rejected as forbidden rather than unauthorized.
+func authenticationFunc(_ context.Context, input *openapi3filter.AuthenticationInput) error {
+	info, ok := authFromCtx(input.RequestValidationInput.Request.Context())
+	if !ok || info.fromQuery != (input.SecuritySchemeName == accessTokenScheme) {
+		return errUnauthenticated
+	}
+
+	return nil
+}
+
+// authorizeMiddleware checks the session role against the roles an operation allows, which are the scopes of its
+// security requirement.
+func authorizeMiddleware(f strictnethttp.StrictHTTPHandlerFunc, _ string) strictnethttp.StrictHTTPHandlerFunc {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request, args any) (any, error) {
+		roles, ok := ctx.Value(v1.SessionScopes).([]string)
+		if !ok || len(roles) == 0 {
+			return f(ctx, w, r, args)
+		}
+
+		info, ok := authFromCtx(ctx)
+		if !ok || !slices.Contains(roles, string(info.user.role)) {
+			writeResponse(w, r, http.StatusForbidden, "forbidden", "This operation is not allowed for your role")
+
+			return nil, nil
+		}
+
+		return f(ctx, w, r, args)
+	}
+}
+
+func (a *v1API) Login(
+	ctx context.Context,
+	request v1.LoginRequestObject,
+) (v1.LoginResponseObject, error) {
+	r, _, ok := rrFromCtx(ctx)
+	if !ok {
+		return nil, fmt.Errorf("%w: rr missing", errInvalidState)
+	}
+
+	token, s, u, err := a.auth.Login(ctx, request.Body.Username, request.Body.Password, r.UserAgent())
+	if errors.Is(err, errInvalidCredentials) {
+		a.logger.Warn("Failed login", "user", request.Body.Username, "remote", r.RemoteAddr)
+
+		return v1.Login401JSONResponse{
+			Error:   "invalid-credentials",
+			Message: "The username or password is incorrect",
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return v1.Login200JSONResponse{
+		Token:   token,
+		Expires: s.expires,
+		User:    toAPIUser(u),
+	}, nil
+}
+
+func (a *v1API) Logout(
+	ctx context.Context,
+	_ v1.LogoutRequestObject,
+) (v1.LogoutResponseObject, error) {
+	info, ok := authFromCtx(ctx)
+	if !ok {
+		return nil, fmt.Errorf("%w: auth missing", errInvalidState)
+	}
+
+	var err error
+
+	// Logging out of a paired device unpairs it
+	if info.device.Valid {
+		err = a.auth.RevokeDevice(ctx, info.user, info.device.UUID)
+	} else {
+		err = a.auth.Logout(ctx, info.token)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return v1.Logout204Response{}, nil
+}
+
+func (a *v1API) GetCurrentUser(
+	ctx context.Context,
+	_ v1.GetCurrentUserRequestObject,
+) (v1.GetCurrentUserResponseObject, error) {
+	info, ok := authFromCtx(ctx)
+	if !ok {
+		return nil, fmt.Errorf("%w: auth missing", errInvalidState)
+	}
+
+	return v1.GetCurrentUser200JSONResponse(toAPIUser(info.user)), nil
+}
+
+func toAPIUser(u user) v1.User {
+	return v1.User{
+		Id:       u.id,
+		Username: u.username,
+		Role:     v1.UserRole(u.role),
+		Created:  u.created,
+	}
+}
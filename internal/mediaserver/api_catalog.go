@@ -0,0 +1,344 @@
+package mediaserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	v1 "github.com/csnewman/cathode/internal/v1"
+	"github.com/google/uuid"
+)
+
+func (a *v1API) ListLibraries(
+	ctx context.Context,
+	_ v1.ListLibrariesRequestObject,
+) (v1.ListLibrariesResponseObject, error) {
+	libs, err := a.library.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := v1.ListLibraries200JSONResponse{
+		Libraries: make([]v1.Library, 0, len(libs)),
+	}
+
+	for _, lib := range libs {
+		res.Libraries = append(res.Libraries, toAPILibrary(lib))
+	}
+
+	return res, nil
+}
+
+func (a *v1API) GetLibrary(
+	ctx context.Context,
+	request v1.GetLibraryRequestObject,
+) (v1.GetLibraryResponseObject, error) {
+	lib, err := a.library.Get(ctx, request.LibraryId)
+	if errors.Is(err, errNotFound) {
+		return v1.GetLibrary404JSONResponse{
+			Error:   "not-found",
+			Message: "Library not found",
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return v1.GetLibrary200JSONResponse(toAPILibrary(lib)), nil
+}
+
+func (a *v1API) ListMovies(
+	ctx context.Context,
+	request v1.ListMoviesRequestObject,
+) (v1.ListMoviesResponseObject, error) {
+	info, ok := authFromCtx(ctx)
+	if !ok {
+		return nil, fmt.Errorf("%w: auth missing", errInvalidState)
+	}
+
+	p := request.Params
+
+	q := newItemQuery(request.LibraryId, itemKindMovie, p.Genre, p.Year, p.AddedSince, p.Sort, p.Order, p.Limit)
+	q.userID = info.user.id
+	q.unwatched = deref(p.Unwatched)
+
+	page, err := a.library.Browse(ctx, q, deref(p.Cursor))
+	if errors.Is(err, errInvalidCursor) {
+		return v1.ListMovies400JSONResponse{
+			Error:   "invalid-cursor",
+			Message: "The cursor is invalid",
+		}, nil
+	} else if errors.Is(err, errNotFound) {
+		return v1.ListMovies404JSONResponse{
+			Error:   "not-found",
+			Message: "Library not found",
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	res := toAPIItemPage(page)
+
+	if err := a.withWatchState(ctx, res.Items); err != nil {
+		return nil, err
+	}
+
+	return v1.ListMovies200JSONResponse(res), nil
+}
+
+func (a *v1API) ListShows(
+	ctx context.Context,
+	request v1.ListShowsRequestObject,
+) (v1.ListShowsResponseObject, error) {
+	info, ok := authFromCtx(ctx)
+	if !ok {
+		return nil, fmt.Errorf("%w: auth missing", errInvalidState)
+	}
+
+	p := request.Params
+
+	q := newItemQuery(request.LibraryId, itemKindShow, p.Genre, p.Year, p.AddedSince, p.Sort, p.Order, p.Limit)
+	q.userID = info.user.id
+	q.unwatched = deref(p.Unwatched)
+
+	page, err := a.library.Browse(ctx, q, deref(p.Cursor))
+	if errors.Is(err, errInvalidCursor) {
+		return v1.ListShows400JSONResponse{
+			Error:   "invalid-cursor",
+			Message: "The cursor is invalid",
+		}, nil
+	} else if errors.Is(err, errNotFound) {
+		return v1.ListShows404JSONResponse{
+			Error:   "not-found",
+			Message: "Library not found",
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	res := toAPIItemPage(page)
+
+	if err := a.withWatchState(ctx, res.Items); err != nil {
+		return nil, err
+	}
+
+	return v1.ListShows200JSONResponse(res), nil
+}
+
+func (a *v1API) ListGenres(
+	ctx context.Context,
+	request v1.ListGenresRequestObject,
+) (v1.ListGenresResponseObject, error) {
+	genres, err := a.library.Genres(ctx, request.LibraryId)
+	if errors.Is(err, errNotFound) {
+		return v1.ListGenres404JSONResponse{
+			Error:   "not-found",
+			Message: "Library not found",
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return v1.ListGenres200JSONResponse{Genres: genres}, nil
+}
+
+func (a *v1API) ListSeasons(
+	ctx context.Context,
+	request v1.ListSeasonsRequestObject,
+) (v1.ListSeasonsResponseObject, error) {
+	items, err := a.library.Children(ctx, request.ItemId, itemKindShow)
+	if errors.Is(err, errNotFound) {
+		return v1.ListSeasons404JSONResponse{
+			Error:   "not-found",
+			Message: "Show not found",
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	res := v1.ListSeasons200JSONResponse{Items: toAPIItems(items)}
+
+	if err := a.withWatchState(ctx, res.Items); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (a *v1API) ListEpisodes(
+	ctx context.Context,
+	request v1.ListEpisodesRequestObject,
+) (v1.ListEpisodesResponseObject, error) {
+	items, err := a.library.Children(ctx, request.ItemId, itemKindSeason)
+	if errors.Is(err, errNotFound) {
+		return v1.ListEpisodes404JSONResponse{
+			Error:   "not-found",
+			Message: "Season not found",
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	res := v1.ListEpisodes200JSONResponse{Items: toAPIItems(items)}
+
+	if err := a.withWatchState(ctx, res.Items); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (a *v1API) GetItem(
+	ctx context.Context,
+	request v1.GetItemRequestObject,
+) (v1.GetItemResponseObject, error) {
+	it, err := a.library.Item(ctx, request.ItemId)
+	if errors.Is(err, errNotFound) {
+		return v1.GetItem404JSONResponse{
+			Error:   "not-found",
+			Message: "Item not found",
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	info, ok := authFromCtx(ctx)
+	if !ok {
+		return nil, fmt.Errorf("%w: auth missing", errInvalidState)
+	}
+
+	states, err := a.watch.States(ctx, info.user.id, map[uuid.UUID]itemKind{it.id: it.kind})
+	if err != nil {
+		return nil, err
+	}
+
+	return v1.GetItem200JSONResponse{
+		Id:               it.id,
+		LibraryId:        it.libraryID,
+		ParentId:         nullUUIDPtr(it.parentID),
+		Kind:             v1.ItemKind(it.kind),
+		Name:             it.name,
+		OriginalName:     it.originalName,
+		OriginalLanguage: it.originalLang,
+		Overview:         it.overview,
+		Year:             nonZero(it.year),
+		ReleaseDate:      nonZero(it.releaseDate),
+		SeasonNumber:     seasonNumber(it.item),
+		EpisodeNumber:    episodeNumber(it.item),
+		Status:           nonZero(it.status),
+		Genres:           it.genres,
+		Cast:             it.cast,
+		ProviderIds:      it.ids,
+		PosterImage:      nullUUIDPtr(it.images.poster),
+		BackdropImage:    nullUUIDPtr(it.images.backdrop),
+		LogoImage:        nullUUIDPtr(it.images.logo),
+		Added:            it.added,
+		WatchState:       toAPIWatchState(states[it.id]),
+	}, nil
+}
+
+// newItemQuery builds a query from the filters shared by the browse endpoints.
+func newItemQuery(
+	libraryID uuid.UUID,
+	kind itemKind,
+	genre *string,
+	year *int,
+	addedSince *time.Time,
+	sort *v1.ItemSort,
+	order *v1.SortOrder,
+	limit *int,
+) itemQuery {
+	q := itemQuery{
+		libraryID:  libraryID,
+		kind:       kind,
+		genre:      deref(genre),
+		year:       deref(year),
+		addedSince: deref(addedSince),
+		sort:       itemSortName,
+		desc:       deref(order) == v1.Desc,
+		limit:      deref(limit),
+	}
+
+	if sort != nil {
+		q.sort = itemSort(*sort)
+	}
+
+	return q
+}
+
+func toAPIItemPage(page itemPage) v1.ItemPage {
+	return v1.ItemPage{
+		Items:      toAPIItems(page.items),
+		NextCursor: nonZero(page.next),
+	}
+}
+
+func toAPIItems(items []catalogItem) []v1.Item {
+	res := make([]v1.Item, 0, len(items))
+
+	for _, it := range items {
+		res = append(res, toAPIItem(it))
+	}
+
+	return res
+}
+
+func toAPIItem(it catalogItem) v1.Item {
+	return v1.Item{
+		Id:            it.id,
+		Kind:          v1.ItemKind(it.kind),
+		Name:          it.name,
+		Year:          nonZero(it.year),
+		ReleaseDate:   nonZero(it.releaseDate),
+		SeasonNumber:  seasonNumber(it.item),
+		EpisodeNumber: episodeNumber(it.item),
+		PosterImage:   nullUUIDPtr(it.images.poster),
+		BackdropImage: nullUUIDPtr(it.images.backdrop),
+		LogoImage:     nullUUIDPtr(it.images.logo),
+		Added:         it.added,
+	}
+}
+
+// seasonNumber returns the season number of seasons and episodes. Specials are season zero.
+func seasonNumber(it item) *int {
+	if it.kind != itemKindSeason && it.kind != itemKindEpisode {
+		return nil
+	}
+
+	return &it.season
+}
+
+func episodeNumber(it item) *int {
+	if it.kind != itemKindEpisode {
+		return nil
+	}
+
+	return &it.episode
+}
+
+func nullUUIDPtr(v uuid.NullUUID) *uuid.UUID {
+	if !v.Valid {
+		return nil
+	}
+
+	return &v.UUID
+}
+
+func nonZero[T comparable](v T) *T {
+	var zero T
+	if v == zero {
+		return nil
+	}
+
+	return &v
+}
+
+func deref[T any](v *T) T {
+	if v == nil {
+		var zero T
+
+		return zero
+	}
+
+	return *v
+}
@@ -0,0 +1,155 @@
+package mediaserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	v1 "github.com/csnewman/cathode/internal/v1"
+)
+
+func (a *v1API) StartPairing(
+	ctx context.Context,
+	request v1.StartPairingRequestObject,
+) (v1.StartPairingResponseObject, error) {
+	r, _, ok := rrFromCtx(ctx)
+	if !ok {
+		return nil, fmt.Errorf("%w: rr missing", errInvalidState)
+	}
+
+	var linkKey []byte
+	if request.Body.LinkKey != nil {
+		linkKey = *request.Body.LinkKey
+	}
+
+	p, pollToken, err := a.auth.StartPairing(request.Body.DeviceName, linkKey, r.RemoteAddr)
+	if errors.Is(err, errInvalidLinkKey) {
+		return v1.StartPairing400JSONResponse{
+			Error:   "invalid-link-key",
+			Message: "The link key must be a base64 encoded X25519 public key",
+		}, nil
+	} else if errors.Is(err, errTooManyPairings) {
+		a.logger.Warn("Too many pairings", "remote", r.RemoteAddr)
+
+		return v1.StartPairing429JSONResponse{
+			Error:   "too-many-pairings",
+			Message: "Too many pairings are in progress from this address, try again later",
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return v1.StartPairing201JSONResponse{
+		Code:         formatPairingCode(p.code),
+		PollToken:    pollToken,
+		Expires:      p.expires,
+		PollInterval: int(pairingPollInterval.Seconds()),
+	}, nil
+}
+
+func (a *v1API) PollPairing(
+	_ context.Context,
+	request v1.PollPairingRequestObject,
+) (v1.PollPairingResponseObject, error) {
+	p, err := a.auth.PollPairing(request.Body.PollToken)
+	if errors.Is(err, errPairingPending) {
+		return v1.PollPairing202Response{}, nil
+	} else if errors.Is(err, errNotFound) {
+		return v1.PollPairing404JSONResponse{
+			Error:   "not-found",
+			Message: "The pairing does not exist or has expired",
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	res := v1.PollPairing200JSONResponse{
+		Token:  p.token,
+		Device: toAPIDevice(p.device),
+		User:   toAPIUser(p.user),
+	}
+
+	if p.serverLinkKey != nil {
+		res.LinkKey = &p.serverLinkKey
+	}
+
+	return res, nil
+}
+
+func (a *v1API) ApprovePairing(
+	ctx context.Context,
+	request v1.ApprovePairingRequestObject,
+) (v1.ApprovePairingResponseObject, error) {
+	info, ok := authFromCtx(ctx)
+	if !ok {
+		return nil, fmt.Errorf("%w: auth missing", errInvalidState)
+	}
+
+	d, err := a.auth.ApprovePairing(ctx, info.user, request.Body.Code)
+	if errors.Is(err, errNotFound) {
+		return v1.ApprovePairing404JSONResponse{
+			Error:   "not-found",
+			Message: "No pending pairing has this code",
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return v1.ApprovePairing200JSONResponse(toAPIDevice(d)), nil
+}
+
+func (a *v1API) ListDevices(
+	ctx context.Context,
+	_ v1.ListDevicesRequestObject,
+) (v1.ListDevicesResponseObject, error) {
+	info, ok := authFromCtx(ctx)
+	if !ok {
+		return nil, fmt.Errorf("%w: auth missing", errInvalidState)
+	}
+
+	devices, err := a.auth.Devices(ctx, info.user.id)
+	if err != nil {
+		return nil, err
+	}
+
+	res := v1.ListDevices200JSONResponse{
+		Devices: make([]v1.Device, 0, len(devices)),
+	}
+
+	for _, d := range devices {
+		res.Devices = append(res.Devices, toAPIDevice(d))
+	}
+
+	return res, nil
+}
+
+func (a *v1API) RevokeDevice(
+	ctx context.Context,
+	request v1.RevokeDeviceRequestObject,
+) (v1.RevokeDeviceResponseObject, error) {
+	info, ok := authFromCtx(ctx)
+	if !ok {
+		return nil, fmt.Errorf("%w: auth missing", errInvalidState)
+	}
+
+	err := a.auth.RevokeDevice(ctx, info.user, request.DeviceId)
+	if errors.Is(err, errNotFound) {
+		return v1.RevokeDevice404JSONResponse{
+			Error:   "not-found",
+			Message: "Device not found",
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return v1.RevokeDevice204Response{}, nil
+}
+
+func toAPIDevice(d device) v1.Device {
+	return v1.Device{
+		Id:       d.id,
+		Name:     d.name,
+		Created:  d.created,
+		LastSeen: d.lastSeen,
+	}
+}
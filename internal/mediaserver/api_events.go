@@ -0,0 +1,195 @@
+package mediaserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	v1 "github.com/csnewman/cathode/internal/v1"
+)
+
+// eventKeepAliveInterval is how often a comment is sent on an idle event stream, so that proxies do not close it.
+const eventKeepAliveInterval = 15 * time.Second
+
+func (a *v1API) StreamEvents(
+	ctx context.Context,
+	request v1.StreamEventsRequestObject,
+) (v1.StreamEventsResponseObject, error) {
+	info, ok := authFromCtx(ctx)
+	if !ok {
+		return nil, fmt.Errorf("%w: auth missing", errInvalidState)
+	}
+
+	r, w, ok := rrFromCtx(ctx)
+	if !ok {
+		return nil, fmt.Errorf("%w: rr missing", errInvalidState)
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, fmt.Errorf("%w: response cannot be flushed", errInvalidState)
+	}
+
+	var types []eventType
+	if request.Params.Types != nil {
+		for _, t := range *request.Params.Types {
+			types = append(types, eventType(t))
+		}
+	}
+
+	sub, err := a.events.Subscribe(userEventFilter(types, info.user))
+	if errors.Is(err, errEventsStopped) {
+		return v1.StreamEvents503JSONResponse{
+			Error:   "shutting-down",
+			Message: "The server is shutting down",
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	defer sub.Close()
+
+	// Streams run far longer than the write timeout meant for API responses. HTTP/3 has no such timeout, so does not
+	// support clearing it.
+	err = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return nil, fmt.Errorf("failed to clear write deadline: %w", err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		next, cancel := context.WithTimeout(r.Context(), eventKeepAliveInterval)
+		e, err := sub.Next(next)
+		cancel()
+
+		var msg []byte
+
+		if errors.Is(err, context.DeadlineExceeded) && r.Context().Err() == nil {
+			msg = []byte(": keep-alive\n\n")
+		} else if err != nil {
+			// The client has gone, or the server is shutting down
+			return nil, nil
+		} else if msg, err = formatServerSentEvent(e); err != nil {
+			return nil, err
+		}
+
+		if _, err := w.Write(msg); err != nil {
+			return nil, nil
+		}
+
+		flusher.Flush()
+	}
+}
+
+// userEventFilter matches events of the types, or of every type if there are none, that the user may see. Admins see
+// every event, while other users only see their own sessions.
+func userEventFilter(types []eventType, u user) func(event) bool {
+	return func(e event) bool {
+		if len(types) > 0 && !slices.Contains(types, e.typ) {
+			return false
+		}
+
+		if u.role == userRoleAdmin {
+			return true
+		}
+
+		switch d := e.data.(type) {
+		case sessionEvent:
+			return d.userID == u.id
+		case certificateEvent:
+			return false
+		default:
+			return true
+		}
+	}
+}
+
+func formatServerSentEvent(e event) ([]byte, error) {
+	data, err := json.Marshal(toAPIEvent(e))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf []byte
+
+	if e.id != 0 {
+		buf = fmt.Appendf(buf, "id: %d\n", e.id)
+	}
+
+	// JSON never contains raw newlines, so the data fits on a single line
+	buf = fmt.Appendf(buf, "event: %s\ndata: %s\n\n", e.typ, data)
+
+	return buf, nil
+}
+
+func toAPIEvent(e event) v1.ServerEvent {
+	res := v1.ServerEvent{
+		Type: v1.EventType(e.typ),
+		Time: e.time,
+	}
+
+	if e.id != 0 {
+		id := int64(e.id)
+		res.Id = &id
+	}
+
+	switch d := e.data.(type) {
+	case itemEvent:
+		res.Item = &v1.ItemEvent{
+			Id:        d.id,
+			LibraryId: d.libraryID,
+		}
+
+		if d.parentID.Valid {
+			res.Item.ParentId = &d.parentID.UUID
+		}
+
+		if d.kind != "" {
+			kind := v1.ItemKind(d.kind)
+			res.Item.Kind = &kind
+		}
+	case scanEvent:
+		res.Scan = &v1.ScanEvent{
+			LibraryId: d.libraryID,
+			State:     v1.ScanEventState(d.state),
+			Scanned:   d.scanned,
+			Imported:  d.imported,
+			Failed:    d.failed,
+			Missing:   d.missing,
+		}
+	case sessionEvent:
+		res.Session = &v1.SessionEvent{
+			Id:     d.id,
+			UserId: d.userID,
+			ItemId: d.itemID,
+			Plan:   v1.PlaybackPlan(d.plan),
+		}
+
+		if d.deviceID.Valid {
+			res.Session.DeviceId = &d.deviceID.UUID
+		}
+
+		if d.reason != "" {
+			reason := v1.SessionEventReason(d.reason)
+			res.Session.Reason = &reason
+		}
+	case certificateEvent:
+		res.Certificate = &v1.CertificateEvent{
+			Source:  v1.CertificateEventSource(d.source),
+			Domains: d.domains,
+			Expires: d.expires,
+		}
+	case droppedEvent:
+		res.Dropped = &v1.DroppedEvent{Count: d.count}
+	}
+
+	return res
+}
@@ -0,0 +1,85 @@
+package mediaserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	v1 "github.com/csnewman/cathode/internal/v1"
+)
+
+func (a *v1API) GetImage(
+	ctx context.Context,
+	request v1.GetImageRequestObject,
+) (v1.GetImageResponseObject, error) {
+	r, w, ok := rrFromCtx(ctx)
+	if !ok {
+		return nil, fmt.Errorf("%w: rr missing", errInvalidState)
+	}
+
+	format := imageFormatJPEG
+
+	if request.Params.Format != nil {
+		// The format is part of the cache key, so unknown values must not reach the cache
+		switch f := imageFormat(*request.Params.Format); f {
+		case imageFormatJPEG, imageFormatWebP:
+			format = f
+		default:
+			return v1.GetImage400JSONResponse{
+				Error:   "bad-request",
+				Message: "Unsupported image format",
+			}, nil
+		}
+	} else {
+		// The representation depends on the client, so shared caches must key on Accept
+		w.Header().Add("Vary", "Accept")
+
+		if strings.Contains(r.Header.Get("Accept"), "image/webp") {
+			format = imageFormatWebP
+		}
+	}
+
+	var width int
+	if request.Params.Width != nil {
+		width = *request.Params.Width
+	}
+
+	img, err := a.images.Get(ctx, request.ImageId, width, format)
+	if errors.Is(err, errNotFound) {
+		return v1.GetImage404JSONResponse{
+			Error:   "not-found",
+			Message: "Image not found",
+		}, nil
+	} else if errors.Is(err, errImageUnavailable) {
+		a.logger.Warn("Image unavailable", "id", request.ImageId, "err", err)
+
+		return v1.GetImage502JSONResponse{
+			Error:   "image-unavailable",
+			Message: "The image could not be downloaded",
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(img.path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	// Variants are derived from content addressed originals, so they never change for a given ETag. The URL may carry an
+	// access token, so only the client's own cache may keep them, and the ETag is independent of the token so
+	// revalidation still works once a new token is issued.
+	w.Header().Set("ETag", img.etag)
+	w.Header().Set("Cache-Control", "private, max-age=86400")
+	w.Header().Set("Content-Type", img.contentType)
+
+	http.ServeContent(w, r, "", time.Time{}, f)
+
+	return nil, nil
+}
@@ -0,0 +1,25 @@
+package mediaserver
+
+import (
+	"context"
+	"errors"
+
+	v1 "github.com/csnewman/cathode/internal/v1"
+)
+
+func (a *v1API) RefreshItem(
+	ctx context.Context,
+	request v1.RefreshItemRequestObject,
+) (v1.RefreshItemResponseObject, error) {
+	err := a.library.RequestRefresh(ctx, request.ItemId)
+	if errors.Is(err, errNotFound) {
+		return v1.RefreshItem404JSONResponse{
+			Error:   "not-found",
+			Message: "Item not found",
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return v1.RefreshItem202Response{}, nil
+}
@@ -0,0 +1,80 @@
+package mediaserver
+
+import (
+	"context"
+	"errors"
+
+	v1 "github.com/csnewman/cathode/internal/v1"
+)
+
+func (a *v1API) CreateLibrary(
+	ctx context.Context,
+	request v1.CreateLibraryRequestObject,
+) (v1.CreateLibraryResponseObject, error) {
+	online := true
+	if request.Body.OnlineLookup != nil {
+		online = *request.Body.OnlineLookup
+	}
+
+	lib, err := a.library.Create(ctx, request.Body.Name, request.Body.Root, online)
+	if errors.Is(err, errInvalidRoot) {
+		return v1.CreateLibrary400JSONResponse{
+			Error:   "invalid-root",
+			Message: err.Error(),
+		}, nil
+	} else if errors.Is(err, errLibraryExists) {
+		return v1.CreateLibrary409JSONResponse{
+			Error:   "library-exists",
+			Message: "A library already exists for this root",
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return v1.CreateLibrary201JSONResponse(toAPILibrary(lib)), nil
+}
+
+func (a *v1API) UpdateLibrary(
+	ctx context.Context,
+	request v1.UpdateLibraryRequestObject,
+) (v1.UpdateLibraryResponseObject, error) {
+	lib, err := a.library.Update(ctx, request.LibraryId, request.Body.Name, request.Body.OnlineLookup)
+	if errors.Is(err, errNotFound) {
+		return v1.UpdateLibrary404JSONResponse{
+			Error:   "not-found",
+			Message: "Library not found",
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return v1.UpdateLibrary200JSONResponse(toAPILibrary(lib)), nil
+}
+
+func (a *v1API) ScanLibrary(
+	ctx context.Context,
+	request v1.ScanLibraryRequestObject,
+) (v1.ScanLibraryResponseObject, error) {
+	if _, err := a.library.Get(ctx, request.LibraryId); errors.Is(err, errNotFound) {
+		return v1.ScanLibrary404JSONResponse{
+			Error:   "not-found",
+			Message: "Library not found",
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	a.library.RequestScan(request.LibraryId)
+
+	return v1.ScanLibrary202Response{}, nil
+}
+
+func toAPILibrary(lib library) v1.Library {
+	return v1.Library{
+		Id:           lib.id,
+		Name:         lib.name,
+		Root:         lib.root,
+		OnlineLookup: lib.onlineLookup,
+		Created:      lib.created,
+	}
+}
@@ -0,0 +1,90 @@
+package mediaserver
+
+import (
+	"context"
+	"errors"
+
+	v1 "github.com/csnewman/cathode/internal/v1"
+)
+
+func (a *v1API) ListDsdmServers(
+	ctx context.Context,
+	_ v1.ListDsdmServersRequestObject,
+) (v1.ListDsdmServersResponseObject, error) {
+	servers, err := a.network.DSDMServers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := v1.ListDsdmServers200JSONResponse{
+		Items: make([]v1.DSDMServer, 0, len(servers)),
+	}
+
+	for _, s := range servers {
+		res.Items = append(res.Items, toAPIDSDMServer(s))
+	}
+
+	return res, nil
+}
+
+func (a *v1API) SetDsdmServer(
+	ctx context.Context,
+	request v1.SetDsdmServerRequestObject,
+) (v1.SetDsdmServerResponseObject, error) {
+	server := dsdmServer{
+		server:   request.Body.Server,
+		provider: acmeProvider(request.Body.Provider),
+		keyType:  acmeKeyType(request.Body.KeyType),
+	}
+
+	err := a.network.SetDSDMServer(ctx, server)
+	if errors.Is(err, errInvalidACMEOption) {
+		return v1.SetDsdmServer400JSONResponse{
+			Error:   "invalid-server",
+			Message: err.Error(),
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return v1.SetDsdmServer200JSONResponse(toAPIDSDMServer(server)), nil
+}
+
+func toAPIDSDMServer(s dsdmServer) v1.DSDMServer {
+	return v1.DSDMServer{
+		Server:   s.server,
+		Provider: v1.ACMEProvider(s.provider),
+		KeyType:  v1.CertificateKeyType(s.keyType),
+	}
+}
+
+func (a *v1API) ListServerAddresses(
+	_ context.Context,
+	_ v1.ListServerAddressesRequestObject,
+) (v1.ListServerAddressesResponseObject, error) {
+	addrs, err := a.network.Addresses()
+	if err != nil {
+		return nil, err
+	}
+
+	res := v1.ListServerAddresses200JSONResponse{
+		Items: make([]v1.ServerAddress, 0, len(addrs)),
+	}
+
+	for _, addr := range addrs {
+		item := v1.ServerAddress{
+			Url:   addr.url,
+			Scope: v1.AddressScope(addr.scope),
+			Http3: addr.http3,
+		}
+
+		if addr.ip.IsValid() {
+			ip := addr.ip.String()
+			item.Ip = &ip
+		}
+
+		res.Items = append(res.Items, item)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,198 @@
+package mediaserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"time"
+
+	v1 "github.com/csnewman/cathode/internal/v1"
+)
+
+func (a *v1API) StartPlayback(
+	ctx context.Context,
+	request v1.StartPlaybackRequestObject,
+) (v1.StartPlaybackResponseObject, error) {
+	r, _, ok := rrFromCtx(ctx)
+	if !ok {
+		return nil, fmt.Errorf("%w: rr missing", errInvalidState)
+	}
+
+	info, ok := authFromCtx(ctx)
+	if !ok {
+		return nil, fmt.Errorf("%w: auth missing", errInvalidState)
+	}
+
+	s, err := a.playback.Start(ctx, info.principal, request.ItemId, request.Body.VideoCodecs, r.RemoteAddr)
+	if errors.Is(err, errNotPlayable) {
+		return v1.StartPlayback400JSONResponse{
+			Error:   "not-playable",
+			Message: "Only movies and episodes can be played",
+		}, nil
+	} else if errors.Is(err, errNotFound) {
+		return v1.StartPlayback404JSONResponse{
+			Error:   "not-found",
+			Message: "Item not found",
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	res := v1.StartPlayback201JSONResponse{
+		Id:   s.id,
+		Plan: v1.PlaybackPlan(s.plan),
+	}
+
+	if s.plan == playbackPlanTranscode {
+		manifest := fmt.Sprintf("/transcode/%v/manifest.m3u8", s.id)
+		res.Manifest = &manifest
+	} else {
+		stream := fmt.Sprintf("/items/%v/stream?session=%v", request.ItemId, s.id)
+		res.Stream = &stream
+	}
+
+	return res, nil
+}
+
+func (a *v1API) StreamItem(
+	ctx context.Context,
+	request v1.StreamItemRequestObject,
+) (v1.StreamItemResponseObject, error) {
+	r, w, ok := rrFromCtx(ctx)
+	if !ok {
+		return nil, fmt.Errorf("%w: rr missing", errInvalidState)
+	}
+
+	info, ok := authFromCtx(ctx)
+	if !ok {
+		return nil, fmt.Errorf("%w: auth missing", errInvalidState)
+	}
+
+	var session *playbackSession
+
+	if request.Params.Session != nil {
+		s, err := a.playback.Touch(*request.Params.Session, info.user.id, r.RemoteAddr)
+		if errors.Is(err, errSessionTerminated) {
+			return v1.StreamItem410JSONResponse{
+				Error:   "session-terminated",
+				Message: "Playback was stopped by an admin",
+			}, nil
+		} else if errors.Is(err, errNotFound) || (err == nil && s.item.id != request.ItemId) {
+			return v1.StreamItem404JSONResponse{
+				Error:   "not-found",
+				Message: "Session not found",
+			}, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		session = s
+	}
+
+	path, err := a.playback.File(ctx, request.ItemId)
+	if errors.Is(err, errNotPlayable) {
+		return v1.StreamItem400JSONResponse{
+			Error:   "not-playable",
+			Message: "Only movies and episodes can be played",
+		}, nil
+	} else if errors.Is(err, errNotFound) {
+		return v1.StreamItem404JSONResponse{
+			Error:   "not-found",
+			Message: "Item not found",
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return v1.StreamItem404JSONResponse{
+			Error:   "not-found",
+			Message: "The file of the item is missing",
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	// Streams run far longer than the write timeout meant for API responses. HTTP/3 has no such timeout, so does not
+	// support clearing it.
+	err = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return nil, fmt.Errorf("failed to clear write deadline: %w", err)
+	}
+
+	// A strong ETag allows clients to resume with If-Range, as it changes whenever the file is replaced
+	w.Header().Set("ETag", fmt.Sprintf(`"%x-%x"`, stat.Size(), stat.ModTime().UnixNano()))
+	w.Header().Set("Cache-Control", "private, no-cache")
+	w.Header().Set("Content-Type", containerContentType(path))
+
+	if session != nil {
+		session.serveContent(w, r, stat.ModTime(), f)
+	} else {
+		http.ServeContent(w, r, "", stat.ModTime(), f)
+	}
+
+	return nil, nil
+}
+
+func (a *v1API) ListPlaybackSessions(
+	_ context.Context,
+	_ v1.ListPlaybackSessionsRequestObject,
+) (v1.ListPlaybackSessionsResponseObject, error) {
+	sessions := a.playback.Sessions()
+
+	res := v1.ListPlaybackSessions200JSONResponse{
+		Items: make([]v1.PlaybackSessionStatus, 0, len(sessions)),
+	}
+
+	for _, s := range sessions {
+		st := v1.PlaybackSessionStatus{
+			Id:            s.id,
+			User:          toAPIUser(s.user),
+			DeviceId:      nullUUIDPtr(s.device),
+			DeviceName:    nonZero(s.deviceName),
+			Item:          toAPIItem(s.item),
+			Plan:          v1.PlaybackPlan(s.plan),
+			Started:       s.started,
+			LastActive:    s.lastActive,
+			RemoteAddress: s.remoteAddr,
+			Bandwidth:     s.bandwidth,
+		}
+
+		if s.transcode != nil {
+			st.TranscodeSpeed = &s.transcode.Speed
+			st.Segment = &s.transcode.Segment
+		}
+
+		res.Items = append(res.Items, st)
+	}
+
+	return res, nil
+}
+
+func (a *v1API) TerminatePlaybackSession(
+	_ context.Context,
+	request v1.TerminatePlaybackSessionRequestObject,
+) (v1.TerminatePlaybackSessionResponseObject, error) {
+	err := a.playback.Terminate(request.SessionId)
+	if errors.Is(err, errNotFound) {
+		return v1.TerminatePlaybackSession404JSONResponse{
+			Error:   "not-found",
+			Message: "Session not found",
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return v1.TerminatePlaybackSession204Response{}, nil
+}
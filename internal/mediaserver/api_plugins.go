@@ -0,0 +1,152 @@
+package mediaserver
+
+import (
+	"context"
+	"errors"
+
+	v1 "github.com/csnewman/cathode/internal/v1"
+)
+
+func (a *v1API) ListPlugins(
+	ctx context.Context,
+	_ v1.ListPluginsRequestObject,
+) (v1.ListPluginsResponseObject, error) {
+	plugins, err := a.plugins.Registrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := v1.ListPlugins200JSONResponse{
+		Plugins: make([]v1.Plugin, 0, len(plugins)),
+	}
+
+	for _, p := range plugins {
+		res.Plugins = append(res.Plugins, toAPIPlugin(p))
+	}
+
+	return res, nil
+}
+
+func (a *v1API) RegisterPlugin(
+	ctx context.Context,
+	request v1.RegisterPluginRequestObject,
+) (v1.RegisterPluginResponseObject, error) {
+	var publicKey []byte
+	if request.Body.PublicKey != nil {
+		publicKey = *request.Body.PublicKey
+	}
+
+	p, apiKey, err := a.plugins.Register(ctx, request.Body.Name, fromAPIScopes(request.Body.Scopes), publicKey)
+	if errors.Is(err, errInvalidPluginKey) {
+		return v1.RegisterPlugin400JSONResponse{
+			Error:   "invalid-public-key",
+			Message: "The public key must be a base64 encoded Ed25519 public key",
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	res := v1.RegisterPlugin201JSONResponse{
+		Plugin: toAPIPlugin(p),
+	}
+
+	if apiKey != "" {
+		res.ApiKey = &apiKey
+	}
+
+	return res, nil
+}
+
+func (a *v1API) UpdatePlugin(
+	ctx context.Context,
+	request v1.UpdatePluginRequestObject,
+) (v1.UpdatePluginResponseObject, error) {
+	u := pluginUpdate{
+		name:    request.Body.Name,
+		enabled: request.Body.Enabled,
+	}
+
+	if request.Body.Scopes != nil {
+		u.scopes = fromAPIScopes(*request.Body.Scopes)
+	}
+
+	p, err := a.plugins.Update(ctx, request.PluginId, u)
+	if errors.Is(err, errNotFound) {
+		return v1.UpdatePlugin404JSONResponse{
+			Error:   "not-found",
+			Message: "Plugin not found",
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return v1.UpdatePlugin200JSONResponse(toAPIPlugin(p)), nil
+}
+
+func (a *v1API) RevokePlugin(
+	ctx context.Context,
+	request v1.RevokePluginRequestObject,
+) (v1.RevokePluginResponseObject, error) {
+	err := a.plugins.Revoke(ctx, request.PluginId)
+	if errors.Is(err, errNotFound) {
+		return v1.RevokePlugin404JSONResponse{
+			Error:   "not-found",
+			Message: "Plugin not found",
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return v1.RevokePlugin204Response{}, nil
+}
+
+func toAPIPlugin(p pluginStatus) v1.Plugin {
+	res := v1.Plugin{
+		Id:      p.id,
+		Name:    p.name,
+		Scopes:  toAPIScopes(p.scopes),
+		Auth:    v1.ApiKey,
+		Enabled: p.enabled,
+		Created: p.created,
+	}
+
+	if len(p.publicKey) > 0 {
+		res.Auth = v1.SignedToken
+	}
+
+	if !p.lastSeen.IsZero() {
+		res.LastSeen = &p.lastSeen
+	}
+
+	if c := p.connection; c != nil {
+		res.Connection = &v1.PluginConnection{
+			Name:          c.info.Name,
+			Version:       c.info.Version,
+			Scopes:        toAPIScopes(c.info.Scopes),
+			RemoteAddress: c.peer.RemoteAddr().String(),
+			Connected:     c.connected,
+		}
+	}
+
+	return res
+}
+
+func toAPIScopes(scopes []string) []v1.PluginScope {
+	res := make([]v1.PluginScope, 0, len(scopes))
+
+	for _, s := range scopes {
+		res = append(res, v1.PluginScope(s))
+	}
+
+	return res
+}
+
+func fromAPIScopes(scopes []v1.PluginScope) []string {
+	res := make([]string, 0, len(scopes))
+
+	for _, s := range scopes {
+		res = append(res, string(s))
+	}
+
+	return res
+}
@@ -0,0 +1,49 @@
+package mediaserver
+
+import (
+	"context"
+
+	v1 "github.com/csnewman/cathode/internal/v1"
+	"github.com/google/uuid"
+)
+
+func (a *v1API) Search(
+	ctx context.Context,
+	request v1.SearchRequestObject,
+) (v1.SearchResponseObject, error) {
+	var libraryID uuid.NullUUID
+	if request.Params.LibraryId != nil {
+		libraryID = uuid.NullUUID{UUID: *request.Params.LibraryId, Valid: true}
+	}
+
+	res, err := a.library.Search(ctx, request.Params.Query, libraryID, deref(request.Params.Limit))
+	if err != nil {
+		return nil, err
+	}
+
+	var movies, tv []catalogItem
+
+	for _, it := range res.items {
+		if it.kind == itemKindMovie {
+			movies = append(movies, it)
+		} else {
+			tv = append(tv, it)
+		}
+	}
+
+	out := v1.Search200JSONResponse{
+		Movie: toAPIItems(movies),
+		Tv:    toAPIItems(tv),
+		Fuzzy: res.fuzzy,
+	}
+
+	if err := a.withWatchState(ctx, out.Movie); err != nil {
+		return nil, err
+	}
+
+	if err := a.withWatchState(ctx, out.Tv); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
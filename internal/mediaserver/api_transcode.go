@@ -2,22 +2,96 @@ package mediaserver
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	v1 "github.com/csnewman/cathode/internal/v1"
+	"github.com/google/uuid"
 )
 
 func (a *v1API) GetTranscodeManifestM3u8(
 	ctx context.Context,
 	request v1.GetTranscodeManifestM3u8RequestObject,
 ) (v1.GetTranscodeManifestM3u8ResponseObject, error) {
-	//TODO implement me
-	panic("implement me")
+	s, err := a.transcodeSession(ctx, request.TranscodeId)
+	if errors.Is(err, errSessionTerminated) {
+		return v1.GetTranscodeManifestM3u8410JSONResponse{
+			Error:   "session-terminated",
+			Message: "Playback was stopped by an admin",
+		}, nil
+	} else if errors.Is(err, errNotFound) {
+		return v1.GetTranscodeManifestM3u8404JSONResponse{
+			Error:   "not-found",
+			Message: "Transcode not found",
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	m := s.manifest()
+
+	return v1.GetTranscodeManifestM3u8200ApplicationvndAppleMpegurlResponse{
+		Body:          strings.NewReader(m),
+		ContentLength: int64(len(m)),
+	}, nil
 }
 
 func (a *v1API) GetTranscodeSegment(
 	ctx context.Context,
 	request v1.GetTranscodeSegmentRequestObject,
 ) (v1.GetTranscodeSegmentResponseObject, error) {
-	//TODO implement me
-	panic("implement me")
+	r, w, ok := rrFromCtx(ctx)
+	if !ok {
+		return nil, fmt.Errorf("%w: rr missing", errInvalidState)
+	}
+
+	s, err := a.transcodeSession(ctx, request.TranscodeId)
+	if errors.Is(err, errSessionTerminated) {
+		return v1.GetTranscodeSegment410JSONResponse{
+			Error:   "session-terminated",
+			Message: "Playback was stopped by an admin",
+		}, nil
+	} else if errors.Is(err, errNotFound) {
+		return v1.GetTranscodeSegment404JSONResponse{
+			Error:   "not-found",
+			Message: "Transcode not found",
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	path, ok := s.segmentPath(request.Segment)
+	if !ok {
+		return v1.GetTranscodeSegment404JSONResponse{
+			Error:   "not-found",
+			Message: "Segment not found",
+		}, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	w.Header().Set("Content-Type", "video/mp4")
+
+	s.serveContent(w, r, time.Time{}, f)
+
+	return nil, nil
+}
+
+// transcodeSession looks up a transcoding session, recording the request. Players cannot always send a token with
+// HLS requests, so knowing the session ID is enough.
+func (a *v1API) transcodeSession(ctx context.Context, id uuid.UUID) (*playbackSession, error) {
+	r, _, ok := rrFromCtx(ctx)
+	if !ok {
+		return nil, fmt.Errorf("%w: rr missing", errInvalidState)
+	}
+
+	return a.playback.TouchTranscode(id, r.RemoteAddr)
 }
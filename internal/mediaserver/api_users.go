@@ -0,0 +1,95 @@
+package mediaserver
+
+import (
+	"context"
+	"errors"
+
+	v1 "github.com/csnewman/cathode/internal/v1"
+)
+
+func (a *v1API) ListUsers(
+	ctx context.Context,
+	_ v1.ListUsersRequestObject,
+) (v1.ListUsersResponseObject, error) {
+	users, err := a.auth.Users(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := v1.ListUsers200JSONResponse{
+		Users: make([]v1.User, 0, len(users)),
+	}
+
+	for _, u := range users {
+		res.Users = append(res.Users, toAPIUser(u))
+	}
+
+	return res, nil
+}
+
+func (a *v1API) CreateUser(
+	ctx context.Context,
+	request v1.CreateUserRequestObject,
+) (v1.CreateUserResponseObject, error) {
+	u, err := a.auth.CreateUser(ctx, request.Body.Username, request.Body.Password, userRole(request.Body.Role))
+	if errors.Is(err, errUserExists) {
+		return v1.CreateUser409JSONResponse{
+			Error:   "user-exists",
+			Message: "A user with this username already exists",
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return v1.CreateUser201JSONResponse(toAPIUser(u)), nil
+}
+
+func (a *v1API) UpdateUser(
+	ctx context.Context,
+	request v1.UpdateUserRequestObject,
+) (v1.UpdateUserResponseObject, error) {
+	var role *userRole
+	if request.Body.Role != nil {
+		r := userRole(*request.Body.Role)
+		role = &r
+	}
+
+	u, err := a.auth.UpdateUser(ctx, request.UserId, request.Body.Password, role)
+	if errors.Is(err, errNotFound) {
+		return v1.UpdateUser404JSONResponse{
+			Error:   "not-found",
+			Message: "User not found",
+		}, nil
+	} else if errors.Is(err, errLastAdmin) {
+		return v1.UpdateUser409JSONResponse{
+			Error:   "last-admin",
+			Message: "At least one admin is required",
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return v1.UpdateUser200JSONResponse(toAPIUser(u)), nil
+}
+
+func (a *v1API) DeleteUser(
+	ctx context.Context,
+	request v1.DeleteUserRequestObject,
+) (v1.DeleteUserResponseObject, error) {
+	err := a.auth.DeleteUser(ctx, request.UserId)
+	if errors.Is(err, errNotFound) {
+		return v1.DeleteUser404JSONResponse{
+			Error:   "not-found",
+			Message: "User not found",
+		}, nil
+	} else if errors.Is(err, errLastAdmin) {
+		return v1.DeleteUser409JSONResponse{
+			Error:   "last-admin",
+			Message: "At least one admin is required",
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return v1.DeleteUser204Response{}, nil
+}
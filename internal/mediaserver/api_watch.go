@@ -0,0 +1,273 @@
+package mediaserver
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	v1 "github.com/csnewman/cathode/internal/v1"
+	"github.com/google/uuid"
+)
+
+func (a *v1API) ReportProgress(
+	ctx context.Context,
+	request v1.ReportProgressRequestObject,
+) (v1.ReportProgressResponseObject, error) {
+	info, ok := authFromCtx(ctx)
+	if !ok {
+		return nil, fmt.Errorf("%w: auth missing", errInvalidState)
+	}
+
+	b := request.Body
+
+	state, err := a.watch.ReportProgress(ctx, info.principal, request.ItemId, progressReport{
+		position:      secondsToDuration(b.Position),
+		duration:      secondsToDuration(b.Duration),
+		paused:        deref(b.Paused),
+		audioTrack:    nullIntFrom(b.AudioTrack),
+		subtitleTrack: nullIntFrom(b.SubtitleTrack),
+	})
+	if errors.Is(err, errNotPlayable) {
+		return v1.ReportProgress400JSONResponse{
+			Error:   "not-playable",
+			Message: "Only movies and episodes can be played",
+		}, nil
+	} else if errors.Is(err, errNotFound) {
+		return v1.ReportProgress404JSONResponse{
+			Error:   "not-found",
+			Message: "Item not found",
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	a.playback.KeepAlive(info.principal, request.ItemId)
+
+	return v1.ReportProgress200JSONResponse(*toAPIWatchState(itemWatch{watchState: state})), nil
+}
+
+func (a *v1API) MarkWatched(
+	ctx context.Context,
+	request v1.MarkWatchedRequestObject,
+) (v1.MarkWatchedResponseObject, error) {
+	info, ok := authFromCtx(ctx)
+	if !ok {
+		return nil, fmt.Errorf("%w: auth missing", errInvalidState)
+	}
+
+	state, err := a.watch.SetWatched(ctx, info.user.id, request.ItemId, true)
+	if errors.Is(err, errNotFound) {
+		return v1.MarkWatched404JSONResponse{
+			Error:   "not-found",
+			Message: "Item not found",
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return v1.MarkWatched200JSONResponse(*toAPIWatchState(state)), nil
+}
+
+func (a *v1API) MarkUnwatched(
+	ctx context.Context,
+	request v1.MarkUnwatchedRequestObject,
+) (v1.MarkUnwatchedResponseObject, error) {
+	info, ok := authFromCtx(ctx)
+	if !ok {
+		return nil, fmt.Errorf("%w: auth missing", errInvalidState)
+	}
+
+	state, err := a.watch.SetWatched(ctx, info.user.id, request.ItemId, false)
+	if errors.Is(err, errNotFound) {
+		return v1.MarkUnwatched404JSONResponse{
+			Error:   "not-found",
+			Message: "Item not found",
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return v1.MarkUnwatched200JSONResponse(*toAPIWatchState(state)), nil
+}
+
+func (a *v1API) ListHistory(
+	ctx context.Context,
+	request v1.ListHistoryRequestObject,
+) (v1.ListHistoryResponseObject, error) {
+	info, ok := authFromCtx(ctx)
+	if !ok {
+		return nil, fmt.Errorf("%w: auth missing", errInvalidState)
+	}
+
+	page, err := a.watch.History(ctx, info.user.id, deref(request.Params.Cursor), deref(request.Params.Limit))
+	if errors.Is(err, errInvalidCursor) {
+		return v1.ListHistory400JSONResponse{
+			Error:   "invalid-cursor",
+			Message: "The cursor is invalid",
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	items := make([]catalogItem, 0, len(page.entries))
+	for _, h := range page.entries {
+		items = append(items, page.items[h.itemID])
+	}
+
+	apiItems := toAPIItems(items)
+
+	if err := a.withWatchState(ctx, apiItems); err != nil {
+		return nil, err
+	}
+
+	res := v1.ListHistory200JSONResponse{
+		Entries:    make([]v1.HistoryEntry, 0, len(page.entries)),
+		NextCursor: nonZero(page.next),
+	}
+
+	for i, h := range page.entries {
+		res.Entries = append(res.Entries, v1.HistoryEntry{
+			Id:          h.id,
+			Item:        apiItems[i],
+			DeviceId:    nullUUIDPtr(h.deviceID),
+			Started:     h.started,
+			Updated:     h.updated,
+			Position:    h.position.Seconds(),
+			WatchedTime: h.watched.Seconds(),
+			Completed:   h.completed,
+		})
+	}
+
+	return res, nil
+}
+
+func (a *v1API) ContinueWatching(
+	ctx context.Context,
+	request v1.ContinueWatchingRequestObject,
+) (v1.ContinueWatchingResponseObject, error) {
+	info, ok := authFromCtx(ctx)
+	if !ok {
+		return nil, fmt.Errorf("%w: auth missing", errInvalidState)
+	}
+
+	p := request.Params
+
+	items, err := a.watch.ContinueWatching(ctx, info.user.id, nullUUID(p.LibraryId), deref(p.Limit))
+	if err != nil {
+		return nil, err
+	}
+
+	res := v1.ContinueWatching200JSONResponse{Items: toAPIItems(items)}
+
+	if err := a.withWatchState(ctx, res.Items); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (a *v1API) NextUp(
+	ctx context.Context,
+	request v1.NextUpRequestObject,
+) (v1.NextUpResponseObject, error) {
+	info, ok := authFromCtx(ctx)
+	if !ok {
+		return nil, fmt.Errorf("%w: auth missing", errInvalidState)
+	}
+
+	p := request.Params
+
+	items, err := a.watch.NextUp(ctx, info.user.id, nullUUID(p.LibraryId), deref(p.Limit))
+	if err != nil {
+		return nil, err
+	}
+
+	res := v1.NextUp200JSONResponse{Items: toAPIItems(items)}
+
+	if err := a.withWatchState(ctx, res.Items); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// withWatchState fills in the current user's progress through each item.
+func (a *v1API) withWatchState(ctx context.Context, items []v1.Item) error {
+	info, ok := authFromCtx(ctx)
+	if !ok {
+		return fmt.Errorf("%w: auth missing", errInvalidState)
+	}
+
+	kinds := make(map[uuid.UUID]itemKind, len(items))
+	for _, it := range items {
+		kinds[it.Id] = itemKind(it.Kind)
+	}
+
+	states, err := a.watch.States(ctx, info.user.id, kinds)
+	if err != nil {
+		return err
+	}
+
+	for i, it := range items {
+		items[i].WatchState = toAPIWatchState(states[it.Id])
+	}
+
+	return nil
+}
+
+func toAPIWatchState(w itemWatch) *v1.WatchState {
+	res := &v1.WatchState{
+		Watched:   w.watched,
+		PlayCount: w.playCount,
+	}
+
+	if w.kind == itemKindShow || w.kind == itemKindSeason {
+		res.UnwatchedEpisodes = &w.unwatched
+
+		return res
+	}
+
+	res.Position = nonZero(w.position.Seconds())
+	res.Duration = nonZero(w.duration.Seconds())
+
+	if !w.lastPlayed.IsZero() {
+		res.LastPlayed = &w.lastPlayed
+	}
+
+	res.AudioTrack = nullIntPtr(w.audioTrack)
+	res.SubtitleTrack = nullIntPtr(w.subtitleTrack)
+
+	return res
+}
+
+func secondsToDuration(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+func nullIntFrom(v *int) sql.NullInt64 {
+	if v == nil {
+		return sql.NullInt64{}
+	}
+
+	return sql.NullInt64{Int64: int64(*v), Valid: true}
+}
+
+func nullIntPtr(v sql.NullInt64) *int {
+	if !v.Valid {
+		return nil
+	}
+
+	i := int(v.Int64)
+
+	return &i
+}
+
+func nullUUID(v *uuid.UUID) uuid.NullUUID {
+	if v == nil {
+		return uuid.NullUUID{}
+	}
+
+	return uuid.NullUUID{UUID: *v, Valid: true}
+}
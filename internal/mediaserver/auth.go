@@ -0,0 +1,408 @@
+package mediaserver
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/csnewman/cathode/internal/db"
+	"github.com/google/uuid"
+)
+
+var (
+	errInvalidCredentials = errors.New("invalid credentials")
+	errUserExists         = errors.New("user already exists")
+	errLastAdmin          = errors.New("at least one admin is required")
+)
+
+const (
+	// sessionLifetime is how long a session stays valid without being used.
+	sessionLifetime = 30 * 24 * time.Hour
+	// sessionTouchInterval limits how often use of a session is written back, extending its lifetime.
+	sessionTouchInterval = time.Hour
+
+	initialAdminUsername = "admin"
+)
+
+type AuthManager struct {
+	logger *slog.Logger
+	db     *db.DB
+
+	// dummyHash is verified against when a username does not exist, so that logins take the same time either way.
+	dummyHash func() string
+
+	pairMu   sync.Mutex
+	pairings map[string]*pairing
+}
+
+func NewAuthManager(logger *slog.Logger, db *db.DB) *AuthManager {
+	return &AuthManager{
+		logger: logger,
+		db:     db,
+		dummyHash: sync.OnceValue(func() string {
+			hash, err := hashPassword(randomToken())
+			if err != nil {
+				panic(err)
+			}
+
+			return hash
+		}),
+		pairings: make(map[string]*pairing),
+	}
+}
+
+// Bootstrap creates an admin account with a random password when there are no users, so that a fresh server can be
+// configured. The password is only ever shown in the log.
+func (m *AuthManager) Bootstrap(ctx context.Context) error {
+	password := randomToken()
+
+	hash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	created := false
+
+	err = m.db.Write(ctx, func(ctx context.Context, tx db.WTx) error {
+		count, err := countUsers(ctx, tx, "")
+		if err != nil || count > 0 {
+			return err
+		}
+
+		created = true
+
+		return insertUser(ctx, tx, user{
+			id:           uuid.New(),
+			username:     initialAdminUsername,
+			passwordHash: hash,
+			role:         userRoleAdmin,
+			created:      time.Now().UTC().Truncate(time.Second),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	if created {
+		m.logger.Warn(
+			"Created initial admin user, change the password after logging in",
+			"username", initialAdminUsername,
+			"password", password,
+		)
+	}
+
+	return nil
+}
+
+// Login checks a username and password, starting a new session if they match. The returned token is only ever known
+// to the client, the database only stores its hash.
+func (m *AuthManager) Login(
+	ctx context.Context,
+	username string,
+	password string,
+	userAgent string,
+) (string, session, user, error) {
+	u, err := db.ReadWithData(ctx, m.db, func(ctx context.Context, tx db.RTx) (user, error) {
+		return getUserByName(ctx, tx, username)
+	})
+
+	hash := u.passwordHash
+	if errors.Is(err, errNotFound) {
+		hash = m.dummyHash()
+	} else if err != nil {
+		return "", session{}, user{}, err
+	}
+
+	ok, verr := verifyPassword(password, hash)
+	if verr != nil {
+		return "", session{}, user{}, verr
+	}
+
+	if err != nil || !ok {
+		return "", session{}, user{}, errInvalidCredentials
+	}
+
+	token := randomToken()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	s := session{
+		tokenHash: hashToken(token),
+		userID:    u.id,
+		lastSeen:  now,
+		expires:   now.Add(sessionLifetime),
+	}
+
+	err = m.db.Write(ctx, func(ctx context.Context, tx db.WTx) error {
+		if err := cleanupSessions(ctx, tx, now); err != nil {
+			return err
+		}
+
+		return insertSession(ctx, tx, s, userAgent)
+	})
+	if err != nil {
+		return "", session{}, user{}, err
+	}
+
+	m.logger.Info("User logged in", "user", u.username)
+
+	return token, s, u, nil
+}
+
+// Logout ends the session for a token.
+func (m *AuthManager) Logout(ctx context.Context, token string) error {
+	return m.db.Write(ctx, func(ctx context.Context, tx db.WTx) error {
+		return deleteSession(ctx, tx, hashToken(token))
+	})
+}
+
+// principal is who a request was authenticated as.
+type principal struct {
+	user user
+	// device is set when a device token was used rather than a session token.
+	device uuid.NullUUID
+}
+
+// Authenticate returns who a session or device token belongs to, recording that it was used.
+func (m *AuthManager) Authenticate(ctx context.Context, token string) (principal, error) {
+	now := time.Now().UTC().Truncate(time.Second)
+	hash := hashToken(token)
+
+	var (
+		s   session
+		d   device
+		res principal
+		err error
+	)
+
+	err = m.db.Read(ctx, func(ctx context.Context, tx db.RTx) error {
+		s, res.user, err = getSession(ctx, tx, hash, now)
+		if !errors.Is(err, errNotFound) {
+			return err
+		}
+
+		if d, err = getDeviceByToken(ctx, tx, hash); err != nil {
+			return err
+		}
+
+		res.device = uuid.NullUUID{UUID: d.id, Valid: true}
+		res.user, err = getUser(ctx, tx, d.userID)
+
+		return err
+	})
+	if errors.Is(err, errNotFound) {
+		return principal{}, errInvalidCredentials
+	} else if err != nil {
+		return principal{}, err
+	}
+
+	if res.device.Valid {
+		m.recordDeviceUse(ctx, res.user, d, now)
+
+		return res, nil
+	}
+
+	if now.Sub(s.lastSeen) < sessionTouchInterval {
+		return res, nil
+	}
+
+	s.lastSeen = now
+	s.expires = now.Add(sessionLifetime)
+
+	err = m.db.Write(ctx, func(ctx context.Context, tx db.WTx) error {
+		return touchSession(ctx, tx, s)
+	})
+	if err != nil {
+		m.logger.Warn("Failed to record token use", "user", res.user.username, "err", err)
+	}
+
+	return res, nil
+}
+
+// linkDevice is a device able to make requests over the link protocol.
+type linkDevice struct {
+	principal principal
+	device    device
+}
+
+// LinkDevice returns who a device belongs to along with its link secret, for requests made over the link protocol.
+// Devices that were paired without a link key cannot use it. Nothing is recorded, as requests must first be verified
+// with the secret, after which RecordLinkUse is called.
+func (m *AuthManager) LinkDevice(ctx context.Context, id uuid.UUID) (linkDevice, error) {
+	var res linkDevice
+
+	err := m.db.Read(ctx, func(ctx context.Context, tx db.RTx) error {
+		var err error
+
+		if res.device, err = getDevice(ctx, tx, id); err != nil {
+			return err
+		}
+
+		res.principal.device = uuid.NullUUID{UUID: res.device.id, Valid: true}
+		res.principal.user, err = getUser(ctx, tx, res.device.userID)
+
+		return err
+	})
+	if errors.Is(err, errNotFound) || (err == nil && res.device.linkSecret == nil) {
+		return linkDevice{}, errInvalidCredentials
+	} else if err != nil {
+		return linkDevice{}, err
+	}
+
+	return res, nil
+}
+
+// RecordLinkUse records that a device made a verified link request.
+func (m *AuthManager) RecordLinkUse(ctx context.Context, d linkDevice) {
+	m.recordDeviceUse(ctx, d.principal.user, d.device, time.Now().UTC().Truncate(time.Second))
+}
+
+// recordDeviceUse records that a device was used, at most once per sessionTouchInterval.
+func (m *AuthManager) recordDeviceUse(ctx context.Context, u user, d device, now time.Time) {
+	if now.Sub(d.lastSeen) < sessionTouchInterval {
+		return
+	}
+
+	err := m.db.Write(ctx, func(ctx context.Context, tx db.WTx) error {
+		return touchDevice(ctx, tx, d.id, now)
+	})
+	if err != nil {
+		m.logger.Warn("Failed to record device use", "user", u.username, "device", d.name, "err", err)
+	}
+}
+
+func (m *AuthManager) Users(ctx context.Context) ([]user, error) {
+	return db.ReadWithData(ctx, m.db, getUsers)
+}
+
+func (m *AuthManager) CreateUser(ctx context.Context, username string, password string, role userRole) (user, error) {
+	hash, err := hashPassword(password)
+	if err != nil {
+		return user{}, err
+	}
+
+	u := user{
+		id:           uuid.New(),
+		username:     username,
+		passwordHash: hash,
+		role:         role,
+		created:      time.Now().UTC().Truncate(time.Second),
+	}
+
+	err = m.db.Write(ctx, func(ctx context.Context, tx db.WTx) error {
+		_, err := getUserByName(ctx, tx, username)
+		if err == nil {
+			return fmt.Errorf("%w: %s", errUserExists, username)
+		} else if !errors.Is(err, errNotFound) {
+			return err
+		}
+
+		return insertUser(ctx, tx, u)
+	})
+	if err != nil {
+		return user{}, err
+	}
+
+	m.logger.Info("User created", "user", u.username, "role", u.role)
+
+	return u, nil
+}
+
+// UpdateUser changes the password or role of a user. Changing the password ends all of the user's sessions.
+func (m *AuthManager) UpdateUser(
+	ctx context.Context,
+	id uuid.UUID,
+	password *string,
+	role *userRole,
+) (user, error) {
+	var hash string
+
+	if password != nil {
+		var err error
+
+		if hash, err = hashPassword(*password); err != nil {
+			return user{}, err
+		}
+	}
+
+	return db.WriteWithData(ctx, m.db, func(ctx context.Context, tx db.WTx) (user, error) {
+		u, err := getUser(ctx, tx, id)
+		if err != nil {
+			return user{}, err
+		}
+
+		if role != nil && *role != userRoleAdmin && u.role == userRoleAdmin {
+			if err := checkNotLastAdmin(ctx, tx); err != nil {
+				return user{}, err
+			}
+		}
+
+		if role != nil {
+			u.role = *role
+		}
+
+		if password != nil {
+			u.passwordHash = hash
+
+			if err := deleteUserSessions(ctx, tx, id); err != nil {
+				return user{}, err
+			}
+		}
+
+		return u, updateUser(ctx, tx, u)
+	})
+}
+
+func (m *AuthManager) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	return m.db.Write(ctx, func(ctx context.Context, tx db.WTx) error {
+		u, err := getUser(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if u.role == userRoleAdmin {
+			if err := checkNotLastAdmin(ctx, tx); err != nil {
+				return err
+			}
+		}
+
+		return deleteUser(ctx, tx, id)
+	})
+}
+
+// checkNotLastAdmin prevents the server being left without anyone able to administer it.
+func checkNotLastAdmin(ctx context.Context, tx db.RTx) error {
+	count, err := countUsers(ctx, tx, userRoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	if count <= 1 {
+		return errLastAdmin
+	}
+
+	return nil
+}
+
+// randomToken returns 256 bits of randomness, encoded for use in headers and URLs.
+func randomToken() string {
+	buf := make([]byte, 32)
+
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,194 @@
+package mediaserver
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/csnewman/cathode/internal/db"
+	"github.com/google/uuid"
+)
+
+type userRole string
+
+const (
+	userRoleAdmin userRole = "admin"
+	userRoleUser  userRole = "user"
+	userRoleGuest userRole = "guest"
+)
+
+type user struct {
+	id           uuid.UUID
+	username     string
+	passwordHash string
+	role         userRole
+	created      time.Time
+}
+
+const userColumns = `id, username, password_hash, role, created`
+
+func scanUser(row interface{ Scan(dest ...any) error }) (user, error) {
+	var (
+		res     user
+		role    string
+		created string
+	)
+
+	if err := row.Scan(&res.id, &res.username, &res.passwordHash, &role, &created); err != nil {
+		return user{}, err
+	}
+
+	res.role = userRole(role)
+	res.created = parseSQLiteTime(created)
+
+	return res, nil
+}
+
+func getUsers(_ context.Context, tx db.RTx) ([]user, error) {
+	var users []user
+
+	rows, err := tx.Query(`SELECT ` + userColumns + ` FROM users ORDER BY username`)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		res, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, res)
+	}
+
+	return users, rows.Err()
+}
+
+func getUser(_ context.Context, tx db.RTx, id uuid.UUID) (user, error) {
+	res, err := scanUser(tx.QueryRow(`SELECT `+userColumns+` FROM users WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return user{}, fmt.Errorf("%w: user %v", errNotFound, id)
+	}
+
+	return res, err
+}
+
+func getUserByName(_ context.Context, tx db.RTx, username string) (user, error) {
+	res, err := scanUser(tx.QueryRow(`SELECT `+userColumns+` FROM users WHERE username = $1`, username))
+	if errors.Is(err, sql.ErrNoRows) {
+		return user{}, fmt.Errorf("%w: user %v", errNotFound, username)
+	}
+
+	return res, err
+}
+
+func countUsers(_ context.Context, tx db.RTx, role userRole) (int, error) {
+	var count int
+
+	err := tx.QueryRow(`SELECT COUNT(*) FROM users WHERE role = $1 OR $1 = ''`, string(role)).Scan(&count)
+
+	return count, err
+}
+
+func insertUser(_ context.Context, tx db.WTx, u user) error {
+	return tx.Exec(
+		`INSERT INTO users (id, username, password_hash, role, created) VALUES ($1, $2, $3, $4, $5)`,
+		u.id,
+		u.username,
+		u.passwordHash,
+		string(u.role),
+		u.created.UTC().Format(sqliteTimeLayout),
+	)
+}
+
+func updateUser(_ context.Context, tx db.WTx, u user) error {
+	return tx.Exec(
+		`UPDATE users SET password_hash = $1, role = $2 WHERE id = $3`,
+		u.passwordHash,
+		string(u.role),
+		u.id,
+	)
+}
+
+func deleteUser(_ context.Context, tx db.WTx, id uuid.UUID) error {
+	return tx.Exec(`DELETE FROM users WHERE id = $1`, id)
+}
+
+type session struct {
+	tokenHash string
+	userID    uuid.UUID
+	lastSeen  time.Time
+	expires   time.Time
+}
+
+func insertSession(_ context.Context, tx db.WTx, s session, userAgent string) error {
+	return tx.Exec(
+		`INSERT INTO sessions (token_hash, user_id, user_agent, created, last_seen, expires)
+		VALUES ($1, $2, $3, $4, $4, $5)`,
+		s.tokenHash,
+		s.userID,
+		userAgent,
+		s.lastSeen.UTC().Format(sqliteTimeLayout),
+		s.expires.UTC().Format(sqliteTimeLayout),
+	)
+}
+
+// getSession returns an unexpired session along with its user.
+func getSession(_ context.Context, tx db.RTx, tokenHash string, now time.Time) (session, user, error) {
+	var (
+		s        session
+		lastSeen string
+		expires  string
+		role     string
+		created  string
+		u        user
+	)
+
+	err := tx.QueryRow(`
+		SELECT s.last_seen, s.expires, u.id, u.username, u.password_hash, u.role, u.created
+		FROM sessions s
+		JOIN users u ON u.id = s.user_id
+		WHERE s.token_hash = $1 AND s.expires > $2`,
+		tokenHash,
+		now.UTC().Format(sqliteTimeLayout),
+	).Scan(&lastSeen, &expires, &u.id, &u.username, &u.passwordHash, &role, &created)
+	if errors.Is(err, sql.ErrNoRows) {
+		return session{}, user{}, fmt.Errorf("%w: session", errNotFound)
+	} else if err != nil {
+		return session{}, user{}, err
+	}
+
+	s.tokenHash = tokenHash
+	s.userID = u.id
+	s.lastSeen = parseSQLiteTime(lastSeen)
+	s.expires = parseSQLiteTime(expires)
+	u.role = userRole(role)
+	u.created = parseSQLiteTime(created)
+
+	return s, u, nil
+}
+
+func touchSession(_ context.Context, tx db.WTx, s session) error {
+	return tx.Exec(
+		`UPDATE sessions SET last_seen = $1, expires = $2 WHERE token_hash = $3`,
+		s.lastSeen.UTC().Format(sqliteTimeLayout),
+		s.expires.UTC().Format(sqliteTimeLayout),
+		s.tokenHash,
+	)
+}
+
+func deleteSession(_ context.Context, tx db.WTx, tokenHash string) error {
+	return tx.Exec(`DELETE FROM sessions WHERE token_hash = $1`, tokenHash)
+}
+
+func deleteUserSessions(_ context.Context, tx db.WTx, userID uuid.UUID) error {
+	return tx.Exec(`DELETE FROM sessions WHERE user_id = $1`, userID)
+}
+
+func cleanupSessions(_ context.Context, tx db.WTx, now time.Time) error {
+	return tx.Exec(`DELETE FROM sessions WHERE expires <= $1`, now.UTC().Format(sqliteTimeLayout))
+}
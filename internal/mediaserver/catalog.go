@@ -0,0 +1,154 @@
+package mediaserver
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/csnewman/cathode/internal/db"
+	"github.com/google/uuid"
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+// itemPage is a page of items, with a cursor for the following page if there is one.
+type itemPage struct {
+	items []catalogItem
+	next  string
+}
+
+// itemDetails is everything known about a single item.
+type itemDetails struct {
+	catalogItem
+	genres []string
+	cast   []string
+	ids    map[string]string
+}
+
+func (l *LibraryManager) List(ctx context.Context) ([]library, error) {
+	return db.ReadWithData(ctx, l.db, getLibraries)
+}
+
+// Browse returns a page of movies or shows from a library.
+func (l *LibraryManager) Browse(ctx context.Context, q itemQuery, cursor string) (itemPage, error) {
+	if cursor != "" {
+		c, err := decodeCursor(cursor)
+		if err != nil {
+			return itemPage{}, err
+		}
+
+		q.after = c
+	}
+
+	if q.limit <= 0 {
+		q.limit = defaultPageSize
+	}
+
+	q.limit = min(q.limit, maxPageSize)
+
+	return db.ReadWithData(ctx, l.db, func(ctx context.Context, tx db.RTx) (itemPage, error) {
+		if _, err := getLibrary(ctx, tx, q.libraryID); err != nil {
+			return itemPage{}, err
+		}
+
+		items, more, err := queryItems(ctx, tx, q)
+		if err != nil {
+			return itemPage{}, err
+		}
+
+		page := itemPage{items: items}
+
+		if more {
+			page.next = encodeCursor(items[len(items)-1].cursor(q.sort))
+		}
+
+		return page, nil
+	})
+}
+
+// Genres returns the genres used within a library.
+func (l *LibraryManager) Genres(ctx context.Context, libraryID uuid.UUID) ([]string, error) {
+	return db.ReadWithData(ctx, l.db, func(ctx context.Context, tx db.RTx) ([]string, error) {
+		if _, err := getLibrary(ctx, tx, libraryID); err != nil {
+			return nil, err
+		}
+
+		return getLibraryGenres(ctx, tx, libraryID)
+	})
+}
+
+// Children returns the seasons of a show or the episodes of a season. The parent must be of the given kind.
+func (l *LibraryManager) Children(ctx context.Context, parentID uuid.UUID, kind itemKind) ([]catalogItem, error) {
+	return db.ReadWithData(ctx, l.db, func(ctx context.Context, tx db.RTx) ([]catalogItem, error) {
+		parent, err := getItem(ctx, tx, parentID)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case kind == itemKindShow && parent.kind == itemKindShow:
+			return getSeasons(ctx, tx, parentID)
+		case kind == itemKindSeason && parent.kind == itemKindSeason:
+			return getEpisodes(ctx, tx, parentID)
+		default:
+			return nil, fmt.Errorf("%w: %v is not a %v", errNotFound, parentID, kind)
+		}
+	})
+}
+
+// Item returns the full details of an item.
+func (l *LibraryManager) Item(ctx context.Context, id uuid.UUID) (itemDetails, error) {
+	return db.ReadWithData(ctx, l.db, func(ctx context.Context, tx db.RTx) (itemDetails, error) {
+		var (
+			res itemDetails
+			err error
+		)
+
+		if res.catalogItem, err = getCatalogItem(ctx, tx, id); err != nil {
+			return itemDetails{}, err
+		}
+
+		if res.genres, err = getItemGenres(ctx, tx, id); err != nil {
+			return itemDetails{}, err
+		}
+
+		if res.cast, err = getItemCast(ctx, tx, id); err != nil {
+			return itemDetails{}, err
+		}
+
+		if res.ids, err = getItemIDs(ctx, tx, id); err != nil {
+			return itemDetails{}, err
+		}
+
+		return res, nil
+	})
+}
+
+func encodeCursor(c *itemCursor) string {
+	// Marshalling a struct of plain fields cannot fail
+	data, _ := json.Marshal(c)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(v string) (*itemCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(v)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidCursor, err)
+	}
+
+	var c itemCursor
+
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidCursor, err)
+	}
+
+	return &c, nil
+}
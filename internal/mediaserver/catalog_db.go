@@ -0,0 +1,393 @@
+package mediaserver
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/csnewman/cathode/internal/db"
+	"github.com/google/uuid"
+)
+
+type itemSort string
+
+const (
+	itemSortName     itemSort = "name"
+	itemSortYear     itemSort = "year"
+	itemSortAdded    itemSort = "added"
+	itemSortReleased itemSort = "released"
+)
+
+// catalogItem is an item along with the fields only needed when presenting it to clients.
+type catalogItem struct {
+	item
+	images itemImages
+	added  time.Time
+}
+
+// itemQuery filters and orders a page of top level items.
+type itemQuery struct {
+	libraryID  uuid.UUID
+	kind       itemKind
+	genre      string
+	year       int
+	addedSince time.Time
+	// userID is whose watch state the unwatched filter applies to.
+	userID    uuid.UUID
+	unwatched bool
+	sort      itemSort
+	desc      bool
+	after     *itemCursor
+	limit     int
+}
+
+// itemCursor identifies the last item of a page. Items are ordered by their sort key, then by id, so the cursor holds
+// both.
+type itemCursor struct {
+	Str string    `json:"s,omitempty"`
+	Num int64     `json:"n,omitempty"`
+	ID  uuid.UUID `json:"id"`
+}
+
+const catalogColumns = `poster_image, backdrop_image, logo_image`
+
+// showAddedExpr is when the latest available episode of a show was added, so shows with new episodes count as
+// recently added.
+const showAddedExpr = `(
+	SELECT MAX(e.added) FROM items s JOIN items e ON e.parent_id = s.id
+	WHERE s.parent_id = i.id AND e.missing_since IS NULL
+)`
+
+// showAvailableExpr matches shows that have at least one episode which is not missing.
+const showAvailableExpr = `EXISTS (
+	SELECT 1 FROM items s JOIN items e ON e.parent_id = s.id
+	WHERE s.parent_id = i.id AND e.missing_since IS NULL
+)`
+
+// sqlArgs collects positional arguments while building a query. Placeholders must be added in the order they appear in
+// the query text.
+type sqlArgs []any
+
+func (a *sqlArgs) add(v any) string {
+	*a = append(*a, v)
+
+	return fmt.Sprintf("$%d", len(*a))
+}
+
+// list adds each id, returning the placeholders separated by commas for use in an IN clause.
+func (a *sqlArgs) list(ids []uuid.UUID) string {
+	placeholders := make([]string, len(ids))
+
+	for i, id := range ids {
+		placeholders[i] = a.add(id)
+	}
+
+	return strings.Join(placeholders, ", ")
+}
+
+func scanCatalogItem(row interface{ Scan(dest ...any) error }) (catalogItem, error) {
+	var (
+		res   catalogItem
+		kind  string
+		added string
+	)
+
+	err := row.Scan(
+		&res.id,
+		&res.libraryID,
+		&res.parentID,
+		&kind,
+		&res.path,
+		&res.fileSize,
+		&res.fileMtime,
+		&res.name,
+		&res.originalName,
+		&res.originalLang,
+		&res.overview,
+		&res.releaseDate,
+		&res.year,
+		&res.season,
+		&res.episode,
+		&res.status,
+		&res.posterURL,
+		&res.backdropURL,
+		&res.logoURL,
+		&res.images.poster,
+		&res.images.backdrop,
+		&res.images.logo,
+		&added,
+	)
+	if err != nil {
+		return catalogItem{}, err
+	}
+
+	res.kind = itemKind(kind)
+	res.added = parseSQLiteTime(added)
+
+	return res, nil
+}
+
+// cursor returns the cursor positioned after this item for the given sort.
+func (c catalogItem) cursor(sort itemSort) *itemCursor {
+	res := &itemCursor{ID: c.id}
+
+	switch sort {
+	case itemSortYear:
+		res.Num = int64(c.year)
+	case itemSortAdded:
+		res.Str = c.added.UTC().Format(sqliteTimeLayout)
+	case itemSortReleased:
+		res.Str = c.releaseDate
+	default:
+		res.Str = c.name
+	}
+
+	return res
+}
+
+// queryItems returns up to limit items matching the query, along with whether more items follow.
+func queryItems(_ context.Context, tx db.RTx, q itemQuery) ([]catalogItem, bool, error) {
+	var (
+		args      sqlArgs
+		addedExpr = `i.added`
+		available = `i.missing_since IS NULL`
+	)
+
+	if q.kind == itemKindShow {
+		addedExpr = showAddedExpr
+		available = showAvailableExpr
+	}
+
+	var sortExpr string
+
+	switch q.sort {
+	case itemSortYear:
+		sortExpr = `COALESCE(i.year, 0)`
+	case itemSortAdded:
+		sortExpr = addedExpr
+	case itemSortReleased:
+		sortExpr = `i.release_date`
+	default:
+		sortExpr = `i.name COLLATE NOCASE`
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString(`SELECT ` + itemColumns + `, ` + catalogColumns + `, ` + addedExpr + ` FROM items i WHERE `)
+	sb.WriteString(`i.library_id = ` + args.add(q.libraryID))
+	sb.WriteString(` AND i.kind = ` + args.add(string(q.kind)))
+	sb.WriteString(` AND ` + available)
+
+	if q.genre != "" {
+		sb.WriteString(` AND EXISTS (SELECT 1 FROM item_genres g WHERE g.item_id = i.id AND g.genre = `)
+		sb.WriteString(args.add(q.genre) + ` COLLATE NOCASE)`)
+	}
+
+	if q.year != 0 {
+		sb.WriteString(` AND i.year = ` + args.add(q.year))
+	}
+
+	if q.unwatched {
+		user := args.add(q.userID)
+
+		watched := func(alias string) string {
+			return `SELECT 1 FROM watch_state w WHERE w.item_id = ` + alias + `.id AND w.user_id = ` + user +
+				` AND w.watched = 1`
+		}
+
+		if q.kind == itemKindShow {
+			// Shows are unwatched while any of their available episodes are
+			sb.WriteString(` AND EXISTS (
+				SELECT 1 FROM items s JOIN items e ON e.parent_id = s.id
+				WHERE s.parent_id = i.id AND e.missing_since IS NULL AND NOT EXISTS (` + watched("e") + `)
+			)`)
+		} else {
+			sb.WriteString(` AND NOT EXISTS (` + watched("i") + `)`)
+		}
+	}
+
+	if !q.addedSince.IsZero() {
+		sb.WriteString(` AND ` + addedExpr + ` >= ` + args.add(q.addedSince.UTC().Format(sqliteTimeLayout)))
+	}
+
+	dir, cmp := `ASC`, `>`
+	if q.desc {
+		dir, cmp = `DESC`, `<`
+	}
+
+	if q.after != nil {
+		var key any = q.after.Str
+		if q.sort == itemSortYear {
+			key = q.after.Num
+		}
+
+		sb.WriteString(` AND (` + sortExpr + ` ` + cmp + ` ` + args.add(key))
+		sb.WriteString(` OR (` + sortExpr + ` = ` + args.add(key))
+		sb.WriteString(` AND i.id ` + cmp + ` ` + args.add(q.after.ID) + `))`)
+	}
+
+	sb.WriteString(` ORDER BY ` + sortExpr + ` ` + dir + `, i.id ` + dir)
+
+	// Fetch one extra row to find out whether there is another page
+	sb.WriteString(` LIMIT ` + args.add(q.limit+1))
+
+	rows, err := tx.Query(sb.String(), args...)
+	if err != nil {
+		return nil, false, err
+	}
+
+	defer rows.Close()
+
+	var items []catalogItem
+
+	for rows.Next() {
+		res, err := scanCatalogItem(rows)
+		if err != nil {
+			return nil, false, err
+		}
+
+		items = append(items, res)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+
+	if len(items) > q.limit {
+		return items[:q.limit], true, nil
+	}
+
+	return items, false, nil
+}
+
+func getCatalogItem(_ context.Context, tx db.RTx, id uuid.UUID) (catalogItem, error) {
+	res, err := scanCatalogItem(tx.QueryRow(
+		`SELECT `+itemColumns+`, `+catalogColumns+`, added FROM items WHERE id = $1`,
+		id,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return catalogItem{}, fmt.Errorf("%w: item %v", errNotFound, id)
+	}
+
+	return res, err
+}
+
+// getCatalogItems returns the items with the given ids, in the same order. Ids that do not exist are skipped.
+func getCatalogItems(_ context.Context, tx db.RTx, ids []uuid.UUID) ([]catalogItem, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var args sqlArgs
+
+	query := `SELECT ` + itemColumns + `, ` + catalogColumns + `, added FROM items WHERE id IN (` + args.list(ids) + `)`
+
+	items, err := queryCatalogItems(tx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uuid.UUID]catalogItem, len(items))
+	for _, it := range items {
+		byID[it.id] = it
+	}
+
+	res := make([]catalogItem, 0, len(items))
+
+	for _, id := range ids {
+		if it, ok := byID[id]; ok {
+			res = append(res, it)
+		}
+	}
+
+	return res, nil
+}
+
+// getSeasons returns the seasons of a show which have available episodes.
+func getSeasons(_ context.Context, tx db.RTx, showID uuid.UUID) ([]catalogItem, error) {
+	return queryCatalogItems(tx, `
+		SELECT `+itemColumns+`, `+catalogColumns+`, added FROM items i
+		WHERE parent_id = $1 AND kind = $2
+		AND EXISTS (SELECT 1 FROM items e WHERE e.parent_id = i.id AND e.missing_since IS NULL)
+		ORDER BY season_number`,
+		showID,
+		string(itemKindSeason),
+	)
+}
+
+// getEpisodes returns the available episodes of a season.
+func getEpisodes(_ context.Context, tx db.RTx, seasonID uuid.UUID) ([]catalogItem, error) {
+	return queryCatalogItems(tx, `
+		SELECT `+itemColumns+`, `+catalogColumns+`, added FROM items
+		WHERE parent_id = $1 AND kind = $2 AND missing_since IS NULL
+		ORDER BY episode_number, name`,
+		seasonID,
+		string(itemKindEpisode),
+	)
+}
+
+func queryCatalogItems(tx db.RTx, query string, args ...any) ([]catalogItem, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var items []catalogItem
+
+	for rows.Next() {
+		res, err := scanCatalogItem(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, res)
+	}
+
+	return items, rows.Err()
+}
+
+func getItemGenres(_ context.Context, tx db.RTx, id uuid.UUID) ([]string, error) {
+	return queryStrings(tx, `SELECT genre FROM item_genres WHERE item_id = $1 ORDER BY genre`, id)
+}
+
+func getItemCast(_ context.Context, tx db.RTx, id uuid.UUID) ([]string, error) {
+	return queryStrings(tx, `SELECT name FROM item_cast WHERE item_id = $1 ORDER BY position`, id)
+}
+
+// getLibraryGenres returns the distinct genres of items in a library.
+func getLibraryGenres(_ context.Context, tx db.RTx, libraryID uuid.UUID) ([]string, error) {
+	return queryStrings(tx, `
+		SELECT DISTINCT g.genre FROM item_genres g
+		JOIN items i ON i.id = g.item_id
+		WHERE i.library_id = $1
+		ORDER BY g.genre COLLATE NOCASE`,
+		libraryID,
+	)
+}
+
+func queryStrings(tx db.RTx, query string, args ...any) ([]string, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	res := make([]string, 0)
+
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+
+		res = append(res, v)
+	}
+
+	return res, rows.Err()
+}
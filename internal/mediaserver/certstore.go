@@ -0,0 +1,183 @@
+package mediaserver
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// certSource identifies where certificates come from, so that each source can be replaced independently.
+type certSource string
+
+const (
+	// certSourceStatic holds the certificate of the files and self-signed modes.
+	certSourceStatic certSource = "static"
+	certSourceDSDM   certSource = "dsdm"
+	certSourceACME   certSource = "acme"
+)
+
+// certStore holds the certificates served over TLS. Lookups read an immutable snapshot, so handshakes never wait on
+// certificates being replaced.
+type certStore struct {
+	// mu serialises writers, which copy the current snapshot.
+	mu   sync.Mutex
+	snap atomic.Pointer[certSnapshot]
+}
+
+type certSnapshot struct {
+	sources map[certSource][]*tls.Certificate
+	// exact and wildcard map lower case names to certificates, with wildcard keyed by the name after the "*.".
+	exact    map[string]*tls.Certificate
+	wildcard map[string]*tls.Certificate
+	// fallback is served to clients that send no server name, or one no certificate covers.
+	fallback *tls.Certificate
+}
+
+func newCertStore() *certStore {
+	s := &certStore{}
+	s.snap.Store(newCertSnapshot(nil))
+
+	return s
+}
+
+// Replace serves certs in place of the existing certificates of a source.
+func (s *certStore) Replace(source certSource, certs []*tls.Certificate) error {
+	for _, cert := range certs {
+		if err := parseLeaf(cert); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sources := maps.Clone(s.snap.Load().sources)
+	sources[source] = slices.Clone(certs)
+
+	s.snap.Store(newCertSnapshot(sources))
+
+	return nil
+}
+
+// Add serves cert alongside the existing certificates of a source.
+func (s *certStore) Add(source certSource, cert *tls.Certificate) error {
+	if err := parseLeaf(cert); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sources := maps.Clone(s.snap.Load().sources)
+	sources[source] = append(slices.Clone(sources[source]), cert)
+
+	s.snap.Store(newCertSnapshot(sources))
+
+	return nil
+}
+
+// Get returns the certificate for a server name, falling back to the default certificate when none covers it. Nil is
+// returned when there are no certificates.
+func (s *certStore) Get(name string) *tls.Certificate {
+	snap := s.snap.Load()
+
+	name = strings.TrimSuffix(strings.ToLower(name), ".")
+
+	if cert, ok := snap.exact[name]; ok {
+		return cert
+	}
+
+	// Wildcards only cover a single label
+	if _, parent, ok := strings.Cut(name, "."); ok {
+		if cert, ok := snap.wildcard[parent]; ok {
+			return cert
+		}
+	}
+
+	return snap.fallback
+}
+
+// Wildcards returns the sorted domains covered by wildcard certificates of a source.
+func (s *certStore) Wildcards(source certSource) []string {
+	var res []string
+
+	for _, cert := range s.snap.Load().sources[source] {
+		for _, name := range cert.Leaf.DNSNames {
+			if domain, ok := strings.CutPrefix(strings.ToLower(name), "*."); ok && !slices.Contains(res, domain) {
+				res = append(res, domain)
+			}
+		}
+	}
+
+	slices.Sort(res)
+
+	return res
+}
+
+// newCertSnapshot indexes certificates by the names they cover. Where several certificates cover a name, the one
+// expiring last is used, as is the case for the default certificate.
+func newCertSnapshot(sources map[certSource][]*tls.Certificate) *certSnapshot {
+	if sources == nil {
+		sources = make(map[certSource][]*tls.Certificate)
+	}
+
+	snap := &certSnapshot{
+		sources:  sources,
+		exact:    make(map[string]*tls.Certificate),
+		wildcard: make(map[string]*tls.Certificate),
+	}
+
+	put := func(m map[string]*tls.Certificate, name string, cert *tls.Certificate) {
+		if cur, ok := m[name]; !ok || cert.Leaf.NotAfter.After(cur.Leaf.NotAfter) {
+			m[name] = cert
+		}
+	}
+
+	for _, certs := range sources {
+		for _, cert := range certs {
+			for _, name := range cert.Leaf.DNSNames {
+				name = strings.ToLower(name)
+
+				if domain, ok := strings.CutPrefix(name, "*."); ok {
+					put(snap.wildcard, domain, cert)
+				} else {
+					put(snap.exact, name, cert)
+				}
+			}
+
+			for _, ip := range cert.Leaf.IPAddresses {
+				put(snap.exact, ip.String(), cert)
+			}
+
+			if snap.fallback == nil || cert.Leaf.NotAfter.After(snap.fallback.Leaf.NotAfter) {
+				snap.fallback = cert
+			}
+		}
+	}
+
+	return snap
+}
+
+func parseLeaf(cert *tls.Certificate) error {
+	if cert.Leaf != nil {
+		return nil
+	}
+
+	if len(cert.Certificate) == 0 {
+		return fmt.Errorf("%w: empty certificate", errInvalidState)
+	}
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return fmt.Errorf("failed to parse cert leaf: %w", err)
+	}
+
+	cert.Leaf = leaf
+
+	return nil
+}
@@ -0,0 +1,212 @@
+package mediaserver
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testCertificate creates a self-signed certificate covering the names, which may include IP addresses.
+func testCertificate(t testing.TB, expires time.Time, names ...string) *tls.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     expires,
+	}
+
+	for _, name := range names {
+		if ip := net.ParseIP(name); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else {
+			tmpl.DNSNames = append(tmpl.DNSNames, name)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  key,
+	}
+}
+
+func certNames(cert *tls.Certificate) []string {
+	if cert == nil {
+		return nil
+	}
+
+	return cert.Leaf.DNSNames
+}
+
+// TestCertStoreGet covers exact, wildcard and default matching. The default is the certificate expiring last, which
+// is static here.
+func TestCertStoreGet(t *testing.T) {
+	now := time.Now()
+
+	static := testCertificate(t, now.Add(96*time.Hour), "localhost", "192.168.1.10")
+	exact := testCertificate(t, now.Add(48*time.Hour), "media.example.com")
+	wildcard := testCertificate(t, now.Add(72*time.Hour), "*.example.com")
+	older := testCertificate(t, now.Add(12*time.Hour), "*.example.com", "example.com")
+
+	s := newCertStore()
+
+	if cert := s.Get("example.com"); cert != nil {
+		t.Fatalf("empty store returned a certificate")
+	}
+
+	if err := s.Replace(certSourceStatic, []*tls.Certificate{static}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Replace(certSourceACME, []*tls.Certificate{exact, older}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Add(certSourceDSDM, wildcard); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		server string
+		want   *tls.Certificate
+	}{
+		{name: "exact", server: "localhost", want: static},
+		{name: "exact ip", server: "192.168.1.10", want: static},
+		{name: "exact over wildcard", server: "media.example.com", want: exact},
+		{name: "case insensitive", server: "Media.Example.COM", want: exact},
+		{name: "trailing dot", server: "media.example.com.", want: exact},
+		{name: "wildcard expiring last", server: "abc.example.com", want: wildcard},
+		{name: "wildcard case insensitive", server: "ABC.example.com", want: wildcard},
+		{name: "wildcard does not cover apex", server: "example.com", want: older},
+		{name: "wildcard covers a single label", server: "a.b.example.com", want: static},
+		{name: "fallback for unknown name", server: "other.test", want: static},
+		{name: "fallback for no name", server: "", want: static},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.Get(tt.server); got != tt.want {
+				t.Errorf("Get(%q) = %v, want %v", tt.server, certNames(got), certNames(tt.want))
+			}
+		})
+	}
+}
+
+func TestCertStoreReplace(t *testing.T) {
+	now := time.Now()
+
+	first := testCertificate(t, now.Add(24*time.Hour), "*.example.com")
+	second := testCertificate(t, now.Add(48*time.Hour), "*.example.com")
+	other := testCertificate(t, now.Add(time.Hour), "*.example.org")
+
+	s := newCertStore()
+
+	if err := s.Replace(certSourceDSDM, []*tls.Certificate{first}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Replace(certSourceACME, []*tls.Certificate{other}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Replace(certSourceDSDM, []*tls.Certificate{second}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := s.Get("a.example.com"); got != second {
+		t.Errorf("replaced certificate still served")
+	}
+
+	if got := s.Get("a.example.org"); got != other {
+		t.Errorf("certificate of another source was replaced")
+	}
+
+	if got := s.Wildcards(certSourceDSDM); len(got) != 1 || got[0] != "example.com" {
+		t.Errorf("Wildcards = %v, want [example.com]", got)
+	}
+
+	if err := s.Replace(certSourceStatic, []*tls.Certificate{{}}); err == nil {
+		t.Errorf("empty certificate was accepted")
+	}
+}
+
+// TestCertStoreConcurrent exercises lookups during replacement, and is meant to be run with -race.
+func TestCertStoreConcurrent(t *testing.T) {
+	now := time.Now()
+
+	certs := []*tls.Certificate{
+		testCertificate(t, now.Add(24*time.Hour), "*.example.com", "example.com"),
+		testCertificate(t, now.Add(48*time.Hour), "*.example.com", "example.com"),
+	}
+
+	s := newCertStore()
+
+	if err := s.Replace(certSourceDSDM, certs[:1]); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+
+	stop := make(chan struct{})
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				for _, name := range []string{"example.com", "a.example.com", "unknown.test"} {
+					if cert := s.Get(name); cert != certs[0] && cert != certs[1] {
+						t.Errorf("Get(%q) returned an unknown certificate", name)
+
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 200; i++ {
+		if err := s.Replace(certSourceDSDM, certs[i%2:i%2+1]); err != nil {
+			t.Error(err)
+		}
+
+		if err := s.Add(certSourceACME, certs[(i+1)%2]); err != nil {
+			t.Error(err)
+		}
+
+		if err := s.Replace(certSourceACME, nil); err != nil {
+			t.Error(err)
+		}
+	}
+
+	close(stop)
+	wg.Wait()
+}
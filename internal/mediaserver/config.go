@@ -0,0 +1,517 @@
+package mediaserver
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultConfigPath is read when no config file is given, and may be absent.
+const DefaultConfigPath = "cathode.yml"
+
+type TLSMode string
+
+const (
+	// TLSModeDynDirect uses certificates for subdomains issued through the configured DSDM servers.
+	TLSModeDynDirect TLSMode = "dyndirect"
+	// TLSModeFiles uses a certificate and key provided by the user.
+	TLSModeFiles TLSMode = "files"
+	// TLSModeSelfSigned uses a certificate generated by the server, which clients must be told to trust.
+	TLSModeSelfSigned TLSMode = "self-signed"
+)
+
+type Config struct {
+	// DataDir holds the database, image cache and transcodes.
+	DataDir   string           `yaml:"dataDir"`
+	Listeners []ListenerConfig `yaml:"listeners"`
+	TLS       TLSConfig        `yaml:"tls"`
+	Discovery DiscoveryConfig  `yaml:"discovery"`
+	// PortMapping forwards the port of a TLS listener on the home router, for access from outside the LAN.
+	PortMapping PortMappingConfig `yaml:"portMapping"`
+	// WatchedThreshold is the fraction of an item that must be played for it to count as watched.
+	WatchedThreshold float64 `yaml:"watchedThreshold"`
+}
+
+type ListenerConfig struct {
+	// Address is a host and port, with an empty host listening on every interface. IPv6 hosts are bracketed.
+	Address string `yaml:"address"`
+	// Plain serves HTTP without TLS, for clients on the LAN that cannot validate a certificate.
+	Plain bool `yaml:"plain"`
+	// HTTP3 additionally serves HTTP/3 over UDP on the same port. It requires TLS.
+	HTTP3 bool `yaml:"http3"`
+}
+
+type TLSConfig struct {
+	Mode     TLSMode `yaml:"mode"`
+	CertFile string  `yaml:"certFile"`
+	KeyFile  string  `yaml:"keyFile"`
+	// RenewBefore is how long before expiry DynDirect and ACME certificates are renewed.
+	RenewBefore time.Duration `yaml:"renewBefore"`
+	ACME        ACMEConfig    `yaml:"acme"`
+}
+
+type ACMEChallenge string
+
+const (
+	// ACMEChallengeHTTP01 answers challenges over plain HTTP, on a port the CA can reach as port 80.
+	ACMEChallengeHTTP01 ACMEChallenge = "http-01"
+	// ACMEChallengeTLSALPN01 answers challenges on the TLS listeners, which the CA must be able to reach as port 443.
+	ACMEChallengeTLSALPN01 ACMEChallenge = "tls-alpn-01"
+	// ACMEChallengeDNS01 answers challenges with TXT records, created through a lego DNS provider.
+	ACMEChallengeDNS01 ACMEChallenge = "dns-01"
+)
+
+// ACMEConfig requests a certificate for domains owned by the user, served alongside the certificates of the TLS mode.
+type ACMEConfig struct {
+	// Domains are all requested on a single certificate. ACME is off when there are none.
+	Domains []string `yaml:"domains"`
+	// Email is given to the CA for expiry notices, and may be empty.
+	Email string `yaml:"email"`
+	// Provider is letsencrypt or letsencrypt-staging.
+	Provider string `yaml:"provider"`
+	// Directory overrides the directory URL of the provider, to use another CA.
+	Directory string        `yaml:"directory"`
+	KeyType   string        `yaml:"keyType"`
+	Challenge ACMEChallenge `yaml:"challenge"`
+	// HTTPAddress is listened on while answering http-01 challenges.
+	HTTPAddress string `yaml:"httpAddress"`
+	// DNSProvider is the name of a lego DNS provider, configured through its usual environment variables.
+	DNSProvider string `yaml:"dnsProvider"`
+}
+
+// Enabled reports whether any domains are configured.
+func (c *ACMEConfig) Enabled() bool {
+	return len(c.Domains) > 0
+}
+
+// DiscoveryConfig controls how the addresses the server can be reached on are found, and how it is advertised.
+type DiscoveryConfig struct {
+	// Name is shown to clients finding the server on the local network. The host name is used when empty.
+	Name string `yaml:"name"`
+	// MDNS advertises the server on the local network, so that clients can find it without being given a URL.
+	MDNS bool `yaml:"mdns"`
+	// STUNServers are asked for the public address of the server, as host and port. Public addresses are not
+	// discovered when there are none.
+	STUNServers []string `yaml:"stunServers"`
+}
+
+type PortMappingMethod string
+
+const (
+	// PortMappingPCP uses the Port Control Protocol (RFC 6887), the successor of NAT-PMP.
+	PortMappingPCP PortMappingMethod = "pcp"
+	// PortMappingNATPMP uses NAT-PMP (RFC 6886).
+	PortMappingNATPMP PortMappingMethod = "natpmp"
+	// PortMappingUPnP uses a UPnP Internet Gateway Device.
+	PortMappingUPnP PortMappingMethod = "upnp"
+)
+
+// PortMappingConfig requests TCP and UDP mappings for the port of a TLS listener from the router. The UDP mapping is
+// only requested when the listener serves HTTP/3.
+type PortMappingConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Methods are tried in order until the router accepts one.
+	Methods []PortMappingMethod `yaml:"methods"`
+	// Lease is how long mappings are requested for. They are renewed halfway through.
+	Lease time.Duration `yaml:"lease"`
+}
+
+// DefaultConfig serves HTTPS and HTTP/3 on port 8443 using DynDirect certificates.
+func DefaultConfig() Config {
+	return Config{
+		DataDir: "data",
+		Listeners: []ListenerConfig{
+			{Address: ":8443", HTTP3: true},
+		},
+		TLS: TLSConfig{
+			Mode:        TLSModeDynDirect,
+			RenewBefore: 10 * 24 * time.Hour,
+			ACME: ACMEConfig{
+				Provider:    string(acmeProviderLetsEncrypt),
+				KeyType:     string(acmeKeyTypeEC256),
+				Challenge:   ACMEChallengeTLSALPN01,
+				HTTPAddress: ":80",
+			},
+		},
+		Discovery: DiscoveryConfig{
+			MDNS:        true,
+			STUNServers: []string{"stun.l.google.com:19302", "stun.cloudflare.com:3478"},
+		},
+		PortMapping: PortMappingConfig{
+			Methods: []PortMappingMethod{PortMappingPCP, PortMappingNATPMP, PortMappingUPnP},
+			Lease:   2 * time.Hour,
+		},
+		WatchedThreshold: defaultWatchedThreshold,
+	}
+}
+
+// LoadConfig reads the config file at path over the defaults, then applies environment variable overrides. A missing
+// file is only an error when the path was given explicitly.
+func LoadConfig(path string) (Config, error) {
+	cfg := DefaultConfig()
+
+	explicit := path != ""
+	if !explicit {
+		path = DefaultConfigPath
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !explicit {
+		data = nil
+	} else if err != nil {
+		return Config{}, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	if len(data) > 0 {
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+
+		if err := dec.Decode(&cfg); err != nil {
+			return Config{}, fmt.Errorf("failed to parse config %v: %w", path, err)
+		}
+	}
+
+	if err := cfg.applyEnv(os.LookupEnv); err != nil {
+		return Config{}, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// applyEnv overrides the config from CATHODE_ environment variables. Listener variables hold comma separated
+// addresses, and replace every listener of their kind.
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	if v, ok := lookup("CATHODE_DATA_DIR"); ok {
+		c.DataDir = v
+	}
+
+	http3 := true
+
+	if v, ok := lookup("CATHODE_HTTP3"); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("CATHODE_HTTP3: %w", err)
+		}
+
+		http3 = b
+
+		for i := range c.Listeners {
+			c.Listeners[i].HTTP3 = b && !c.Listeners[i].Plain
+		}
+	}
+
+	if v, ok := lookup("CATHODE_LISTEN"); ok {
+		c.replaceListeners(false, splitList(v), http3)
+	}
+
+	if v, ok := lookup("CATHODE_PLAIN_LISTEN"); ok {
+		c.replaceListeners(true, splitList(v), false)
+	}
+
+	if v, ok := lookup("CATHODE_TLS_MODE"); ok {
+		c.TLS.Mode = TLSMode(v)
+	}
+
+	if v, ok := lookup("CATHODE_TLS_CERT_FILE"); ok {
+		c.TLS.CertFile = v
+	}
+
+	if v, ok := lookup("CATHODE_TLS_KEY_FILE"); ok {
+		c.TLS.KeyFile = v
+	}
+
+	if v, ok := lookup("CATHODE_TLS_RENEW_BEFORE"); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("CATHODE_TLS_RENEW_BEFORE: %w", err)
+		}
+
+		c.TLS.RenewBefore = d
+	}
+
+	if v, ok := lookup("CATHODE_ACME_DOMAINS"); ok {
+		c.TLS.ACME.Domains = splitList(v)
+	}
+
+	if v, ok := lookup("CATHODE_ACME_EMAIL"); ok {
+		c.TLS.ACME.Email = v
+	}
+
+	if v, ok := lookup("CATHODE_ACME_CHALLENGE"); ok {
+		c.TLS.ACME.Challenge = ACMEChallenge(v)
+	}
+
+	if v, ok := lookup("CATHODE_SERVER_NAME"); ok {
+		c.Discovery.Name = v
+	}
+
+	if v, ok := lookup("CATHODE_MDNS"); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("CATHODE_MDNS: %w", err)
+		}
+
+		c.Discovery.MDNS = b
+	}
+
+	if v, ok := lookup("CATHODE_STUN_SERVERS"); ok {
+		c.Discovery.STUNServers = splitList(v)
+	}
+
+	if v, ok := lookup("CATHODE_PORT_MAPPING"); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("CATHODE_PORT_MAPPING: %w", err)
+		}
+
+		c.PortMapping.Enabled = b
+	}
+
+	if v, ok := lookup("CATHODE_WATCHED_THRESHOLD"); ok {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("CATHODE_WATCHED_THRESHOLD: %w", err)
+		}
+
+		c.WatchedThreshold = f
+	}
+
+	return nil
+}
+
+func (c *Config) replaceListeners(plain bool, addrs []string, http3 bool) {
+	kept := c.Listeners[:0]
+
+	for _, l := range c.Listeners {
+		if l.Plain != plain {
+			kept = append(kept, l)
+		}
+	}
+
+	for _, addr := range addrs {
+		kept = append(kept, ListenerConfig{
+			Address: addr,
+			Plain:   plain,
+			HTTP3:   http3,
+		})
+	}
+
+	c.Listeners = kept
+}
+
+func splitList(v string) []string {
+	var res []string
+
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			res = append(res, s)
+		}
+	}
+
+	return res
+}
+
+// Validate checks the config, reporting every problem found.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.DataDir == "" {
+		errs = append(errs, errors.New("dataDir: must be set"))
+	}
+
+	if len(c.Listeners) == 0 {
+		errs = append(errs, errors.New("listeners: at least one listener is required"))
+	}
+
+	seen := make(map[string]int)
+	usesTLS := false
+
+	for i, l := range c.Listeners {
+		if err := validateListenAddress(l.Address); err != nil {
+			errs = append(errs, fmt.Errorf("listeners[%d].address: %w", i, err))
+		}
+
+		if j, ok := seen[l.Address]; ok {
+			errs = append(errs, fmt.Errorf("listeners[%d].address: %q is also used by listeners[%d]", i, l.Address, j))
+		}
+
+		seen[l.Address] = i
+
+		if l.Plain && l.HTTP3 {
+			errs = append(errs, fmt.Errorf("listeners[%d]: http3 requires TLS, so cannot be used with plain", i))
+		}
+
+		usesTLS = usesTLS || !l.Plain
+	}
+
+	if usesTLS {
+		errs = append(errs, c.TLS.validate()...)
+	} else if c.TLS.ACME.Enabled() {
+		errs = append(errs, errors.New("tls.acme: requires a TLS listener"))
+	}
+
+	for i, server := range c.Discovery.STUNServers {
+		if _, port, err := net.SplitHostPort(server); err != nil || port == "" {
+			errs = append(errs, fmt.Errorf("discovery.stunServers[%d]: %q is not a host and port", i, server))
+		}
+	}
+
+	if c.PortMapping.Enabled {
+		errs = append(errs, c.PortMapping.validate()...)
+	}
+
+	// Written so that NaN is rejected too
+	if !(c.WatchedThreshold > 0 && c.WatchedThreshold <= 1) {
+		errs = append(errs, fmt.Errorf("watchedThreshold: %v must be above 0 and at most 1", c.WatchedThreshold))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *TLSConfig) validate() []error {
+	var errs []error
+
+	switch c.Mode {
+	case TLSModeFiles:
+		if c.CertFile == "" {
+			errs = append(errs, errors.New("tls.certFile: must be set in files mode"))
+		}
+
+		if c.KeyFile == "" {
+			errs = append(errs, errors.New("tls.keyFile: must be set in files mode"))
+		}
+	case TLSModeDynDirect, TLSModeSelfSigned:
+		if c.CertFile != "" || c.KeyFile != "" {
+			errs = append(errs, fmt.Errorf("tls: certFile and keyFile are only used in files mode, not %v", c.Mode))
+		}
+	default:
+		errs = append(errs, fmt.Errorf(
+			"tls.mode: %q is not one of %v, %v or %v",
+			c.Mode,
+			TLSModeDynDirect,
+			TLSModeFiles,
+			TLSModeSelfSigned,
+		))
+	}
+
+	if (c.Mode == TLSModeDynDirect || c.ACME.Enabled()) && c.RenewBefore <= 0 {
+		errs = append(errs, errors.New("tls.renewBefore: must be positive"))
+	}
+
+	if c.ACME.Enabled() {
+		errs = append(errs, c.ACME.validate()...)
+	}
+
+	return errs
+}
+
+func (c *ACMEConfig) validate() []error {
+	var errs []error
+
+	for i, d := range c.Domains {
+		if strings.HasPrefix(d, "*.") && c.Challenge != ACMEChallengeDNS01 {
+			errs = append(errs, fmt.Errorf("tls.acme.domains[%d]: wildcards require the dns-01 challenge", i))
+		} else if d == "" || strings.ContainsAny(d, "/: ") {
+			errs = append(errs, fmt.Errorf("tls.acme.domains[%d]: %q is not a domain", i, d))
+		}
+	}
+
+	if c.Directory == "" {
+		if p := acmeProvider(c.Provider); p != acmeProviderLetsEncrypt && p != acmeProviderLetsEncryptStaging {
+			errs = append(errs, fmt.Errorf(
+				"tls.acme.provider: %q is not one of %v or %v",
+				c.Provider,
+				acmeProviderLetsEncrypt,
+				acmeProviderLetsEncryptStaging,
+			))
+		}
+	} else if u, err := url.Parse(c.Directory); err != nil || u.Scheme != "https" {
+		errs = append(errs, fmt.Errorf("tls.acme.directory: %q is not an https url", c.Directory))
+	}
+
+	if err := acmeKeyType(c.KeyType).validate(); err != nil {
+		errs = append(errs, fmt.Errorf("tls.acme.keyType: %w", err))
+	}
+
+	switch c.Challenge {
+	case ACMEChallengeHTTP01:
+		if err := validateListenAddress(c.HTTPAddress); err != nil {
+			errs = append(errs, fmt.Errorf("tls.acme.httpAddress: %w", err))
+		}
+	case ACMEChallengeTLSALPN01:
+	case ACMEChallengeDNS01:
+		if c.DNSProvider == "" {
+			errs = append(errs, errors.New("tls.acme.dnsProvider: must be set for the dns-01 challenge"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf(
+			"tls.acme.challenge: %q is not one of %v, %v or %v",
+			c.Challenge,
+			ACMEChallengeHTTP01,
+			ACMEChallengeTLSALPN01,
+			ACMEChallengeDNS01,
+		))
+	}
+
+	return errs
+}
+
+func (c *PortMappingConfig) validate() []error {
+	var errs []error
+
+	if len(c.Methods) == 0 {
+		errs = append(errs, errors.New("portMapping.methods: at least one method is required"))
+	}
+
+	for i, m := range c.Methods {
+		switch m {
+		case PortMappingPCP, PortMappingNATPMP, PortMappingUPnP:
+		default:
+			errs = append(errs, fmt.Errorf(
+				"portMapping.methods[%d]: %q is not one of %v, %v or %v",
+				i,
+				m,
+				PortMappingPCP,
+				PortMappingNATPMP,
+				PortMappingUPnP,
+			))
+		}
+	}
+
+	// Routers remove mappings that are not renewed, so very short leases would be renewed constantly
+	if c.Lease < time.Minute {
+		errs = append(errs, errors.New("portMapping.lease: must be at least 1m"))
+	}
+
+	return errs
+}
+
+func validateListenAddress(addr string) error {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+
+	if host != "" && host != "localhost" && net.ParseIP(host) == nil {
+		return fmt.Errorf("host %q must be an IP address", host)
+	}
+
+	p, err := strconv.Atoi(port)
+	if err != nil || p < 1 || p > 65535 {
+		return fmt.Errorf("port %q must be between 1 and 65535", port)
+	}
+
+	return nil
+}
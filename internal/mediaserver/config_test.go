@@ -0,0 +1,177 @@
+package mediaserver
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		env     map[string]string
+		want    func(cfg *Config)
+		wantErr string
+	}{
+		{
+			name: "defaults for an empty file",
+			want: func(cfg *Config) {},
+		},
+		{
+			name: "file over defaults",
+			file: "dataDir: /srv/cathode\nwatchedThreshold: 0.8\n",
+			want: func(cfg *Config) {
+				cfg.DataDir = "/srv/cathode"
+				cfg.WatchedThreshold = 0.8
+			},
+		},
+		{
+			name:    "unknown field",
+			file:    "dataDirectory: /srv/cathode\n",
+			wantErr: "field dataDirectory not found",
+		},
+		{
+			name:    "unknown nested field",
+			file:    "tls:\n  mod: files\n",
+			wantErr: "field mod not found",
+		},
+		{
+			name: "env over file",
+			file: "dataDir: /srv/cathode\ndiscovery:\n  mdns: true\n",
+			env: map[string]string{
+				"CATHODE_DATA_DIR": "/var/lib/cathode",
+				"CATHODE_MDNS":     "false",
+			},
+			want: func(cfg *Config) {
+				cfg.DataDir = "/var/lib/cathode"
+				cfg.Discovery.MDNS = false
+			},
+		},
+		{
+			name: "env listeners replace only their kind",
+			file: "listeners:\n  - address: \":8443\"\n  - address: \":8080\"\n    plain: true\n",
+			env: map[string]string{
+				"CATHODE_LISTEN": ":9443, :9444",
+				"CATHODE_HTTP3":  "false",
+			},
+			want: func(cfg *Config) {
+				cfg.Listeners = []ListenerConfig{
+					{Address: ":8080", Plain: true},
+					{Address: ":9443"},
+					{Address: ":9444"},
+				}
+			},
+		},
+		{
+			name:    "invalid env value",
+			env:     map[string]string{"CATHODE_PORT_MAPPING": "sometimes"},
+			wantErr: "CATHODE_PORT_MAPPING",
+		},
+		{
+			name:    "env validated",
+			file:    "watchedThreshold: 0.8\n",
+			env:     map[string]string{"CATHODE_WATCHED_THRESHOLD": "1.5"},
+			wantErr: "watchedThreshold: 1.5 must be above 0 and at most 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			path := filepath.Join(t.TempDir(), "cathode.yml")
+
+			if err := os.WriteFile(path, []byte(tt.file), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, err := LoadConfig(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadConfig() error = %v, want %q", err, tt.wantErr)
+				}
+
+				return
+			} else if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+
+			want := DefaultConfig()
+			tt.want(&want)
+
+			if !reflect.DeepEqual(cfg, want) {
+				t.Errorf("LoadConfig() = %+v, want %+v", cfg, want)
+			}
+		})
+	}
+
+	t.Run("missing default file", func(t *testing.T) {
+		cfg, err := LoadConfig("")
+		if err != nil {
+			t.Fatalf("LoadConfig() error = %v", err)
+		}
+
+		if want := DefaultConfig(); !reflect.DeepEqual(cfg, want) {
+			t.Errorf("LoadConfig() = %+v, want %+v", cfg, want)
+		}
+	})
+
+	t.Run("missing explicit file", func(t *testing.T) {
+		if _, err := LoadConfig(filepath.Join(t.TempDir(), "missing.yml")); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("LoadConfig() error = %v, want not exist", err)
+		}
+	})
+}
+
+func TestConfigValidate(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.DataDir = ""
+	cfg.Listeners = []ListenerConfig{
+		{Address: ":8443", HTTP3: true},
+		{Address: ":8443"},
+		{Address: "example.com:8080", Plain: true, HTTP3: true},
+	}
+	cfg.TLS.Mode = TLSModeFiles
+	cfg.WatchedThreshold = 0
+
+	want := []string{
+		"dataDir: must be set",
+		`listeners[1].address: ":8443" is also used by listeners[0]`,
+		`listeners[2].address: host "example.com" must be an IP address`,
+		"listeners[2]: http3 requires TLS, so cannot be used with plain",
+		"tls.certFile: must be set in files mode",
+		"tls.keyFile: must be set in files mode",
+		"watchedThreshold: 0 must be above 0 and at most 1",
+	}
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Validate() = nil, want errors")
+	}
+
+	var joined interface{ Unwrap() []error }
+	if !errors.As(err, &joined) {
+		t.Fatalf("Validate() = %v, want joined errors", err)
+	}
+
+	var got []string
+	for _, err := range joined.Unwrap() {
+		got = append(got, err.Error())
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate() = %q, want %q", got, want)
+	}
+
+	def := DefaultConfig()
+
+	if err := def.Validate(); err != nil {
+		t.Errorf("default config is invalid: %v", err)
+	}
+}
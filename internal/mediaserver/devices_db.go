@@ -0,0 +1,108 @@
+package mediaserver
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/csnewman/cathode/internal/db"
+	"github.com/google/uuid"
+)
+
+type device struct {
+	id        uuid.UUID
+	userID    uuid.UUID
+	name      string
+	tokenHash string
+	created   time.Time
+	lastSeen  time.Time
+	// linkSecret is negotiated during pairing for use by the encrypted link protocol. It is nil for devices that did
+	// not provide a link key.
+	linkSecret []byte
+}
+
+const deviceColumns = `id, user_id, name, token_hash, created, last_seen, link_secret`
+
+func scanDevice(row interface{ Scan(dest ...any) error }) (device, error) {
+	var (
+		res      device
+		created  string
+		lastSeen string
+	)
+
+	if err := row.Scan(&res.id, &res.userID, &res.name, &res.tokenHash, &created, &lastSeen, &res.linkSecret); err != nil {
+		return device{}, err
+	}
+
+	res.created = parseSQLiteTime(created)
+	res.lastSeen = parseSQLiteTime(lastSeen)
+
+	return res, nil
+}
+
+func getUserDevices(_ context.Context, tx db.RTx, userID uuid.UUID) ([]device, error) {
+	var devices []device
+
+	rows, err := tx.Query(`SELECT `+deviceColumns+` FROM devices WHERE user_id = $1 ORDER BY created`, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		res, err := scanDevice(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		devices = append(devices, res)
+	}
+
+	return devices, rows.Err()
+}
+
+func getDevice(_ context.Context, tx db.RTx, id uuid.UUID) (device, error) {
+	res, err := scanDevice(tx.QueryRow(`SELECT `+deviceColumns+` FROM devices WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return device{}, fmt.Errorf("%w: device %v", errNotFound, id)
+	}
+
+	return res, err
+}
+
+func getDeviceByToken(_ context.Context, tx db.RTx, tokenHash string) (device, error) {
+	res, err := scanDevice(tx.QueryRow(`SELECT `+deviceColumns+` FROM devices WHERE token_hash = $1`, tokenHash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return device{}, fmt.Errorf("%w: device", errNotFound)
+	}
+
+	return res, err
+}
+
+func insertDevice(_ context.Context, tx db.WTx, d device) error {
+	return tx.Exec(
+		`INSERT INTO devices (id, user_id, name, token_hash, created, last_seen, link_secret)
+		VALUES ($1, $2, $3, $4, $5, $5, $6)`,
+		d.id,
+		d.userID,
+		d.name,
+		d.tokenHash,
+		d.created.UTC().Format(sqliteTimeLayout),
+		d.linkSecret,
+	)
+}
+
+func touchDevice(_ context.Context, tx db.WTx, id uuid.UUID, lastSeen time.Time) error {
+	return tx.Exec(
+		`UPDATE devices SET last_seen = $1 WHERE id = $2`,
+		lastSeen.UTC().Format(sqliteTimeLayout),
+		id,
+	)
+}
+
+func deleteDevice(_ context.Context, tx db.WTx, id uuid.UUID) error {
+	return tx.Exec(`DELETE FROM devices WHERE id = $1`, id)
+}
@@ -0,0 +1,304 @@
+package mediaserver
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	errEventsStopped      = errors.New("events stopped for shutdown")
+	errSubscriptionClosed = errors.New("subscription closed")
+)
+
+type eventType string
+
+const (
+	eventItemAdded          eventType = "item.added"
+	eventItemUpdated        eventType = "item.updated"
+	eventItemRemoved        eventType = "item.removed"
+	eventLibraryScan        eventType = "library.scan"
+	eventSessionStarted     eventType = "session.started"
+	eventSessionStopped     eventType = "session.stopped"
+	eventCertificateRenewed eventType = "certificate.renewed"
+	// eventsDropped is sent to a subscriber in place of the events it was too slow to receive.
+	eventsDropped eventType = "events.dropped"
+)
+
+// subscriptionQueueSize bounds the events waiting for a subscriber. Further events are dropped until it catches up.
+const subscriptionQueueSize = 256
+
+// event is something that happened on the server. data holds the details, of the type matching the event type.
+type event struct {
+	id   uint64
+	typ  eventType
+	time time.Time
+	data any
+}
+
+// itemEvent describes an item that was added, updated or removed. The kind is not known for all removed items.
+type itemEvent struct {
+	id        uuid.UUID
+	libraryID uuid.UUID
+	parentID  uuid.NullUUID
+	kind      itemKind
+}
+
+type scanState string
+
+const (
+	scanStateStarted   scanState = "started"
+	scanStateRunning   scanState = "running"
+	scanStateCompleted scanState = "completed"
+	scanStateFailed    scanState = "failed"
+)
+
+// scanEvent reports the progress of a full library scan.
+type scanEvent struct {
+	libraryID uuid.UUID
+	state     scanState
+	scanned   int
+	imported  int
+	failed    int
+	missing   int
+}
+
+type sessionEvent struct {
+	id       uuid.UUID
+	userID   uuid.UUID
+	itemID   uuid.UUID
+	deviceID uuid.NullUUID
+	plan     playbackPlan
+	// reason is why a session stopped, being idle, terminated or failed.
+	reason string
+}
+
+type certificateEvent struct {
+	source  certSource
+	domains []string
+	expires time.Time
+}
+
+type droppedEvent struct {
+	count int
+}
+
+func newItemEvent(typ eventType, it item) event {
+	return event{
+		typ: typ,
+		data: itemEvent{
+			id:        it.id,
+			libraryID: it.libraryID,
+			parentID:  it.parentID,
+			kind:      it.kind,
+		},
+	}
+}
+
+// EventBus delivers events published by the rest of the server to subscribers, such as clients streaming events and
+// plugins. Publishing never blocks, as each subscriber has a bounded queue, and a subscriber too slow to keep up is
+// sent a single eventsDropped event in place of those it missed.
+type EventBus struct {
+	logger *slog.Logger
+
+	mu     sync.Mutex
+	nextID uint64
+	subs   map[*eventSubscription]struct{}
+	// stopped is set once Run has closed every subscription, after which events are discarded.
+	stopped bool
+}
+
+// eventSubscription is the queue of events matching a subscriber's filter.
+type eventSubscription struct {
+	bus   *EventBus
+	match func(event) bool
+	wake  chan struct{}
+
+	mu     sync.Mutex
+	queue  []event
+	lost   int
+	closed bool
+}
+
+func NewEventBus(logger *slog.Logger) *EventBus {
+	return &EventBus{
+		logger: logger,
+		nextID: 1,
+		subs:   make(map[*eventSubscription]struct{}),
+	}
+}
+
+// Run closes every subscription once the context is cancelled.
+func (b *EventBus) Run(ctx context.Context) {
+	<-ctx.Done()
+
+	b.mu.Lock()
+	b.stopped = true
+	subs := b.subs
+	b.subs = make(map[*eventSubscription]struct{})
+	b.mu.Unlock()
+
+	for s := range subs {
+		s.close()
+	}
+}
+
+// Publish delivers the events to every subscriber they match.
+func (b *EventBus) Publish(events ...event) {
+	if len(events) == 0 {
+		return
+	}
+
+	now := time.Now().UTC()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.stopped {
+		return
+	}
+
+	for _, e := range events {
+		e.id = b.nextID
+		e.time = now
+		b.nextID++
+
+		for s := range b.subs {
+			if s.match == nil || s.match(e) {
+				s.push(e)
+			}
+		}
+	}
+}
+
+// Subscribe returns a subscription to the events matching the filter, or every event when it is nil. The subscription
+// must be closed once no longer needed.
+func (b *EventBus) Subscribe(match func(event) bool) (*eventSubscription, error) {
+	s := &eventSubscription{
+		bus:   b,
+		match: match,
+		wake:  make(chan struct{}, 1),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.stopped {
+		return nil, errEventsStopped
+	}
+
+	b.subs[s] = struct{}{}
+
+	return s, nil
+}
+
+// matchEventTypes returns a filter matching events of the types, or every event when there are none.
+func matchEventTypes(types []eventType) func(event) bool {
+	if len(types) == 0 {
+		return nil
+	}
+
+	return func(e event) bool {
+		return slices.Contains(types, e.typ)
+	}
+}
+
+func (s *eventSubscription) push(e event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	if len(s.queue) >= subscriptionQueueSize {
+		s.lost++
+
+		return
+	}
+
+	// The drop is reported where it happened, so the subscriber sees events in order
+	if s.lost > 0 {
+		s.queue = append(s.queue, s.droppedLocked(e.time))
+	}
+
+	s.queue = append(s.queue, e)
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *eventSubscription) droppedLocked(t time.Time) event {
+	e := event{
+		typ:  eventsDropped,
+		time: t,
+		data: droppedEvent{count: s.lost},
+	}
+
+	s.lost = 0
+
+	return e
+}
+
+// Next waits for the next event, returning errSubscriptionClosed once the subscription has been closed.
+func (s *eventSubscription) Next(ctx context.Context) (event, error) {
+	for {
+		s.mu.Lock()
+
+		if len(s.queue) > 0 {
+			e := s.queue[0]
+			s.queue = s.queue[1:]
+			s.mu.Unlock()
+
+			return e, nil
+		}
+
+		if s.lost > 0 {
+			e := s.droppedLocked(time.Now().UTC())
+			s.mu.Unlock()
+
+			return e, nil
+		}
+
+		closed := s.closed
+		s.mu.Unlock()
+
+		if closed {
+			return event{}, errSubscriptionClosed
+		}
+
+		select {
+		case <-ctx.Done():
+			return event{}, ctx.Err()
+		case <-s.wake:
+		}
+	}
+}
+
+// Close stops delivery to the subscription.
+func (s *eventSubscription) Close() {
+	s.bus.mu.Lock()
+	delete(s.bus.subs, s)
+	s.bus.mu.Unlock()
+
+	s.close()
+}
+
+func (s *eventSubscription) close() {
+	s.mu.Lock()
+	s.closed = true
+	s.queue = nil
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
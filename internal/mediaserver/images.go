@@ -0,0 +1,428 @@
+package mediaserver
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	goimage "image"
+	"image/jpeg"
+	_ "image/png" // Register decoder
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/chai2010/webp"
+	"github.com/csnewman/cathode/internal/db"
+	"github.com/google/uuid"
+	"golang.org/x/image/draw"
+)
+
+var errImageUnavailable = errors.New("image unavailable")
+
+// maxImageSize limits the size of downloaded originals.
+const maxImageSize = 32 << 20
+
+// maxImagePixels limits the dimensions of originals. Decoding allocates memory for every pixel, so a small file
+// declaring huge dimensions would otherwise exhaust memory when resized.
+const maxImagePixels = 64 << 20
+
+var errImageTooLarge = errors.New("image too large")
+
+const (
+	jpegQuality = 85
+	webpQuality = 80
+)
+
+// imageWidths are the widths variants are generated at. Requested widths are rounded up to the next size, bounding the
+// number of variants stored per image.
+var imageWidths = []int{92, 154, 185, 342, 500, 780, 1280, 1920, 3840}
+
+type imageFormat string
+
+const (
+	imageFormatJPEG imageFormat = "jpeg"
+	imageFormatWebP imageFormat = "webp"
+)
+
+func (f imageFormat) contentType() string {
+	if f == imageFormatWebP {
+		return "image/webp"
+	}
+
+	return "image/jpeg"
+}
+
+// ImageCache stores artwork content-addressed on disk. Originals are downloaded once and resized variants are
+// generated on demand.
+type ImageCache struct {
+	logger *slog.Logger
+	db     *db.DB
+	dir    string
+	client *http.Client
+	queue  chan uuid.UUID
+
+	mu    sync.Mutex
+	locks map[string]*keyLock
+}
+
+type keyLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// cachedImage is a file ready to be served.
+type cachedImage struct {
+	path        string
+	etag        string
+	contentType string
+}
+
+func NewImageCache(logger *slog.Logger, db *db.DB, dir string) (*ImageCache, error) {
+	for _, sub := range []string{"originals", "variants", "tmp"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create image cache dir: %w", err)
+		}
+	}
+
+	return &ImageCache{
+		logger: logger,
+		db:     db,
+		dir:    dir,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		queue: make(chan uuid.UUID, 256),
+		locks: make(map[string]*keyLock),
+	}, nil
+}
+
+// Run downloads images queued for prefetching until the context is cancelled. Images left over from a previous run
+// are queued first.
+func (c *ImageCache) Run(ctx context.Context) {
+	pending, err := db.ReadWithData(ctx, c.db, func(ctx context.Context, tx db.RTx) ([]uuid.UUID, error) {
+		return getUnfetchedImages(ctx, tx, cap(c.queue))
+	})
+	if err != nil {
+		c.logger.Error("Failed to load pending images", "err", err)
+	}
+
+	for _, id := range pending {
+		c.Prefetch(id)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-c.queue:
+			if _, err := c.original(ctx, id); err != nil {
+				c.logger.Warn("Failed to prefetch image", "id", id, "err", err)
+			}
+		}
+	}
+}
+
+// Prefetch queues images to be downloaded in the background. Images are still downloaded on first request if the
+// queue is full.
+func (c *ImageCache) Prefetch(ids ...uuid.UUID) {
+	for _, id := range ids {
+		select {
+		case c.queue <- id:
+		default:
+			return
+		}
+	}
+}
+
+// Get returns the cached file for an image at the requested width and format, downloading and converting as needed.
+// A width of zero returns the image at its original size.
+func (c *ImageCache) Get(ctx context.Context, id uuid.UUID, width int, format imageFormat) (*cachedImage, error) {
+	img, err := c.original(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	width = variantWidth(width, img.width)
+
+	etag := fmt.Sprintf(`"%s-%d-%s"`, img.hash[:32], width, format)
+
+	if width == img.width && img.contentType == format.contentType() {
+		return &cachedImage{
+			path:        c.originalPath(img.hash),
+			etag:        etag,
+			contentType: img.contentType,
+		}, nil
+	}
+
+	path := filepath.Join(c.dir, "variants", img.hash[:2], img.hash+"-"+strconv.Itoa(width)+"."+string(format))
+
+	res := &cachedImage{
+		path:        path,
+		etag:        etag,
+		contentType: format.contentType(),
+	}
+
+	unlock := c.lock(path)
+	defer unlock()
+
+	if fileExists(path) {
+		return res, nil
+	}
+
+	if err := c.generateVariant(img, path, width, format); err != nil {
+		return nil, fmt.Errorf("failed to generate variant: %w", err)
+	}
+
+	return res, nil
+}
+
+// original ensures the original of an image has been downloaded, returning its record.
+func (c *ImageCache) original(ctx context.Context, id uuid.UUID) (image, error) {
+	unlock := c.lock(id.String())
+	defer unlock()
+
+	img, err := db.ReadWithData(ctx, c.db, func(ctx context.Context, tx db.RTx) (image, error) {
+		return getImage(ctx, tx, id)
+	})
+	if err != nil {
+		return image{}, err
+	}
+
+	if img.fetched() && fileExists(c.originalPath(img.hash)) {
+		return img, nil
+	}
+
+	data, err := c.download(ctx, img.sourceURL)
+	if err != nil {
+		return image{}, fmt.Errorf("%w: %w", errImageUnavailable, err)
+	}
+
+	img.contentType = http.DetectContentType(data)
+
+	switch img.contentType {
+	case "image/jpeg", "image/png", "image/webp":
+	default:
+		return image{}, fmt.Errorf("%w: unsupported content type %q", errImageUnavailable, img.contentType)
+	}
+
+	cfg, _, err := goimage.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return image{}, fmt.Errorf("%w: %w", errImageUnavailable, err)
+	}
+
+	if int64(cfg.Width)*int64(cfg.Height) > maxImagePixels {
+		return image{}, fmt.Errorf("%w: %w: %dx%d", errImageUnavailable, errImageTooLarge, cfg.Width, cfg.Height)
+	}
+
+	sum := sha256.Sum256(data)
+	img.hash = hex.EncodeToString(sum[:])
+	img.width = cfg.Width
+	img.height = cfg.Height
+
+	if err := c.writeFile(c.originalPath(img.hash), data); err != nil {
+		return image{}, err
+	}
+
+	if err := c.db.Write(ctx, func(ctx context.Context, tx db.WTx) error {
+		return setImageFetched(ctx, tx, img)
+	}); err != nil {
+		return image{}, err
+	}
+
+	c.logger.Debug("Cached image", "id", id, "source", img.sourceURL, "hash", img.hash)
+
+	return img, nil
+}
+
+func (c *ImageCache) download(ctx context.Context, source string) ([]byte, error) {
+	u, err := url.Parse(source)
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "file":
+		f, err := os.Open(filepath.FromSlash(u.Path))
+		if err != nil {
+			return nil, err
+		}
+
+		defer f.Close()
+
+		return readImage(f)
+
+	case "http", "https":
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status %v", resp.Status)
+		}
+
+		return readImage(resp.Body)
+
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+}
+
+// readImage reads an original, failing rather than truncating those reaching maxImageSize.
+func readImage(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxImageSize))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) >= maxImageSize {
+		return nil, fmt.Errorf("%w: exceeds %d bytes", errImageTooLarge, maxImageSize)
+	}
+
+	return data, nil
+}
+
+// generateVariant resizes and re-encodes an original. JPEG has no alpha channel, so transparent regions of logos
+// become black, matching the dark backgrounds they are displayed on.
+func (c *ImageCache) generateVariant(img image, path string, width int, format imageFormat) error {
+	// Originals cached before the limit was introduced are checked too
+	if int64(img.width)*int64(img.height) > maxImagePixels {
+		return fmt.Errorf("%w: %w: %dx%d", errImageUnavailable, errImageTooLarge, img.width, img.height)
+	}
+
+	f, err := os.Open(c.originalPath(img.hash))
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	src, _, err := goimage.Decode(f)
+	if err != nil {
+		return err
+	}
+
+	var dst goimage.Image = src
+
+	if b := src.Bounds(); width != b.Dx() {
+		height := max(1, b.Dy()*width/b.Dx())
+		scaled := goimage.NewRGBA(goimage.Rect(0, 0, width, height))
+		draw.CatmullRom.Scale(scaled, scaled.Bounds(), src, b, draw.Src, nil)
+		dst = scaled
+	}
+
+	var buf bytes.Buffer
+
+	switch format {
+	case imageFormatWebP:
+		data, err := webp.EncodeRGBA(dst, webpQuality)
+		if err != nil {
+			return err
+		}
+
+		buf.Write(data)
+	default:
+		if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return err
+		}
+	}
+
+	return c.writeFile(path, buf.Bytes())
+}
+
+// writeFile atomically writes a file into the cache.
+func (c *ImageCache) writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Join(c.dir, "tmp"), "img-*")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (c *ImageCache) originalPath(hash string) string {
+	return filepath.Join(c.dir, "originals", hash[:2], hash)
+}
+
+// lock serialises work on a single key, such as downloading an image or generating a variant.
+func (c *ImageCache) lock(key string) func() {
+	c.mu.Lock()
+
+	l, ok := c.locks[key]
+	if !ok {
+		l = &keyLock{}
+		c.locks[key] = l
+	}
+
+	l.refs++
+	c.mu.Unlock()
+
+	l.mu.Lock()
+
+	return func() {
+		l.mu.Unlock()
+
+		c.mu.Lock()
+		l.refs--
+
+		if l.refs == 0 {
+			delete(c.locks, key)
+		}
+
+		c.mu.Unlock()
+	}
+}
+
+// variantWidth rounds a requested width up to the next supported size, never exceeding the original width.
+func variantWidth(requested int, original int) int {
+	if requested <= 0 {
+		return original
+	}
+
+	for _, w := range imageWidths {
+		if w >= requested {
+			return min(w, original)
+		}
+	}
+
+	return original
+}
+
+func fileExists(path string) bool {
+	st, err := os.Stat(path)
+
+	return err == nil && st.Mode().IsRegular()
+}
@@ -0,0 +1,98 @@
+package mediaserver
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/csnewman/cathode/internal/db"
+	"github.com/google/uuid"
+)
+
+type image struct {
+	id          uuid.UUID
+	sourceURL   string
+	hash        string
+	contentType string
+	width       int
+	height      int
+}
+
+// fetched reports whether the original has been downloaded into the cache.
+func (i image) fetched() bool {
+	return i.hash != ""
+}
+
+func getImage(_ context.Context, tx db.RTx, id uuid.UUID) (image, error) {
+	var res image
+
+	err := tx.QueryRow(
+		`SELECT id, source_url, hash, content_type, width, height FROM images WHERE id = $1`,
+		id,
+	).Scan(&res.id, &res.sourceURL, &res.hash, &res.contentType, &res.width, &res.height)
+	if errors.Is(err, sql.ErrNoRows) {
+		return image{}, fmt.Errorf("%w: image %v", errNotFound, id)
+	}
+
+	return res, err
+}
+
+// registerImage returns the id of the image for a source URL, allocating one if the URL has not been seen before.
+// Nothing is downloaded until the image is first requested or prefetched.
+func registerImage(_ context.Context, tx db.WTx, sourceURL string) (uuid.NullUUID, error) {
+	if sourceURL == "" {
+		return uuid.NullUUID{}, nil
+	}
+
+	err := tx.Exec(
+		`INSERT INTO images (id, source_url, created) VALUES ($1, $2, datetime()) ON CONFLICT (source_url) DO NOTHING`,
+		uuid.New(),
+		sourceURL,
+	)
+	if err != nil {
+		return uuid.NullUUID{}, err
+	}
+
+	var id uuid.UUID
+
+	if err := tx.QueryRow(`SELECT id FROM images WHERE source_url = $1`, sourceURL).Scan(&id); err != nil {
+		return uuid.NullUUID{}, err
+	}
+
+	return uuid.NullUUID{UUID: id, Valid: true}, nil
+}
+
+func setImageFetched(_ context.Context, tx db.WTx, img image) error {
+	return tx.Exec(
+		`UPDATE images SET hash = $1, content_type = $2, width = $3, height = $4, fetched = datetime() WHERE id = $5`,
+		img.hash,
+		img.contentType,
+		img.width,
+		img.height,
+		img.id,
+	)
+}
+
+// getUnfetchedImages returns images which have not yet been downloaded.
+func getUnfetchedImages(_ context.Context, tx db.RTx, limit int) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+
+	rows, err := tx.Query(`SELECT id FROM images WHERE hash IS NULL ORDER BY created LIMIT $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
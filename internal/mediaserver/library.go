@@ -0,0 +1,323 @@
+package mediaserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/csnewman/cathode/internal/db"
+	"github.com/csnewman/cathode/internal/transcoder"
+	"github.com/csnewman/cathode/mediaserver"
+	"github.com/google/uuid"
+)
+
+var (
+	errInvalidRoot   = errors.New("invalid library root")
+	errLibraryExists = errors.New("library already exists")
+)
+
+// Provider priorities used when building a library index. Local metadata curated by the user always wins.
+const (
+	localProviderPriority = 100
+	tmdbProviderPriority  = 50
+
+	// maxQueuedScanPaths is how many changed paths of a library may wait for a targeted scan before a full scan is
+	// cheaper.
+	maxQueuedScanPaths = 10000
+)
+
+type LibraryManager struct {
+	logger *slog.Logger
+	db     *db.DB
+	online mediaserver.Index
+	images *ImageCache
+	events *EventBus
+
+	queue  chan uuid.UUID
+	mu     sync.Mutex
+	queued map[uuid.UUID]struct{}
+	// paths holds changed paths waiting for a targeted scan, by library. Batches are merged until the scan worker
+	// picks them up.
+	paths     map[uuid.UUID]map[string]struct{}
+	pathsWake chan struct{}
+
+	refreshWake chan struct{}
+	watchAdd    chan library
+
+	// scanMu serialises scans, so full and targeted scans never import the same file concurrently.
+	scanMu sync.Mutex
+
+	// locals holds the local metadata index of each library, kept between scans so targeted scans only reread the
+	// changed paths.
+	localsMu sync.Mutex
+	locals   map[uuid.UUID]*mediaserver.LocalIndex
+}
+
+func NewLibraryManager(
+	logger *slog.Logger,
+	db *db.DB,
+	online mediaserver.Index,
+	images *ImageCache,
+	events *EventBus,
+) *LibraryManager {
+	return &LibraryManager{
+		logger: logger,
+		db:     db,
+		online: online,
+		images: images,
+		events: events,
+		queue:  make(chan uuid.UUID, 64),
+		queued: make(map[uuid.UUID]struct{}),
+		locals: make(map[uuid.UUID]*mediaserver.LocalIndex),
+
+		paths:     make(map[uuid.UUID]map[string]struct{}),
+		pathsWake: make(chan struct{}, 1),
+
+		refreshWake: make(chan struct{}, 1),
+		watchAdd:    make(chan library, 8),
+	}
+}
+
+// Run processes queued scans until the context is cancelled.
+func (l *LibraryManager) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-l.queue:
+			l.mu.Lock()
+			delete(l.queued, id)
+			// The full scan covers any changed paths
+			delete(l.paths, id)
+			l.mu.Unlock()
+
+			if err := l.Scan(ctx, id); err != nil {
+				l.logger.Error("Library scan failed", "library", id, "err", err)
+			}
+		case <-l.pathsWake:
+			l.mu.Lock()
+			batches := l.paths
+			l.paths = make(map[uuid.UUID]map[string]struct{})
+			l.mu.Unlock()
+
+			for id, set := range batches {
+				paths := make([]string, 0, len(set))
+				for path := range set {
+					paths = append(paths, path)
+				}
+
+				slices.Sort(paths)
+
+				l.logger.Debug("Scanning changed paths", "library", id, "count", len(paths))
+
+				if err := l.ScanPaths(ctx, id, paths); err != nil {
+					l.logger.Error("Failed to scan changed paths", "library", id, "err", err)
+				}
+			}
+		}
+	}
+}
+
+// RequestScan queues a full scan of a library. Requests for a library that is already queued are merged.
+func (l *LibraryManager) RequestScan(id uuid.UUID) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.requestScanLocked(id)
+}
+
+func (l *LibraryManager) requestScanLocked(id uuid.UUID) {
+	if _, ok := l.queued[id]; ok {
+		return
+	}
+
+	select {
+	case l.queue <- id:
+		l.queued[id] = struct{}{}
+	default:
+		l.logger.Warn("Scan queue full, dropping request", "library", id)
+	}
+}
+
+// RequestScanPaths queues a targeted scan of changed paths in a library, merging them with paths already waiting. A
+// full scan is queued instead once too many paths are waiting.
+func (l *LibraryManager) RequestScanPaths(id uuid.UUID, paths []string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// A queued full scan has not started yet, so will see the changes
+	if _, ok := l.queued[id]; ok {
+		return
+	}
+
+	set, ok := l.paths[id]
+	if !ok {
+		set = make(map[string]struct{})
+		l.paths[id] = set
+	}
+
+	for _, path := range paths {
+		set[path] = struct{}{}
+	}
+
+	if len(set) > maxQueuedScanPaths {
+		delete(l.paths, id)
+		l.requestScanLocked(id)
+
+		return
+	}
+
+	select {
+	case l.pathsWake <- struct{}{}:
+	default:
+	}
+}
+
+// RequestScanAll queues a scan of every library.
+func (l *LibraryManager) RequestScanAll(ctx context.Context) error {
+	libs, err := db.ReadWithData(ctx, l.db, getLibraries)
+	if err != nil {
+		return err
+	}
+
+	for _, lib := range libs {
+		l.RequestScan(lib.id)
+	}
+
+	return nil
+}
+
+func (l *LibraryManager) Create(ctx context.Context, name string, root string, onlineLookup bool) (library, error) {
+	root = filepath.Clean(root)
+
+	if !filepath.IsAbs(root) {
+		return library{}, fmt.Errorf("%w: path must be absolute", errInvalidRoot)
+	}
+
+	st, err := os.Stat(root)
+	if err != nil {
+		return library{}, fmt.Errorf("%w: %w", errInvalidRoot, err)
+	}
+
+	if !st.IsDir() {
+		return library{}, fmt.Errorf("%w: not a directory", errInvalidRoot)
+	}
+
+	lib := library{
+		id:           uuid.New(),
+		name:         name,
+		root:         root,
+		onlineLookup: onlineLookup,
+		created:      time.Now().UTC().Truncate(time.Second),
+	}
+
+	err = l.db.Write(ctx, func(ctx context.Context, tx db.WTx) error {
+		exists, err := libraryRootExists(ctx, tx, root)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			return fmt.Errorf("%w: %s", errLibraryExists, root)
+		}
+
+		return insertLibrary(ctx, tx, lib)
+	})
+	if err != nil {
+		return library{}, err
+	}
+
+	l.logger.Info("Library created", "id", lib.id, "root", lib.root, "online", lib.onlineLookup)
+
+	select {
+	case l.watchAdd <- lib:
+	default:
+		l.logger.Warn("Watcher busy, library changes will only be seen on rescan", "id", lib.id)
+	}
+
+	l.RequestScan(lib.id)
+
+	return lib, nil
+}
+
+func (l *LibraryManager) Get(ctx context.Context, id uuid.UUID) (library, error) {
+	return db.ReadWithData(ctx, l.db, func(ctx context.Context, tx db.RTx) (library, error) {
+		return getLibrary(ctx, tx, id)
+	})
+}
+
+func (l *LibraryManager) Update(
+	ctx context.Context,
+	id uuid.UUID,
+	name *string,
+	onlineLookup *bool,
+) (library, error) {
+	return db.WriteWithData(ctx, l.db, func(ctx context.Context, tx db.WTx) (library, error) {
+		lib, err := getLibrary(ctx, tx, id)
+		if err != nil {
+			return library{}, err
+		}
+
+		if name != nil {
+			lib.name = *name
+		}
+
+		if onlineLookup != nil {
+			lib.onlineLookup = *onlineLookup
+		}
+
+		return lib, updateLibrary(ctx, tx, lib)
+	})
+}
+
+// index builds the metadata index used for a library. Online providers are only consulted when enabled for the
+// library.
+func (l *LibraryManager) index(lib library) *mediaserver.Registry {
+	reg := mediaserver.NewRegistry()
+	reg.ErrorHandler = func(provider string, err error) {
+		l.logger.Warn("Metadata provider failed", "library", lib.id, "provider", provider, "err", err)
+	}
+
+	// Names are unique within a freshly created registry
+	_ = reg.Register(mediaserver.Provider{
+		Name:     mediaserver.ProviderLocal,
+		Index:    l.localIndex(lib),
+		Priority: localProviderPriority,
+	})
+
+	if lib.onlineLookup && l.online != nil {
+		_ = reg.Register(mediaserver.Provider{
+			Name:     mediaserver.ProviderTMDB,
+			Index:    l.online,
+			Priority: tmdbProviderPriority,
+		})
+	}
+
+	return reg
+}
+
+// localIndex returns the local metadata index of a library, which loads in full when first used.
+func (l *LibraryManager) localIndex(lib library) *mediaserver.LocalIndex {
+	l.localsMu.Lock()
+	defer l.localsMu.Unlock()
+
+	if local, ok := l.locals[lib.id]; ok {
+		return local
+	}
+
+	local := mediaserver.NewLocalIndex(lib.root)
+	local.TagReader = transcoder.ReadTags
+	local.ErrorHandler = func(path string, err error) {
+		l.logger.Warn("Failed to read metadata path", "library", lib.id, "path", path, "err", err)
+	}
+
+	l.locals[lib.id] = local
+
+	return local
+}
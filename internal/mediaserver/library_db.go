@@ -0,0 +1,613 @@
+package mediaserver
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/csnewman/cathode/internal/db"
+	"github.com/google/uuid"
+)
+
+var errNotFound = errors.New("not found")
+
+// sqliteTimeLayout matches the output of the sqlite datetime() function.
+const sqliteTimeLayout = time.DateTime
+
+type itemKind string
+
+const (
+	itemKindMovie   itemKind = "movie"
+	itemKindShow    itemKind = "show"
+	itemKindSeason  itemKind = "season"
+	itemKindEpisode itemKind = "episode"
+)
+
+type library struct {
+	id           uuid.UUID
+	name         string
+	root         string
+	onlineLookup bool
+	created      time.Time
+}
+
+const libraryColumns = `id, name, root, online_lookup, created`
+
+func scanLibrary(row interface{ Scan(dest ...any) error }) (library, error) {
+	var (
+		res     library
+		created string
+	)
+
+	if err := row.Scan(&res.id, &res.name, &res.root, &res.onlineLookup, &created); err != nil {
+		return library{}, err
+	}
+
+	res.created = parseSQLiteTime(created)
+
+	return res, nil
+}
+
+func getLibraries(_ context.Context, tx db.RTx) ([]library, error) {
+	var libraries []library
+
+	rows, err := tx.Query(`SELECT ` + libraryColumns + ` FROM libraries ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		res, err := scanLibrary(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		libraries = append(libraries, res)
+	}
+
+	return libraries, rows.Err()
+}
+
+func getLibrary(_ context.Context, tx db.RTx, id uuid.UUID) (library, error) {
+	res, err := scanLibrary(tx.QueryRow(`SELECT `+libraryColumns+` FROM libraries WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return library{}, fmt.Errorf("%w: library %v", errNotFound, id)
+	}
+
+	return res, err
+}
+
+func libraryRootExists(_ context.Context, tx db.RTx, root string) (bool, error) {
+	var count int
+
+	err := tx.QueryRow(`SELECT COUNT(*) FROM libraries WHERE root = $1`, root).Scan(&count)
+
+	return count > 0, err
+}
+
+func insertLibrary(_ context.Context, tx db.WTx, lib library) error {
+	return tx.Exec(
+		`INSERT INTO libraries (id, name, root, online_lookup, created) VALUES ($1, $2, $3, $4, $5)`,
+		lib.id,
+		lib.name,
+		lib.root,
+		lib.onlineLookup,
+		lib.created.UTC().Format(sqliteTimeLayout),
+	)
+}
+
+func updateLibrary(_ context.Context, tx db.WTx, lib library) error {
+	return tx.Exec(
+		`UPDATE libraries SET name = $1, online_lookup = $2 WHERE id = $3`,
+		lib.name,
+		lib.onlineLookup,
+		lib.id,
+	)
+}
+
+type item struct {
+	id           uuid.UUID
+	libraryID    uuid.UUID
+	parentID     uuid.NullUUID
+	kind         itemKind
+	path         string
+	fileSize     int64
+	fileMtime    int64
+	name         string
+	originalName string
+	originalLang string
+	overview     string
+	releaseDate  string
+	year         int
+	season       int
+	episode      int
+	status       string
+	posterURL    string
+	backdropURL  string
+	logoURL      string
+}
+
+const itemColumns = `id, library_id, parent_id, kind, path, file_size, file_mtime, name, original_name, original_lang,
+	overview, release_date, year, season_number, episode_number, status, poster_url, backdrop_url, logo_url`
+
+func scanItem(row interface{ Scan(dest ...any) error }) (item, error) {
+	var (
+		res  item
+		kind string
+	)
+
+	err := row.Scan(
+		&res.id,
+		&res.libraryID,
+		&res.parentID,
+		&kind,
+		&res.path,
+		&res.fileSize,
+		&res.fileMtime,
+		&res.name,
+		&res.originalName,
+		&res.originalLang,
+		&res.overview,
+		&res.releaseDate,
+		&res.year,
+		&res.season,
+		&res.episode,
+		&res.status,
+		&res.posterURL,
+		&res.backdropURL,
+		&res.logoURL,
+	)
+	if err != nil {
+		return item{}, err
+	}
+
+	res.kind = itemKind(kind)
+
+	return res, nil
+}
+
+func getItem(_ context.Context, tx db.RTx, id uuid.UUID) (item, error) {
+	res, err := scanItem(tx.QueryRow(`SELECT `+itemColumns+` FROM items WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return item{}, fmt.Errorf("%w: item %v", errNotFound, id)
+	}
+
+	return res, err
+}
+
+func getChildItems(_ context.Context, tx db.RTx, parentID uuid.UUID) ([]item, error) {
+	var items []item
+
+	rows, err := tx.Query(`SELECT `+itemColumns+` FROM items WHERE parent_id = $1`, parentID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		res, err := scanItem(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, res)
+	}
+
+	return items, rows.Err()
+}
+
+func getItemIDs(_ context.Context, tx db.RTx, id uuid.UUID) (map[string]string, error) {
+	ids := make(map[string]string)
+
+	rows, err := tx.Query(`SELECT provider, value FROM item_ids WHERE item_id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var provider, value string
+		if err := rows.Scan(&provider, &value); err != nil {
+			return nil, err
+		}
+
+		ids[provider] = value
+	}
+
+	return ids, rows.Err()
+}
+
+// itemFile is the subset of an item used to detect changes on disk.
+type itemFile struct {
+	id      uuid.UUID
+	size    int64
+	mtime   int64
+	missing bool
+}
+
+func getLibraryFiles(_ context.Context, tx db.RTx, libraryID uuid.UUID) (map[string]itemFile, error) {
+	files := make(map[string]itemFile)
+
+	rows, err := tx.Query(
+		`SELECT id, path, file_size, file_mtime, missing_since IS NOT NULL FROM items
+		WHERE library_id = $1 AND path IS NOT NULL`,
+		libraryID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			res  itemFile
+			path string
+		)
+
+		if err := rows.Scan(&res.id, &path, &res.size, &res.mtime, &res.missing); err != nil {
+			return nil, err
+		}
+
+		files[path] = res
+	}
+
+	return files, rows.Err()
+}
+
+func getItemFile(_ context.Context, tx db.RTx, path string) (itemFile, bool, error) {
+	var res itemFile
+
+	err := tx.QueryRow(
+		`SELECT id, file_size, file_mtime, missing_since IS NOT NULL FROM items WHERE path = $1`,
+		path,
+	).Scan(&res.id, &res.size, &res.mtime, &res.missing)
+	if errors.Is(err, sql.ErrNoRows) {
+		return itemFile{}, false, nil
+	} else if err != nil {
+		return itemFile{}, false, err
+	}
+
+	return res, true, nil
+}
+
+// findMissingItemByFile finds a missing item whose file matched the given size and modification time, allowing
+// renamed or moved files to keep their item.
+func findMissingItemByFile(
+	_ context.Context,
+	tx db.RTx,
+	libraryID uuid.UUID,
+	size int64,
+	mtime int64,
+) (uuid.UUID, bool, error) {
+	var id uuid.UUID
+
+	err := tx.QueryRow(`
+		SELECT id FROM items
+		WHERE library_id = $1 AND missing_since IS NOT NULL AND file_size = $2 AND file_mtime = $3
+		LIMIT 1`,
+		libraryID,
+		size,
+		mtime,
+	).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return uuid.Nil, false, nil
+	} else if err != nil {
+		return uuid.Nil, false, err
+	}
+
+	return id, true, nil
+}
+
+// markItemMissing flags an item whose file has disappeared. Missing items are kept, along with their watch history,
+// until they have been missing for longer than the retention period.
+func markItemMissing(_ context.Context, tx db.WTx, id uuid.UUID) error {
+	return tx.Exec(`UPDATE items SET missing_since = datetime() WHERE id = $1 AND missing_since IS NULL`, id)
+}
+
+// markItemsMissingUnder flags every item with a file inside dir, returning the items flagged.
+func markItemsMissingUnder(_ context.Context, tx db.WTx, libraryID uuid.UUID, dir string) ([]item, error) {
+	rows, err := tx.Query(`
+		UPDATE items SET missing_since = datetime()
+		WHERE library_id = $1 AND missing_since IS NULL AND substr(path, 1, length($2)) = $2
+		RETURNING id, library_id, parent_id, kind`,
+		libraryID,
+		dir,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanItemRefs(rows)
+}
+
+// scanItemRefs reads rows of the id, library, parent and kind of items.
+func scanItemRefs(rows *db.Rows) ([]item, error) {
+	defer rows.Close()
+
+	var items []item
+
+	for rows.Next() {
+		var (
+			it   item
+			kind string
+		)
+
+		if err := rows.Scan(&it.id, &it.libraryID, &it.parentID, &kind); err != nil {
+			return nil, err
+		}
+
+		it.kind = itemKind(kind)
+		items = append(items, it)
+	}
+
+	return items, rows.Err()
+}
+
+func clearItemMissing(_ context.Context, tx db.WTx, id uuid.UUID) error {
+	return tx.Exec(`UPDATE items SET missing_since = NULL WHERE id = $1`, id)
+}
+
+// purgeMissingItems deletes items that have been missing since before the given time.
+func purgeMissingItems(_ context.Context, tx db.WTx, libraryID uuid.UUID, before time.Time) (int64, error) {
+	return tx.ExecRes(
+		`DELETE FROM items WHERE library_id = $1 AND missing_since < $2`,
+		libraryID,
+		before.UTC().Format(sqliteTimeLayout),
+	)
+}
+
+func findItemByProviderID(
+	_ context.Context,
+	tx db.RTx,
+	libraryID uuid.UUID,
+	kind itemKind,
+	provider string,
+	value string,
+) (uuid.UUID, bool, error) {
+	var id uuid.UUID
+
+	err := tx.QueryRow(`
+		SELECT i.id FROM items i
+		JOIN item_ids d ON d.item_id = i.id
+		WHERE i.library_id = $1 AND i.kind = $2 AND d.provider = $3 AND d.value = $4
+		LIMIT 1`,
+		libraryID,
+		string(kind),
+		provider,
+		value,
+	).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return uuid.Nil, false, nil
+	} else if err != nil {
+		return uuid.Nil, false, err
+	}
+
+	return id, true, nil
+}
+
+func findItemByName(
+	_ context.Context,
+	tx db.RTx,
+	libraryID uuid.UUID,
+	kind itemKind,
+	name string,
+) (uuid.UUID, bool, error) {
+	var id uuid.UUID
+
+	err := tx.QueryRow(
+		`SELECT id FROM items WHERE library_id = $1 AND kind = $2 AND name = $3 COLLATE NOCASE LIMIT 1`,
+		libraryID,
+		string(kind),
+		name,
+	).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return uuid.Nil, false, nil
+	} else if err != nil {
+		return uuid.Nil, false, err
+	}
+
+	return id, true, nil
+}
+
+func findSeason(_ context.Context, tx db.RTx, showID uuid.UUID, season int) (uuid.UUID, bool, error) {
+	var id uuid.UUID
+
+	err := tx.QueryRow(
+		`SELECT id FROM items WHERE parent_id = $1 AND kind = $2 AND season_number = $3`,
+		showID,
+		string(itemKindSeason),
+		season,
+	).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return uuid.Nil, false, nil
+	} else if err != nil {
+		return uuid.Nil, false, err
+	}
+
+	return id, true, nil
+}
+
+// itemImages holds the cached image ids for the artwork of an item.
+type itemImages struct {
+	poster   uuid.NullUUID
+	backdrop uuid.NullUUID
+	logo     uuid.NullUUID
+}
+
+// ids returns the ids of the images that are set.
+func (i itemImages) ids() []uuid.UUID {
+	var ids []uuid.UUID
+
+	for _, id := range []uuid.NullUUID{i.poster, i.backdrop, i.logo} {
+		if id.Valid {
+			ids = append(ids, id.UUID)
+		}
+	}
+
+	return ids
+}
+
+func upsertItem(_ context.Context, tx db.WTx, it item, images itemImages) error {
+	return tx.Exec(`
+		INSERT INTO items (
+			id, library_id, parent_id, kind, path, file_size, file_mtime, name, original_name, original_lang, overview,
+			release_date, year, season_number, episode_number, status, poster_url, backdrop_url, logo_url, poster_image,
+			backdrop_image, logo_image, added, updated
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22,
+			datetime(), datetime()
+		)
+		ON CONFLICT (id) DO UPDATE SET
+			parent_id = excluded.parent_id,
+			path = excluded.path,
+			file_size = excluded.file_size,
+			file_mtime = excluded.file_mtime,
+			name = excluded.name,
+			original_name = excluded.original_name,
+			original_lang = excluded.original_lang,
+			overview = excluded.overview,
+			release_date = excluded.release_date,
+			year = excluded.year,
+			season_number = excluded.season_number,
+			episode_number = excluded.episode_number,
+			status = excluded.status,
+			poster_url = excluded.poster_url,
+			backdrop_url = excluded.backdrop_url,
+			logo_url = excluded.logo_url,
+			poster_image = excluded.poster_image,
+			backdrop_image = excluded.backdrop_image,
+			logo_image = excluded.logo_image,
+			missing_since = NULL,
+			updated = excluded.updated`,
+		it.id,
+		it.libraryID,
+		it.parentID,
+		string(it.kind),
+		nullString(it.path),
+		nullInt(it.fileSize, it.path != ""),
+		nullInt(it.fileMtime, it.path != ""),
+		it.name,
+		it.originalName,
+		it.originalLang,
+		it.overview,
+		it.releaseDate,
+		nullInt(int64(it.year), it.year != 0),
+		nullInt(int64(it.season), it.kind == itemKindSeason || it.kind == itemKindEpisode),
+		nullInt(int64(it.episode), it.kind == itemKindEpisode),
+		it.status,
+		it.posterURL,
+		it.backdropURL,
+		it.logoURL,
+		images.poster,
+		images.backdrop,
+		images.logo,
+	)
+}
+
+func setItemIDs(_ context.Context, tx db.WTx, id uuid.UUID, ids map[string]string) error {
+	if err := tx.Exec(`DELETE FROM item_ids WHERE item_id = $1`, id); err != nil {
+		return err
+	}
+
+	for provider, value := range ids {
+		if value == "" {
+			continue
+		}
+
+		err := tx.Exec(`INSERT INTO item_ids (item_id, provider, value) VALUES ($1, $2, $3)`, id, provider, value)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func setItemGenres(_ context.Context, tx db.WTx, id uuid.UUID, genres []string) error {
+	if err := tx.Exec(`DELETE FROM item_genres WHERE item_id = $1`, id); err != nil {
+		return err
+	}
+
+	for _, genre := range genres {
+		err := tx.Exec(`INSERT OR IGNORE INTO item_genres (item_id, genre) VALUES ($1, $2)`, id, genre)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func setItemCast(_ context.Context, tx db.WTx, id uuid.UUID, cast []string) error {
+	if err := tx.Exec(`DELETE FROM item_cast WHERE item_id = $1`, id); err != nil {
+		return err
+	}
+
+	for i, name := range cast {
+		err := tx.Exec(`INSERT INTO item_cast (item_id, position, name) VALUES ($1, $2, $3)`, id, i, name)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// cleanupEmptyItems removes seasons and shows that no longer contain any episodes.
+func cleanupEmptyItems(_ context.Context, tx db.WTx, libraryID uuid.UUID) ([]item, error) {
+	var removed []item
+
+	for _, kind := range []itemKind{itemKindSeason, itemKindShow} {
+		rows, err := tx.Query(`
+			DELETE FROM items
+			WHERE library_id = $1 AND kind = $2 AND NOT EXISTS (SELECT 1 FROM items c WHERE c.parent_id = items.id)
+			RETURNING id, library_id, parent_id, kind`,
+			libraryID,
+			string(kind),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		items, err := scanItemRefs(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		removed = append(removed, items...)
+	}
+
+	return removed, nil
+}
+
+func nullString(v string) any {
+	if v == "" {
+		return nil
+	}
+
+	return v
+}
+
+func nullInt(v int64, valid bool) any {
+	if !valid {
+		return nil
+	}
+
+	return v
+}
+
+func parseSQLiteTime(v string) time.Time {
+	t, err := time.ParseInLocation(sqliteTimeLayout, v, time.UTC)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return t
+}
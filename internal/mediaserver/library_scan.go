@@ -0,0 +1,777 @@
+package mediaserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/csnewman/cathode/internal/db"
+	"github.com/csnewman/cathode/mediaserver"
+	"github.com/google/uuid"
+)
+
+var mediaExtensions = map[string]struct{}{
+	".mkv":  {},
+	".mp4":  {},
+	".m4v":  {},
+	".avi":  {},
+	".mov":  {},
+	".webm": {},
+	".ts":   {},
+	".m2ts": {},
+	".wmv":  {},
+	".mpg":  {},
+	".mpeg": {},
+	".flv":  {},
+	".ogv":  {},
+}
+
+func isMediaFile(path string) bool {
+	_, ok := mediaExtensions[strings.ToLower(filepath.Ext(path))]
+
+	return ok
+}
+
+// libraryScan holds the state of a single scan of a library.
+type libraryScan struct {
+	manager *LibraryManager
+	lib     library
+	index   *mediaserver.Registry
+	// shows caches show details by show key, so each show is only looked up once per scan.
+	shows map[string]*mediaserver.Details
+	// pendingImages holds images referenced by written items, which are prefetched once the write commits.
+	pendingImages []uuid.UUID
+	// pendingEvents holds events about written items, which are published once the write commits.
+	pendingEvents []event
+}
+
+// missingRetention is how long items stay in the library after their file disappears. Keeping them for a while
+// means a temporarily unavailable share does not lose watch history.
+const missingRetention = 30 * 24 * time.Hour
+
+var errRootUnavailable = errors.New("library root unavailable")
+
+func (l *LibraryManager) newScan(lib library) *libraryScan {
+	return &libraryScan{
+		manager: l,
+		lib:     lib,
+		index:   l.index(lib),
+		shows:   make(map[string]*mediaserver.Details),
+	}
+}
+
+// Scan walks a library root, importing new and changed media files and marking items whose files have gone as
+// missing.
+func (l *LibraryManager) Scan(ctx context.Context, id uuid.UUID) error {
+	l.scanMu.Lock()
+	defer l.scanMu.Unlock()
+
+	lib, err := l.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// An unreadable root most likely means the share is unmounted, rather than that every file was deleted
+	if _, err := os.ReadDir(lib.root); err != nil {
+		return fmt.Errorf("%w: %w", errRootUnavailable, err)
+	}
+
+	l.logger.Info("Scanning library", "id", lib.id, "root", lib.root)
+	start := time.Now()
+
+	progress := scanEvent{
+		libraryID: lib.id,
+		state:     scanStateStarted,
+	}
+
+	l.publishScan(progress)
+
+	err = l.scan(ctx, lib, &progress)
+	if err != nil {
+		progress.state = scanStateFailed
+	} else {
+		progress.state = scanStateCompleted
+	}
+
+	l.publishScan(progress)
+
+	if err != nil {
+		return err
+	}
+
+	l.logger.Info(
+		"Library scan complete",
+		"id", lib.id,
+		"imported", progress.imported,
+		"failed", progress.failed,
+		"missing", progress.missing,
+		"took", time.Since(start),
+	)
+
+	return nil
+}
+
+// scanProgressInterval is the most often progress is published during a scan.
+const scanProgressInterval = time.Second
+
+func (l *LibraryManager) publishScan(progress scanEvent) {
+	l.events.Publish(event{
+		typ:  eventLibraryScan,
+		data: progress,
+	})
+}
+
+func (l *LibraryManager) scan(ctx context.Context, lib library, progress *scanEvent) error {
+	known, err := db.ReadWithData(ctx, l.db, func(ctx context.Context, tx db.RTx) (map[string]itemFile, error) {
+		return getLibraryFiles(ctx, tx, lib.id)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load library files: %w", err)
+	}
+
+	// Changes may have been missed, so the local index is rebuilt along with the library
+	l.localIndex(lib).Invalidate()
+
+	s := l.newScan(lib)
+
+	progress.state = scanStateRunning
+	lastProgress := time.Now()
+
+	seen := make(map[string]struct{})
+
+	err = filepath.WalkDir(lib.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			l.logger.Warn("Failed to read library path", "path", path, "err", err)
+
+			return nil
+		}
+
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		if d.IsDir() {
+			if path != lib.root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if !d.Type().IsRegular() || !isMediaFile(path) {
+			return nil
+		}
+
+		seen[path] = struct{}{}
+
+		info, err := d.Info()
+		if err != nil {
+			l.logger.Warn("Failed to stat media file", "path", path, "err", err)
+
+			return nil
+		}
+
+		prev, exists := known[path]
+
+		changed, err := s.scanFile(ctx, path, info, prev, exists)
+		if err != nil {
+			l.logger.Warn("Failed to import media file", "path", path, "err", err)
+
+			progress.failed++
+		} else if changed {
+			progress.imported++
+		}
+
+		progress.scanned++
+
+		if time.Since(lastProgress) >= scanProgressInterval {
+			l.publishScan(*progress)
+			lastProgress = time.Now()
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to walk library: %w", err)
+	}
+
+	var purged int64
+
+	s.pendingEvents = s.pendingEvents[:0]
+
+	err = l.db.Write(ctx, func(ctx context.Context, tx db.WTx) error {
+		for path, f := range known {
+			if _, ok := seen[path]; ok || f.missing {
+				continue
+			}
+
+			if err := markItemMissing(ctx, tx, f.id); err != nil {
+				return err
+			}
+
+			s.itemRemoved(item{id: f.id, libraryID: lib.id})
+
+			progress.missing++
+		}
+
+		var err error
+
+		if purged, err = purgeMissingItems(ctx, tx, lib.id, time.Now().Add(-missingRetention)); err != nil {
+			return err
+		}
+
+		removed, err := cleanupEmptyItems(ctx, tx, lib.id)
+		if err != nil {
+			return err
+		}
+
+		for _, it := range removed {
+			s.itemRemoved(it)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update missing items: %w", err)
+	}
+
+	l.events.Publish(s.pendingEvents...)
+
+	if purged > 0 {
+		l.logger.Info("Purged missing items", "id", lib.id, "purged", purged)
+	}
+
+	return nil
+}
+
+// ScanPaths updates only the given paths of a library. Paths that no longer exist are marked missing, and
+// directories are scanned recursively.
+func (l *LibraryManager) ScanPaths(ctx context.Context, id uuid.UUID, paths []string) error {
+	l.scanMu.Lock()
+	defer l.scanMu.Unlock()
+
+	lib, err := l.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	l.localIndex(lib).Refresh(paths...)
+
+	s := l.newScan(lib)
+
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := s.scanPath(ctx, path); err != nil {
+			l.logger.Warn("Failed to scan path", "path", path, "err", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *libraryScan) scanPath(ctx context.Context, path string) error {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		s.manager.logger.Debug("Path removed", "path", path)
+
+		s.pendingEvents = s.pendingEvents[:0]
+
+		err := s.manager.db.Write(ctx, func(ctx context.Context, tx db.WTx) error {
+			f, exists, err := getItemFile(ctx, tx, path)
+			if err != nil {
+				return err
+			}
+
+			if exists {
+				if !f.missing {
+					s.itemRemoved(item{id: f.id, libraryID: s.lib.id})
+				}
+
+				return markItemMissing(ctx, tx, f.id)
+			}
+
+			// The path may have been a directory
+			removed, err := markItemsMissingUnder(ctx, tx, s.lib.id, path+string(filepath.Separator))
+			if err != nil {
+				return err
+			}
+
+			for _, it := range removed {
+				s.itemRemoved(it)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		s.manager.events.Publish(s.pendingEvents...)
+
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		return filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+
+			if d.IsDir() {
+				if p != path && strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+
+				return nil
+			}
+
+			if err := s.scanPath(ctx, p); err != nil {
+				s.manager.logger.Warn("Failed to scan path", "path", p, "err", err)
+			}
+
+			return nil
+		})
+	}
+
+	if !info.Mode().IsRegular() || !isMediaFile(path) {
+		return nil
+	}
+
+	var (
+		prev   itemFile
+		exists bool
+	)
+
+	err = s.manager.db.Read(ctx, func(ctx context.Context, tx db.RTx) error {
+		var err error
+
+		prev, exists, err = getItemFile(ctx, tx, path)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.scanFile(ctx, path, info, prev, exists)
+
+	return err
+}
+
+// scanFile imports a media file if it is new or has changed since it was last imported.
+func (s *libraryScan) scanFile(
+	ctx context.Context,
+	path string,
+	info fs.FileInfo,
+	prev itemFile,
+	exists bool,
+) (bool, error) {
+	if exists && prev.size == info.Size() && prev.mtime == info.ModTime().Unix() {
+		if !prev.missing {
+			return false, nil
+		}
+
+		// The file is back unchanged, such as after a share is remounted
+		err := s.manager.db.Write(ctx, func(ctx context.Context, tx db.WTx) error {
+			return clearItemMissing(ctx, tx, prev.id)
+		})
+		if err != nil {
+			return false, err
+		}
+
+		s.manager.events.Publish(newItemEvent(eventItemAdded, item{id: prev.id, libraryID: s.lib.id}))
+
+		return false, nil
+	}
+
+	s.pendingImages = s.pendingImages[:0]
+	s.pendingEvents = s.pendingEvents[:0]
+
+	if err := s.importFile(ctx, path, info, prev, exists); err != nil {
+		return false, err
+	}
+
+	if s.manager.images != nil {
+		s.manager.images.Prefetch(s.pendingImages...)
+	}
+
+	s.manager.events.Publish(s.pendingEvents...)
+
+	return true, nil
+}
+
+func (s *libraryScan) importFile(
+	ctx context.Context,
+	path string,
+	info fs.FileInfo,
+	prev itemFile,
+	exists bool,
+) error {
+	ident, err := s.index.Identify(path)
+	if err != nil {
+		return fmt.Errorf("failed to identify: %w", err)
+	}
+
+	existingID := prev.id
+
+	// Missing items were removed as far as clients are concerned
+	change := eventItemUpdated
+	if !exists || prev.missing {
+		change = eventItemAdded
+	}
+
+	if !exists {
+		// A missing item with an identical file was most likely renamed or moved, so keep its id and history
+		err := s.manager.db.Read(ctx, func(ctx context.Context, tx db.RTx) error {
+			var err error
+
+			existingID, exists, err = findMissingItemByFile(ctx, tx, s.lib.id, info.Size(), info.ModTime().Unix())
+
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if !exists {
+		existingID = uuid.New()
+	}
+
+	it := item{
+		id:        existingID,
+		libraryID: s.lib.id,
+		path:      path,
+		fileSize:  info.Size(),
+		fileMtime: info.ModTime().Unix(),
+	}
+
+	s.resolveIDs(ident)
+
+	if ident.Episode == nil {
+		details := s.details(mediaserver.MediaTypeMovie, ident)
+
+		it.kind = itemKindMovie
+		applyDetails(&it, details)
+
+		return s.manager.db.Write(ctx, func(ctx context.Context, tx db.WTx) error {
+			if err := s.writeItem(ctx, tx, it, details.IDs, details.Genres, details.Cast); err != nil {
+				return err
+			}
+
+			s.pendingEvents = append(s.pendingEvents, newItemEvent(change, it))
+
+			return nil
+		})
+	}
+
+	show := s.show(ident)
+
+	ref := *ident.Episode
+	ref.Show = show.IDs
+
+	episode, err := s.index.Episode(ref)
+	if err != nil {
+		if !errors.Is(err, mediaserver.ErrNotFound) {
+			s.manager.logger.Warn("Episode lookup failed", "path", path, "err", err)
+		}
+
+		episode = &mediaserver.EpisodeDetails{
+			IDs:    ref.Episode,
+			Season: ref.Season,
+			Number: ref.Number,
+		}
+	}
+
+	it.kind = itemKindEpisode
+	it.name = episode.Name
+	it.overview = episode.Overview
+	it.releaseDate = episode.AirDate
+	it.posterURL = episode.StillURL
+	it.season = ref.Season
+	it.episode = ref.Number
+
+	if it.name == "" {
+		it.name = fmt.Sprintf("Episode %d", ref.Number)
+	}
+
+	return s.manager.db.Write(ctx, func(ctx context.Context, tx db.WTx) error {
+		showID, err := s.ensureShow(ctx, tx, show)
+		if err != nil {
+			return err
+		}
+
+		seasonID, err := s.ensureSeason(ctx, tx, showID, ref.Season)
+		if err != nil {
+			return err
+		}
+
+		it.parentID = uuid.NullUUID{UUID: seasonID, Valid: true}
+
+		if err := s.writeItem(ctx, tx, it, episode.IDs, nil, nil); err != nil {
+			return err
+		}
+
+		s.pendingEvents = append(s.pendingEvents, newItemEvent(change, it))
+
+		return nil
+	})
+}
+
+// resolveIDs searches for the online ids of media which could not be identified by id locally. The first result with
+// a matching type, and year when known, is assumed to be correct.
+func (s *libraryScan) resolveIDs(ident *mediaserver.Identification) {
+	if !s.lib.onlineLookup || ident.IDs[mediaserver.ProviderTMDB] != "" || ident.Name == "" {
+		return
+	}
+
+	results, err := s.index.Search(ident.Name)
+	if err != nil {
+		s.manager.logger.Warn("Metadata search failed", "name", ident.Name, "err", err)
+
+		return
+	}
+
+	for _, res := range results {
+		if res.Type != ident.Type || res.IDs[mediaserver.ProviderTMDB] == "" {
+			continue
+		}
+
+		if ident.Year != "" && !strings.HasPrefix(res.ReleaseDate, ident.Year) {
+			continue
+		}
+
+		for k, v := range res.IDs {
+			if _, ok := ident.IDs[k]; !ok {
+				ident.IDs[k] = v
+			}
+		}
+
+		return
+	}
+}
+
+// details fetches the full record for an identified movie or show, falling back to what was identified locally.
+func (s *libraryScan) details(t mediaserver.MediaType, ident *mediaserver.Identification) *mediaserver.Details {
+	details, err := s.index.Details(t, ident.IDs)
+	if err != nil {
+		if !errors.Is(err, mediaserver.ErrNotFound) {
+			s.manager.logger.Warn("Metadata lookup failed", "name", ident.Name, "err", err)
+		}
+
+		details = &mediaserver.Details{
+			SearchResult: mediaserver.SearchResult{
+				Type:        t,
+				IDs:         ident.IDs,
+				Name:        ident.Name,
+				ReleaseDate: ident.Year,
+			},
+		}
+	}
+
+	if details.Name == "" {
+		details.Name = ident.Name
+	}
+
+	if details.IDs == nil {
+		details.IDs = make(mediaserver.IDs)
+	}
+
+	return details
+}
+
+func (s *libraryScan) show(ident *mediaserver.Identification) *mediaserver.Details {
+	key := showKey(ident.IDs, ident.Name)
+
+	if show, ok := s.shows[key]; ok {
+		return show
+	}
+
+	show := s.details(mediaserver.MediaTypeTv, ident)
+	s.shows[key] = show
+
+	return show
+}
+
+// ensureShow returns the id of the show item, creating or updating it as required. Shows are matched by provider id
+// and then by name, so episodes identified from different sources end up grouped together.
+func (s *libraryScan) ensureShow(ctx context.Context, tx db.WTx, show *mediaserver.Details) (uuid.UUID, error) {
+	var (
+		id    uuid.UUID
+		found bool
+		err   error
+	)
+
+	for _, provider := range []string{mediaserver.ProviderTMDB, mediaserver.ProviderIMDB, mediaserver.ProviderLocal} {
+		value := show.IDs[provider]
+		if value == "" {
+			continue
+		}
+
+		id, found, err = findItemByProviderID(ctx, tx, s.lib.id, itemKindShow, provider, value)
+		if err != nil {
+			return uuid.Nil, err
+		}
+
+		if found {
+			break
+		}
+	}
+
+	if !found {
+		id, found, err = findItemByName(ctx, tx, s.lib.id, itemKindShow, show.Name)
+		if err != nil {
+			return uuid.Nil, err
+		}
+	}
+
+	if !found {
+		id = uuid.New()
+	}
+
+	it := item{
+		id:        id,
+		libraryID: s.lib.id,
+		kind:      itemKindShow,
+	}
+
+	applyDetails(&it, show)
+
+	if err := s.writeItem(ctx, tx, it, show.IDs, show.Genres, show.Cast); err != nil {
+		return uuid.Nil, err
+	}
+
+	// Shows are rewritten for each of their episodes, so only their creation is published
+	if !found {
+		s.pendingEvents = append(s.pendingEvents, newItemEvent(eventItemAdded, it))
+	}
+
+	return id, nil
+}
+
+func (s *libraryScan) ensureSeason(ctx context.Context, tx db.WTx, showID uuid.UUID, season int) (uuid.UUID, error) {
+	id, found, err := findSeason(ctx, tx, showID, season)
+	if err != nil || found {
+		return id, err
+	}
+
+	name := "Season " + strconv.Itoa(season)
+	if season == 0 {
+		name = "Specials"
+	}
+
+	it := item{
+		id:        uuid.New(),
+		libraryID: s.lib.id,
+		parentID:  uuid.NullUUID{UUID: showID, Valid: true},
+		kind:      itemKindSeason,
+		name:      name,
+		season:    season,
+	}
+
+	if err := upsertItem(ctx, tx, it, itemImages{}); err != nil {
+		return uuid.Nil, err
+	}
+
+	s.pendingEvents = append(s.pendingEvents, newItemEvent(eventItemAdded, it))
+
+	return it.id, nil
+}
+
+func (s *libraryScan) itemRemoved(it item) {
+	s.pendingEvents = append(s.pendingEvents, newItemEvent(eventItemRemoved, it))
+}
+
+func (s *libraryScan) writeItem(
+	ctx context.Context,
+	tx db.WTx,
+	it item,
+	ids mediaserver.IDs,
+	genres []string,
+	cast []string,
+) error {
+	var (
+		images itemImages
+		err    error
+	)
+
+	if images.poster, err = registerImage(ctx, tx, it.posterURL); err != nil {
+		return fmt.Errorf("failed to register poster: %w", err)
+	}
+
+	if images.backdrop, err = registerImage(ctx, tx, it.backdropURL); err != nil {
+		return fmt.Errorf("failed to register backdrop: %w", err)
+	}
+
+	if images.logo, err = registerImage(ctx, tx, it.logoURL); err != nil {
+		return fmt.Errorf("failed to register logo: %w", err)
+	}
+
+	s.pendingImages = append(s.pendingImages, images.ids()...)
+
+	if err := upsertItem(ctx, tx, it, images); err != nil {
+		return fmt.Errorf("failed to write item: %w", err)
+	}
+
+	if err := setItemIDs(ctx, tx, it.id, ids); err != nil {
+		return fmt.Errorf("failed to write item ids: %w", err)
+	}
+
+	if err := setItemGenres(ctx, tx, it.id, genres); err != nil {
+		return fmt.Errorf("failed to write item genres: %w", err)
+	}
+
+	if err := setItemCast(ctx, tx, it.id, cast); err != nil {
+		return fmt.Errorf("failed to write item cast: %w", err)
+	}
+
+	if it.kind == itemKindMovie || it.kind == itemKindShow {
+		// The scan has just fetched fresh metadata, so the first refresh is a full interval away
+		next := time.Now().Add(refreshInterval(it.kind, it.status))
+
+		if err := ensureMetadataJob(ctx, tx, it.id, next); err != nil {
+			return fmt.Errorf("failed to schedule metadata refresh: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func applyDetails(it *item, d *mediaserver.Details) {
+	it.name = d.Name
+	it.originalName = d.OriginalName
+	it.originalLang = d.OriginalLang
+	it.overview = d.Overview
+	it.releaseDate = d.ReleaseDate
+	it.status = string(d.Status)
+	it.posterURL = d.PosterURL
+	it.backdropURL = d.BackdropURL
+	it.logoURL = d.LogoURL
+
+	if len(d.ReleaseDate) >= 4 {
+		it.year, _ = strconv.Atoi(d.ReleaseDate[:4])
+	}
+}
+
+func showKey(ids mediaserver.IDs, name string) string {
+	if v := ids[mediaserver.ProviderTMDB]; v != "" {
+		return mediaserver.ProviderTMDB + ":" + v
+	}
+
+	if v := ids[mediaserver.ProviderLocal]; v != "" {
+		return mediaserver.ProviderLocal + ":" + v
+	}
+
+	return "name:" + strings.ToLower(strings.TrimSpace(name))
+}
@@ -0,0 +1,218 @@
+package mediaserver
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/csnewman/cathode/internal/db"
+	"github.com/fsnotify/fsnotify"
+	"github.com/google/uuid"
+)
+
+const (
+	// watchQuietPeriod is how long a path must go without events, and without its size changing, before it is
+	// scanned. This avoids importing files that are still being downloaded or copied.
+	watchQuietPeriod = 5 * time.Second
+	watchTick        = time.Second
+)
+
+// watchedPath tracks a path with pending events.
+type watchedPath struct {
+	library uuid.UUID
+	// settled is when the path last changed, either through an event or a change in size.
+	settled time.Time
+	size    int64
+	mtime   time.Time
+}
+
+// Watch follows filesystem events under every library root, queuing affected paths for scanning once they settle. It
+// only collects events, leaving the scans to Run, so that it keeps up with bursts of events. It runs until the context
+// is cancelled.
+func (l *LibraryManager) Watch(ctx context.Context) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		l.logger.Error("Failed to create filesystem watcher", "err", err)
+
+		return
+	}
+
+	defer w.Close()
+
+	roots := make(map[string]uuid.UUID)
+
+	libs, err := db.ReadWithData(ctx, l.db, getLibraries)
+	if err != nil {
+		l.logger.Error("Failed to load libraries for watching", "err", err)
+	}
+
+	for _, lib := range libs {
+		roots[lib.root] = lib.id
+		l.watchTree(w, lib.root)
+	}
+
+	pending := make(map[string]*watchedPath)
+
+	ticker := time.NewTicker(watchTick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case lib := <-l.watchAdd:
+			roots[lib.root] = lib.id
+			l.watchTree(w, lib.root)
+
+		case ev, ok := <-w.Events:
+			if !ok {
+				return
+			}
+
+			if ev.Op == fsnotify.Chmod || strings.HasPrefix(filepath.Base(ev.Name), ".") {
+				continue
+			}
+
+			id, ok := libraryForPath(roots, ev.Name)
+			if !ok {
+				continue
+			}
+
+			// New directories need watching before anything is written into them
+			if ev.Has(fsnotify.Create) {
+				if st, err := os.Stat(ev.Name); err == nil && st.IsDir() {
+					l.watchTree(w, ev.Name)
+				}
+			}
+
+			p, ok := pending[ev.Name]
+			if !ok {
+				p = &watchedPath{library: id, size: -1}
+				pending[ev.Name] = p
+			}
+
+			p.settled = time.Now()
+
+		case err, ok := <-w.Errors:
+			if !ok {
+				return
+			}
+
+			l.logger.Error("Filesystem watcher error", "err", err)
+
+			// Events may have been dropped, so fall back to full scans
+			for _, id := range roots {
+				l.RequestScan(id)
+			}
+
+		case <-ticker.C:
+			l.flushWatched(pending)
+		}
+	}
+}
+
+// flushWatched queues paths that have settled for scanning, grouped by library.
+func (l *LibraryManager) flushWatched(pending map[string]*watchedPath) {
+	now := time.Now()
+	ready := make(map[uuid.UUID][]string)
+
+	for path, p := range pending {
+		if now.Sub(p.settled) < watchQuietPeriod {
+			continue
+		}
+
+		st, err := os.Stat(path)
+
+		if err == nil && st.IsDir() {
+			// Files already inside a new directory produced no events of their own, so track them individually
+			delete(pending, path)
+			l.watchDirFiles(pending, p.library, path, now)
+
+			continue
+		}
+
+		if err == nil && st.Mode().IsRegular() {
+			// Files being written without generating events, such as over some network shares, are caught by
+			// requiring the size to stay the same across a full quiet period
+			if st.Size() != p.size || !st.ModTime().Equal(p.mtime) {
+				p.size = st.Size()
+				p.mtime = st.ModTime()
+				p.settled = now
+
+				continue
+			}
+		}
+
+		delete(pending, path)
+
+		ready[p.library] = append(ready[p.library], path)
+	}
+
+	for id, paths := range ready {
+		l.RequestScanPaths(id, paths)
+	}
+}
+
+// watchDirFiles adds every media file under dir to the pending set.
+func (l *LibraryManager) watchDirFiles(pending map[string]*watchedPath, id uuid.UUID, dir string, now time.Time) {
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		if d.IsDir() {
+			if path != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if _, ok := pending[path]; !ok && isMediaFile(path) {
+			pending[path] = &watchedPath{library: id, settled: now, size: -1}
+		}
+
+		return nil
+	})
+}
+
+// watchTree adds a watch on a directory and all of its subdirectories. Inotify watches are not recursive.
+func (l *LibraryManager) watchTree(w *fsnotify.Watcher, root string) {
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		if !d.IsDir() {
+			return nil
+		}
+
+		if path != root && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+
+		if err := w.Add(path); err != nil {
+			l.logger.Warn("Failed to watch directory", "path", path, "err", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		l.logger.Warn("Failed to watch library", "root", root, "err", err)
+	}
+}
+
+// libraryForPath returns the library whose root contains path.
+func libraryForPath(roots map[string]uuid.UUID, path string) (uuid.UUID, bool) {
+	for root, id := range roots {
+		if path == root || strings.HasPrefix(path, root+string(filepath.Separator)) {
+			return id, true
+		}
+	}
+
+	return uuid.Nil, false
+}
@@ -0,0 +1,550 @@
+package mediaserver
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "github.com/csnewman/cathode/internal/v1"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/vmihailenco/msgpack/v5"
+	"golang.org/x/crypto/hkdf"
+)
+
+// The link protocol lets paired devices call the v1 API over plain HTTP POSTs to /v1, with each request and response
+// encrypted using a secret negotiated during pairing. Requests are framed as:
+//
+//	device id (16) | nonce (16) | ciphertext | mac (32)
+//
+// and responses as:
+//
+//	nonce (16) | ciphertext | mac (32)
+//
+// The ciphertext is msgpack encrypted with AES-128-CTR, using the nonce as the initial counter block. The mac is
+// HMAC-SHA256 over everything before it, with responses also covering the request nonce to bind them to the request.
+// Requests and responses use separate keys, derived from the link secret with HKDF-SHA256.
+//
+// Requests name an operation from the v1 spec, which is served by the same handlers as the REST API, as the device's
+// user:
+//
+//	{"ts": <unix seconds>, "op": "get-item", "params": {"itemId": "..."}, "body": {...}}
+//
+// Responses hold the status and decoded JSON body of the operation:
+//
+//	{"status": 200, "body": {...}}
+//
+// Responses are buffered whole, so only operations answering with JSON or no body can be called over the link.
+// Streams, such as events, media and images, must be requested directly.
+
+const (
+	linkNonceSize = 16
+	linkMacSize   = sha256.Size
+	linkIDSize    = 16
+	// linkReplayWindow is how far a request timestamp may be from the server's clock. Nonces are remembered for this
+	// long, after which the timestamp check rejects replays.
+	linkReplayWindow = time.Minute
+	linkMaxRequest   = 1 << 20
+	// linkMaxResponse bounds the response of an operation, which is buffered before being encrypted.
+	linkMaxResponse = 4 << 20
+
+	linkSecretInfo   = "cathode link secret"
+	linkRequestInfo  = "cathode link request"
+	linkResponseInfo = "cathode link response"
+)
+
+var (
+	errLinkReplay = errors.New("link request replayed or outside the replay window")
+	errLinkMac    = errors.New("link mac mismatch")
+	errLinkLarge  = errors.New("link response too large")
+)
+
+// negotiateLinkSecret generates a server key pair for a device's link key, returning the derived secret and the
+// server's public key to hand to the device.
+func negotiateLinkSecret(deviceKey *ecdh.PublicKey) ([]byte, []byte, error) {
+	serverKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	shared, err := serverKey.ECDH(deviceKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", errInvalidLinkKey, err)
+	}
+
+	serverPub := serverKey.PublicKey().Bytes()
+
+	info := make([]byte, 0, len(linkSecretInfo)+64)
+	info = append(info, linkSecretInfo...)
+	info = append(info, deviceKey.Bytes()...)
+	info = append(info, serverPub...)
+
+	secret, err := linkDerive(shared, info, 32)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return secret, serverPub, nil
+}
+
+func linkDerive(secret []byte, info []byte, size int) ([]byte, error) {
+	buf := make([]byte, size)
+
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, nil, info), buf); err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+// linkKeys derives the cipher and mac key for one direction of the link.
+func linkKeys(secret []byte, info string) (cipher.Block, []byte, error) {
+	buf, err := linkDerive(secret, []byte(info), 16+32)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	block, err := aes.NewCipher(buf[:16])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return block, buf[16:], nil
+}
+
+type linkRequest struct {
+	Timestamp int64          `msgpack:"ts"`
+	Op        string         `msgpack:"op"`
+	Params    map[string]any `msgpack:"params"`
+	Body      any            `msgpack:"body"`
+}
+
+type linkResponse struct {
+	Status int `msgpack:"status"`
+	Body   any `msgpack:"body"`
+}
+
+type linkOp struct {
+	method string
+	path   string
+	params openapi3.Parameters
+	// buffered is set for operations whose successful responses are JSON or empty, which are the only ones the link
+	// serves.
+	buffered bool
+}
+
+type linkHandler struct {
+	logger  *slog.Logger
+	auth    *AuthManager
+	handler http.Handler
+	ops     map[string]linkOp
+
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+// newLinkHandler creates the link endpoint, dispatching operations to handler, which must serve the v1 API under
+// /api/v1.
+func newLinkHandler(logger *slog.Logger, auth *AuthManager, handler http.Handler) (*linkHandler, error) {
+	spec, err := v1.GetSwagger()
+	if err != nil {
+		return nil, err
+	}
+
+	ops := make(map[string]linkOp)
+
+	for path, item := range spec.Paths {
+		for method, op := range item.Operations() {
+			if op.OperationID == "" {
+				continue
+			}
+
+			params := make(openapi3.Parameters, 0, len(item.Parameters)+len(op.Parameters))
+			params = append(params, item.Parameters...)
+			params = append(params, op.Parameters...)
+
+			ops[linkOpName(op.OperationID)] = linkOp{
+				method:   method,
+				path:     path,
+				params:   params,
+				buffered: linkBuffered(op),
+			}
+		}
+	}
+
+	return &linkHandler{
+		logger:  logger,
+		auth:    auth,
+		handler: handler,
+		ops:     ops,
+		seen:    make(map[string]time.Time),
+	}, nil
+}
+
+func (h *linkHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	frame, err := io.ReadAll(http.MaxBytesReader(w, r.Body, linkMaxRequest))
+	if err != nil || len(frame) < linkIDSize+linkNonceSize+linkMacSize {
+		http.Error(w, "invalid link request", http.StatusBadRequest)
+
+		return
+	}
+
+	deviceID, err := uuid.FromBytes(frame[:linkIDSize])
+	if err != nil {
+		http.Error(w, "invalid link request", http.StatusBadRequest)
+
+		return
+	}
+
+	d, err := h.auth.LinkDevice(r.Context(), deviceID)
+	if errors.Is(err, errInvalidCredentials) {
+		http.Error(w, "unknown device", http.StatusUnauthorized)
+
+		return
+	} else if err != nil {
+		h.logger.Error("Failed to authenticate link request", "err", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+
+		return
+	}
+
+	nonce := frame[linkIDSize : linkIDSize+linkNonceSize]
+
+	secret := d.device.linkSecret
+
+	req, err := h.open(secret, frame)
+	if errors.Is(err, errLinkMac) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+
+		return
+	} else if err != nil {
+		h.logger.Warn("Invalid link request", "device", deviceID, "err", err)
+		http.Error(w, "invalid link request", http.StatusBadRequest)
+
+		return
+	}
+
+	if err := h.checkReplay(deviceID, nonce, time.Unix(req.Timestamp, 0)); err != nil {
+		h.logger.Warn("Rejected link request", "device", deviceID, "err", err)
+		http.Error(w, "stale request", http.StatusUnauthorized)
+
+		return
+	}
+
+	// Only requests proven to come from the device count as it being used
+	h.auth.RecordLinkUse(r.Context(), d)
+
+	res, err := h.dispatch(r, d.principal, req)
+	if err != nil {
+		h.logger.Error("Failed to dispatch link request", "device", deviceID, "op", req.Op, "err", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+
+		return
+	}
+
+	out, err := h.seal(secret, nonce, res)
+	if err != nil {
+		h.logger.Error("Failed to seal link response", "device", deviceID, "err", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(out)
+}
+
+// open verifies and decrypts a request frame.
+func (h *linkHandler) open(secret []byte, frame []byte) (linkRequest, error) {
+	block, macKey, err := linkKeys(secret, linkRequestInfo)
+	if err != nil {
+		return linkRequest{}, err
+	}
+
+	body, sum := frame[:len(frame)-linkMacSize], frame[len(frame)-linkMacSize:]
+
+	mac := hmac.New(sha256.New, macKey)
+	mac.Write(body)
+
+	if !hmac.Equal(mac.Sum(nil), sum) {
+		return linkRequest{}, errLinkMac
+	}
+
+	nonce := body[linkIDSize : linkIDSize+linkNonceSize]
+	plain := make([]byte, len(body)-linkIDSize-linkNonceSize)
+	cipher.NewCTR(block, nonce).XORKeyStream(plain, body[linkIDSize+linkNonceSize:])
+
+	var req linkRequest
+	if err := msgpack.Unmarshal(plain, &req); err != nil {
+		return linkRequest{}, fmt.Errorf("failed to decode request: %w", err)
+	}
+
+	return req, nil
+}
+
+// seal encrypts a response to the request with the given nonce.
+func (h *linkHandler) seal(secret []byte, reqNonce []byte, res linkResponse) ([]byte, error) {
+	var buf bytes.Buffer
+
+	enc := msgpack.NewEncoder(&buf)
+	// Dart on the web cannot decode 64 bit integers, so integers are given their smallest encoding
+	enc.UseCompactInts(true)
+
+	if err := enc.Encode(res); err != nil {
+		return nil, err
+	}
+
+	plain := buf.Bytes()
+
+	block, macKey, err := linkKeys(secret, linkResponseInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, linkNonceSize+len(plain), linkNonceSize+len(plain)+linkMacSize)
+
+	if _, err := rand.Read(out[:linkNonceSize]); err != nil {
+		return nil, err
+	}
+
+	cipher.NewCTR(block, out[:linkNonceSize]).XORKeyStream(out[linkNonceSize:], plain)
+
+	mac := hmac.New(sha256.New, macKey)
+	mac.Write(reqNonce)
+	mac.Write(out)
+
+	return mac.Sum(out), nil
+}
+
+// checkReplay rejects requests whose timestamp is outside the replay window, or whose nonce has been seen within it.
+func (h *linkHandler) checkReplay(deviceID uuid.UUID, nonce []byte, ts time.Time) error {
+	now := time.Now()
+
+	if ts.Before(now.Add(-linkReplayWindow)) || ts.After(now.Add(linkReplayWindow)) {
+		return fmt.Errorf("%w: timestamp %v", errLinkReplay, ts)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for k, expires := range h.seen {
+		if now.After(expires) {
+			delete(h.seen, k)
+		}
+	}
+
+	key := string(deviceID[:]) + string(nonce)
+
+	if _, ok := h.seen[key]; ok {
+		return fmt.Errorf("%w: nonce reused", errLinkReplay)
+	}
+
+	h.seen[key] = ts.Add(linkReplayWindow)
+
+	return nil
+}
+
+// dispatch serves a request through the v1 API as the device's user, so that it is validated and authorized exactly
+// as a REST request would be.
+func (h *linkHandler) dispatch(r *http.Request, p principal, req linkRequest) (linkResponse, error) {
+	op, ok := h.ops[linkOpName(req.Op)]
+	if !ok {
+		return linkError(http.StatusNotFound, "unknown-operation", "The operation does not exist"), nil
+	}
+
+	if !op.buffered {
+		return linkError(
+			http.StatusBadRequest,
+			"unsupported-operation",
+			"The operation streams its response, so must be requested directly",
+		), nil
+	}
+
+	path := op.path
+	query := url.Values{}
+
+	for _, ref := range op.params {
+		param := ref.Value
+
+		v, ok := req.Params[param.Name]
+		if !ok {
+			continue
+		}
+
+		switch param.In {
+		case openapi3.ParameterInPath:
+			path = strings.ReplaceAll(path, "{"+param.Name+"}", url.PathEscape(fmt.Sprint(v)))
+		case openapi3.ParameterInQuery:
+			query.Set(param.Name, fmt.Sprint(v))
+		}
+	}
+
+	if strings.Contains(path, "{") {
+		return linkError(http.StatusBadRequest, "bad-request", "A path parameter is missing"), nil
+	}
+
+	var body io.Reader = http.NoBody
+
+	if req.Body != nil {
+		buf, err := json.Marshal(req.Body)
+		if err != nil {
+			return linkError(http.StatusBadRequest, "bad-request", "The body cannot be encoded as JSON"), nil
+		}
+
+		body = bytes.NewReader(buf)
+	}
+
+	target := "/api/v1" + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	// The route context of the link request is cleared, otherwise the router would resume routing it
+	ctx := context.WithValue(r.Context(), chi.RouteCtxKey, (*chi.Context)(nil))
+	ctx = context.WithValue(ctx, authKey, &authInfo{principal: p})
+
+	ireq, err := http.NewRequestWithContext(ctx, op.method, target, body)
+	if err != nil {
+		return linkResponse{}, err
+	}
+
+	ireq.RemoteAddr = r.RemoteAddr
+	ireq.Header.Set("User-Agent", r.UserAgent())
+
+	if req.Body != nil {
+		ireq.Header.Set("Content-Type", "application/json")
+	}
+
+	rec := &linkRecorder{ResponseRecorder: httptest.NewRecorder()}
+	h.handler.ServeHTTP(rec, ireq)
+
+	if rec.large {
+		h.logger.Warn("Link response too large", "op", req.Op)
+
+		return linkError(http.StatusInternalServerError, "response-too-large", "The response is too large"), nil
+	}
+
+	res := linkResponse{Status: rec.Code}
+
+	if rec.Body.Len() == 0 {
+		return res, nil
+	}
+
+	if mt, _, _ := mime.ParseMediaType(rec.Header().Get("Content-Type")); mt != "application/json" {
+		res.Body = rec.Body.Bytes()
+
+		return res, nil
+	}
+
+	dec := json.NewDecoder(rec.Body)
+	dec.UseNumber()
+
+	if err := dec.Decode(&res.Body); err != nil {
+		return linkResponse{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	res.Body = linkValue(res.Body)
+
+	return res, nil
+}
+
+// linkRecorder records a response, failing writes beyond linkMaxResponse rather than buffering them.
+type linkRecorder struct {
+	*httptest.ResponseRecorder
+	large bool
+}
+
+func (r *linkRecorder) Write(buf []byte) (int, error) {
+	if r.Body.Len()+len(buf) > linkMaxResponse {
+		r.large = true
+
+		return 0, errLinkLarge
+	}
+
+	return r.ResponseRecorder.Write(buf)
+}
+
+func (r *linkRecorder) WriteString(str string) (int, error) {
+	return r.Write([]byte(str))
+}
+
+// linkBuffered reports whether every successful response of an operation is JSON or empty, so can be buffered.
+// Operations without a successful response, such as protocol upgrades, are not.
+func linkBuffered(op *openapi3.Operation) bool {
+	found := false
+
+	for code, res := range op.Responses {
+		if !strings.HasPrefix(code, "2") {
+			continue
+		}
+
+		found = true
+
+		for mt := range res.Value.Content {
+			if mt != "application/json" {
+				return false
+			}
+		}
+	}
+
+	return found
+}
+
+// linkOpName normalizes operation names, as the embedded spec has its operation ids rewritten to Go names, such as
+// GetItem for get-item.
+func linkOpName(id string) string {
+	return strings.ToLower(strings.ReplaceAll(id, "-", ""))
+}
+
+func linkError(status int, code string, msg string) linkResponse {
+	return linkResponse{
+		Status: status,
+		Body: map[string]any{
+			"error":   code,
+			"message": msg,
+		},
+	}
+}
+
+// linkValue converts JSON numbers, so that integers are encoded as msgpack integers rather than floats.
+func linkValue(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+
+		f, _ := v.Float64()
+
+		return f
+	case map[string]any:
+		for k, e := range v {
+			v[k] = linkValue(e)
+		}
+	case []any:
+		for i, e := range v {
+			v[i] = linkValue(e)
+		}
+	}
+
+	return v
+}
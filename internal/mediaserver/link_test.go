@@ -0,0 +1,41 @@
+package mediaserver
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+)
+
+func TestLinkBufferedOps(t *testing.T) {
+	h, err := newLinkHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), nil, http.NotFoundHandler())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]bool{
+		"get-item":                    true,
+		"list-libraries":              true,
+		"logout":                      true,
+		"poll-pairing":                true,
+		"stream-events":               false,
+		"stream-item":                 false,
+		"get-image":                   false,
+		"get-transcode-manifest-m3u8": false,
+		"get-transcode-segment":       false,
+		"connect-plugin-transport":    false,
+	}
+
+	for id, want := range tests {
+		op, ok := h.ops[linkOpName(id)]
+		if !ok {
+			t.Errorf("operation %v missing", id)
+
+			continue
+		}
+
+		if op.buffered != want {
+			t.Errorf("operation %v buffered = %v, want %v", id, op.buffered, want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package mediaserver
+
+import (
+	"context"
+	"net"
+	"strconv"
+
+	"github.com/csnewman/cathode/discovery"
+	"github.com/csnewman/cathode/internal/db"
+)
+
+// RunAdvertise advertises the server on the local network over mDNS until the context is cancelled. A TLS listener is
+// advertised in preference to a plain one, and listeners bound to loopback are never advertised.
+func (m *NetworkManager) RunAdvertise(ctx context.Context) {
+	if !m.mdns {
+		return
+	}
+
+	l, ok := m.advertisedListener()
+	if !ok {
+		m.logger.Warn("Not advertising over mDNS, as every listener is bound to loopback")
+
+		return
+	}
+
+	id, err := db.ReadWithData(ctx, m.db, getServerID)
+	if err != nil {
+		m.logger.Error("Failed to fetch server id", "err", err)
+
+		return
+	}
+
+	host, portStr, _ := net.SplitHostPort(l.Address)
+	port, _ := strconv.Atoi(portStr)
+	name := serverName(m.name)
+
+	responder := discovery.NewResponder(func() discovery.Server {
+		s := discovery.Server{
+			ID:      id.String(),
+			Name:    name,
+			Version: serverVersion(),
+			Port:    port,
+			TLS:     !l.Plain,
+		}
+
+		if domains := m.certs.Wildcards(certSourceDSDM); !l.Plain && len(domains) > 0 {
+			s.Domain = domains[0]
+		}
+
+		local, err := localAddresses()
+		if err != nil {
+			m.logger.Warn("Failed to list addresses", "err", err)
+		}
+
+		for _, addr := range local {
+			if !addr.IsLoopback() && listensOn(host, addr) {
+				s.Addrs = append(s.Addrs, addr)
+			}
+		}
+
+		return s
+	})
+
+	m.logger.Info("Advertising over mDNS", "name", name, "service", discovery.Service, "port", port)
+
+	if err := responder.Run(ctx); err != nil {
+		m.logger.Error("mDNS advertising failed", "err", err)
+	}
+}
+
+func (m *NetworkManager) advertisedListener() (ListenerConfig, bool) {
+	var (
+		res ListenerConfig
+		ok  bool
+	)
+
+	for _, l := range m.listeners {
+		host, _, _ := net.SplitHostPort(l.Address)
+		if boundToLoopback(host) {
+			continue
+		}
+
+		if !ok || (res.Plain && !l.Plain) {
+			res, ok = l, true
+		}
+	}
+
+	return res, ok
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/csnewman/cathode/internal/db"
+	"github.com/google/uuid"
 )
 
 func (s *Server) migrate(ctx context.Context) error {
@@ -14,6 +15,19 @@ func (s *Server) migrate(ctx context.Context) error {
 	}
 
 	m.Register(1, s.migrateInit)
+	m.Register(2, s.migrateLibraries)
+	m.Register(3, s.migrateImages)
+	m.Register(4, s.migrateMetadataJobs)
+	m.Register(5, s.migrateMissingItems)
+	m.Register(6, s.migrateSearch)
+	m.Register(7, s.migrateUsers)
+	m.Register(8, s.migrateDevices)
+	m.Register(9, s.migrateDeviceLinks)
+	m.Register(10, s.migrateWatchState)
+	m.Register(11, s.migrateDSDMProviders)
+	m.Register(12, s.migrateCerts)
+	m.Register(13, s.migrateServerID)
+	m.Register(14, s.migratePlugins)
 
 	return m.Migrate(ctx)
 }
@@ -46,3 +60,490 @@ func (s *Server) migrateInit(_ context.Context, tx db.WTx) error {
 
 	return nil
 }
+
+func (s *Server) migrateLibraries(_ context.Context, tx db.WTx) error {
+	err := tx.Exec(`
+		CREATE TABLE libraries (
+			id            TEXT NOT NULL,
+			name          TEXT NOT NULL,
+			root          TEXT NOT NULL,
+			online_lookup INTEGER NOT NULL DEFAULT 1,
+			created       TEXT NOT NULL,
+			CONSTRAINT libraries_pk PRIMARY KEY (id),
+			CONSTRAINT libraries_root_uq UNIQUE (root)
+		) STRICT;
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create libraries table: %w", err)
+	}
+
+	err = tx.Exec(`
+		CREATE TABLE items (
+			id             TEXT NOT NULL,
+			library_id     TEXT NOT NULL,
+			parent_id      TEXT,
+			kind           TEXT NOT NULL,
+			path           TEXT,
+			file_size      INTEGER,
+			file_mtime     INTEGER,
+			name           TEXT NOT NULL,
+			original_name  TEXT NOT NULL DEFAULT '',
+			original_lang  TEXT NOT NULL DEFAULT '',
+			overview       TEXT NOT NULL DEFAULT '',
+			release_date   TEXT NOT NULL DEFAULT '',
+			year           INTEGER,
+			season_number  INTEGER,
+			episode_number INTEGER,
+			status         TEXT NOT NULL DEFAULT '',
+			poster_url     TEXT NOT NULL DEFAULT '',
+			backdrop_url   TEXT NOT NULL DEFAULT '',
+			added          TEXT NOT NULL,
+			updated        TEXT NOT NULL,
+			CONSTRAINT items_pk PRIMARY KEY (id),
+			CONSTRAINT items_library_fk FOREIGN KEY (library_id) REFERENCES libraries (id) ON DELETE CASCADE,
+			CONSTRAINT items_parent_fk FOREIGN KEY (parent_id) REFERENCES items (id) ON DELETE CASCADE
+		) STRICT;
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create items table: %w", err)
+	}
+
+	err = tx.Exec(`
+		CREATE TABLE item_ids (
+			item_id  TEXT NOT NULL,
+			provider TEXT NOT NULL,
+			value    TEXT NOT NULL,
+			CONSTRAINT item_ids_pk PRIMARY KEY (item_id, provider),
+			CONSTRAINT item_ids_item_fk FOREIGN KEY (item_id) REFERENCES items (id) ON DELETE CASCADE
+		) STRICT;
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create item_ids table: %w", err)
+	}
+
+	err = tx.Exec(`
+		CREATE TABLE item_genres (
+			item_id TEXT NOT NULL,
+			genre   TEXT NOT NULL,
+			CONSTRAINT item_genres_pk PRIMARY KEY (item_id, genre),
+			CONSTRAINT item_genres_item_fk FOREIGN KEY (item_id) REFERENCES items (id) ON DELETE CASCADE
+		) STRICT;
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create item_genres table: %w", err)
+	}
+
+	err = tx.Exec(`
+		CREATE TABLE item_cast (
+			item_id  TEXT NOT NULL,
+			position INTEGER NOT NULL,
+			name     TEXT NOT NULL,
+			CONSTRAINT item_cast_pk PRIMARY KEY (item_id, position),
+			CONSTRAINT item_cast_item_fk FOREIGN KEY (item_id) REFERENCES items (id) ON DELETE CASCADE
+		) STRICT;
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create item_cast table: %w", err)
+	}
+
+	indexes := []string{
+		`CREATE UNIQUE INDEX items_path_idx ON items (path) WHERE path IS NOT NULL`,
+		`CREATE INDEX items_library_kind_idx ON items (library_id, kind)`,
+		`CREATE INDEX items_parent_idx ON items (parent_id)`,
+		`CREATE INDEX item_ids_value_idx ON item_ids (provider, value)`,
+		`CREATE INDEX item_genres_genre_idx ON item_genres (genre)`,
+	}
+
+	for _, idx := range indexes {
+		if err := tx.Exec(idx); err != nil {
+			return fmt.Errorf("failed to create index: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) migrateImages(_ context.Context, tx db.WTx) error {
+	err := tx.Exec(`
+		CREATE TABLE images (
+			id           TEXT NOT NULL,
+			source_url   TEXT NOT NULL,
+			hash         TEXT,
+			content_type TEXT NOT NULL DEFAULT '',
+			width        INTEGER,
+			height       INTEGER,
+			fetched      TEXT,
+			created      TEXT NOT NULL,
+			CONSTRAINT images_pk PRIMARY KEY (id),
+			CONSTRAINT images_source_uq UNIQUE (source_url)
+		) STRICT;
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create images table: %w", err)
+	}
+
+	columns := []string{
+		`ALTER TABLE items ADD COLUMN logo_url TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE items ADD COLUMN poster_image TEXT REFERENCES images (id) ON DELETE SET NULL`,
+		`ALTER TABLE items ADD COLUMN backdrop_image TEXT REFERENCES images (id) ON DELETE SET NULL`,
+		`ALTER TABLE items ADD COLUMN logo_image TEXT REFERENCES images (id) ON DELETE SET NULL`,
+	}
+
+	for _, col := range columns {
+		if err := tx.Exec(col); err != nil {
+			return fmt.Errorf("failed to alter items table: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) migrateMetadataJobs(_ context.Context, tx db.WTx) error {
+	err := tx.Exec(`
+		CREATE TABLE metadata_jobs (
+			item_id    TEXT NOT NULL,
+			next_run   TEXT NOT NULL,
+			last_run   TEXT,
+			attempts   INTEGER NOT NULL DEFAULT 0,
+			last_error TEXT NOT NULL DEFAULT '',
+			CONSTRAINT metadata_jobs_pk PRIMARY KEY (item_id),
+			CONSTRAINT metadata_jobs_item_fk FOREIGN KEY (item_id) REFERENCES items (id) ON DELETE CASCADE
+		) STRICT;
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create metadata_jobs table: %w", err)
+	}
+
+	if err := tx.Exec(`CREATE INDEX metadata_jobs_next_run_idx ON metadata_jobs (next_run)`); err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+
+	// Schedule a refresh of everything that was imported before jobs existed
+	err = tx.Exec(`
+		INSERT INTO metadata_jobs (item_id, next_run)
+		SELECT id, datetime() FROM items WHERE kind IN ('movie', 'show')
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to schedule existing items: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Server) migrateMissingItems(_ context.Context, tx db.WTx) error {
+	if err := tx.Exec(`ALTER TABLE items ADD COLUMN missing_since TEXT`); err != nil {
+		return fmt.Errorf("failed to alter items table: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Server) migrateSearch(_ context.Context, tx db.WTx) error {
+	// Rowids of items are not stable across a VACUUM, so each item is given its own search rowid
+	if err := tx.Exec(`ALTER TABLE items ADD COLUMN search_rowid INTEGER`); err != nil {
+		return fmt.Errorf("failed to alter items table: %w", err)
+	}
+
+	err := tx.Exec(`CREATE UNIQUE INDEX items_search_rowid_idx ON items (search_rowid) WHERE search_rowid IS NOT NULL`)
+	if err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+
+	err = tx.Exec(`
+		CREATE VIRTUAL TABLE item_search USING fts5 (
+			name,
+			original_name,
+			overview,
+			cast_names,
+			tokenize = 'unicode61 remove_diacritics 2',
+			prefix = '2 3'
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create item_search table: %w", err)
+	}
+
+	err = tx.Exec(`CREATE VIRTUAL TABLE item_search_vocab USING fts5vocab (item_search, 'row')`)
+	if err != nil {
+		return fmt.Errorf("failed to create item_search_vocab table: %w", err)
+	}
+
+	// Seasons are only ever named after their number, so are left out of the index
+	triggers := []string{
+		`CREATE TRIGGER items_search_insert AFTER INSERT ON items WHEN NEW.kind <> 'season' BEGIN
+			UPDATE items SET search_rowid = (SELECT COALESCE(MAX(search_rowid), 0) + 1 FROM items) WHERE id = NEW.id;
+			INSERT INTO item_search (rowid, name, original_name, overview, cast_names)
+			SELECT search_rowid, NEW.name, NEW.original_name, NEW.overview, '' FROM items WHERE id = NEW.id;
+		END`,
+		`CREATE TRIGGER items_search_update AFTER UPDATE OF name, original_name, overview ON items
+		WHEN NEW.search_rowid IS NOT NULL BEGIN
+			UPDATE item_search SET name = NEW.name, original_name = NEW.original_name, overview = NEW.overview
+			WHERE rowid = NEW.search_rowid;
+		END`,
+		`CREATE TRIGGER items_search_delete AFTER DELETE ON items WHEN OLD.search_rowid IS NOT NULL BEGIN
+			DELETE FROM item_search WHERE rowid = OLD.search_rowid;
+		END`,
+		`CREATE TRIGGER item_cast_search_insert AFTER INSERT ON item_cast BEGIN
+			UPDATE item_search
+			SET cast_names = (SELECT COALESCE(group_concat(name, ' '), '') FROM item_cast WHERE item_id = NEW.item_id)
+			WHERE rowid = (SELECT search_rowid FROM items WHERE id = NEW.item_id);
+		END`,
+		`CREATE TRIGGER item_cast_search_delete AFTER DELETE ON item_cast BEGIN
+			UPDATE item_search
+			SET cast_names = (SELECT COALESCE(group_concat(name, ' '), '') FROM item_cast WHERE item_id = OLD.item_id)
+			WHERE rowid = (SELECT search_rowid FROM items WHERE id = OLD.item_id);
+		END`,
+	}
+
+	for _, trigger := range triggers {
+		if err := tx.Exec(trigger); err != nil {
+			return fmt.Errorf("failed to create trigger: %w", err)
+		}
+	}
+
+	// Index everything that was imported before search existed
+	err = tx.Exec(`UPDATE items SET search_rowid = rowid WHERE kind <> 'season'`)
+	if err != nil {
+		return fmt.Errorf("failed to assign search rowids: %w", err)
+	}
+
+	err = tx.Exec(`
+		INSERT INTO item_search (rowid, name, original_name, overview, cast_names)
+		SELECT
+			search_rowid,
+			name,
+			original_name,
+			overview,
+			(SELECT COALESCE(group_concat(c.name, ' '), '') FROM item_cast c WHERE c.item_id = items.id)
+		FROM items
+		WHERE search_rowid IS NOT NULL
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to index existing items: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Server) migrateUsers(_ context.Context, tx db.WTx) error {
+	err := tx.Exec(`
+		CREATE TABLE users (
+			id            TEXT NOT NULL,
+			username      TEXT NOT NULL COLLATE NOCASE,
+			password_hash TEXT NOT NULL,
+			role          TEXT NOT NULL,
+			created       TEXT NOT NULL,
+			CONSTRAINT users_pk PRIMARY KEY (id),
+			CONSTRAINT users_username_uq UNIQUE (username)
+		) STRICT;
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create users table: %w", err)
+	}
+
+	err = tx.Exec(`
+		CREATE TABLE sessions (
+			token_hash TEXT NOT NULL,
+			user_id    TEXT NOT NULL,
+			user_agent TEXT NOT NULL DEFAULT '',
+			created    TEXT NOT NULL,
+			last_seen  TEXT NOT NULL,
+			expires    TEXT NOT NULL,
+			CONSTRAINT sessions_pk PRIMARY KEY (token_hash),
+			CONSTRAINT sessions_user_fk FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+		) STRICT;
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create sessions table: %w", err)
+	}
+
+	if err := tx.Exec(`CREATE INDEX sessions_user_idx ON sessions (user_id)`); err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Server) migrateDevices(_ context.Context, tx db.WTx) error {
+	err := tx.Exec(`
+		CREATE TABLE devices (
+			id         TEXT NOT NULL,
+			user_id    TEXT NOT NULL,
+			name       TEXT NOT NULL,
+			token_hash TEXT NOT NULL,
+			created    TEXT NOT NULL,
+			last_seen  TEXT NOT NULL,
+			CONSTRAINT devices_pk PRIMARY KEY (id),
+			CONSTRAINT devices_token_uq UNIQUE (token_hash),
+			CONSTRAINT devices_user_fk FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+		) STRICT;
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create devices table: %w", err)
+	}
+
+	if err := tx.Exec(`CREATE INDEX devices_user_idx ON devices (user_id)`); err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Server) migrateDeviceLinks(_ context.Context, tx db.WTx) error {
+	if err := tx.Exec(`ALTER TABLE devices ADD COLUMN link_secret BLOB`); err != nil {
+		return fmt.Errorf("failed to add link secret column: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Server) migrateWatchState(_ context.Context, tx db.WTx) error {
+	err := tx.Exec(`
+		CREATE TABLE watch_state (
+			user_id        TEXT NOT NULL,
+			item_id        TEXT NOT NULL,
+			position_ms    INTEGER NOT NULL DEFAULT 0,
+			duration_ms    INTEGER NOT NULL DEFAULT 0,
+			watched        INTEGER NOT NULL DEFAULT 0,
+			play_count     INTEGER NOT NULL DEFAULT 0,
+			audio_track    INTEGER,
+			subtitle_track INTEGER,
+			last_played    TEXT NOT NULL,
+			CONSTRAINT watch_state_pk PRIMARY KEY (user_id, item_id),
+			CONSTRAINT watch_state_user_fk FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+			CONSTRAINT watch_state_item_fk FOREIGN KEY (item_id) REFERENCES items (id) ON DELETE CASCADE
+		) STRICT;
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create watch_state table: %w", err)
+	}
+
+	if err := tx.Exec(`CREATE INDEX watch_state_played_idx ON watch_state (user_id, last_played)`); err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+
+	if err := tx.Exec(`CREATE INDEX watch_state_item_idx ON watch_state (item_id)`); err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+
+	err = tx.Exec(`
+		CREATE TABLE watch_history (
+			id          TEXT NOT NULL,
+			user_id     TEXT NOT NULL,
+			item_id     TEXT NOT NULL,
+			device_id   TEXT,
+			started     TEXT NOT NULL,
+			updated     TEXT NOT NULL,
+			position_ms INTEGER NOT NULL,
+			watched_ms  INTEGER NOT NULL DEFAULT 0,
+			paused      INTEGER NOT NULL DEFAULT 0,
+			completed   INTEGER NOT NULL DEFAULT 0,
+			CONSTRAINT watch_history_pk PRIMARY KEY (id),
+			CONSTRAINT watch_history_user_fk FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+			CONSTRAINT watch_history_item_fk FOREIGN KEY (item_id) REFERENCES items (id) ON DELETE CASCADE,
+			CONSTRAINT watch_history_device_fk FOREIGN KEY (device_id) REFERENCES devices (id) ON DELETE SET NULL
+		) STRICT;
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create watch_history table: %w", err)
+	}
+
+	if err := tx.Exec(`CREATE INDEX watch_history_user_idx ON watch_history (user_id, started)`); err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+
+	if err := tx.Exec(`CREATE INDEX watch_history_item_idx ON watch_history (item_id)`); err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+
+	if err := tx.Exec(`CREATE INDEX watch_history_device_idx ON watch_history (device_id)`); err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Server) migrateDSDMProviders(_ context.Context, tx db.WTx) error {
+	// Existing servers keep the provider and key type that were previously hard-coded
+	err := tx.Exec(`ALTER TABLE dsdm_servers ADD COLUMN provider TEXT NOT NULL DEFAULT 'zerossl'`)
+	if err != nil {
+		return fmt.Errorf("failed to add provider column: %w", err)
+	}
+
+	err = tx.Exec(`ALTER TABLE dsdm_servers ADD COLUMN key_type TEXT NOT NULL DEFAULT 'rsa4096'`)
+	if err != nil {
+		return fmt.Errorf("failed to add key_type column: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Server) migrateCerts(_ context.Context, tx db.WTx) error {
+	err := tx.Exec(`
+		CREATE TABLE certs (
+			source      TEXT NOT NULL,
+			name        TEXT NOT NULL,
+			cert        BLOB NOT NULL,
+			pri_key     BLOB NOT NULL,
+			issue_date  TEXT NOT NULL,
+			expire_date TEXT NOT NULL,
+			CONSTRAINT certs_pk PRIMARY KEY (source, name)
+		) STRICT;
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create certs table: %w", err)
+	}
+
+	err = tx.Exec(`
+		CREATE TABLE acme_accounts (
+			directory TEXT NOT NULL,
+			email     TEXT NOT NULL,
+			pri_key   BLOB NOT NULL,
+			CONSTRAINT acme_accounts_pk PRIMARY KEY (directory, email)
+		) STRICT;
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create acme_accounts table: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Server) migrateServerID(_ context.Context, tx db.WTx) error {
+	err := tx.Exec(`
+		CREATE TABLE server (
+			id TEXT NOT NULL
+		) STRICT;
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create server table: %w", err)
+	}
+
+	if err := tx.Exec(`INSERT INTO server (id) VALUES ($1)`, uuid.New()); err != nil {
+		return fmt.Errorf("failed to insert server id: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Server) migratePlugins(_ context.Context, tx db.WTx) error {
+	err := tx.Exec(`
+		CREATE TABLE plugins (
+			id         TEXT NOT NULL,
+			name       TEXT NOT NULL,
+			scopes     TEXT NOT NULL,
+			key_hash   TEXT,
+			public_key BLOB,
+			enabled    INTEGER NOT NULL DEFAULT 1,
+			created    TEXT NOT NULL,
+			last_seen  TEXT,
+			CONSTRAINT plugins_pk PRIMARY KEY (id),
+			CONSTRAINT plugins_key_uq UNIQUE (key_hash)
+		) STRICT;
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create plugins table: %w", err)
+	}
+
+	return nil
+}
@@ -3,17 +3,21 @@ package mediaserver
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net"
 	"net/http"
+	"net/url"
 	"runtime/debug"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/csnewman/cathode/internal/db"
 	dsdm "github.com/csnewman/dyndirect/go"
-	"github.com/go-acme/lego/v4/certcrypto"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
@@ -22,38 +26,99 @@ import (
 )
 
 type NetworkManager struct {
-	logger *slog.Logger
-	db     *db.DB
-	certs  map[string]*tls.Certificate
-	router *chi.Mux
-	h3s    *webtransport.Server
+	logger      *slog.Logger
+	db          *db.DB
+	dataDir     string
+	listeners   []ListenerConfig
+	tls         TLSConfig
+	certs       *certStore
+	router      *chi.Mux
+	renewWake   chan struct{}
+	alpn        *alpnChallenges
+	discovery   *addressDiscovery
+	mdns        bool
+	name        string
+	portMapping PortMappingConfig
+	events      *EventBus
+
+	mu       sync.Mutex
+	closing  bool
+	servers  []*listenerServers
+	inflight map[uint64]inflightRequest
+	nextReq  uint64
+	drained  chan struct{}
 }
 
-func NewNetworkManager(logger *slog.Logger, db *db.DB) (*NetworkManager, error) {
+// listenerServers are the servers of a single listener. The HTTP/3 server is nil when HTTP/3 is off.
+type listenerServers struct {
+	hs  *http.Server
+	wts *webtransport.Server
+}
+
+type inflightRequest struct {
+	method  string
+	path    string
+	proto   string
+	remote  string
+	started time.Time
+}
+
+func NewNetworkManager(
+	logger *slog.Logger,
+	db *db.DB,
+	cfg Config,
+	library *LibraryManager,
+	images *ImageCache,
+	auth *AuthManager,
+	watch *WatchManager,
+	playback *PlaybackManager,
+	plugins *PluginManager,
+	events *EventBus,
+) (*NetworkManager, error) {
 	m := &NetworkManager{
-		logger: logger,
-		db:     db,
+		logger:      logger,
+		db:          db,
+		dataDir:     cfg.DataDir,
+		listeners:   cfg.Listeners,
+		tls:         cfg.TLS,
+		certs:       newCertStore(),
+		renewWake:   make(chan struct{}, 1),
+		alpn:        newALPNChallenges(),
+		discovery:   newAddressDiscovery(logger, cfg.Discovery.STUNServers),
+		mdns:        cfg.Discovery.MDNS,
+		name:        cfg.Discovery.Name,
+		portMapping: cfg.PortMapping,
+		events:      events,
+		inflight:    make(map[uint64]inflightRequest),
+		drained:     make(chan struct{}, 1),
 	}
 
 	m.router = chi.NewRouter()
+	m.router.Use(m.trackMiddleware)
 	m.router.Use(m.loggerMiddleware)
 	m.router.Use(m.recoverMiddleware)
 
 	m.router.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"https://*", "http://*"},
-		AllowedMethods:   []string{"GET", "POST"},
-		AllowedHeaders:   []string{"Accept", "Content-Type"},
+		AllowedMethods:   []string{"GET", "POST", "PATCH", "DELETE"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
 		ExposedHeaders:   []string{},
 		AllowCredentials: false,
 		MaxAge:           300,
 	}))
 
-	v1, err := newV1API(logger, m.upgradeWT)
+	v1, err := newV1API(logger, m.upgradeWT, m, library, images, auth, watch, playback, plugins, events)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create v1: %w", err)
 	}
 
+	link, err := newLinkHandler(logger, auth, m.router)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create link: %w", err)
+	}
+
 	m.router.Mount("/api/v1", v1.router)
+	m.router.Post("/v1", link.ServeHTTP)
 	m.router.HandleFunc("/api/*", m.fallbackAPIRoute)
 	m.router.HandleFunc("/api", m.fallbackAPIRoute)
 	m.router.HandleFunc("/*", m.fallbackPageRoute)
@@ -61,166 +126,497 @@ func NewNetworkManager(logger *slog.Logger, db *db.DB) (*NetworkManager, error)
 	return m, nil
 }
 
-func (m *NetworkManager) Refresh(ctx context.Context) {
-	if err := m.refreshInner(ctx); err != nil {
-		m.logger.Error("Refresh failed", "err", err)
+// Refresh loads the certificates for the configured TLS mode. DynDirect and ACME certificates are loaded from the
+// database, with missing ones requested by RunRenewal.
+func (m *NetworkManager) Refresh(ctx context.Context) error {
+	if !m.usesTLS() {
+		return nil
+	}
+
+	switch m.tls.Mode {
+	case TLSModeFiles:
+		cert, err := loadCertificateFiles(m.tls)
+		if err != nil {
+			return err
+		}
+
+		return m.certs.Replace(certSourceStatic, []*tls.Certificate{cert})
+	case TLSModeSelfSigned:
+		cert, err := selfSignedCertificate(m.dataDir)
+		if err != nil {
+			return err
+		}
+
+		return m.certs.Replace(certSourceStatic, []*tls.Certificate{cert})
+	case TLSModeDynDirect:
+		if _, err := m.loadDSDMCerts(ctx); err != nil {
+			return err
+		}
+	}
+
+	if m.tls.ACME.Enabled() {
+		if _, err := m.loadACMECerts(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *NetworkManager) usesTLS() bool {
+	return slices.ContainsFunc(m.listeners, func(l ListenerConfig) bool { return !l.Plain })
+}
+
+const (
+	// renewRetryMin is the delay before retrying a failed renewal, doubling with each failure up to renewRetryMax.
+	renewRetryMin = time.Minute
+	renewRetryMax = 6 * time.Hour
+	// renewCheckInterval bounds the time between renewal checks, so that expired certificates are dropped.
+	renewCheckInterval = 12 * time.Hour
+)
+
+// RunRenewal keeps a DynDirect certificate for every DSDM server, and an ACME certificate for the configured domains,
+// requesting one when there is none that is valid beyond the renewal lead time. Failures are retried with backoff
+// until the context is cancelled.
+func (m *NetworkManager) RunRenewal(ctx context.Context) {
+	if !m.usesTLS() || (m.tls.Mode != TLSModeDynDirect && !m.tls.ACME.Enabled()) {
+		return
+	}
+
+	backoff := renewRetryMin
+
+	for {
+		next, err := m.renew(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		wait := min(time.Until(next), renewCheckInterval)
+
+		if err != nil {
+			m.logger.Error("Certificate renewal failed", "err", err, "retry", backoff)
+
+			if wait <= 0 || wait > backoff {
+				wait = backoff
+			}
+
+			backoff = min(backoff*2, renewRetryMax)
+		} else {
+			backoff = renewRetryMin
+		}
+
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return
+		case <-m.renewWake:
+			timer.Stop()
+		case <-timer.C:
+		}
 	}
 }
 
-func (m *NetworkManager) refreshInner(ctx context.Context) error {
+func (m *NetworkManager) publishCertificate(source certSource, leaf *x509.Certificate) {
+	m.events.Publish(event{
+		typ: eventCertificateRenewed,
+		data: certificateEvent{
+			source:  source,
+			domains: leaf.DNSNames,
+			expires: leaf.NotAfter,
+		},
+	})
+}
+
+// DSDMServers returns the DSDM servers certificates are requested through.
+func (m *NetworkManager) DSDMServers(ctx context.Context) ([]dsdmServer, error) {
+	return db.ReadWithData(ctx, m.db, getDSDMServers)
+}
+
+// SetDSDMServer adds a DSDM server or updates its provider and key type, waking the renewal loop so that a new server
+// is requested a certificate.
+func (m *NetworkManager) SetDSDMServer(ctx context.Context, server dsdmServer) error {
+	if err := server.provider.validate(); err != nil {
+		return err
+	}
+
+	if err := server.keyType.validate(); err != nil {
+		return err
+	}
+
+	u, err := url.Parse(server.server)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("%w: %q is not an http or https url", errInvalidACMEOption, server.server)
+	}
+
+	err = m.db.Write(ctx, func(ctx context.Context, tx db.WTx) error {
+		return upsertDSDMServer(ctx, tx, server)
+	})
+	if err != nil {
+		return err
+	}
+
+	select {
+	case m.renewWake <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// renew requests the certificates that are due, returning when the next renewal is due.
+func (m *NetworkManager) renew(ctx context.Context) (time.Time, error) {
 	m.logger.Info("Refreshing network")
 
+	next := time.Now().Add(renewCheckInterval)
+
+	var errs []error
+
+	if m.tls.Mode == TLSModeDynDirect {
+		due, err := m.renewDSDM(ctx)
+		if err != nil {
+			errs = append(errs, err)
+		}
+
+		next = minTime(next, due)
+	}
+
+	if m.tls.ACME.Enabled() {
+		due, err := m.renewACME(ctx)
+		if err != nil {
+			errs = append(errs, err)
+		}
+
+		next = minTime(next, due)
+	}
+
+	m.logger.Info("Network refreshed", "next", next)
+
+	return next, errors.Join(errs...)
+}
+
+// renewDSDM requests certificates from the DSDM servers that are due one, returning when the next renewal is due.
+func (m *NetworkManager) renewDSDM(ctx context.Context) (time.Time, error) {
+	next := time.Now().Add(renewCheckInterval)
+
 	servers, err := db.ReadWithData(ctx, m.db, getDSDMServers)
 	if err != nil {
-		return fmt.Errorf("failed to fetch servers: %w", err)
+		return next, fmt.Errorf("failed to fetch servers: %w", err)
 	}
 
 	// Default to dyndirect if no servers are configured
 	if len(servers) == 0 {
-		servers = append(servers, dsdm.DynDirect)
+		def := dsdmServer{
+			server:   dsdm.DynDirect,
+			provider: acmeProviderZeroSSL,
+			keyType:  acmeKeyTypeRSA4096,
+		}
+
+		servers = append(servers, def)
 
 		err := m.db.Write(ctx, func(ctx context.Context, tx db.WTx) error {
-			return insertDSDMServer(ctx, tx, dsdm.DynDirect)
+			return upsertDSDMServer(ctx, tx, def)
 		})
 		if err != nil {
-			return fmt.Errorf("failed to insert default server: %w", err)
+			return next, fmt.Errorf("failed to insert default server: %w", err)
 		}
 	}
 
 	if err := m.db.Write(ctx, cleanupDSDMCerts); err != nil {
-		return fmt.Errorf("failed to cleanup old certs: %w", err)
+		return next, fmt.Errorf("failed to cleanup old certs: %w", err)
 	}
 
-	entries, err := db.ReadWithData(ctx, m.db, getDSDMCerts)
+	latest, err := m.loadDSDMCerts(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to fetch certs: %w", err)
+		return next, err
 	}
 
-	seenServers := make(map[string]struct{})
-	newCerts := make(map[string]*tls.Certificate)
+	var errs []error
 
-	for _, entry := range entries {
-		parsed, err := tls.X509KeyPair(entry.cert, entry.priKey)
-		if err != nil {
-			return fmt.Errorf("failed to parse cert: %w", err)
+	for _, server := range servers {
+		due := m.renewalDue(latest[server.server])
+
+		if time.Now().After(due) {
+			leaf, err := m.refreshDSDM(ctx, server)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("server %v: %w", server.server, err))
+
+				continue
+			}
+
+			due = m.renewalDue(leaf)
 		}
 
-		seenServers[entry.server] = struct{}{}
-		newCerts[entry.domain] = &parsed
+		next = minTime(next, due)
 	}
 
-	m.certs = newCerts
+	return next, errors.Join(errs...)
+}
+
+func minTime(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+
+	return a
+}
+
+// renewalDue returns when a certificate should be renewed, which is immediately when there is none. The lead time is
+// capped to a third of the lifetime, so that short-lived certificates are not renewed as soon as they are issued.
+func (m *NetworkManager) renewalDue(leaf *x509.Certificate) time.Time {
+	if leaf == nil {
+		return time.Time{}
+	}
+
+	lead := min(m.tls.RenewBefore, leaf.NotAfter.Sub(leaf.NotBefore)/3)
+
+	return leaf.NotAfter.Add(-lead)
+}
+
+// loadDSDMCerts replaces the served DynDirect certificates with those stored, returning the certificate of each
+// server that expires last.
+func (m *NetworkManager) loadDSDMCerts(ctx context.Context) (map[string]*x509.Certificate, error) {
+	entries, err := db.ReadWithData(ctx, m.db, getDSDMCerts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch certs: %w", err)
+	}
+
+	latest := make(map[string]*x509.Certificate)
+
+	var certs []*tls.Certificate
+
+	for _, entry := range entries {
+		parsed, err := parseCertificate(entry.cert, entry.priKey)
+		if err != nil {
+			m.logger.Warn("Ignoring unusable certificate", "domain", entry.domain, "err", err)
 
-	for _, server := range servers {
-		if _, ok := seenServers[server]; ok {
 			continue
 		}
 
-		err := m.refreshDSDM(ctx, server)
-		if err != nil {
-			m.logger.Error("Failed to refresh DSDM server", "server", server, "err", err)
+		if time.Now().After(parsed.Leaf.NotAfter) {
+			continue
+		}
+
+		if cur, ok := latest[entry.server]; !ok || parsed.Leaf.NotAfter.After(cur.NotAfter) {
+			latest[entry.server] = parsed.Leaf
 		}
+
+		certs = append(certs, parsed)
 	}
 
-	m.logger.Info("Network refreshed")
+	if err := m.certs.Replace(certSourceDSDM, certs); err != nil {
+		return nil, err
+	}
 
-	return nil
+	return latest, nil
+}
+
+// parseCertificate parses a PEM encoded certificate and key, keeping the parsed leaf for its validity period.
+func parseCertificate(certPEM []byte, keyPEM []byte) (*tls.Certificate, error) {
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse cert: %w", err)
+	}
+
+	cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse cert leaf: %w", err)
+	}
+
+	return &cert, nil
 }
 
-func (m *NetworkManager) refreshDSDM(ctx context.Context, server string) error {
+// refreshDSDM allocates a new subdomain from a DSDM server and requests a certificate for it, returning the parsed
+// certificate. The subdomain token is not kept, so renewals also use a new subdomain.
+func (m *NetworkManager) refreshDSDM(ctx context.Context, ds dsdmServer) (*x509.Certificate, error) {
+	server := ds.server
+
 	dc, err := dsdm.New(server)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	m.logger.Info("Requesting subdomain", "server", server)
 
 	resp, err := dc.RequestSubdomain(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get subdomain: %w", err)
+		return nil, fmt.Errorf("failed to get subdomain: %w", err)
 	}
 
 	m.logger.Info("Subdomain allocated", "server", server, "id", resp.Id, "domain", resp.Domain)
 
-	m.logger.Info("Requesting certificate", "server", server)
-
-	cert, err := dc.AcquireCertificate(ctx, dsdm.AcquireCertificateRequest{
-		ID:     resp.Id,
-		Domain: resp.Domain,
-		Token:  resp.Token,
-		// TODO: Support other providers
-		Provider:   dsdm.ProviderZeroSSL,
-		KeyType:    certcrypto.RSA4096,
-		Timeout:    time.Second * 120,
-		SilenceLog: true,
-	})
+	certPEM, keyPEM, err := m.acquireDSDM(ctx, dc, resp, ds)
 	if err != nil {
-		return fmt.Errorf("failed to acquire cert: %w", err)
+		return nil, err
 	}
 
-	m.logger.Debug("Certificate issued", "server", server)
-
-	parsed, err := tls.X509KeyPair(cert.Certificate, cert.PrivateKey)
+	parsed, err := parseCertificate(certPEM, keyPEM)
 	if err != nil {
-		return fmt.Errorf("failed to parse cert: %w", err)
+		return nil, err
 	}
 
+	m.logger.Info("Certificate issued", "server", server, "expires", parsed.Leaf.NotAfter)
+
 	err = m.db.Write(ctx, func(ctx context.Context, tx db.WTx) error {
 		return insertDSDMCert(ctx, tx, dsdmEntry{
-			domain: resp.Domain,
-			server: server,
-			cert:   cert.Certificate,
-			priKey: cert.PrivateKey,
+			domain:  resp.Domain,
+			server:  server,
+			cert:    certPEM,
+			priKey:  keyPEM,
+			issued:  parsed.Leaf.NotBefore,
+			expires: parsed.Leaf.NotAfter,
 		})
 	})
 	if err != nil {
-		return fmt.Errorf("failed to store: %w", err)
+		return nil, fmt.Errorf("failed to store: %w", err)
 	}
 
-	m.certs[resp.Domain] = &parsed
+	if err := m.certs.Add(certSourceDSDM, parsed); err != nil {
+		return nil, err
+	}
 
-	return nil
+	m.publishCertificate(certSourceDSDM, parsed.Leaf)
+
+	m.logAddresses(resp.Domain)
+
+	return parsed.Leaf, nil
 }
 
-func (m *NetworkManager) Run(_ context.Context) {
-	for dom := range m.certs {
-		m.logger.Info(
-			"Address",
-			"url", fmt.Sprintf("https://127-0-0-1-v4.%s:8443", dom),
+// acquireDSDM requests a certificate for a subdomain from the provider of the server, falling back to the alternate
+// provider if that fails.
+func (m *NetworkManager) acquireDSDM(
+	ctx context.Context,
+	dc *dsdm.Client,
+	sub *dsdm.SubdomainResponse,
+	ds dsdmServer,
+) ([]byte, []byte, error) {
+	m.logger.Info("Requesting certificate", "server", ds.server, "provider", ds.provider, "keyType", ds.keyType)
+
+	certPEM, keyPEM, err := acquireDSDMCertificate(ctx, dc, sub, ds.provider, ds.keyType)
+	if err == nil {
+		return certPEM, keyPEM, nil
+	}
+
+	fallback, ok := ds.provider.fallback()
+	if !ok || ctx.Err() != nil {
+		return nil, nil, fmt.Errorf("failed to acquire cert from %v: %w", ds.provider, err)
+	}
+
+	m.logger.Warn("Certificate request failed, trying fallback", "provider", ds.provider, "fallback", fallback, "err", err)
+
+	certPEM, keyPEM, fallbackErr := acquireDSDMCertificate(ctx, dc, sub, fallback, ds.keyType)
+	if fallbackErr != nil {
+		return nil, nil, fmt.Errorf(
+			"failed to acquire cert from %v: %w, and from %v: %w",
+			ds.provider,
+			err,
+			fallback,
+			fallbackErr,
 		)
 	}
 
-	if err := m.createServer(8443); err != nil {
-		m.logger.Error("temp", "err", err)
+	return certPEM, keyPEM, nil
+}
+
+// logAddresses logs the addresses each TLS listener can be reached on using a DynDirect domain.
+func (m *NetworkManager) logAddresses(domain string) {
+	addrs, err := m.Addresses()
+	if err != nil {
+		m.logger.Warn("Failed to list addresses", "err", err)
+
+		return
+	}
+
+	for _, a := range addrs {
+		if strings.HasSuffix(a.host, "."+domain) {
+			m.logger.Info("Address", "url", a.url, "scope", a.scope)
+		}
+	}
+}
+
+// RunDiscovery keeps the public addresses of the server up to date until the context is cancelled.
+func (m *NetworkManager) RunDiscovery(ctx context.Context) {
+	m.discovery.Run(ctx)
+}
+
+// Run serves every listener, returning once they have all been stopped by Shutdown or failed.
+func (m *NetworkManager) Run(_ context.Context) {
+	for _, dom := range m.certs.Wildcards(certSourceDSDM) {
+		m.logAddresses(dom)
+	}
+
+	var wg sync.WaitGroup
+
+	for _, l := range m.listeners {
+		wg.Add(1)
+
+		go func(l ListenerConfig) {
+			defer wg.Done()
+
+			m.logger.Info("Listening", "address", l.Address, "plain", l.Plain, "http3", l.HTTP3)
+
+			if err := m.serve(l); err != nil {
+				m.logger.Error("Listener failed", "address", l.Address, "err", err)
+			}
+		}(l)
 	}
+
+	wg.Wait()
 }
 
-func (m *NetworkManager) createServer(port int) error {
+func (m *NetworkManager) serve(l ListenerConfig) error {
 	hs := &http.Server{
-		Addr:         fmt.Sprintf(":%d", port),
+		Addr:         l.Address,
 		ReadTimeout:  5 * time.Second,
 		WriteTimeout: 5 * time.Second,
 		IdleTimeout:  30 * time.Second,
-		TLSConfig: &tls.Config{
-			MinVersion:     tls.VersionTLS12,
-			GetCertificate: m.resolveCertificate,
-		},
-		Handler: m.router,
+		Handler:      m.router,
 	}
 
-	m.h3s = &webtransport.Server{
-		H3: http3.Server{
-			Addr: fmt.Sprintf(":%d", port),
-			TLSConfig: &tls.Config{
-				MinVersion:     tls.VersionTLS12,
-				GetCertificate: m.resolveCertificate,
+	servers := &listenerServers{hs: hs}
+
+	if !l.Plain {
+		hs.TLSConfig = &tls.Config{
+			MinVersion:         tls.VersionTLS12,
+			GetCertificate:     m.resolveCertificate,
+			GetConfigForClient: m.alpn.configForClient,
+		}
+	}
+
+	if l.HTTP3 {
+		wts := &webtransport.Server{
+			H3: http3.Server{
+				Addr: l.Address,
+				TLSConfig: &tls.Config{
+					MinVersion:     tls.VersionTLS12,
+					GetCertificate: m.resolveCertificate,
+				},
 			},
-			Handler: m.router,
-		},
+		}
+
+		wts.H3.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			m.router.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), wtServerKey, wts)))
+		})
+
+		servers.wts = wts
+	}
+
+	if !m.register(servers) {
+		return nil
+	}
+
+	if l.Plain {
+		return ignoreServerClosed(hs.ListenAndServe())
+	}
+
+	if servers.wts == nil {
+		return ignoreServerClosed(hs.ListenAndServeTLS("", ""))
 	}
 
 	// Open the listeners
-	udpAddr, err := net.ResolveUDPAddr("udp", m.h3s.H3.Addr)
+	udpAddr, err := net.ResolveUDPAddr("udp", l.Address)
 	if err != nil {
 		return err
 	}
@@ -240,39 +636,201 @@ func (m *NetworkManager) createServer(port int) error {
 	}()
 
 	go func() {
-		qErr <- m.h3s.Serve(udpConn)
+		qErr <- servers.wts.Serve(udpConn)
 	}()
 
+	// On shutdown both servers stop, with the HTTP/3 server only closed once its requests have drained
 	select {
 	case err := <-hErr:
-		_ = m.h3s.Close()
+		if m.isClosing() {
+			<-qErr
+
+			return nil
+		}
+
+		_ = servers.wts.Close()
 
 		return err
 	case err := <-qErr:
+		if m.isClosing() {
+			<-hErr
+
+			return nil
+		}
+
 		_ = hs.Close()
 
 		return err
 	}
 }
 
-func (m *NetworkManager) upgradeWT(w http.ResponseWriter, r *http.Request) (*webtransport.Session, error) {
-	return m.h3s.Upgrade(w, r)
+// register records the servers of a listener for Shutdown, returning false if shutdown has already begun.
+func (m *NetworkManager) register(servers *listenerServers) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closing {
+		return false
+	}
+
+	m.servers = append(m.servers, servers)
+
+	return true
 }
 
-func (m *NetworkManager) resolveCertificate(info *tls.ClientHelloInfo) (*tls.Certificate, error) {
-	m.logger.Debug("Resolving certificate", "name", info.ServerName)
-	parts := strings.SplitN(info.ServerName, ".", 2)
+func (m *NetworkManager) isClosing() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.closing
+}
+
+func ignoreServerClosed(err error) error {
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+
+	return err
+}
+
+// Shutdown stops accepting connections and waits for in-flight requests to finish, until the context is done. Any
+// requests still running then are logged and aborted.
+func (m *NetworkManager) Shutdown(ctx context.Context) {
+	m.mu.Lock()
+	m.closing = true
+	servers := m.servers
+	m.mu.Unlock()
+
+	m.logger.Info("Stopping listeners")
+
+	var wg sync.WaitGroup
+
+	for _, s := range servers {
+		wg.Add(1)
+
+		go func(hs *http.Server) {
+			defer wg.Done()
+
+			if err := hs.Shutdown(ctx); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+				m.logger.Warn("Failed to shut down listener", "address", hs.Addr, "err", err)
+			}
+		}(s.hs)
+	}
+
+	if running := m.drain(ctx); len(running) > 0 {
+		for _, r := range running {
+			m.logger.Warn(
+				"Request still running at shutdown deadline",
+				"method", r.method,
+				"path", r.path,
+				"proto", r.proto,
+				"remote", r.remote,
+				"running", time.Since(r.started).Round(time.Millisecond),
+			)
+		}
+	}
+
+	for _, s := range servers {
+		if s.wts != nil {
+			_ = s.wts.Close()
+		}
 
-	if len(parts) != 2 {
-		return nil, nil //nolint:nilnil
+		_ = s.hs.Close()
 	}
 
-	cert, ok := m.certs[strings.ToLower(parts[1])]
+	wg.Wait()
+}
+
+// drain waits for in-flight requests to finish, returning those still running when the context is done.
+func (m *NetworkManager) drain(ctx context.Context) []inflightRequest {
+	for {
+		m.mu.Lock()
+		n := len(m.inflight)
+		m.mu.Unlock()
+
+		if n == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			m.mu.Lock()
+			defer m.mu.Unlock()
+
+			res := make([]inflightRequest, 0, len(m.inflight))
+			for _, r := range m.inflight {
+				res = append(res, r)
+			}
+
+			return res
+		case <-m.drained:
+		}
+	}
+}
+
+// trackMiddleware records in-flight requests so that shutdown can wait for them, turning away new requests once
+// shutdown has begun. HTTP/3 connections are otherwise left open until their requests drain.
+func (m *NetworkManager) trackMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+
+		if m.closing {
+			m.mu.Unlock()
+
+			w.Header().Set("Connection", "close")
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		id := m.nextReq
+		m.nextReq++
+
+		m.inflight[id] = inflightRequest{
+			method:  r.Method,
+			path:    r.URL.Path,
+			proto:   r.Proto,
+			remote:  r.RemoteAddr,
+			started: time.Now(),
+		}
+
+		m.mu.Unlock()
+
+		defer func() {
+			m.mu.Lock()
+			delete(m.inflight, id)
+			m.mu.Unlock()
+
+			select {
+			case m.drained <- struct{}{}:
+			default:
+			}
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+type wtServerKeyType string
+
+// wtServerKey holds the WebTransport server a request arrived on, as sessions can only be upgraded by that server.
+const wtServerKey wtServerKeyType = "ct-wt-server"
+
+var errWebTransportUnavailable = errors.New("webtransport requires http/3")
+
+func (m *NetworkManager) upgradeWT(w http.ResponseWriter, r *http.Request) (*webtransport.Session, error) {
+	wts, ok := r.Context().Value(wtServerKey).(*webtransport.Server)
 	if !ok {
-		return nil, nil //nolint:nilnil
+		return nil, errWebTransportUnavailable
 	}
 
-	return cert, nil
+	return wts.Upgrade(w, r)
+}
+
+func (m *NetworkManager) resolveCertificate(info *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	m.logger.Debug("Resolving certificate", "name", info.ServerName)
+
+	return m.certs.Get(info.ServerName), nil
 }
 
 type h3Hijacker struct {
@@ -313,7 +871,8 @@ func (m *NetworkManager) loggerMiddleware(next http.Handler) http.Handler {
 		defer func() {
 			m.logger.Debug(
 				"Request",
-				"path", r.RequestURI,
+				// The query is left out as it may carry an access token
+				"path", r.URL.Path,
 				"status", ww.Status(),
 				"size", ww.BytesWritten(),
 				"time", time.Since(t1),
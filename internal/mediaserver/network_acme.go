@@ -0,0 +1,292 @@
+package mediaserver
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/csnewman/cathode/internal/db"
+	"github.com/go-acme/lego/v4/certificate"
+	"github.com/go-acme/lego/v4/challenge/http01"
+	"github.com/go-acme/lego/v4/challenge/tlsalpn01"
+	"github.com/go-acme/lego/v4/lego"
+	"github.com/go-acme/lego/v4/providers/dns"
+	"github.com/go-acme/lego/v4/registration"
+)
+
+// acmeName identifies the certificate for the configured domains, so that changing them requests a new one.
+func (m *NetworkManager) acmeName() string {
+	domains := make([]string, 0, len(m.tls.ACME.Domains))
+	for _, d := range m.tls.ACME.Domains {
+		domains = append(domains, strings.ToLower(d))
+	}
+
+	slices.Sort(domains)
+
+	return strings.Join(domains, ",")
+}
+
+func (m *NetworkManager) acmeDirectory() string {
+	switch {
+	case m.tls.ACME.Directory != "":
+		return m.tls.ACME.Directory
+	case acmeProvider(m.tls.ACME.Provider) == acmeProviderLetsEncryptStaging:
+		return lego.LEDirectoryStaging
+	default:
+		return lego.LEDirectoryProduction
+	}
+}
+
+// loadACMECerts serves the stored certificate for the configured domains, returning it parsed. Nil is returned when
+// there is none.
+func (m *NetworkManager) loadACMECerts(ctx context.Context) (*x509.Certificate, error) {
+	entries, err := db.ReadWithData(ctx, m.db, func(ctx context.Context, tx db.RTx) ([]certEntry, error) {
+		return getCerts(ctx, tx, certSourceACME)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch certs: %w", err)
+	}
+
+	name := m.acmeName()
+
+	for _, entry := range entries {
+		if entry.name != name {
+			continue
+		}
+
+		parsed, err := parseCertificate(entry.cert, entry.priKey)
+		if err != nil {
+			m.logger.Warn("Ignoring unusable certificate", "name", entry.name, "err", err)
+
+			continue
+		}
+
+		if err := m.certs.Replace(certSourceACME, []*tls.Certificate{parsed}); err != nil {
+			return nil, err
+		}
+
+		return parsed.Leaf, nil
+	}
+
+	return nil, nil
+}
+
+// renewACME requests a certificate for the configured domains when the current one is due for renewal, returning
+// when the next renewal is due.
+func (m *NetworkManager) renewACME(ctx context.Context) (time.Time, error) {
+	leaf, err := m.loadACMECerts(ctx)
+	if err != nil {
+		return time.Now().Add(renewCheckInterval), err
+	}
+
+	if due := m.renewalDue(leaf); time.Now().Before(due) {
+		return due, nil
+	}
+
+	leaf, err = m.obtainACME(ctx)
+	if err != nil {
+		return time.Now().Add(renewCheckInterval), fmt.Errorf("acme: %w", err)
+	}
+
+	return m.renewalDue(leaf), nil
+}
+
+// obtainACME requests a certificate for the configured domains using the configured challenge, returning it parsed.
+func (m *NetworkManager) obtainACME(ctx context.Context) (*x509.Certificate, error) {
+	cfg := m.tls.ACME
+	directory := m.acmeDirectory()
+
+	key, err := m.acmeAccountKey(ctx, directory, cfg.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &acmeUser{email: cfg.Email, key: key}
+
+	lcfg := lego.NewConfig(user)
+	lcfg.CADirURL = directory
+	lcfg.Certificate.KeyType = acmeKeyTypes[acmeKeyType(cfg.KeyType)]
+
+	client, err := lego.NewClient(lcfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create acme client: %w", err)
+	}
+
+	switch cfg.Challenge {
+	case ACMEChallengeHTTP01:
+		host, port, err := net.SplitHostPort(cfg.HTTPAddress)
+		if err != nil {
+			return nil, err
+		}
+
+		err = client.Challenge.SetHTTP01Provider(http01.NewProviderServer(host, port))
+		if err != nil {
+			return nil, err
+		}
+	case ACMEChallengeTLSALPN01:
+		if err := client.Challenge.SetTLSALPN01Provider(m.alpn); err != nil {
+			return nil, err
+		}
+	case ACMEChallengeDNS01:
+		provider, err := dns.NewDNSChallengeProviderByName(cfg.DNSProvider)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create dns provider: %w", err)
+		}
+
+		if err := client.Challenge.SetDNS01Provider(provider); err != nil {
+			return nil, err
+		}
+	}
+
+	// Registering an existing key returns its account
+	user.registration, err = client.Registration.Register(registration.RegisterOptions{TermsOfServiceAgreed: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to register acme account: %w", err)
+	}
+
+	m.logger.Info("Requesting certificate", "domains", cfg.Domains, "challenge", cfg.Challenge, "directory", directory)
+
+	res, err := client.Certificate.Obtain(certificate.ObtainRequest{
+		Domains: cfg.Domains,
+		Bundle:  true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to obtain cert: %w", err)
+	}
+
+	parsed, err := parseCertificate(res.Certificate, res.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	m.logger.Info("Certificate issued", "domains", cfg.Domains, "expires", parsed.Leaf.NotAfter)
+
+	err = m.db.Write(ctx, func(ctx context.Context, tx db.WTx) error {
+		return replaceCerts(ctx, tx, certEntry{
+			source:  certSourceACME,
+			name:    m.acmeName(),
+			cert:    res.Certificate,
+			priKey:  res.PrivateKey,
+			issued:  parsed.Leaf.NotBefore,
+			expires: parsed.Leaf.NotAfter,
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store: %w", err)
+	}
+
+	if err := m.certs.Replace(certSourceACME, []*tls.Certificate{parsed}); err != nil {
+		return nil, err
+	}
+
+	m.publishCertificate(certSourceACME, parsed.Leaf)
+
+	return parsed.Leaf, nil
+}
+
+// acmeAccountKey loads the key of the account used with a directory, creating one if needed. Reusing the account
+// avoids the limits CAs place on creating accounts.
+func (m *NetworkManager) acmeAccountKey(ctx context.Context, directory string, email string) (*ecdsa.PrivateKey, error) {
+	keyPEM, err := db.ReadWithData(ctx, m.db, func(ctx context.Context, tx db.RTx) ([]byte, error) {
+		return getACMEAccountKey(ctx, tx, directory, email)
+	})
+	if err == nil {
+		block, _ := pem.Decode(keyPEM)
+		if block == nil {
+			return nil, fmt.Errorf("%w: acme account key is not pem", errInvalidState)
+		}
+
+		return x509.ParseECPrivateKey(block.Bytes)
+	} else if !errors.Is(err, errNotFound) {
+		return nil, fmt.Errorf("failed to fetch acme account: %w", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate account key: %w", err)
+	}
+
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode account key: %w", err)
+	}
+
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+
+	err = m.db.Write(ctx, func(ctx context.Context, tx db.WTx) error {
+		return insertACMEAccount(ctx, tx, directory, email, keyPEM)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store acme account: %w", err)
+	}
+
+	return key, nil
+}
+
+// alpnChallenges answers tls-alpn-01 challenges on the TLS listeners, rather than on a separate listener as lego does.
+type alpnChallenges struct {
+	mu    sync.Mutex
+	certs map[string]*tls.Certificate
+}
+
+func newALPNChallenges() *alpnChallenges {
+	return &alpnChallenges{
+		certs: make(map[string]*tls.Certificate),
+	}
+}
+
+func (c *alpnChallenges) Present(domain string, _ string, keyAuth string) error {
+	cert, err := tlsalpn01.ChallengeCert(domain, keyAuth)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.certs[strings.ToLower(domain)] = cert
+
+	return nil
+}
+
+func (c *alpnChallenges) CleanUp(domain string, _ string, _ string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.certs, strings.ToLower(domain))
+
+	return nil
+}
+
+// configForClient serves the challenge certificate to CAs validating a domain, leaving other handshakes to the
+// listener config.
+func (c *alpnChallenges) configForClient(info *tls.ClientHelloInfo) (*tls.Config, error) {
+	if !slices.Contains(info.SupportedProtos, tlsalpn01.ACMETLS1Protocol) {
+		return nil, nil //nolint:nilnil
+	}
+
+	c.mu.Lock()
+	cert, ok := c.certs[strings.ToLower(info.ServerName)]
+	c.mu.Unlock()
+
+	if !ok {
+		return nil, nil //nolint:nilnil
+	}
+
+	return &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{*cert},
+		NextProtos:   []string{tlsalpn01.ACMETLS1Protocol},
+	}, nil
+}
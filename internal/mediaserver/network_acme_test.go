@@ -0,0 +1,306 @@
+package mediaserver
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/csnewman/cathode/internal/db"
+)
+
+// pebbleServer is a local pebble ACME server, validating challenges against this process.
+type pebbleServer struct {
+	directory string
+	httpPort  int
+	tlsPort   int
+}
+
+// startPebble runs the pebble binary on the PATH, skipping the test when there is none. Pebble resolves localhost
+// through the system resolver, so certificates can be issued for it.
+func startPebble(t *testing.T) *pebbleServer {
+	t.Helper()
+
+	bin, err := exec.LookPath("pebble")
+	if err != nil {
+		t.Skip("pebble is not on the PATH")
+	}
+
+	dir := t.TempDir()
+
+	// The directory is served over HTTPS, with a certificate lego is told to trust
+	certPath := filepath.Join(dir, "cert.pem")
+	keyPath := filepath.Join(dir, "key.pem")
+
+	cert := testCertificate(t, time.Now().Add(time.Hour), "localhost", "127.0.0.1")
+
+	key, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writePEM(t, certPath, "CERTIFICATE", cert.Certificate[0])
+	writePEM(t, keyPath, "PRIVATE KEY", key)
+
+	t.Setenv("LEGO_CA_CERTIFICATES", certPath)
+
+	s := &pebbleServer{
+		httpPort: freePort(t),
+		tlsPort:  freePort(t),
+	}
+
+	listenPort := freePort(t)
+	s.directory = fmt.Sprintf("https://localhost:%d/dir", listenPort)
+
+	cfg := map[string]any{
+		"pebble": map[string]any{
+			"listenAddress":           net.JoinHostPort("127.0.0.1", strconv.Itoa(listenPort)),
+			"managementListenAddress": net.JoinHostPort("127.0.0.1", strconv.Itoa(freePort(t))),
+			"certificate":             certPath,
+			"privateKey":              keyPath,
+			"httpPort":                s.httpPort,
+			"tlsPort":                 s.tlsPort,
+			"keyAlgorithm":            "ecdsa",
+			"profiles": map[string]any{
+				"default": map[string]any{
+					"description":    "default",
+					"validityPeriod": int((90 * 24 * time.Hour).Seconds()),
+				},
+			},
+		},
+	}
+
+	cfgData, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfgPath := filepath.Join(dir, "pebble.json")
+
+	if err := os.WriteFile(cfgPath, cfgData, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(bin, "-config", cfgPath)
+	cmd.Env = append(os.Environ(), "PEBBLE_VA_NOSLEEP=1", "PEBBLE_WFE_NONCEREJECT=0")
+
+	if testing.Verbose() {
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+	}
+
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+
+	for start := time.Now(); ; time.Sleep(50 * time.Millisecond) {
+		conn, err := net.Dial("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(listenPort)))
+		if err == nil {
+			_ = conn.Close()
+
+			break
+		}
+
+		if time.Since(start) > 10*time.Second {
+			t.Fatalf("pebble did not start: %v", err)
+		}
+	}
+
+	return s
+}
+
+func writePEM(t *testing.T, path string, typ string, der []byte) {
+	t.Helper()
+
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func freePort(t *testing.T) int {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer l.Close()
+
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+// openTestDB opens a migrated database in a temporary directory.
+func openTestDB(t *testing.T, logger *slog.Logger) *db.DB {
+	t.Helper()
+
+	d, err := db.Open(filepath.Join(t.TempDir(), "db.sqlite3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = d.Close()
+	})
+
+	if err := (&Server{logger: logger, db: d}).migrate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	return d
+}
+
+// serveTLS answers TLS handshakes on port as the TLS listeners do, so that tls-alpn-01 challenges can be validated.
+func serveTLS(t *testing.T, m *NetworkManager, port int) {
+	t.Helper()
+
+	l, err := tls.Listen("tcp", net.JoinHostPort("", strconv.Itoa(port)), &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		GetCertificate:     m.resolveCertificate,
+		GetConfigForClient: m.alpn.configForClient,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = l.Close()
+	})
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if errors.Is(err, net.ErrClosed) {
+				return
+			} else if err != nil {
+				continue
+			}
+
+			go func() {
+				defer conn.Close()
+
+				_ = conn.(*tls.Conn).Handshake()
+			}()
+		}
+	}()
+}
+
+// TestACMEPebble issues a certificate for localhost from pebble with each challenge answered in process, checking that
+// it is stored in the certs table and served for handshakes.
+func TestACMEPebble(t *testing.T) {
+	if testing.Short() {
+		t.Skip("issuing certificates is slow")
+	}
+
+	pebble := startPebble(t)
+
+	for _, challenge := range []ACMEChallenge{ACMEChallengeHTTP01, ACMEChallengeTLSALPN01} {
+		t.Run(string(challenge), func(t *testing.T) {
+			ctx := context.Background()
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			database := openTestDB(t, logger)
+
+			newManager := func() *NetworkManager {
+				return &NetworkManager{
+					logger: logger,
+					db:     database,
+					tls: TLSConfig{
+						RenewBefore: 10 * 24 * time.Hour,
+						ACME: ACMEConfig{
+							Domains:     []string{"localhost"},
+							Directory:   pebble.directory,
+							KeyType:     string(acmeKeyTypeEC256),
+							Challenge:   challenge,
+							HTTPAddress: net.JoinHostPort("", strconv.Itoa(pebble.httpPort)),
+						},
+					},
+					certs:  newCertStore(),
+					alpn:   newALPNChallenges(),
+					events: NewEventBus(logger),
+				}
+			}
+
+			m := newManager()
+			serveTLS(t, m, pebble.tlsPort)
+
+			due, err := m.renewACME(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !due.After(time.Now()) {
+				t.Errorf("renewal due %v, want in the future", due)
+			}
+
+			entries, err := db.ReadWithData(ctx, database, func(ctx context.Context, tx db.RTx) ([]certEntry, error) {
+				return getCerts(ctx, tx, certSourceACME)
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(entries) != 1 || entries[0].name != "localhost" {
+				t.Fatalf("stored certs = %v, want one for localhost", len(entries))
+			}
+
+			served, err := m.resolveCertificate(&tls.ClientHelloInfo{ServerName: "localhost"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if served == nil || !slices.Contains(served.Leaf.DNSNames, "localhost") {
+				t.Fatalf("served certificate does not cover localhost")
+			}
+
+			// A handshake is answered with the issued certificate
+			conn, err := tls.Dial("tcp", net.JoinHostPort("localhost", strconv.Itoa(pebble.tlsPort)), &tls.Config{
+				ServerName:         "localhost",
+				InsecureSkipVerify: true, //nolint:gosec
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			peer := conn.ConnectionState().PeerCertificates[0]
+			_ = conn.Close()
+
+			if peer.SerialNumber.Cmp(served.Leaf.SerialNumber) != 0 {
+				t.Errorf("handshake served serial %v, want %v", peer.SerialNumber, served.Leaf.SerialNumber)
+			}
+
+			// A restarted server serves the stored certificate rather than requesting another
+			restarted := newManager()
+
+			if _, err := restarted.renewACME(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			reloaded, err := restarted.resolveCertificate(&tls.ClientHelloInfo{ServerName: "localhost"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if reloaded == nil || reloaded.Leaf.SerialNumber.Cmp(served.Leaf.SerialNumber) != 0 {
+				t.Errorf("restarted server did not serve the stored certificate")
+			}
+		})
+	}
+}
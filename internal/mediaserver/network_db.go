@@ -2,32 +2,55 @@ package mediaserver
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/csnewman/cathode/internal/db"
 )
 
-func getDSDMServers(_ context.Context, tx db.RTx) ([]string, error) {
-	var servers []string
+// dsdmServer is a DSDM server certificates are requested through, with the ACME provider and key type to use.
+type dsdmServer struct {
+	server   string
+	provider acmeProvider
+	keyType  acmeKeyType
+}
+
+func getDSDMServers(_ context.Context, tx db.RTx) ([]dsdmServer, error) {
+	var servers []dsdmServer
 
-	rows, err := tx.Query(`SELECT server FROM dsdm_servers`)
+	rows, err := tx.Query(`SELECT server, provider, key_type FROM dsdm_servers ORDER BY server`)
 	if err != nil {
 		return nil, err
 	}
 
+	defer rows.Close()
+
 	for rows.Next() {
-		var res string
-		if err := rows.Scan(&res); err != nil {
+		var server, provider, keyType string
+		if err := rows.Scan(&server, &provider, &keyType); err != nil {
 			return nil, err
 		}
 
-		servers = append(servers, res)
+		servers = append(servers, dsdmServer{
+			server:   server,
+			provider: acmeProvider(provider),
+			keyType:  acmeKeyType(keyType),
+		})
 	}
 
 	return servers, nil
 }
 
-func insertDSDMServer(_ context.Context, tx db.WTx, server string) error {
-	return tx.Exec(`INSERT OR REPLACE INTO dsdm_servers (server) VALUES ($1)`, server)
+func upsertDSDMServer(_ context.Context, tx db.WTx, server dsdmServer) error {
+	return tx.Exec(`
+		INSERT INTO dsdm_servers (server, provider, key_type) VALUES ($1, $2, $3)
+		ON CONFLICT (server) DO UPDATE SET provider = excluded.provider, key_type = excluded.key_type`,
+		server.server,
+		string(server.provider),
+		string(server.keyType),
+	)
 }
 
 func cleanupDSDMCerts(_ context.Context, tx db.WTx) error {
@@ -35,10 +58,12 @@ func cleanupDSDMCerts(_ context.Context, tx db.WTx) error {
 }
 
 type dsdmEntry struct {
-	domain string
-	server string
-	cert   []byte
-	priKey []byte
+	domain  string
+	server  string
+	cert    []byte
+	priKey  []byte
+	issued  time.Time
+	expires time.Time
 }
 
 func getDSDMCerts(_ context.Context, tx db.RTx) ([]dsdmEntry, error) {
@@ -65,10 +90,93 @@ func insertDSDMCert(_ context.Context, tx db.WTx, entry dsdmEntry) error {
 	return tx.Exec(`
 		INSERT OR REPLACE INTO dsdm_certs
 		(domain, server, cert, pri_key, issue_date, expire_date)
-		VALUES ($1, $2, $3, $4, datetime(), datetime('now', '+30 days'))`,
+		VALUES ($1, $2, $3, $4, $5, $6)`,
 		entry.domain,
 		entry.server,
 		entry.cert,
 		entry.priKey,
+		entry.issued.UTC().Format(sqliteTimeLayout),
+		entry.expires.UTC().Format(sqliteTimeLayout),
+	)
+}
+
+// certEntry is a certificate from a source other than DSDM, named so that a source can hold several.
+type certEntry struct {
+	source  certSource
+	name    string
+	cert    []byte
+	priKey  []byte
+	issued  time.Time
+	expires time.Time
+}
+
+func getCerts(_ context.Context, tx db.RTx, source certSource) ([]certEntry, error) {
+	rows, err := tx.Query(`
+		SELECT name, cert, pri_key, issue_date, expire_date FROM certs WHERE source = $1 AND expire_date > datetime()`,
+		string(source),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var entries []certEntry
+
+	for rows.Next() {
+		res := certEntry{source: source}
+
+		var issued, expires string
+		if err := rows.Scan(&res.name, &res.cert, &res.priKey, &issued, &expires); err != nil {
+			return nil, err
+		}
+
+		res.issued = parseSQLiteTime(issued)
+		res.expires = parseSQLiteTime(expires)
+
+		entries = append(entries, res)
+	}
+
+	return entries, nil
+}
+
+// replaceCerts stores a certificate, removing any others of its source.
+func replaceCerts(_ context.Context, tx db.WTx, entry certEntry) error {
+	if err := tx.Exec(`DELETE FROM certs WHERE source = $1`, string(entry.source)); err != nil {
+		return err
+	}
+
+	return tx.Exec(`
+		INSERT INTO certs (source, name, cert, pri_key, issue_date, expire_date) VALUES ($1, $2, $3, $4, $5, $6)`,
+		string(entry.source),
+		entry.name,
+		entry.cert,
+		entry.priKey,
+		entry.issued.UTC().Format(sqliteTimeLayout),
+		entry.expires.UTC().Format(sqliteTimeLayout),
+	)
+}
+
+func getACMEAccountKey(_ context.Context, tx db.RTx, directory string, email string) ([]byte, error) {
+	var key []byte
+
+	err := tx.QueryRow(
+		`SELECT pri_key FROM acme_accounts WHERE directory = $1 AND email = $2`,
+		directory,
+		email,
+	).Scan(&key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: acme account for %v", errNotFound, directory)
+	}
+
+	return key, err
+}
+
+func insertACMEAccount(_ context.Context, tx db.WTx, directory string, email string, key []byte) error {
+	return tx.Exec(
+		`INSERT INTO acme_accounts (directory, email, pri_key) VALUES ($1, $2, $3)`,
+		directory,
+		email,
+		key,
 	)
 }
@@ -0,0 +1,67 @@
+package mediaserver
+
+import (
+	"crypto/x509"
+	"testing"
+	"time"
+)
+
+func TestRenewalDue(t *testing.T) {
+	issued := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name        string
+		renewBefore time.Duration
+		lifetime    time.Duration
+		want        time.Time
+	}{
+		{
+			name:        "lead time",
+			renewBefore: 30 * day,
+			lifetime:    90 * day,
+			want:        issued.Add(60 * day),
+		},
+		{
+			name:        "short lead time",
+			renewBefore: 10 * day,
+			lifetime:    90 * day,
+			want:        issued.Add(80 * day),
+		},
+		{
+			name:        "capped to a third of the lifetime",
+			renewBefore: 30 * day,
+			lifetime:    6 * day,
+			want:        issued.Add(4 * day),
+		},
+		{
+			name:        "lead time equal to a third",
+			renewBefore: 2 * day,
+			lifetime:    6 * day,
+			want:        issued.Add(4 * day),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &NetworkManager{tls: TLSConfig{RenewBefore: tt.renewBefore}}
+
+			leaf := &x509.Certificate{
+				NotBefore: issued,
+				NotAfter:  issued.Add(tt.lifetime),
+			}
+
+			if got := m.renewalDue(leaf); !got.Equal(tt.want) {
+				t.Errorf("renewalDue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("no certificate", func(t *testing.T) {
+		m := &NetworkManager{tls: TLSConfig{RenewBefore: 30 * day}}
+
+		if got := m.renewalDue(nil); !got.IsZero() {
+			t.Errorf("renewalDue(nil) = %v, want immediately", got)
+		}
+	})
+}
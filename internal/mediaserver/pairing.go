@@ -0,0 +1,295 @@
+package mediaserver
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/csnewman/cathode/internal/db"
+	"github.com/google/uuid"
+)
+
+var (
+	errPairingPending  = errors.New("pairing not yet approved")
+	errTooManyPairings = errors.New("too many pending pairings from address")
+	errInvalidLinkKey  = errors.New("invalid link key")
+)
+
+const (
+	pairingLifetime     = 10 * time.Minute
+	pairingPollInterval = 5 * time.Second
+	// maxPendingPairings bounds memory use, as anyone can start a pairing. The oldest pairing is dropped to make room.
+	maxPendingPairings = 64
+	// maxAddressPairings is how many pairings may be pending from one address, so that a single client cannot push out
+	// everyone else's.
+	maxAddressPairings = 3
+
+	// pairingAlphabet leaves out letters and digits that are easily confused, such as O and 0.
+	pairingAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	pairingCodeLen  = 8
+)
+
+// pairing is a request from a device, such as a TV, to be signed in by a user who enters the displayed code elsewhere.
+// Pairings only live in memory, as they expire within minutes.
+type pairing struct {
+	code       string
+	pollHash   string
+	deviceName string
+	created    time.Time
+	expires    time.Time
+	// source is the address or IPv6 network the pairing was started from.
+	source string
+	// linkKey is the X25519 public key of the device, if it uses the link protocol.
+	linkKey *ecdh.PublicKey
+
+	// approved is set once a user enters the code, with the token held until the device collects it.
+	approved bool
+	token    string
+	device   device
+	user     user
+	// serverLinkKey is the public half of the key the server generated to negotiate the device's link secret.
+	serverLinkKey []byte
+}
+
+// StartPairing creates a pairing for a device, returning the code to display and a secret the device polls with. The
+// link key is optional.
+func (m *AuthManager) StartPairing(deviceName string, linkKey []byte, remoteAddr string) (*pairing, string, error) {
+	var pub *ecdh.PublicKey
+
+	if linkKey != nil {
+		var err error
+
+		if pub, err = ecdh.X25519().NewPublicKey(linkKey); err != nil {
+			return nil, "", fmt.Errorf("%w: %w", errInvalidLinkKey, err)
+		}
+	}
+
+	m.pairMu.Lock()
+	defer m.pairMu.Unlock()
+
+	m.prunePairings()
+
+	source := pairingSource(remoteAddr)
+
+	var (
+		fromSource int
+		oldest     *pairing
+	)
+
+	for _, p := range m.pairings {
+		if p.source == source && !p.approved {
+			fromSource++
+		}
+
+		// Approved pairings hold a token the device is about to collect, so pending ones are dropped first
+		if oldest == nil || oldest.approved && !p.approved ||
+			oldest.approved == p.approved && p.created.Before(oldest.created) {
+			oldest = p
+		}
+	}
+
+	if fromSource >= maxAddressPairings {
+		return nil, "", errTooManyPairings
+	}
+
+	if len(m.pairings) >= maxPendingPairings {
+		m.logger.Warn("Too many pending pairings, dropping the oldest", "source", oldest.source)
+
+		delete(m.pairings, oldest.code)
+	}
+
+	code := pairingCode()
+	for m.pairings[code] != nil {
+		code = pairingCode()
+	}
+
+	pollToken := randomToken()
+	now := time.Now()
+
+	p := &pairing{
+		code:       code,
+		pollHash:   hashToken(pollToken),
+		deviceName: deviceName,
+		created:    now,
+		expires:    now.Add(pairingLifetime).UTC().Truncate(time.Second),
+		source:     source,
+		linkKey:    pub,
+	}
+
+	m.pairings[code] = p
+
+	return p, pollToken, nil
+}
+
+// ApprovePairing signs the device waiting on a code in as the given user.
+func (m *AuthManager) ApprovePairing(ctx context.Context, u user, code string) (device, error) {
+	m.pairMu.Lock()
+	defer m.pairMu.Unlock()
+
+	m.prunePairings()
+
+	p, ok := m.pairings[normalizePairingCode(code)]
+	if !ok || p.approved {
+		return device{}, fmt.Errorf("%w: pairing", errNotFound)
+	}
+
+	token := randomToken()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	d := device{
+		id:        uuid.New(),
+		userID:    u.id,
+		name:      p.deviceName,
+		tokenHash: hashToken(token),
+		created:   now,
+		lastSeen:  now,
+	}
+
+	var serverKey []byte
+
+	if p.linkKey != nil {
+		var err error
+
+		if d.linkSecret, serverKey, err = negotiateLinkSecret(p.linkKey); err != nil {
+			return device{}, err
+		}
+	}
+
+	err := m.db.Write(ctx, func(ctx context.Context, tx db.WTx) error {
+		return insertDevice(ctx, tx, d)
+	})
+	if err != nil {
+		return device{}, err
+	}
+
+	p.approved = true
+	p.token = token
+	p.device = d
+	p.user = u
+	p.serverLinkKey = serverKey
+
+	m.logger.Info("Device paired", "user", u.username, "device", d.name)
+
+	return d, nil
+}
+
+// PollPairing returns the approved pairing, holding the device token. The pairing is only returned once.
+func (m *AuthManager) PollPairing(pollToken string) (*pairing, error) {
+	m.pairMu.Lock()
+	defer m.pairMu.Unlock()
+
+	m.prunePairings()
+
+	hash := hashToken(pollToken)
+
+	for code, p := range m.pairings {
+		if subtle.ConstantTimeCompare([]byte(p.pollHash), []byte(hash)) != 1 {
+			continue
+		}
+
+		if !p.approved {
+			return nil, errPairingPending
+		}
+
+		delete(m.pairings, code)
+
+		return p, nil
+	}
+
+	return nil, fmt.Errorf("%w: pairing", errNotFound)
+}
+
+// prunePairings drops expired pairings. Approved pairings that were never collected are dropped too, leaving a device
+// that can be revoked by its user.
+func (m *AuthManager) prunePairings() {
+	now := time.Now()
+
+	for code, p := range m.pairings {
+		if now.After(p.expires) {
+			delete(m.pairings, code)
+		}
+	}
+}
+
+func (m *AuthManager) Devices(ctx context.Context, userID uuid.UUID) ([]device, error) {
+	return db.ReadWithData(ctx, m.db, func(ctx context.Context, tx db.RTx) ([]device, error) {
+		return getUserDevices(ctx, tx, userID)
+	})
+}
+
+// RevokeDevice removes a paired device, invalidating its token. Users may only revoke their own devices, unless they
+// are an admin.
+func (m *AuthManager) RevokeDevice(ctx context.Context, u user, id uuid.UUID) error {
+	return m.db.Write(ctx, func(ctx context.Context, tx db.WTx) error {
+		d, err := getDevice(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if d.userID != u.id && u.role != userRoleAdmin {
+			return fmt.Errorf("%w: device %v", errNotFound, id)
+		}
+
+		return deleteDevice(ctx, tx, id)
+	})
+}
+
+// pairingSource returns the host of a remote address, widening IPv6 addresses to their /64 as clients are usually
+// given a whole network.
+func pairingSource(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return host
+	}
+
+	addr = addr.Unmap()
+
+	if addr.Is6() {
+		return netip.PrefixFrom(addr, 64).Masked().String()
+	}
+
+	return addr.String()
+}
+
+func pairingCode() string {
+	buf := make([]byte, pairingCodeLen)
+
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+
+	for i, b := range buf {
+		// The alphabet has 32 characters, so masking keeps the distribution uniform
+		buf[i] = pairingAlphabet[b&31]
+	}
+
+	return string(buf)
+}
+
+// normalizePairingCode accepts codes typed in lower case or with separators.
+func normalizePairingCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+
+		return r
+	}, strings.ToUpper(code))
+}
+
+// formatPairingCode splits a code in two to make it easier to read.
+func formatPairingCode(code string) string {
+	return code[:pairingCodeLen/2] + "-" + code[pairingCodeLen/2:]
+}
@@ -0,0 +1,71 @@
+package mediaserver
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+func TestStartPairingLimits(t *testing.T) {
+	m := NewAuthManager(slog.New(slog.NewTextHandler(io.Discard, nil)), nil)
+
+	first, _, err := m.StartPairing("tv", nil, "192.0.2.1:1000")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 1; i < maxAddressPairings; i++ {
+		if _, _, err := m.StartPairing("tv", nil, fmt.Sprintf("192.0.2.1:%d", 1000+i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, _, err := m.StartPairing("tv", nil, "192.0.2.1:2000"); !errors.Is(err, errTooManyPairings) {
+		t.Fatalf("StartPairing() error = %v, want too many pairings", err)
+	}
+
+	for i := 0; i < maxAddressPairings; i++ {
+		if _, _, err := m.StartPairing("tv", nil, fmt.Sprintf("[2001:db8::%d]:1000", i+1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, _, err := m.StartPairing("tv", nil, "[2001:db8::ff]:1000"); !errors.Is(err, errTooManyPairings) {
+		t.Fatalf("StartPairing() from the same /64 error = %v, want too many pairings", err)
+	}
+
+	// Filling the table drops the oldest pairing rather than refusing new ones
+	for i := len(m.pairings); i < maxPendingPairings+1; i++ {
+		if _, _, err := m.StartPairing("tv", nil, fmt.Sprintf("198.51.100.%d:1000", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(m.pairings) != maxPendingPairings {
+		t.Errorf("%d pairings pending, want %d", len(m.pairings), maxPendingPairings)
+	}
+
+	if m.pairings[first.code] != nil {
+		t.Errorf("oldest pairing was kept")
+	}
+}
+
+func TestPairingSource(t *testing.T) {
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{addr: "192.0.2.1:1000", want: "192.0.2.1"},
+		{addr: "[::ffff:192.0.2.1]:1000", want: "192.0.2.1"},
+		{addr: "[2001:db8:1:2:3:4:5:6]:1000", want: "2001:db8:1:2::/64"},
+		{addr: "unix", want: "unix"},
+	}
+
+	for _, tt := range tests {
+		if got := pairingSource(tt.addr); got != tt.want {
+			t.Errorf("pairingSource(%q) = %q, want %q", tt.addr, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,665 @@
+package mediaserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/csnewman/cathode/internal/db"
+	"github.com/csnewman/cathode/internal/transcoder"
+	"github.com/google/uuid"
+)
+
+var (
+	errSessionTerminated = errors.New("playback session terminated")
+	errPlaybackStopped   = errors.New("playback stopped for shutdown")
+	errSessionEnded      = errors.New("playback session ended")
+)
+
+type playbackPlan string
+
+const (
+	playbackPlanCopy      playbackPlan = "copy"
+	playbackPlanTranscode playbackPlan = "transcode"
+)
+
+const (
+	// playbackIdleTimeout is how long a session can go without activity before it is ended. Players buffer ahead, so
+	// this is generous.
+	playbackIdleTimeout = 2 * time.Minute
+	// terminatedRetention is how long a terminated session is remembered, so that its client is told why playback
+	// stopped rather than that the session does not exist.
+	terminatedRetention = 10 * time.Minute
+	// playbackSampleInterval is how often bandwidth is sampled and idle sessions are ended.
+	playbackSampleInterval = 5 * time.Second
+)
+
+// PlaybackManager tracks the sessions clients are playing items through, running a transcode for clients that cannot
+// play the source directly. Sessions only live in memory, as they end with the client or the server.
+type PlaybackManager struct {
+	logger *slog.Logger
+	db     *db.DB
+	dir    string
+	events *EventBus
+
+	mu       sync.Mutex
+	sessions map[uuid.UUID]*playbackSession
+	// stopped is set once Run has ended every session, after which no more can start.
+	stopped bool
+	// transcodes tracks the running transcode goroutines, so that shutdown can wait for them to stop.
+	transcodes sync.WaitGroup
+}
+
+func NewPlaybackManager(logger *slog.Logger, db *db.DB, dir string, events *EventBus) *PlaybackManager {
+	return &PlaybackManager{
+		logger:   logger,
+		db:       db,
+		dir:      dir,
+		events:   events,
+		sessions: make(map[uuid.UUID]*playbackSession),
+	}
+}
+
+type playbackSession struct {
+	id         uuid.UUID
+	user       user
+	device     uuid.NullUUID
+	deviceName string
+	item       catalogItem
+	plan       playbackPlan
+	started    time.Time
+	// dir holds the output of the transcode, and is empty when playing directly.
+	dir       string
+	transcode *transcoder.Session
+
+	// bytes is the total served to the client, sampled to estimate bandwidth.
+	bytes atomic.Int64
+	// streams counts the responses being served, as a single request can stream a whole file when playing directly.
+	streams atomic.Int32
+	// closed is set once the session has ended, cutting short any responses still being served.
+	closed atomic.Bool
+
+	mu           sync.Mutex
+	remoteAddr   string
+	lastActive   time.Time
+	sampledBytes int64
+	bandwidth    float64
+	// terminated is set when an admin ends the session.
+	terminated bool
+	ended      time.Time
+}
+
+// playbackStatus is a snapshot of a session, as shown to admins.
+type playbackStatus struct {
+	id         uuid.UUID
+	user       user
+	device     uuid.NullUUID
+	deviceName string
+	item       catalogItem
+	plan       playbackPlan
+	started    time.Time
+	remoteAddr string
+	lastActive time.Time
+	bandwidth  float64
+	transcode  *transcoder.Stats
+}
+
+// Run samples session bandwidth and ends idle sessions until the context is cancelled, after which every transcode is
+// stopped and waited for.
+func (m *PlaybackManager) Run(ctx context.Context) {
+	// Sessions do not survive restarts, so any output left behind is stale
+	if err := os.RemoveAll(m.dir); err != nil {
+		m.logger.Warn("Failed to remove old transcode output", "err", err)
+	}
+
+	ticker := time.NewTicker(playbackSampleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			m.mu.Lock()
+
+			m.stopped = true
+
+			for id, s := range m.sessions {
+				m.end(s)
+				delete(m.sessions, id)
+			}
+
+			m.mu.Unlock()
+
+			m.transcodes.Wait()
+
+			return
+		case <-ticker.C:
+			m.sample()
+		}
+	}
+}
+
+func (m *PlaybackManager) sample() {
+	now := time.Now()
+
+	var idle []*playbackSession
+
+	m.mu.Lock()
+
+	for id, s := range m.sessions {
+		s.mu.Lock()
+
+		total := s.bytes.Load()
+		s.bandwidth = float64(total-s.sampledBytes) / playbackSampleInterval.Seconds()
+
+		// Clients playing directly may make a single request for the whole file, so data still flowing counts as
+		// activity too
+		if total > s.sampledBytes || s.streams.Load() > 0 {
+			s.lastActive = now.UTC().Truncate(time.Second)
+		}
+
+		s.sampledBytes = total
+
+		isIdle := now.Sub(s.lastActive) > playbackIdleTimeout
+		expired := s.terminated && now.Sub(s.ended) > terminatedRetention
+
+		s.mu.Unlock()
+
+		if expired {
+			delete(m.sessions, id)
+		} else if isIdle && !s.isTerminated() {
+			delete(m.sessions, id)
+
+			idle = append(idle, s)
+		}
+	}
+
+	m.mu.Unlock()
+
+	// Ending a session removes its output, so is done without holding the lock
+	for _, s := range idle {
+		m.logger.Info("Playback session idle", "session", s.id, "user", s.user.username, "item", s.item.name)
+
+		m.end(s)
+
+		m.publish(eventSessionStopped, s, "idle")
+	}
+}
+
+// Start creates a playback session for a movie or episode. The source is copied when the client supports its video
+// codec, and otherwise transcoded.
+func (m *PlaybackManager) Start(
+	ctx context.Context,
+	p principal,
+	itemID uuid.UUID,
+	codecs []string,
+	remoteAddr string,
+) (*playbackSession, error) {
+	type source struct {
+		item       catalogItem
+		deviceName string
+	}
+
+	src, err := db.ReadWithData(ctx, m.db, func(ctx context.Context, tx db.RTx) (source, error) {
+		items, err := getCatalogItems(ctx, tx, []uuid.UUID{itemID})
+		if err != nil {
+			return source{}, err
+		}
+
+		if len(items) == 0 {
+			return source{}, fmt.Errorf("%w: item %v", errNotFound, itemID)
+		}
+
+		res := source{item: items[0]}
+
+		if p.device.Valid {
+			d, err := getDevice(ctx, tx, p.device.UUID)
+			if err != nil {
+				return source{}, err
+			}
+
+			res.deviceName = d.name
+		}
+
+		return res, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if src.item.kind != itemKindMovie && src.item.kind != itemKindEpisode {
+		return nil, fmt.Errorf("%w: %v is a %v", errNotPlayable, itemID, src.item.kind)
+	}
+
+	probe, err := transcoder.ProbeFile(src.item.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to probe %v: %w", src.item.path, err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+
+	s := &playbackSession{
+		id:         uuid.New(),
+		user:       p.user,
+		device:     p.device,
+		deviceName: src.deviceName,
+		item:       src.item,
+		plan:       playbackPlanCopy,
+		started:    now,
+		remoteAddr: remoteAddr,
+		lastActive: now,
+	}
+
+	if !slices.Contains(codecs, probe.VideoCodec) {
+		s.plan = playbackPlanTranscode
+		s.dir = filepath.Join(m.dir, s.id.String())
+
+		if err := m.startTranscode(s); err != nil {
+			return nil, err
+		}
+	}
+
+	m.mu.Lock()
+
+	if m.stopped {
+		m.mu.Unlock()
+
+		m.end(s)
+
+		if s.transcode != nil {
+			s.transcode.Close()
+		}
+
+		return nil, errPlaybackStopped
+	}
+
+	m.sessions[s.id] = s
+
+	if s.transcode != nil {
+		m.transcodes.Add(1)
+
+		go m.runTranscode(s)
+	}
+
+	m.mu.Unlock()
+
+	m.publish(eventSessionStarted, s, "")
+
+	m.logger.Info(
+		"Playback started",
+		"session", s.id,
+		"user", s.user.username,
+		"item", s.item.name,
+		"plan", s.plan,
+		"codec", probe.VideoCodec,
+	)
+
+	return s, nil
+}
+
+func (m *PlaybackManager) startTranscode(s *playbackSession) error {
+	if err := os.MkdirAll(s.dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create transcode dir: %w", err)
+	}
+
+	s.transcode = transcoder.Open(m.logger.With("session", s.id), s.item.path, s.dir)
+
+	if err := s.transcode.Start(); err != nil {
+		s.transcode.Close()
+		_ = os.RemoveAll(s.dir)
+
+		return fmt.Errorf("failed to start transcode: %w", err)
+	}
+
+	return nil
+}
+
+// runTranscode runs the transcode of a session, ending the session if the transcode fails.
+func (m *PlaybackManager) runTranscode(s *playbackSession) {
+	defer m.transcodes.Done()
+
+	err := s.transcode.Run()
+
+	s.transcode.Close()
+
+	if err == nil || errors.Is(err, transcoder.ErrStopped) {
+		return
+	}
+
+	m.logger.Error("Transcode failed", "session", s.id, "user", s.user.username, "item", s.item.name, "err", err)
+
+	// The session may have ended while the transcode was failing
+	m.mu.Lock()
+
+	live := m.sessions[s.id] == s && !s.isTerminated()
+	if live {
+		delete(m.sessions, s.id)
+	}
+
+	m.mu.Unlock()
+
+	if !live {
+		return
+	}
+
+	m.end(s)
+
+	m.publish(eventSessionStopped, s, "failed")
+}
+
+// File returns the path of the original file of a movie or episode, for playing directly.
+func (m *PlaybackManager) File(ctx context.Context, itemID uuid.UUID) (string, error) {
+	return db.ReadWithData(ctx, m.db, func(ctx context.Context, tx db.RTx) (string, error) {
+		it, err := getItem(ctx, tx, itemID)
+		if err != nil {
+			return "", err
+		}
+
+		if it.kind != itemKindMovie && it.kind != itemKindEpisode {
+			return "", fmt.Errorf("%w: %v is a %v", errNotPlayable, itemID, it.kind)
+		}
+
+		f, ok, err := getItemFile(ctx, tx, it.path)
+		if err != nil {
+			return "", err
+		} else if !ok || f.missing {
+			return "", fmt.Errorf("%w: file of %v", errNotFound, itemID)
+		}
+
+		return it.path, nil
+	})
+}
+
+// containerTypes maps the extensions of supported containers to their content type, as the standard library only knows
+// a few video types.
+var containerTypes = map[string]string{
+	".avi":  "video/x-msvideo",
+	".flv":  "video/x-flv",
+	".m2ts": "video/mp2t",
+	".m4v":  "video/mp4",
+	".mkv":  "video/x-matroska",
+	".mov":  "video/quicktime",
+	".mp4":  "video/mp4",
+	".mpg":  "video/mpeg",
+	".mpeg": "video/mpeg",
+	".ogv":  "video/ogg",
+	".ts":   "video/mp2t",
+	".webm": "video/webm",
+	".wmv":  "video/x-ms-wmv",
+}
+
+func containerContentType(path string) string {
+	if t, ok := containerTypes[strings.ToLower(filepath.Ext(path))]; ok {
+		return t
+	}
+
+	return "application/octet-stream"
+}
+
+// Touch records a request made by the owner of a session, returning errSessionTerminated if an admin has ended it.
+func (m *PlaybackManager) Touch(id uuid.UUID, userID uuid.UUID, remoteAddr string) (*playbackSession, error) {
+	m.mu.Lock()
+	s, ok := m.sessions[id]
+	m.mu.Unlock()
+
+	if !ok || s.user.id != userID {
+		return nil, fmt.Errorf("%w: session %v", errNotFound, id)
+	}
+
+	return s, s.touch(remoteAddr)
+}
+
+// TouchTranscode records a request for the output of a transcoding session, returning errSessionTerminated if an admin
+// has ended it. The owner is not checked, as the session ID is only known to the client that started playback.
+func (m *PlaybackManager) TouchTranscode(id uuid.UUID, remoteAddr string) (*playbackSession, error) {
+	m.mu.Lock()
+	s, ok := m.sessions[id]
+	m.mu.Unlock()
+
+	if !ok || s.plan != playbackPlanTranscode {
+		return nil, fmt.Errorf("%w: transcode %v", errNotFound, id)
+	}
+
+	return s, s.touch(remoteAddr)
+}
+
+func (s *playbackSession) touch(remoteAddr string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.terminated {
+		return errSessionTerminated
+	}
+
+	s.remoteAddr = remoteAddr
+	s.lastActive = time.Now().UTC().Truncate(time.Second)
+
+	return nil
+}
+
+// KeepAlive records activity on the sessions a client is playing an item through. Players buffer ahead, so may go a
+// long time without requests while still reporting progress.
+func (m *PlaybackManager) KeepAlive(p principal, itemID uuid.UUID) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, s := range m.sessions {
+		if s.user.id != p.user.id || s.device != p.device || s.item.id != itemID {
+			continue
+		}
+
+		s.mu.Lock()
+
+		if !s.terminated {
+			s.lastActive = now
+		}
+
+		s.mu.Unlock()
+	}
+}
+
+// Sessions returns the sessions that have not been terminated, oldest first.
+func (m *PlaybackManager) Sessions() []playbackStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	res := make([]playbackStatus, 0, len(m.sessions))
+
+	for _, s := range m.sessions {
+		if st, ok := s.status(); ok {
+			res = append(res, st)
+		}
+	}
+
+	slices.SortFunc(res, func(a, b playbackStatus) int {
+		if c := a.started.Compare(b.started); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.id.String(), b.id.String())
+	})
+
+	return res
+}
+
+// Terminate ends a session on behalf of an admin, stopping its transcode.
+func (m *PlaybackManager) Terminate(id uuid.UUID) error {
+	m.mu.Lock()
+
+	s, ok := m.sessions[id]
+	if !ok || s.isTerminated() {
+		m.mu.Unlock()
+
+		return fmt.Errorf("%w: session %v", errNotFound, id)
+	}
+
+	// The session stays listed, so its client can be told it was terminated
+	s.mu.Lock()
+	s.terminated = true
+	s.ended = time.Now()
+	s.mu.Unlock()
+
+	m.mu.Unlock()
+
+	m.end(s)
+
+	m.publish(eventSessionStopped, s, "terminated")
+
+	m.logger.Info("Playback session terminated", "session", id, "user", s.user.username, "item", s.item.name)
+
+	return nil
+}
+
+func (m *PlaybackManager) publish(typ eventType, s *playbackSession, reason string) {
+	m.events.Publish(event{
+		typ: typ,
+		data: sessionEvent{
+			id:       s.id,
+			userID:   s.user.id,
+			itemID:   s.item.id,
+			deviceID: s.device,
+			plan:     s.plan,
+			reason:   reason,
+		},
+	})
+}
+
+// end stops the transcode of a session and removes its output. Files still being streamed to the client stop with
+// their next write.
+func (m *PlaybackManager) end(s *playbackSession) {
+	s.mu.Lock()
+	s.ended = time.Now()
+	s.mu.Unlock()
+
+	s.closed.Store(true)
+
+	if s.transcode == nil {
+		return
+	}
+
+	s.transcode.Stop()
+
+	if err := os.RemoveAll(s.dir); err != nil {
+		m.logger.Warn("Failed to remove transcode output", "session", s.id, "err", err)
+	}
+}
+
+func (s *playbackSession) isTerminated() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.terminated
+}
+
+func (s *playbackSession) status() (playbackStatus, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.terminated {
+		return playbackStatus{}, false
+	}
+
+	res := playbackStatus{
+		id:         s.id,
+		user:       s.user,
+		device:     s.device,
+		deviceName: s.deviceName,
+		item:       s.item,
+		plan:       s.plan,
+		started:    s.started,
+		remoteAddr: s.remoteAddr,
+		lastActive: s.lastActive,
+		bandwidth:  s.bandwidth,
+	}
+
+	if s.transcode != nil {
+		st := s.transcode.Stats()
+		res.transcode = &st
+	}
+
+	return res, true
+}
+
+// manifest builds an HLS event playlist of the segments transcoded so far.
+func (s *playbackSession) manifest() string {
+	st := s.transcode.Stats()
+
+	target := transcoder.SegmentLength
+	for _, d := range st.Segments {
+		target = max(target, d)
+	}
+
+	var b strings.Builder
+
+	b.WriteString("#EXTM3U\n")
+	b.WriteString("#EXT-X-VERSION:7\n")
+	fmt.Fprintf(&b, "#EXT-X-TARGETDURATION:%d\n", int(math.Ceil(target.Seconds())))
+	b.WriteString("#EXT-X-PLAYLIST-TYPE:EVENT\n")
+	fmt.Fprintf(&b, "#EXT-X-MAP:URI=%q\n", transcoder.InitSegment)
+
+	for i, d := range st.Segments {
+		fmt.Fprintf(&b, "#EXTINF:%.3f,\n%s\n", d.Seconds(), transcoder.SegmentName(i+1))
+	}
+
+	if st.Done {
+		b.WriteString("#EXT-X-ENDLIST\n")
+	}
+
+	return b.String()
+}
+
+// segmentPath returns the path of a complete segment, or false if the transcode has not produced it.
+func (s *playbackSession) segmentPath(name string) (string, bool) {
+	if name == transcoder.InitSegment {
+		return filepath.Join(s.dir, name), true
+	}
+
+	st := s.transcode.Stats()
+
+	for i := range st.Segments {
+		if transcoder.SegmentName(i+1) == name {
+			return filepath.Join(s.dir, name), true
+		}
+	}
+
+	return "", false
+}
+
+// serveContent serves a file to the client of a session, recording the bytes sent. The response stops if the session
+// ends.
+func (s *playbackSession) serveContent(w http.ResponseWriter, r *http.Request, modtime time.Time, f io.ReadSeeker) {
+	s.streams.Add(1)
+	defer s.streams.Add(-1)
+
+	http.ServeContent(sessionWriter{ResponseWriter: w, s: s}, r, "", modtime, f)
+}
+
+// sessionWriter records the bytes written to the client of a session, failing once the session has ended so that
+// the response is abandoned.
+type sessionWriter struct {
+	http.ResponseWriter
+	s *playbackSession
+}
+
+func (w sessionWriter) Write(b []byte) (int, error) {
+	if w.s.closed.Load() {
+		return 0, errSessionEnded
+	}
+
+	n, err := w.ResponseWriter.Write(b)
+	w.s.bytes.Add(int64(n))
+
+	return n, err
+}
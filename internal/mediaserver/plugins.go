@@ -0,0 +1,705 @@
+package mediaserver
+
+import (
+	"context"
+	"crypto/ed25519"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/csnewman/cathode/internal/db"
+	"github.com/csnewman/cathode/plugin"
+	"github.com/google/uuid"
+	"github.com/quic-go/webtransport-go"
+)
+
+var (
+	errPluginsStopped   = errors.New("plugins stopped for shutdown")
+	errPluginDisabled   = errors.New("plugin disabled")
+	errPluginScope      = errors.New("plugin has not been granted the scope")
+	errInvalidScope     = errors.New("invalid plugin scope")
+	errInvalidPluginKey = errors.New("invalid plugin public key")
+)
+
+// PluginManager tracks the plugins connected over WebTransport, routing their calls to the handlers registered by the
+// rest of the server. Plugins must be registered by an admin, and are identified by their registration, so a
+// reconnecting plugin replaces its old connection.
+//
+// Each registration grants scopes, of which a plugin declares those it needs when connecting. Calls in either direction
+// are only allowed when the scope they require was declared.
+type PluginManager struct {
+	logger *slog.Logger
+	db     *db.DB
+	name   string
+	events *EventBus
+
+	mu      sync.Mutex
+	methods map[string]pluginMethod
+	plugins map[uuid.UUID]*connectedPlugin
+	// subscriptions holds the event subscription of each connection that has made one.
+	subscriptions map[*plugin.Peer]*eventSubscription
+	// stopped is set once Run has disconnected every plugin, after which no more can connect.
+	stopped bool
+}
+
+type pluginMethod struct {
+	scope   string
+	handler plugin.Handler
+}
+
+type connectedPlugin struct {
+	id        uuid.UUID
+	info      plugin.Info
+	peer      *plugin.Peer
+	connected time.Time
+}
+
+func NewPluginManager(logger *slog.Logger, db *db.DB, name string, events *EventBus) *PluginManager {
+	return &PluginManager{
+		logger:        logger,
+		db:            db,
+		name:          name,
+		events:        events,
+		methods:       make(map[string]pluginMethod),
+		plugins:       make(map[uuid.UUID]*connectedPlugin),
+		subscriptions: make(map[*plugin.Peer]*eventSubscription),
+	}
+}
+
+// Run disconnects every plugin once the context is cancelled.
+func (m *PluginManager) Run(ctx context.Context) {
+	<-ctx.Done()
+
+	m.mu.Lock()
+	m.stopped = true
+	plugins := m.plugins
+	m.plugins = make(map[uuid.UUID]*connectedPlugin)
+	m.mu.Unlock()
+
+	for _, p := range plugins {
+		_ = p.peer.Close()
+	}
+}
+
+// Handle registers the handler for calls plugins make to the method, which are only allowed for plugins that declared
+// the scope. Handlers must be registered before plugins connect.
+func (m *PluginManager) Handle(method string, scope string, h plugin.Handler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.methods[method] = pluginMethod{
+		scope:   scope,
+		handler: h,
+	}
+}
+
+// Authenticate resolves the registration of a plugin from the bearer token it connects with, which is either its API
+// key or a token signed with its key.
+func (m *PluginManager) Authenticate(ctx context.Context, token string) (pluginRegistration, error) {
+	var (
+		reg pluginRegistration
+		err error
+	)
+
+	if plugin.IsSignedToken(token) {
+		_, err = plugin.VerifyToken(token, time.Now(), func(id string) (ed25519.PublicKey, error) {
+			pluginID, err := uuid.Parse(id)
+			if err != nil {
+				return nil, plugin.ErrInvalidToken
+			}
+
+			reg, err = db.ReadWithData(ctx, m.db, func(ctx context.Context, tx db.RTx) (pluginRegistration, error) {
+				return getPluginRegistration(ctx, tx, pluginID)
+			})
+			if errors.Is(err, errNotFound) {
+				return nil, plugin.ErrInvalidToken
+			}
+
+			return reg.publicKey, err
+		})
+		if errors.Is(err, plugin.ErrInvalidToken) || errors.Is(err, plugin.ErrTokenExpired) {
+			return pluginRegistration{}, fmt.Errorf("%w: %w", errInvalidCredentials, err)
+		}
+	} else {
+		reg, err = db.ReadWithData(ctx, m.db, func(ctx context.Context, tx db.RTx) (pluginRegistration, error) {
+			return getPluginRegistrationByKey(ctx, tx, hashToken(token))
+		})
+		if errors.Is(err, errNotFound) {
+			return pluginRegistration{}, errInvalidCredentials
+		}
+	}
+
+	if err != nil {
+		return pluginRegistration{}, err
+	}
+
+	if !reg.enabled {
+		return pluginRegistration{}, fmt.Errorf("%w: %v", errPluginDisabled, reg.id)
+	}
+
+	return reg, nil
+}
+
+// Connect completes the handshake with an authenticated plugin on a session upgraded from the request, then tracks the
+// plugin in the background until it disconnects. Failures are logged, as the response has already been sent.
+func (m *PluginManager) Connect(
+	w http.ResponseWriter,
+	r *http.Request,
+	session *webtransport.Session,
+	reg pluginRegistration,
+) {
+	ctx := session.Context()
+
+	id, err := db.ReadWithData(ctx, m.db, getServerID)
+	if err != nil {
+		m.logger.Error("Failed to fetch server id", "err", err)
+
+		_ = session.CloseWithError(0, "")
+
+		return
+	}
+
+	var declared []string
+
+	peer, info, err := plugin.Accept(ctx, w, r, session, plugin.AcceptConfig{
+		Welcome: func(info plugin.Info) (plugin.Welcome, error) {
+			if info.ID != "" && info.ID != reg.id.String() {
+				return plugin.Welcome{}, &plugin.Error{
+					Code:    plugin.CodeForbidden,
+					Message: "The plugin id does not match the credentials",
+				}
+			}
+
+			if scope, ok := missingScope(reg.scopes, info.Scopes); ok {
+				return plugin.Welcome{}, &plugin.Error{
+					Code:    plugin.CodeForbidden,
+					Message: fmt.Sprintf("The %v scope has not been granted to the plugin", scope),
+				}
+			}
+
+			declared = info.Scopes
+
+			return plugin.Welcome{
+				PluginID:      reg.id.String(),
+				ServerID:      id.String(),
+				ServerName:    serverName(m.name),
+				ServerVersion: serverVersion(),
+			}, nil
+		},
+		Handler:   m.scopedMux(&declared),
+		OnMessage: m.onMessage,
+	})
+	if err != nil {
+		m.logger.Warn("Plugin handshake failed", "id", reg.id, "remote", session.RemoteAddr(), "err", err)
+
+		return
+	}
+
+	info.ID = reg.id.String()
+
+	p := &connectedPlugin{
+		id:        reg.id,
+		info:      info,
+		peer:      peer,
+		connected: time.Now(),
+	}
+
+	m.mu.Lock()
+
+	// The registration is checked again while holding the lock, so that a plugin cannot remain connected after being
+	// disabled or revoked during its handshake. Changes are written before the lock is taken to disconnect plugins.
+	err = m.db.Write(ctx, func(ctx context.Context, tx db.WTx) error {
+		current, err := getPluginRegistration(ctx, tx, reg.id)
+		if err != nil {
+			return err
+		}
+
+		if !current.enabled {
+			return errPluginDisabled
+		}
+
+		if scope, ok := missingScope(current.scopes, info.Scopes); ok {
+			return fmt.Errorf("%w: %v", errPluginScope, scope)
+		}
+
+		return touchPluginRegistration(ctx, tx, reg.id, p.connected)
+	})
+	if m.stopped || err != nil {
+		m.mu.Unlock()
+
+		if err != nil {
+			m.logger.Warn("Plugin registration changed during handshake", "id", reg.id, "err", err)
+		}
+
+		_ = peer.Close()
+
+		return
+	}
+
+	prev := m.plugins[reg.id]
+	m.plugins[reg.id] = p
+
+	m.mu.Unlock()
+
+	if prev != nil {
+		m.logger.Info("Plugin reconnected, closing previous connection", "id", reg.id)
+
+		_ = prev.peer.Close()
+	}
+
+	m.logger.Info(
+		"Plugin connected",
+		"id", reg.id,
+		"name", info.Name,
+		"version", info.Version,
+		"capabilities", info.Capabilities,
+		"scopes", info.Scopes,
+		"remote", peer.RemoteAddr(),
+		"datagrams", peer.SupportsDatagrams(),
+	)
+
+	go func() {
+		<-peer.Done()
+
+		m.mu.Lock()
+		if m.plugins[reg.id] == p {
+			delete(m.plugins, reg.id)
+		}
+		m.mu.Unlock()
+
+		m.logger.Info("Plugin disconnected", "id", reg.id, "connected", time.Since(p.connected))
+	}()
+}
+
+// scopedMux returns the handler for calls made by a connection, rejecting those needing a scope it did not declare.
+// The scopes are read once calls arrive, after the handshake has set them.
+func (m *PluginManager) scopedMux(scopes *[]string) *plugin.Mux {
+	mux := plugin.NewMux()
+
+	mux.Handle(plugin.MethodSubscribeEvents, func(_ context.Context, req *plugin.Request) (any, error) {
+		return nil, m.subscribe(req, *scopes)
+	})
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for method, pm := range m.methods {
+		pm := pm
+
+		mux.Handle(method, func(ctx context.Context, req *plugin.Request) (any, error) {
+			if !slices.Contains(*scopes, pm.scope) {
+				return nil, &plugin.Error{
+					Code:    plugin.CodeForbidden,
+					Message: fmt.Sprintf("The %v scope is required", pm.scope),
+				}
+			}
+
+			return pm.handler(ctx, req)
+		})
+	}
+
+	return mux
+}
+
+// pluginEventScopes holds the scope required to receive each type of event. Other types are not sent to plugins.
+var pluginEventScopes = map[eventType]string{
+	eventItemAdded:      plugin.ScopeLibraryRead,
+	eventItemUpdated:    plugin.ScopeLibraryRead,
+	eventItemRemoved:    plugin.ScopeLibraryRead,
+	eventLibraryScan:    plugin.ScopeLibraryRead,
+	eventSessionStarted: plugin.ScopePlaybackEvents,
+	eventSessionStopped: plugin.ScopePlaybackEvents,
+}
+
+// subscribe replaces the event subscription of the connection making the request.
+func (m *PluginManager) subscribe(req *plugin.Request, scopes []string) error {
+	var body plugin.SubscribeRequest
+
+	if err := req.Decode(&body); err != nil {
+		return err
+	}
+
+	var types []eventType
+
+	for _, t := range body.Types {
+		scope, ok := pluginEventScopes[eventType(t)]
+		if !ok {
+			return &plugin.Error{
+				Code:    plugin.CodeBadRequest,
+				Message: fmt.Sprintf("Unknown event type %v", t),
+			}
+		}
+
+		if !slices.Contains(scopes, scope) {
+			return &plugin.Error{
+				Code:    plugin.CodeForbidden,
+				Message: fmt.Sprintf("The %v scope is required for %v events", scope, t),
+			}
+		}
+
+		types = append(types, eventType(t))
+	}
+
+	if len(body.Types) == 0 {
+		for t, scope := range pluginEventScopes {
+			if slices.Contains(scopes, scope) {
+				types = append(types, t)
+			}
+		}
+
+		if len(types) == 0 {
+			return &plugin.Error{
+				Code:    plugin.CodeForbidden,
+				Message: "No events are allowed by the declared scopes",
+			}
+		}
+	}
+
+	sub, err := m.events.Subscribe(matchEventTypes(types))
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	prev := m.subscriptions[req.Peer]
+	m.subscriptions[req.Peer] = sub
+	m.mu.Unlock()
+
+	if prev != nil {
+		prev.Close()
+	}
+
+	go m.forwardEvents(req.Peer, sub)
+
+	return nil
+}
+
+// forwardEvents sends the events of a subscription to the plugin as notifications, until either is closed.
+func (m *PluginManager) forwardEvents(peer *plugin.Peer, sub *eventSubscription) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	defer func() {
+		cancel()
+
+		m.mu.Lock()
+		if m.subscriptions[peer] == sub {
+			delete(m.subscriptions, peer)
+		}
+		m.mu.Unlock()
+
+		sub.Close()
+	}()
+
+	go func() {
+		select {
+		case <-peer.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for {
+		e, err := sub.Next(ctx)
+		if err != nil {
+			return
+		}
+
+		if err := peer.Notify(string(e.typ), toPluginEvent(e)); err != nil {
+			m.logger.Debug("Failed to send event to plugin", "remote", peer.RemoteAddr(), "type", e.typ, "err", err)
+
+			return
+		}
+	}
+}
+
+func toPluginEvent(e event) plugin.Event {
+	res := plugin.Event{
+		ID:   e.id,
+		Type: string(e.typ),
+		Time: e.time,
+	}
+
+	switch d := e.data.(type) {
+	case itemEvent:
+		res.Item = &plugin.ItemEvent{
+			ID:        d.id.String(),
+			LibraryID: d.libraryID.String(),
+			Kind:      string(d.kind),
+		}
+
+		if d.parentID.Valid {
+			res.Item.ParentID = d.parentID.UUID.String()
+		}
+	case scanEvent:
+		res.Scan = &plugin.ScanEvent{
+			LibraryID: d.libraryID.String(),
+			State:     string(d.state),
+			Scanned:   d.scanned,
+			Imported:  d.imported,
+			Failed:    d.failed,
+			Missing:   d.missing,
+		}
+	case sessionEvent:
+		res.Session = &plugin.SessionEvent{
+			ID:     d.id.String(),
+			UserID: d.userID.String(),
+			ItemID: d.itemID.String(),
+			Plan:   string(d.plan),
+			Reason: d.reason,
+		}
+
+		if d.deviceID.Valid {
+			res.Session.DeviceID = d.deviceID.UUID.String()
+		}
+	case droppedEvent:
+		res.Dropped = &plugin.DroppedEvent{Count: d.count}
+	}
+
+	return res
+}
+
+func (m *PluginManager) onMessage(msg plugin.Message) {
+	m.logger.Debug("Plugin message", "topic", msg.Topic, "datagram", msg.Datagram, "remote", msg.Peer.RemoteAddr())
+}
+
+// Call calls the method on the connected plugin with the ID, which must have declared the scope.
+func (m *PluginManager) Call(
+	ctx context.Context,
+	id uuid.UUID,
+	scope string,
+	method string,
+	req any,
+	res any,
+) error {
+	m.mu.Lock()
+	p, ok := m.plugins[id]
+	stopped := m.stopped
+	m.mu.Unlock()
+
+	if stopped {
+		return errPluginsStopped
+	}
+
+	if !ok {
+		return fmt.Errorf("%w: plugin %v", errNotFound, id)
+	}
+
+	if !slices.Contains(p.info.Scopes, scope) {
+		return fmt.Errorf("%w: plugin %v lacks %v", errPluginScope, id, scope)
+	}
+
+	return p.peer.Call(ctx, method, req, res)
+}
+
+// Notify sends the notification to every connected plugin that declared the scope.
+func (m *PluginManager) Notify(scope string, topic string, body any) {
+	m.mu.Lock()
+	plugins := make([]*connectedPlugin, 0, len(m.plugins))
+	for _, p := range m.plugins {
+		if slices.Contains(p.info.Scopes, scope) {
+			plugins = append(plugins, p)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, p := range plugins {
+		if err := p.peer.Notify(topic, body); err != nil {
+			m.logger.Warn("Failed to notify plugin", "id", p.id, "topic", topic, "err", err)
+		}
+	}
+}
+
+// pluginStatus is a registration, along with its connection if the plugin is connected.
+type pluginStatus struct {
+	pluginRegistration
+	connection *connectedPlugin
+}
+
+// Registrations returns every registered plugin.
+func (m *PluginManager) Registrations(ctx context.Context) ([]pluginStatus, error) {
+	regs, err := db.ReadWithData(ctx, m.db, getPluginRegistrations)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	res := make([]pluginStatus, 0, len(regs))
+
+	for _, reg := range regs {
+		res = append(res, pluginStatus{
+			pluginRegistration: reg,
+			connection:         m.plugins[reg.id],
+		})
+	}
+
+	return res, nil
+}
+
+// Register registers a plugin with the scopes. Plugins registered with a public key authenticate with tokens signed by
+// its private half, otherwise an API key is generated and returned, which cannot be retrieved later.
+func (m *PluginManager) Register(
+	ctx context.Context,
+	name string,
+	scopes []string,
+	publicKey []byte,
+) (pluginStatus, string, error) {
+	if scope, ok := missingScope(plugin.Scopes, scopes); ok {
+		return pluginStatus{}, "", fmt.Errorf("%w: %v", errInvalidScope, scope)
+	}
+
+	reg := pluginRegistration{
+		id:      uuid.New(),
+		name:    name,
+		scopes:  normalizeScopes(scopes),
+		enabled: true,
+		created: time.Now(),
+	}
+
+	var apiKey string
+
+	if publicKey != nil {
+		if len(publicKey) != ed25519.PublicKeySize {
+			return pluginStatus{}, "", errInvalidPluginKey
+		}
+
+		reg.publicKey = publicKey
+	} else {
+		apiKey = randomToken()
+		reg.keyHash = sql.NullString{String: hashToken(apiKey), Valid: true}
+	}
+
+	err := m.db.Write(ctx, func(ctx context.Context, tx db.WTx) error {
+		return insertPluginRegistration(ctx, tx, reg)
+	})
+	if err != nil {
+		return pluginStatus{}, "", err
+	}
+
+	m.logger.Info("Plugin registered", "id", reg.id, "name", name, "scopes", reg.scopes)
+
+	return pluginStatus{pluginRegistration: reg}, apiKey, nil
+}
+
+// pluginUpdate holds the changes to a registration, with nil fields left unchanged.
+type pluginUpdate struct {
+	name    *string
+	scopes  []string
+	enabled *bool
+}
+
+// Update changes a registration. The plugin is disconnected if it is disabled, or loses a scope it declared.
+func (m *PluginManager) Update(ctx context.Context, id uuid.UUID, u pluginUpdate) (pluginStatus, error) {
+	if u.scopes != nil {
+		if scope, ok := missingScope(plugin.Scopes, u.scopes); ok {
+			return pluginStatus{}, fmt.Errorf("%w: %v", errInvalidScope, scope)
+		}
+	}
+
+	reg, err := db.WriteWithData(ctx, m.db, func(ctx context.Context, tx db.WTx) (pluginRegistration, error) {
+		reg, err := getPluginRegistration(ctx, tx, id)
+		if err != nil {
+			return pluginRegistration{}, err
+		}
+
+		if u.name != nil {
+			reg.name = *u.name
+		}
+
+		if u.scopes != nil {
+			reg.scopes = normalizeScopes(u.scopes)
+		}
+
+		if u.enabled != nil {
+			reg.enabled = *u.enabled
+		}
+
+		return reg, updatePluginRegistration(ctx, tx, reg)
+	})
+	if err != nil {
+		return pluginStatus{}, err
+	}
+
+	m.mu.Lock()
+	p := m.plugins[id]
+
+	if p != nil {
+		if _, lost := missingScope(reg.scopes, p.info.Scopes); reg.enabled && !lost {
+			p = nil
+		} else {
+			delete(m.plugins, id)
+		}
+	}
+
+	m.mu.Unlock()
+
+	if p != nil {
+		m.logger.Info("Disconnecting plugin after its registration changed", "id", id)
+
+		_ = p.peer.Close()
+	}
+
+	return m.status(reg), nil
+}
+
+// Revoke deletes a registration, disconnecting the plugin.
+func (m *PluginManager) Revoke(ctx context.Context, id uuid.UUID) error {
+	err := m.db.Write(ctx, func(ctx context.Context, tx db.WTx) error {
+		if _, err := getPluginRegistration(ctx, tx, id); err != nil {
+			return err
+		}
+
+		return deletePluginRegistration(ctx, tx, id)
+	})
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	p := m.plugins[id]
+	delete(m.plugins, id)
+	m.mu.Unlock()
+
+	if p != nil {
+		_ = p.peer.Close()
+	}
+
+	m.logger.Info("Plugin revoked", "id", id)
+
+	return nil
+}
+
+func (m *PluginManager) status(reg pluginRegistration) pluginStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return pluginStatus{
+		pluginRegistration: reg,
+		connection:         m.plugins[reg.id],
+	}
+}
+
+// missingScope returns the first requested scope that has not been granted, if any.
+func missingScope(granted []string, requested []string) (string, bool) {
+	for _, s := range requested {
+		if !slices.Contains(granted, s) {
+			return s, true
+		}
+	}
+
+	return "", false
+}
+
+// normalizeScopes sorts the scopes and removes duplicates.
+func normalizeScopes(scopes []string) []string {
+	res := slices.Clone(scopes)
+	slices.Sort(res)
+
+	return slices.Compact(res)
+}
@@ -0,0 +1,131 @@
+package mediaserver
+
+import (
+	"context"
+	"crypto/ed25519"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/csnewman/cathode/internal/db"
+	"github.com/google/uuid"
+)
+
+// pluginRegistration is a plugin an admin has allowed to connect. Plugins authenticate with either an API key, of
+// which only the hash is kept, or tokens signed by the private half of publicKey.
+type pluginRegistration struct {
+	id        uuid.UUID
+	name      string
+	scopes    []string
+	keyHash   sql.NullString
+	publicKey ed25519.PublicKey
+	enabled   bool
+	created   time.Time
+	// lastSeen is when the plugin last connected, or zero if it never has.
+	lastSeen time.Time
+}
+
+const pluginColumns = `id, name, scopes, key_hash, public_key, enabled, created, last_seen`
+
+func scanPluginRegistration(row interface{ Scan(dest ...any) error }) (pluginRegistration, error) {
+	var (
+		res       pluginRegistration
+		scopes    string
+		publicKey []byte
+		created   string
+		lastSeen  sql.NullString
+	)
+
+	err := row.Scan(&res.id, &res.name, &scopes, &res.keyHash, &publicKey, &res.enabled, &created, &lastSeen)
+	if err != nil {
+		return pluginRegistration{}, err
+	}
+
+	res.scopes = strings.Fields(scopes)
+	res.publicKey = publicKey
+	res.created = parseSQLiteTime(created)
+
+	if lastSeen.Valid {
+		res.lastSeen = parseSQLiteTime(lastSeen.String)
+	}
+
+	return res, nil
+}
+
+func getPluginRegistrations(_ context.Context, tx db.RTx) ([]pluginRegistration, error) {
+	var plugins []pluginRegistration
+
+	rows, err := tx.Query(`SELECT ` + pluginColumns + ` FROM plugins ORDER BY name, created`)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		res, err := scanPluginRegistration(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		plugins = append(plugins, res)
+	}
+
+	return plugins, rows.Err()
+}
+
+func getPluginRegistration(_ context.Context, tx db.RTx, id uuid.UUID) (pluginRegistration, error) {
+	res, err := scanPluginRegistration(tx.QueryRow(`SELECT `+pluginColumns+` FROM plugins WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return pluginRegistration{}, fmt.Errorf("%w: plugin %v", errNotFound, id)
+	}
+
+	return res, err
+}
+
+func getPluginRegistrationByKey(_ context.Context, tx db.RTx, keyHash string) (pluginRegistration, error) {
+	res, err := scanPluginRegistration(tx.QueryRow(`SELECT `+pluginColumns+` FROM plugins WHERE key_hash = $1`, keyHash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return pluginRegistration{}, fmt.Errorf("%w: plugin", errNotFound)
+	}
+
+	return res, err
+}
+
+func insertPluginRegistration(_ context.Context, tx db.WTx, p pluginRegistration) error {
+	return tx.Exec(
+		`INSERT INTO plugins (id, name, scopes, key_hash, public_key, enabled, created)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		p.id,
+		p.name,
+		strings.Join(p.scopes, " "),
+		p.keyHash,
+		[]byte(p.publicKey),
+		p.enabled,
+		p.created.UTC().Format(sqliteTimeLayout),
+	)
+}
+
+func updatePluginRegistration(_ context.Context, tx db.WTx, p pluginRegistration) error {
+	return tx.Exec(
+		`UPDATE plugins SET name = $1, scopes = $2, enabled = $3 WHERE id = $4`,
+		p.name,
+		strings.Join(p.scopes, " "),
+		p.enabled,
+		p.id,
+	)
+}
+
+func touchPluginRegistration(_ context.Context, tx db.WTx, id uuid.UUID, lastSeen time.Time) error {
+	return tx.Exec(
+		`UPDATE plugins SET last_seen = $1 WHERE id = $2`,
+		lastSeen.UTC().Format(sqliteTimeLayout),
+		id,
+	)
+}
+
+func deletePluginRegistration(_ context.Context, tx db.WTx, id uuid.UUID) error {
+	return tx.Exec(`DELETE FROM plugins WHERE id = $1`, id)
+}
@@ -0,0 +1,106 @@
+package mediaserver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/csnewman/cathode/plugin"
+	"github.com/google/uuid"
+)
+
+// registerPluginMethods registers the methods plugins may call, each requiring the scope documented in the plugin
+// package.
+func registerPluginMethods(plugins *PluginManager, library *LibraryManager) {
+	plugins.Handle(plugin.MethodListLibraries, plugin.ScopeLibraryRead, func(
+		ctx context.Context,
+		_ *plugin.Request,
+	) (any, error) {
+		libs, err := library.List(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		res := make([]plugin.Library, 0, len(libs))
+
+		for _, l := range libs {
+			res = append(res, plugin.Library{
+				ID:   l.id.String(),
+				Name: l.name,
+			})
+		}
+
+		return res, nil
+	})
+
+	plugins.Handle(plugin.MethodGetItem, plugin.ScopeLibraryRead, func(
+		ctx context.Context,
+		req *plugin.Request,
+	) (any, error) {
+		id, err := decodeItemRequest(req)
+		if err != nil {
+			return nil, err
+		}
+
+		it, err := library.Item(ctx, id)
+		if errors.Is(err, errNotFound) {
+			return nil, &plugin.Error{Code: plugin.CodeNotFound, Message: "Item not found"}
+		} else if err != nil {
+			return nil, err
+		}
+
+		return toPluginItem(it), nil
+	})
+
+	plugins.Handle(plugin.MethodRefreshItem, plugin.ScopeMetadataWrite, func(
+		ctx context.Context,
+		req *plugin.Request,
+	) (any, error) {
+		id, err := decodeItemRequest(req)
+		if err != nil {
+			return nil, err
+		}
+
+		err = library.RequestRefresh(ctx, id)
+		if errors.Is(err, errNotFound) {
+			return nil, &plugin.Error{Code: plugin.CodeNotFound, Message: "Item not found"}
+		}
+
+		return nil, err
+	})
+}
+
+func decodeItemRequest(req *plugin.Request) (uuid.UUID, error) {
+	var body plugin.ItemRequest
+
+	if err := req.Decode(&body); err != nil {
+		return uuid.UUID{}, err
+	}
+
+	id, err := uuid.Parse(body.ID)
+	if err != nil {
+		return uuid.UUID{}, &plugin.Error{Code: plugin.CodeBadRequest, Message: "The item id must be a uuid"}
+	}
+
+	return id, nil
+}
+
+func toPluginItem(it itemDetails) plugin.Item {
+	res := plugin.Item{
+		ID:        it.id.String(),
+		LibraryID: it.libraryID.String(),
+		Kind:      string(it.kind),
+		Name:      it.name,
+		Overview:  it.overview,
+		Year:      it.year,
+		Season:    it.season,
+		Episode:   it.episode,
+		Genres:    it.genres,
+		IDs:       it.ids,
+	}
+
+	if it.parentID.Valid {
+		res.ParentID = it.parentID.UUID.String()
+	}
+
+	return res
+}
@@ -0,0 +1,339 @@
+package mediaserver
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var errNoGateway = errors.New("default gateway not found")
+
+const (
+	// portMapTimeout bounds requesting the mappings through one method, including searching for UPnP devices.
+	portMapTimeout = 10 * time.Second
+	// portUnmapTimeout bounds removing the mappings on shutdown, which must fit within taskStopTimeout.
+	portUnmapTimeout = 3 * time.Second
+	// portMapRetryMin is the delay before retrying failed mappings, doubling with each failure up to portMapRetryMax.
+	portMapRetryMin = time.Minute
+	portMapRetryMax = 30 * time.Minute
+)
+
+// portMapping is a port forwarded by the router.
+type portMapping struct {
+	// protocol is tcp or udp.
+	protocol     string
+	internalPort int
+	external     netip.AddrPort
+	lease        time.Duration
+}
+
+// portMapClient requests port mappings from the router using a single method.
+type portMapClient interface {
+	method() PortMappingMethod
+	// Map forwards the port to the same external port where possible. Repeating a request renews the mapping.
+	Map(ctx context.Context, protocol string, port int, lease time.Duration) (portMapping, error)
+	Unmap(ctx context.Context, m portMapping) error
+}
+
+// RunPortMapping keeps the port of the advertised TLS listener forwarded on the router until the context is
+// cancelled, when the mappings are removed. The external address is listed by Addresses while mapped.
+func (m *NetworkManager) RunPortMapping(ctx context.Context) {
+	if !m.portMapping.Enabled {
+		return
+	}
+
+	l, ok := m.advertisedListener()
+	if !ok || l.Plain {
+		m.logger.Warn("Not mapping ports, as there is no TLS listener that is not bound to loopback")
+
+		return
+	}
+
+	_, portStr, _ := net.SplitHostPort(l.Address)
+	port, _ := strconv.Atoi(portStr)
+
+	protocols := []string{"tcp"}
+	if l.HTTP3 {
+		protocols = append(protocols, "udp")
+	}
+
+	var (
+		client   portMapClient
+		mappings []portMapping
+	)
+
+	backoff := portMapRetryMin
+
+	for ctx.Err() == nil {
+		var (
+			wait time.Duration
+			err  error
+		)
+
+		client, mappings, err = m.mapPorts(ctx, client, mappings, port, protocols)
+		if ctx.Err() != nil {
+			break
+		}
+
+		if err != nil {
+			m.logger.Error("Port mapping failed", "port", port, "err", err, "retry", backoff)
+			m.discovery.SetMapped(port, netip.AddrPort{})
+
+			wait = backoff
+			backoff = min(backoff*2, portMapRetryMax)
+		} else {
+			m.discovery.SetMapped(port, mappings[0].external)
+
+			wait = m.portMapping.Lease / 2
+			backoff = portMapRetryMin
+
+			// Routers may grant shorter leases than requested
+			for _, pm := range mappings {
+				if pm.lease > 0 {
+					wait = min(wait, pm.lease/2)
+				}
+			}
+		}
+
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+
+	m.discovery.SetMapped(port, netip.AddrPort{})
+
+	if client == nil {
+		return
+	}
+
+	unmapCtx, cancel := context.WithTimeout(context.Background(), portUnmapTimeout)
+	defer cancel()
+
+	for _, pm := range mappings {
+		if err := client.Unmap(unmapCtx, pm); err != nil {
+			m.logger.Warn("Failed to remove port mapping", "protocol", pm.protocol, "port", pm.internalPort, "err", err)
+		}
+	}
+
+	m.logger.Info("Port mappings removed", "port", port)
+}
+
+// mapPorts renews the mappings with the client that made them, falling back to trying each configured method in
+// turn. The client and mappings are nil when every method failed.
+func (m *NetworkManager) mapPorts(
+	ctx context.Context,
+	client portMapClient,
+	prev []portMapping,
+	port int,
+	protocols []string,
+) (portMapClient, []portMapping, error) {
+	var errs []error
+
+	if client != nil {
+		mappings, err := m.mapWith(ctx, client, port, protocols)
+		if err == nil {
+			m.logMappings(client, prev, mappings)
+
+			return client, mappings, nil
+		}
+
+		errs = append(errs, fmt.Errorf("renewing with %v: %w", client.method(), err))
+	}
+
+	for _, method := range m.portMapping.Methods {
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+
+		c, err := newPortMapClient(ctx, method)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%v: %w", method, err))
+
+			continue
+		}
+
+		mappings, err := m.mapWith(ctx, c, port, protocols)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%v: %w", method, err))
+
+			continue
+		}
+
+		m.logMappings(c, prev, mappings)
+
+		return c, mappings, nil
+	}
+
+	return nil, nil, errors.Join(errs...)
+}
+
+func (m *NetworkManager) mapWith(
+	ctx context.Context,
+	client portMapClient,
+	port int,
+	protocols []string,
+) ([]portMapping, error) {
+	ctx, cancel := context.WithTimeout(ctx, portMapTimeout)
+	defer cancel()
+
+	var res []portMapping
+
+	for _, protocol := range protocols {
+		pm, err := client.Map(ctx, protocol, port, m.portMapping.Lease)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", protocol, err)
+		}
+
+		res = append(res, pm)
+	}
+
+	return res, nil
+}
+
+// logMappings logs mappings that are new or changed, so that renewals stay quiet.
+func (m *NetworkManager) logMappings(client portMapClient, prev []portMapping, mappings []portMapping) {
+	for _, pm := range mappings {
+		if slices.ContainsFunc(prev, func(p portMapping) bool {
+			return p.protocol == pm.protocol && p.external == pm.external
+		}) {
+			continue
+		}
+
+		m.logger.Info(
+			"Port mapped",
+			"method", client.method(),
+			"protocol", pm.protocol,
+			"port", pm.internalPort,
+			"external", pm.external,
+			"lease", pm.lease,
+		)
+	}
+}
+
+func newPortMapClient(ctx context.Context, method PortMappingMethod) (portMapClient, error) {
+	switch method {
+	case PortMappingPCP, PortMappingNATPMP:
+		gateway, err := defaultGateway()
+		if err != nil {
+			return nil, err
+		}
+
+		addr := netip.AddrPortFrom(gateway, natPMPPort)
+
+		if method == PortMappingNATPMP {
+			return &natPMPClient{gateway: addr}, nil
+		}
+
+		local, err := localAddrTowards(gateway)
+		if err != nil {
+			return nil, err
+		}
+
+		return newPCPClient(addr, local), nil
+	case PortMappingUPnP:
+		ctx, cancel := context.WithTimeout(ctx, portMapTimeout)
+		defer cancel()
+
+		return newUPnPClient(ctx)
+	}
+
+	return nil, fmt.Errorf("unknown port mapping method %q", method)
+}
+
+// defaultGateway returns the IPv4 default gateway. It is read from the routing table on Linux, and elsewhere assumed
+// to be the first address of the local network, as is usual for home routers.
+func defaultGateway() (netip.Addr, error) {
+	if gateway, err := linuxDefaultGateway(); err == nil {
+		return gateway, nil
+	}
+
+	// No packets are sent, as dialing UDP only selects the route
+	local, err := localAddrTowards(netip.MustParseAddr("192.0.2.1"))
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("%w: %w", errNoGateway, err)
+	}
+
+	ifAddrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("%w: %w", errNoGateway, err)
+	}
+
+	for _, ifAddr := range ifAddrs {
+		prefix, err := netip.ParsePrefix(ifAddr.String())
+		if err != nil || prefix.Addr().Unmap() != local {
+			continue
+		}
+
+		return prefix.Masked().Addr().Next(), nil
+	}
+
+	return netip.Addr{}, errNoGateway
+}
+
+// linuxDefaultGateway reads the default IPv4 route from /proc/net/route, where addresses are little endian hex.
+func linuxDefaultGateway() (netip.Addr, error) {
+	f, err := os.Open("/proc/net/route")
+	if err != nil {
+		return netip.Addr{}, err
+	}
+
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || fields[1] != "00000000" {
+			continue
+		}
+
+		// The route must have the gateway flag
+		flags, err := strconv.ParseUint(fields[3], 16, 16)
+		if err != nil || flags&0x2 == 0 {
+			continue
+		}
+
+		raw, err := hex.DecodeString(fields[2])
+		if err != nil || len(raw) != 4 {
+			continue
+		}
+
+		var ip [4]byte
+		binary.BigEndian.PutUint32(ip[:], binary.LittleEndian.Uint32(raw))
+
+		return netip.AddrFrom4(ip), nil
+	}
+
+	if err := scanner.Err(); err != nil {
+		return netip.Addr{}, err
+	}
+
+	return netip.Addr{}, errNoGateway
+}
+
+// localAddrTowards returns the local address used to reach addr.
+func localAddrTowards(addr netip.Addr) (netip.Addr, error) {
+	conn, err := net.DialUDP("udp", nil, net.UDPAddrFromAddrPort(netip.AddrPortFrom(addr, natPMPPort)))
+	if err != nil {
+		return netip.Addr{}, err
+	}
+
+	defer conn.Close()
+
+	//nolint:forcetypeassert
+	return conn.LocalAddr().(*net.UDPAddr).AddrPort().Addr().Unmap(), nil
+}
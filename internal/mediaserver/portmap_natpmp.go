@@ -0,0 +1,300 @@
+package mediaserver
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"sync"
+	"time"
+)
+
+var errPortMapRejected = errors.New("router rejected port mapping")
+
+const (
+	// natPMPPort is where routers listen for NAT-PMP and PCP requests.
+	natPMPPort = 5351
+	// natPMPRetries is how many times a request is sent, with the wait for a response doubling from natPMPRetryWait.
+	natPMPRetries   = 3
+	natPMPRetryWait = 250 * time.Millisecond
+
+	pcpVersion   = 2
+	pcpOpMap     = 1
+	pcpHeaderLen = 24
+	pcpMapLen    = 36
+)
+
+// natPMPRequest sends a request to the gateway, returning the first response that matches.
+func natPMPRequest(
+	ctx context.Context,
+	gateway netip.AddrPort,
+	req []byte,
+	match func(res []byte) bool,
+) ([]byte, error) {
+	var d net.Dialer
+
+	conn, err := d.DialContext(ctx, "udp4", gateway.String())
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Close()
+
+	buf := make([]byte, 1100)
+	wait := natPMPRetryWait
+
+	for i := 0; i < natPMPRetries; i++ {
+		if _, err := conn.Write(req); err != nil {
+			return nil, err
+		}
+
+		deadline := time.Now().Add(wait)
+		if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+			deadline = ctxDeadline
+		}
+
+		if err := conn.SetReadDeadline(deadline); err != nil {
+			return nil, err
+		}
+
+		for {
+			n, err := conn.Read(buf)
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				break
+			} else if err != nil {
+				return nil, err
+			}
+
+			if match(buf[:n]) {
+				return buf[:n], nil
+			}
+		}
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		wait *= 2
+	}
+
+	return nil, fmt.Errorf("no response from %v", gateway)
+}
+
+func protocolNumber(protocol string) byte {
+	if protocol == "udp" {
+		return 17
+	}
+
+	return 6
+}
+
+// natPMPClient maps ports using NAT-PMP (RFC 6886).
+type natPMPClient struct {
+	// gateway is the address of the router, on natPMPPort.
+	gateway netip.AddrPort
+}
+
+func (c *natPMPClient) method() PortMappingMethod {
+	return PortMappingNATPMP
+}
+
+func (c *natPMPClient) Map(ctx context.Context, protocol string, port int, lease time.Duration) (portMapping, error) {
+	external, err := c.externalAddr(ctx)
+	if err != nil {
+		return portMapping{}, err
+	}
+
+	mapped, granted, err := c.request(ctx, protocol, port, port, lease)
+	if err != nil {
+		return portMapping{}, err
+	}
+
+	return portMapping{
+		protocol:     protocol,
+		internalPort: port,
+		external:     netip.AddrPortFrom(external, mapped),
+		lease:        granted,
+	}, nil
+}
+
+func (c *natPMPClient) Unmap(ctx context.Context, m portMapping) error {
+	// A lifetime of zero deletes the mapping, which requires a suggested external port of zero
+	_, _, err := c.request(ctx, m.protocol, m.internalPort, 0, 0)
+
+	return err
+}
+
+func (c *natPMPClient) externalAddr(ctx context.Context) (netip.Addr, error) {
+	res, err := natPMPRequest(ctx, c.gateway, []byte{0, 0}, func(res []byte) bool {
+		return len(res) >= 12 && res[0] == 0 && res[1] == 128
+	})
+	if err != nil {
+		return netip.Addr{}, err
+	}
+
+	if code := binary.BigEndian.Uint16(res[2:]); code != 0 {
+		return netip.Addr{}, fmt.Errorf("%w: nat-pmp result %d", errPortMapRejected, code)
+	}
+
+	return netip.AddrFrom4([4]byte(res[8:12])), nil
+}
+
+func (c *natPMPClient) request(
+	ctx context.Context,
+	protocol string,
+	port int,
+	external int,
+	lease time.Duration,
+) (uint16, time.Duration, error) {
+	op := byte(2)
+	if protocol == "udp" {
+		op = 1
+	}
+
+	req := make([]byte, 12)
+	req[1] = op
+	binary.BigEndian.PutUint16(req[4:], uint16(port))
+	binary.BigEndian.PutUint16(req[6:], uint16(external))
+	binary.BigEndian.PutUint32(req[8:], uint32(lease/time.Second))
+
+	res, err := natPMPRequest(ctx, c.gateway, req, func(res []byte) bool {
+		return len(res) >= 16 && res[0] == 0 && res[1] == op+128 && binary.BigEndian.Uint16(res[8:]) == uint16(port)
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if code := binary.BigEndian.Uint16(res[2:]); code != 0 {
+		return 0, 0, fmt.Errorf("%w: nat-pmp result %d", errPortMapRejected, code)
+	}
+
+	mapped := binary.BigEndian.Uint16(res[10:])
+	granted := time.Duration(binary.BigEndian.Uint32(res[12:])) * time.Second
+
+	return mapped, granted, nil
+}
+
+// pcpClient maps ports using PCP (RFC 6887). Each mapping is identified by a nonce, which must be repeated to renew or
+// delete it.
+type pcpClient struct {
+	// gateway is the address of the router, on natPMPPort.
+	gateway netip.AddrPort
+	local   netip.Addr
+
+	mu     sync.Mutex
+	nonces map[string][12]byte
+}
+
+func newPCPClient(gateway netip.AddrPort, local netip.Addr) *pcpClient {
+	return &pcpClient{
+		gateway: gateway,
+		local:   local,
+		nonces:  make(map[string][12]byte),
+	}
+}
+
+func (c *pcpClient) method() PortMappingMethod {
+	return PortMappingPCP
+}
+
+func (c *pcpClient) Map(ctx context.Context, protocol string, port int, lease time.Duration) (portMapping, error) {
+	external, granted, err := c.request(ctx, protocol, port, port, lease)
+	if err != nil {
+		return portMapping{}, err
+	}
+
+	return portMapping{
+		protocol:     protocol,
+		internalPort: port,
+		external:     external,
+		lease:        granted,
+	}, nil
+}
+
+func (c *pcpClient) Unmap(ctx context.Context, m portMapping) error {
+	_, _, err := c.request(ctx, m.protocol, m.internalPort, 0, 0)
+
+	return err
+}
+
+func (c *pcpClient) nonce(protocol string, port int) ([12]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := fmt.Sprintf("%v/%d", protocol, port)
+
+	nonce, ok := c.nonces[key]
+	if !ok {
+		if _, err := rand.Read(nonce[:]); err != nil {
+			return nonce, err
+		}
+
+		c.nonces[key] = nonce
+	}
+
+	return nonce, nil
+}
+
+func (c *pcpClient) request(
+	ctx context.Context,
+	protocol string,
+	port int,
+	external int,
+	lease time.Duration,
+) (netip.AddrPort, time.Duration, error) {
+	nonce, err := c.nonce(protocol, port)
+	if err != nil {
+		return netip.AddrPort{}, 0, err
+	}
+
+	req := make([]byte, pcpHeaderLen+pcpMapLen)
+	req[0] = pcpVersion
+	req[1] = pcpOpMap
+	binary.BigEndian.PutUint32(req[4:], uint32(lease/time.Second))
+
+	// Addresses are sent in their 16 byte form, which is the IPv4-mapped form for IPv4
+	local := c.local.As16()
+	copy(req[8:24], local[:])
+
+	op := req[pcpHeaderLen:]
+	copy(op[0:12], nonce[:])
+	op[12] = protocolNumber(protocol)
+	binary.BigEndian.PutUint16(op[16:], uint16(port))
+	binary.BigEndian.PutUint16(op[18:], uint16(external))
+
+	// No external address is suggested, which for IPv4 is given as 0.0.0.0
+	suggested := netip.IPv4Unspecified().As16()
+	copy(op[20:36], suggested[:])
+
+	res, err := natPMPRequest(ctx, c.gateway, req, func(res []byte) bool {
+		// Routers only speaking NAT-PMP answer with an unsupported version error in its format
+		if len(res) >= 4 && res[0] == 0 {
+			return true
+		}
+
+		return len(res) >= pcpHeaderLen+pcpMapLen && res[1] == 0x80|pcpOpMap &&
+			string(res[pcpHeaderLen:pcpHeaderLen+12]) == string(nonce[:])
+	})
+	if err != nil {
+		return netip.AddrPort{}, 0, err
+	}
+
+	if res[0] != pcpVersion {
+		return netip.AddrPort{}, 0, fmt.Errorf("%w: pcp is not supported", errPortMapRejected)
+	}
+
+	if code := res[3]; code != 0 {
+		return netip.AddrPort{}, 0, fmt.Errorf("%w: pcp result %d", errPortMapRejected, code)
+	}
+
+	granted := time.Duration(binary.BigEndian.Uint32(res[4:])) * time.Second
+
+	op = res[pcpHeaderLen:]
+	addr := netip.AddrFrom16([16]byte(op[20:36])).Unmap()
+
+	return netip.AddrPortFrom(addr, binary.BigEndian.Uint16(op[18:])), granted, nil
+}
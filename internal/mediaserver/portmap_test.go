@@ -0,0 +1,615 @@
+package mediaserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/huin/goupnp"
+	"github.com/huin/goupnp/dcps/internetgateway2"
+	"github.com/huin/goupnp/soap"
+)
+
+var (
+	testExternalAddr = netip.MustParseAddr("203.0.113.7")
+	testLocalAddr    = netip.MustParseAddr("192.168.1.20")
+)
+
+// fakeGateway answers NAT-PMP and PCP requests on a loopback port, recording the requests it receives.
+type fakeGateway struct {
+	conn    *net.UDPConn
+	respond func(req []byte) [][]byte
+
+	mu       sync.Mutex
+	requests [][]byte
+}
+
+func newFakeGateway(t *testing.T, respond func(req []byte) [][]byte) *fakeGateway {
+	t.Helper()
+
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := &fakeGateway{
+		conn:    conn,
+		respond: respond,
+	}
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	go func() {
+		buf := make([]byte, 1100)
+
+		for {
+			n, addr, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				return
+			}
+
+			req := bytes.Clone(buf[:n])
+
+			g.mu.Lock()
+			g.requests = append(g.requests, req)
+			g.mu.Unlock()
+
+			for _, res := range g.respond(req) {
+				_, _ = conn.WriteToUDP(res, addr)
+			}
+		}
+	}()
+
+	return g
+}
+
+func (g *fakeGateway) addr() netip.AddrPort {
+	return g.conn.LocalAddr().(*net.UDPAddr).AddrPort()
+}
+
+func (g *fakeGateway) received() [][]byte {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return slices.Clone(g.requests)
+}
+
+// natPMPResponder answers like a NAT-PMP router, mapping internal ports to external ports offset by 10000 and granting
+// at most an hour. Mapping requests for a port in reject are refused with the given result code.
+func natPMPResponder(reject map[uint16]uint16) func(req []byte) [][]byte {
+	return func(req []byte) [][]byte {
+		res := make([]byte, 16)
+		res[1] = req[1] + 128
+		binary.BigEndian.PutUint32(res[4:], 1234)
+
+		if req[1] == 0 {
+			copy(res[8:12], testExternalAddr.AsSlice())
+
+			return [][]byte{res[:12]}
+		}
+
+		internal := binary.BigEndian.Uint16(req[4:])
+		lifetime := min(binary.BigEndian.Uint32(req[8:]), 3600)
+
+		binary.BigEndian.PutUint16(res[2:], reject[internal])
+		binary.BigEndian.PutUint16(res[8:], internal)
+		binary.BigEndian.PutUint32(res[12:], lifetime)
+
+		if lifetime > 0 {
+			binary.BigEndian.PutUint16(res[10:], internal+10000)
+		}
+
+		// A response for another port comes first, which must be ignored
+		other := bytes.Clone(res)
+		binary.BigEndian.PutUint16(other[8:], internal+1)
+		binary.BigEndian.PutUint16(other[10:], 1)
+
+		return [][]byte{other, res}
+	}
+}
+
+func TestNATPMPMap(t *testing.T) {
+	gw := newFakeGateway(t, natPMPResponder(nil))
+	c := &natPMPClient{gateway: gw.addr()}
+
+	ctx := context.Background()
+
+	tcp, err := c.Map(ctx, "tcp", 8443, 2*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := portMapping{
+		protocol:     "tcp",
+		internalPort: 8443,
+		external:     netip.AddrPortFrom(testExternalAddr, 18443),
+		lease:        time.Hour,
+	}
+
+	if tcp != want {
+		t.Errorf("Map() = %+v, want %+v", tcp, want)
+	}
+
+	udp, err := c.Map(ctx, "udp", 8443, 2*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if udp.protocol != "udp" || udp.external.Port() != 18443 {
+		t.Errorf("Map() = %+v, want udp mapped to 18443", udp)
+	}
+
+	if err := c.Unmap(ctx, tcp); err != nil {
+		t.Fatal(err)
+	}
+
+	reqs := gw.received()
+	if len(reqs) != 5 {
+		t.Fatalf("got %d requests, want 5", len(reqs))
+	}
+
+	tests := []struct {
+		name     string
+		req      []byte
+		op       byte
+		external uint16
+		lifetime uint32
+	}{
+		{name: "map tcp", req: reqs[1], op: 2, external: 8443, lifetime: 7200},
+		{name: "map udp", req: reqs[3], op: 1, external: 8443, lifetime: 7200},
+		{name: "unmap", req: reqs[4], op: 2, external: 0, lifetime: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if len(tt.req) != 12 || tt.req[0] != 0 || tt.req[1] != tt.op {
+				t.Fatalf("request = %x, want version 0 op %d", tt.req, tt.op)
+			}
+
+			if port := binary.BigEndian.Uint16(tt.req[4:]); port != 8443 {
+				t.Errorf("internal port = %d, want 8443", port)
+			}
+
+			if port := binary.BigEndian.Uint16(tt.req[6:]); port != tt.external {
+				t.Errorf("suggested external port = %d, want %d", port, tt.external)
+			}
+
+			if lifetime := binary.BigEndian.Uint32(tt.req[8:]); lifetime != tt.lifetime {
+				t.Errorf("lifetime = %d, want %d", lifetime, tt.lifetime)
+			}
+		})
+	}
+}
+
+func TestNATPMPRejected(t *testing.T) {
+	// Result code 2 is not authorized
+	gw := newFakeGateway(t, natPMPResponder(map[uint16]uint16{8443: 2}))
+	c := &natPMPClient{gateway: gw.addr()}
+
+	_, err := c.Map(context.Background(), "tcp", 8443, time.Hour)
+	if !errors.Is(err, errPortMapRejected) {
+		t.Errorf("Map() error = %v, want %v", err, errPortMapRejected)
+	}
+}
+
+// pcpResponder answers like a PCP router, mapping internal ports to external ports offset by 10000 with the given
+// result code.
+func pcpResponder(code byte) func(req []byte) [][]byte {
+	return func(req []byte) [][]byte {
+		res := bytes.Clone(req)
+		res[1] = 0x80 | req[1]
+		res[3] = code
+		binary.BigEndian.PutUint32(res[4:], min(binary.BigEndian.Uint32(req[4:]), 3600))
+		clear(res[8:pcpHeaderLen])
+
+		op := res[pcpHeaderLen:]
+		binary.BigEndian.PutUint16(op[18:], binary.BigEndian.Uint16(op[16:])+10000)
+
+		external := netip.AddrFrom4(testExternalAddr.As4()).As16()
+		copy(op[20:36], external[:])
+
+		return [][]byte{res}
+	}
+}
+
+func TestPCPMap(t *testing.T) {
+	gw := newFakeGateway(t, pcpResponder(0))
+	c := newPCPClient(gw.addr(), testLocalAddr)
+
+	ctx := context.Background()
+
+	m, err := c.Map(ctx, "udp", 8443, 2*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := portMapping{
+		protocol:     "udp",
+		internalPort: 8443,
+		external:     netip.AddrPortFrom(testExternalAddr, 18443),
+		lease:        time.Hour,
+	}
+
+	if m != want {
+		t.Errorf("Map() = %+v, want %+v", m, want)
+	}
+
+	// Renewing and deleting must repeat the nonce of the mapping
+	if _, err := c.Map(ctx, "udp", 8443, 2*time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Unmap(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+
+	reqs := gw.received()
+	if len(reqs) != 3 {
+		t.Fatalf("got %d requests, want 3", len(reqs))
+	}
+
+	req := reqs[0]
+
+	if len(req) != pcpHeaderLen+pcpMapLen || req[0] != pcpVersion || req[1] != pcpOpMap {
+		t.Fatalf("request = %x, want a version %d map request", req, pcpVersion)
+	}
+
+	if lifetime := binary.BigEndian.Uint32(req[4:]); lifetime != 7200 {
+		t.Errorf("lifetime = %d, want 7200", lifetime)
+	}
+
+	if client := netip.AddrFrom16([16]byte(req[8:24])); client.Unmap() != testLocalAddr || !client.Is4In6() {
+		t.Errorf("client address = %v, want %v in its mapped form", client, testLocalAddr)
+	}
+
+	op := req[pcpHeaderLen:]
+
+	if op[12] != 17 {
+		t.Errorf("protocol = %d, want 17", op[12])
+	}
+
+	if port := binary.BigEndian.Uint16(op[16:]); port != 8443 {
+		t.Errorf("internal port = %d, want 8443", port)
+	}
+
+	if port := binary.BigEndian.Uint16(op[18:]); port != 8443 {
+		t.Errorf("suggested external port = %d, want 8443", port)
+	}
+
+	if suggested := netip.AddrFrom16([16]byte(op[20:36])); suggested != netip.AddrFrom16(netip.IPv4Unspecified().As16()) {
+		t.Errorf("suggested external address = %v, want ::ffff:0.0.0.0", suggested)
+	}
+
+	unmap := reqs[2]
+
+	if lifetime := binary.BigEndian.Uint32(unmap[4:]); lifetime != 0 {
+		t.Errorf("unmap lifetime = %d, want 0", lifetime)
+	}
+
+	if port := binary.BigEndian.Uint16(unmap[pcpHeaderLen+18:]); port != 0 {
+		t.Errorf("unmap suggested port = %d, want 0", port)
+	}
+
+	for i, r := range reqs[1:] {
+		if !bytes.Equal(r[pcpHeaderLen:pcpHeaderLen+12], op[:12]) {
+			t.Errorf("request %d nonce = %x, want %x", i+1, r[pcpHeaderLen:pcpHeaderLen+12], op[:12])
+		}
+	}
+}
+
+func TestPCPRejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		respond func(req []byte) [][]byte
+	}{
+		{
+			// Result code 8 is no resources
+			name:    "result code",
+			respond: pcpResponder(8),
+		},
+		{
+			name: "nat-pmp only",
+			respond: func(req []byte) [][]byte {
+				// Routers only speaking NAT-PMP answer with an unsupported version result
+				return [][]byte{{0, 0x80 | req[1], 0, 1, 0, 0, 0, 0}}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gw := newFakeGateway(t, tt.respond)
+			c := newPCPClient(gw.addr(), testLocalAddr)
+
+			_, err := c.Map(context.Background(), "tcp", 8443, time.Hour)
+			if !errors.Is(err, errPortMapRejected) {
+				t.Errorf("Map() error = %v, want %v", err, errPortMapRejected)
+			}
+		})
+	}
+}
+
+// fakeIGD answers the port mapping actions of a UPnP Internet Gateway Device connection service.
+type fakeIGD struct {
+	// permanentOnly refuses lease durations, as IGDv1 devices may.
+	permanentOnly bool
+	// fault is returned for every AddPortMapping action when set.
+	fault int
+
+	mu      sync.Mutex
+	actions []upnpAction
+}
+
+type upnpAction struct {
+	name string
+	args map[string]string
+}
+
+func (g *fakeIGD) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	_, name, _ := strings.Cut(strings.Trim(r.Header.Get("SOAPACTION"), `"`), "#")
+
+	args, err := decodeUPnPArgs(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	g.mu.Lock()
+	g.actions = append(g.actions, upnpAction{name: name, args: args})
+	g.mu.Unlock()
+
+	var body string
+
+	switch {
+	case name == "AddPortMapping" && g.fault != 0:
+		writeUPnPFault(w, g.fault)
+
+		return
+	case name == "AddPortMapping" && g.permanentOnly && args["NewLeaseDuration"] != "0":
+		writeUPnPFault(w, upnpOnlyPermanentLeases)
+
+		return
+	case name == "GetExternalIPAddress":
+		body = "<NewExternalIPAddress>" + testExternalAddr.String() + "</NewExternalIPAddress>"
+	}
+
+	w.Header().Set("Content-Type", `text/xml; charset="utf-8"`)
+	fmt.Fprintf(
+		w,
+		`<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" `+
+			`s:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/"><s:Body>`+
+			`<u:%sResponse xmlns:u="%s">%s</u:%[1]sResponse></s:Body></s:Envelope>`,
+		name, internetgateway2.URN_WANIPConnection_1, body,
+	)
+}
+
+func (g *fakeIGD) received() []upnpAction {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return slices.Clone(g.actions)
+}
+
+func writeUPnPFault(w http.ResponseWriter, code int) {
+	w.Header().Set("Content-Type", `text/xml; charset="utf-8"`)
+	w.WriteHeader(http.StatusInternalServerError)
+	fmt.Fprintf(
+		w,
+		`<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" `+
+			`s:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/"><s:Body><s:Fault>`+
+			`<faultcode>s:Client</faultcode><faultstring>UPnPError</faultstring><detail>`+
+			`<UPnPError xmlns="urn:schemas-upnp-org:control-1-0"><errorCode>%d</errorCode>`+
+			`<errorDescription>Error</errorDescription></UPnPError></detail></s:Fault></s:Body></s:Envelope>`,
+		code,
+	)
+}
+
+// decodeUPnPArgs returns the arguments of a SOAP action, which are the elements within the action element.
+func decodeUPnPArgs(r io.Reader) (map[string]string, error) {
+	args := make(map[string]string)
+	dec := xml.NewDecoder(r)
+
+	var (
+		depth int
+		name  string
+	)
+
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return args, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			depth++
+
+			// Arguments sit within the envelope, body and action elements
+			if depth == 4 {
+				name = tok.Name.Local
+				args[name] = ""
+			}
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			if depth == 4 {
+				args[name] += string(tok)
+			}
+		}
+	}
+}
+
+// fakeUPnPConnection is a connection service client whose device was discovered from testLocalAddr.
+type fakeUPnPConnection struct {
+	*internetgateway2.WANIPConnection1
+}
+
+func (fakeUPnPConnection) LocalAddr() net.IP {
+	return testLocalAddr.AsSlice()
+}
+
+func newFakeUPnPClient(t *testing.T, igd *fakeIGD) *upnpClient {
+	t.Helper()
+
+	srv := httptest.NewServer(igd)
+	t.Cleanup(srv.Close)
+
+	u, err := url.Parse(srv.URL + "/ctl/IPConn")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &upnpClient{
+		conn: fakeUPnPConnection{
+			WANIPConnection1: &internetgateway2.WANIPConnection1{
+				ServiceClient: goupnp.ServiceClient{
+					SOAPClient: soap.NewSOAPClient(*u),
+				},
+			},
+		},
+	}
+}
+
+func TestUPnPMap(t *testing.T) {
+	igd := &fakeIGD{}
+	c := newFakeUPnPClient(t, igd)
+
+	ctx := context.Background()
+
+	m, err := c.Map(ctx, "tcp", 8443, 2*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := portMapping{
+		protocol:     "tcp",
+		internalPort: 8443,
+		external:     netip.AddrPortFrom(testExternalAddr, 8443),
+		lease:        2 * time.Hour,
+	}
+
+	if m != want {
+		t.Errorf("Map() = %+v, want %+v", m, want)
+	}
+
+	if err := c.Unmap(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+
+	actions := igd.received()
+	if len(actions) != 3 {
+		t.Fatalf("got %d actions, want 3", len(actions))
+	}
+
+	tests := []struct {
+		action upnpAction
+		want   upnpAction
+	}{
+		{
+			action: actions[0],
+			want: upnpAction{
+				name: "AddPortMapping",
+				args: map[string]string{
+					"NewRemoteHost":             "",
+					"NewExternalPort":           "8443",
+					"NewProtocol":               "TCP",
+					"NewInternalPort":           "8443",
+					"NewInternalClient":         testLocalAddr.String(),
+					"NewEnabled":                "1",
+					"NewPortMappingDescription": "Cathode Media Server",
+					"NewLeaseDuration":          "7200",
+				},
+			},
+		},
+		{
+			action: actions[1],
+			want: upnpAction{
+				name: "GetExternalIPAddress",
+				args: map[string]string{},
+			},
+		},
+		{
+			action: actions[2],
+			want: upnpAction{
+				name: "DeletePortMapping",
+				args: map[string]string{
+					"NewRemoteHost":   "",
+					"NewExternalPort": "8443",
+					"NewProtocol":     "TCP",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want.name, func(t *testing.T) {
+			if tt.action.name != tt.want.name {
+				t.Fatalf("action = %v, want %v", tt.action.name, tt.want.name)
+			}
+
+			if fmt.Sprint(tt.action.args) != fmt.Sprint(tt.want.args) {
+				t.Errorf("args = %v, want %v", tt.action.args, tt.want.args)
+			}
+		})
+	}
+}
+
+func TestUPnPPermanentLeases(t *testing.T) {
+	igd := &fakeIGD{permanentOnly: true}
+	c := newFakeUPnPClient(t, igd)
+
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		m, err := c.Map(ctx, "udp", 8443, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if m.lease != time.Hour {
+			t.Errorf("lease = %v, want the requested lease for renewal", m.lease)
+		}
+	}
+
+	var leases []string
+
+	for _, a := range igd.received() {
+		if a.name == "AddPortMapping" {
+			leases = append(leases, a.args["NewLeaseDuration"])
+		}
+	}
+
+	// Once refused, later mappings go straight to a permanent lease
+	if got := strings.Join(leases, ","); got != "3600,0,0" {
+		t.Errorf("lease durations = %v, want 3600,0,0", got)
+	}
+}
+
+func TestUPnPRejected(t *testing.T) {
+	// Fault 718 is a conflicting mapping
+	c := newFakeUPnPClient(t, &fakeIGD{fault: 718})
+
+	_, err := c.Map(context.Background(), "tcp", 8443, time.Hour)
+	if !errors.Is(err, errPortMapRejected) {
+		t.Errorf("Map() error = %v, want %v", err, errPortMapRejected)
+	}
+}
@@ -0,0 +1,178 @@
+package mediaserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/huin/goupnp/dcps/internetgateway2"
+	"github.com/huin/goupnp/soap"
+)
+
+var errNoGatewayDevice = errors.New("no upnp gateway device found")
+
+// upnpOnlyPermanentLeases is the error IGDv1 routers return when given a lease duration.
+const upnpOnlyPermanentLeases = 725
+
+// upnpConnection is implemented by the clients of the IGD connection services, which share their port mapping
+// actions.
+type upnpConnection interface {
+	AddPortMappingCtx(
+		ctx context.Context,
+		NewRemoteHost string,
+		NewExternalPort uint16,
+		NewProtocol string,
+		NewInternalPort uint16,
+		NewInternalClient string,
+		NewEnabled bool,
+		NewPortMappingDescription string,
+		NewLeaseDuration uint32,
+	) error
+	DeletePortMappingCtx(ctx context.Context, NewRemoteHost string, NewExternalPort uint16, NewProtocol string) error
+	GetExternalIPAddressCtx(ctx context.Context) (string, error)
+	LocalAddr() net.IP
+}
+
+// upnpClient maps ports through a UPnP Internet Gateway Device.
+type upnpClient struct {
+	conn upnpConnection
+
+	mu sync.Mutex
+	// permanent is set once the device has refused a lease duration, after which mappings are requested without one.
+	permanent bool
+}
+
+// newUPnPClient searches the network for a gateway device, preferring the newest connection service it offers.
+func newUPnPClient(ctx context.Context) (*upnpClient, error) {
+	searches := []func(context.Context) ([]upnpConnection, error){
+		upnpSearch(internetgateway2.NewWANIPConnection2ClientsCtx),
+		upnpSearch(internetgateway2.NewWANIPConnection1ClientsCtx),
+		upnpSearch(internetgateway2.NewWANPPPConnection1ClientsCtx),
+	}
+
+	found := make([][]upnpConnection, len(searches))
+	errs := make([]error, len(searches))
+
+	var wg sync.WaitGroup
+
+	// Each search takes a couple of seconds, so they are made together
+	for i, search := range searches {
+		wg.Add(1)
+
+		go func(i int, search func(context.Context) ([]upnpConnection, error)) {
+			defer wg.Done()
+
+			found[i], errs[i] = search(ctx)
+		}(i, search)
+	}
+
+	wg.Wait()
+
+	for _, conns := range found {
+		if len(conns) > 0 {
+			return &upnpClient{conn: conns[0]}, nil
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("%w: %w", errNoGatewayDevice, err)
+	}
+
+	return nil, errNoGatewayDevice
+}
+
+func upnpSearch[T upnpConnection](
+	search func(context.Context) ([]T, []error, error),
+) func(context.Context) ([]upnpConnection, error) {
+	return func(ctx context.Context) ([]upnpConnection, error) {
+		clients, errs, err := search(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		res := make([]upnpConnection, 0, len(clients))
+		for _, c := range clients {
+			res = append(res, c)
+		}
+
+		return res, errors.Join(errs...)
+	}
+}
+
+func (c *upnpClient) method() PortMappingMethod {
+	return PortMappingUPnP
+}
+
+func (c *upnpClient) Map(ctx context.Context, protocol string, port int, lease time.Duration) (portMapping, error) {
+	local, ok := netip.AddrFromSlice(c.conn.LocalAddr())
+	if !ok {
+		return portMapping{}, fmt.Errorf("%w: local address unknown", errNoGatewayDevice)
+	}
+
+	c.mu.Lock()
+	permanent := c.permanent
+	c.mu.Unlock()
+
+	add := func(lease time.Duration) error {
+		return c.conn.AddPortMappingCtx(
+			ctx,
+			"",
+			uint16(port),
+			strings.ToUpper(protocol),
+			uint16(port),
+			local.Unmap().String(),
+			true,
+			"Cathode Media Server",
+			uint32(lease/time.Second),
+		)
+	}
+
+	var err error
+
+	if permanent {
+		err = add(0)
+	} else if err = add(lease); isUPnPError(err, upnpOnlyPermanentLeases) {
+		c.mu.Lock()
+		c.permanent = true
+		c.mu.Unlock()
+
+		err = add(0)
+	}
+
+	if err != nil {
+		return portMapping{}, fmt.Errorf("%w: %w", errPortMapRejected, err)
+	}
+
+	externalStr, err := c.conn.GetExternalIPAddressCtx(ctx)
+	if err != nil {
+		return portMapping{}, fmt.Errorf("failed to get external address: %w", err)
+	}
+
+	external, err := netip.ParseAddr(externalStr)
+	if err != nil {
+		return portMapping{}, fmt.Errorf("invalid external address %q: %w", externalStr, err)
+	}
+
+	// Permanent mappings are still renewed at the requested lease, in case the router was restarted
+	return portMapping{
+		protocol:     protocol,
+		internalPort: port,
+		external:     netip.AddrPortFrom(external.Unmap(), uint16(port)),
+		lease:        lease,
+	}, nil
+}
+
+func (c *upnpClient) Unmap(ctx context.Context, m portMapping) error {
+	return c.conn.DeletePortMappingCtx(ctx, "", m.external.Port(), strings.ToUpper(m.protocol))
+}
+
+func isUPnPError(err error, code int) bool {
+	var fault *soap.SOAPFaultError
+
+	return errors.As(err, &fault) && fault.Detail.UPnPError.Errorcode == code
+}
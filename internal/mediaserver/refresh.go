@@ -0,0 +1,347 @@
+package mediaserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/csnewman/cathode/internal/db"
+	"github.com/csnewman/cathode/mediaserver"
+	"github.com/google/uuid"
+)
+
+const (
+	// refreshOngoingInterval applies to shows that may still gain episodes.
+	refreshOngoingInterval = 24 * time.Hour
+	// refreshEndedInterval applies to movies and finished shows, whose metadata rarely changes.
+	refreshEndedInterval = 30 * 24 * time.Hour
+
+	refreshRetryBase  = 15 * time.Minute
+	refreshBatchSize  = 20
+	refreshMaxIdleGap = time.Hour
+)
+
+// refreshInterval returns how long metadata for an item stays fresh.
+func refreshInterval(kind itemKind, status string) time.Duration {
+	if kind == itemKindShow && status != string(mediaserver.ShowStatusEnded) {
+		return refreshOngoingInterval
+	}
+
+	return refreshEndedInterval
+}
+
+// refreshRetryDelay backs off exponentially between failed attempts, capped at the ongoing interval.
+func refreshRetryDelay(attempts int) time.Duration {
+	delay := refreshRetryBase
+
+	for i := 0; i < attempts && delay < refreshOngoingInterval; i++ {
+		delay *= 2
+	}
+
+	return min(delay, refreshOngoingInterval)
+}
+
+// RunRefresh refreshes stale metadata as jobs become due, until the context is cancelled. Job state is stored in the
+// database, so schedules survive restarts.
+func (l *LibraryManager) RunRefresh(ctx context.Context) {
+	for {
+		if err := l.refreshDue(ctx); err != nil {
+			l.logger.Error("Metadata refresh failed", "err", err)
+		}
+
+		timer := time.NewTimer(l.nextRefreshWait(ctx))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return
+		case <-l.refreshWake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// RequestRefresh forces a refresh of an item. Episodes and seasons are refreshed as part of their show.
+func (l *LibraryManager) RequestRefresh(ctx context.Context, id uuid.UUID) error {
+	err := l.db.Write(ctx, func(ctx context.Context, tx db.WTx) error {
+		it, err := getItem(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		for it.parentID.Valid {
+			if it, err = getItem(ctx, tx, it.parentID.UUID); err != nil {
+				return err
+			}
+		}
+
+		return setMetadataJobDue(ctx, tx, it.id, time.Now())
+	})
+	if err != nil {
+		return err
+	}
+
+	select {
+	case l.refreshWake <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+func (l *LibraryManager) refreshDue(ctx context.Context) error {
+	for {
+		ids, err := db.ReadWithData(ctx, l.db, func(ctx context.Context, tx db.RTx) ([]uuid.UUID, error) {
+			return getDueMetadataJobs(ctx, tx, time.Now(), refreshBatchSize)
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(ids) == 0 {
+			return nil
+		}
+
+		for _, id := range ids {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			l.runRefreshJob(ctx, id)
+		}
+	}
+}
+
+func (l *LibraryManager) runRefreshJob(ctx context.Context, id uuid.UUID) {
+	interval, err := l.refreshItem(ctx, id)
+	if err == nil {
+		err = l.db.Write(ctx, func(ctx context.Context, tx db.WTx) error {
+			return completeMetadataJob(ctx, tx, id, time.Now().Add(interval))
+		})
+		if err != nil {
+			l.logger.Error("Failed to complete metadata job", "item", id, "err", err)
+		}
+
+		return
+	}
+
+	l.logger.Warn("Failed to refresh item metadata", "item", id, "err", err)
+
+	reason := err.Error()
+
+	err = l.db.Write(ctx, func(ctx context.Context, tx db.WTx) error {
+		attempts, err := getMetadataJobAttempts(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		return failMetadataJob(ctx, tx, id, time.Now().Add(refreshRetryDelay(attempts)), reason)
+	})
+	if err != nil {
+		l.logger.Error("Failed to record metadata job failure", "item", id, "err", err)
+	}
+}
+
+// refreshItem fetches fresh metadata for a movie or show, including every episode of a show. It returns how long until
+// the item should next be refreshed.
+func (l *LibraryManager) refreshItem(ctx context.Context, id uuid.UUID) (time.Duration, error) {
+	var (
+		it  item
+		lib library
+		ids map[string]string
+	)
+
+	err := l.db.Read(ctx, func(ctx context.Context, tx db.RTx) error {
+		var err error
+
+		if it, err = getItem(ctx, tx, id); err != nil {
+			return err
+		}
+
+		if lib, err = getLibrary(ctx, tx, it.libraryID); err != nil {
+			return err
+		}
+
+		ids, err = getItemIDs(ctx, tx, id)
+
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var t mediaserver.MediaType
+
+	switch it.kind {
+	case itemKindMovie:
+		t = mediaserver.MediaTypeMovie
+	case itemKindShow:
+		t = mediaserver.MediaTypeTv
+	default:
+		return 0, fmt.Errorf("%w: cannot refresh %v items", errInvalidState, it.kind)
+	}
+
+	s := l.newScan(lib)
+
+	details, err := s.index.Details(t, ids)
+	if errors.Is(err, mediaserver.ErrNotFound) {
+		// Nothing knows about this item any more, keep what we have
+		return refreshInterval(it.kind, it.status), nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	applyDetails(&it, details)
+
+	var episodes []refreshedEpisode
+
+	if it.kind == itemKindShow {
+		if episodes, err = s.refreshEpisodes(ctx, it.id, details.IDs); err != nil {
+			return 0, err
+		}
+	}
+
+	err = l.db.Write(ctx, func(ctx context.Context, tx db.WTx) error {
+		if err := s.writeItem(ctx, tx, it, details.IDs, details.Genres, details.Cast); err != nil {
+			return err
+		}
+
+		for _, ep := range episodes {
+			if err := s.writeItem(ctx, tx, ep.item, ep.ids, nil, nil); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if l.images != nil {
+		l.images.Prefetch(s.pendingImages...)
+	}
+
+	updated := []event{newItemEvent(eventItemUpdated, it)}
+	for _, ep := range episodes {
+		updated = append(updated, newItemEvent(eventItemUpdated, ep.item))
+	}
+
+	l.events.Publish(updated...)
+
+	l.logger.Debug("Refreshed item metadata", "item", it.id, "name", it.name, "episodes", len(episodes))
+
+	return refreshInterval(it.kind, it.status), nil
+}
+
+type refreshedEpisode struct {
+	item item
+	ids  mediaserver.IDs
+}
+
+// refreshEpisodes fetches fresh metadata for every episode of a show, returning the updated episodes.
+func (s *libraryScan) refreshEpisodes(
+	ctx context.Context,
+	showID uuid.UUID,
+	showIDs mediaserver.IDs,
+) ([]refreshedEpisode, error) {
+	var entries []refreshedEpisode
+
+	err := s.manager.db.Read(ctx, func(ctx context.Context, tx db.RTx) error {
+		seasons, err := getChildItems(ctx, tx, showID)
+		if err != nil {
+			return err
+		}
+
+		for _, season := range seasons {
+			episodes, err := getChildItems(ctx, tx, season.id)
+			if err != nil {
+				return err
+			}
+
+			for _, ep := range episodes {
+				ids, err := getItemIDs(ctx, tx, ep.id)
+				if err != nil {
+					return err
+				}
+
+				entries = append(entries, refreshedEpisode{item: ep, ids: ids})
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var updated []refreshedEpisode
+
+	for _, e := range entries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		details, err := s.index.Episode(mediaserver.EpisodeRef{
+			Show:    showIDs,
+			Episode: e.ids,
+			Season:  e.item.season,
+			Number:  e.item.episode,
+		})
+		if errors.Is(err, mediaserver.ErrNotFound) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		ep := e.item
+
+		if details.Name != "" {
+			ep.name = details.Name
+		}
+
+		ep.overview = details.Overview
+		ep.releaseDate = details.AirDate
+		ep.posterURL = details.StillURL
+
+		for k, v := range e.ids {
+			if _, ok := details.IDs[k]; !ok {
+				details.IDs[k] = v
+			}
+		}
+
+		updated = append(updated, refreshedEpisode{item: ep, ids: details.IDs})
+	}
+
+	return updated, nil
+}
+
+// nextRefreshWait returns how long to sleep until the next job is due.
+func (l *LibraryManager) nextRefreshWait(ctx context.Context) time.Duration {
+	var (
+		next  time.Time
+		found bool
+	)
+
+	err := l.db.Read(ctx, func(ctx context.Context, tx db.RTx) error {
+		var err error
+
+		next, found, err = getNextMetadataJobTime(ctx, tx)
+
+		return err
+	})
+	if err != nil {
+		l.logger.Error("Failed to query next metadata job", "err", err)
+
+		return refreshMaxIdleGap
+	}
+
+	if !found {
+		return refreshMaxIdleGap
+	}
+
+	return min(max(time.Until(next), time.Second), refreshMaxIdleGap)
+}
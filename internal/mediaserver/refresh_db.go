@@ -0,0 +1,99 @@
+package mediaserver
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/csnewman/cathode/internal/db"
+	"github.com/google/uuid"
+)
+
+// ensureMetadataJob schedules a refresh of an item, unless one is already scheduled.
+func ensureMetadataJob(_ context.Context, tx db.WTx, itemID uuid.UUID, next time.Time) error {
+	return tx.Exec(
+		`INSERT INTO metadata_jobs (item_id, next_run) VALUES ($1, $2) ON CONFLICT (item_id) DO NOTHING`,
+		itemID,
+		next.UTC().Format(sqliteTimeLayout),
+	)
+}
+
+// setMetadataJobDue schedules a refresh of an item at the given time, replacing any existing schedule.
+func setMetadataJobDue(_ context.Context, tx db.WTx, itemID uuid.UUID, next time.Time) error {
+	return tx.Exec(
+		`INSERT INTO metadata_jobs (item_id, next_run) VALUES ($1, $2)
+		ON CONFLICT (item_id) DO UPDATE SET next_run = excluded.next_run`,
+		itemID,
+		next.UTC().Format(sqliteTimeLayout),
+	)
+}
+
+func getDueMetadataJobs(_ context.Context, tx db.RTx, now time.Time, limit int) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+
+	rows, err := tx.Query(
+		`SELECT item_id FROM metadata_jobs WHERE next_run <= $1 ORDER BY next_run LIMIT $2`,
+		now.UTC().Format(sqliteTimeLayout),
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// getNextMetadataJobTime returns when the next job is due, or false if no jobs exist.
+func getNextMetadataJobTime(_ context.Context, tx db.RTx) (time.Time, bool, error) {
+	var next string
+
+	err := tx.QueryRow(`SELECT next_run FROM metadata_jobs ORDER BY next_run LIMIT 1`).Scan(&next)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, false, nil
+	} else if err != nil {
+		return time.Time{}, false, err
+	}
+
+	return parseSQLiteTime(next), true, nil
+}
+
+func getMetadataJobAttempts(_ context.Context, tx db.RTx, itemID uuid.UUID) (int, error) {
+	var attempts int
+
+	err := tx.QueryRow(`SELECT attempts FROM metadata_jobs WHERE item_id = $1`, itemID).Scan(&attempts)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+
+	return attempts, err
+}
+
+func completeMetadataJob(_ context.Context, tx db.WTx, itemID uuid.UUID, next time.Time) error {
+	return tx.Exec(
+		`UPDATE metadata_jobs SET next_run = $1, last_run = datetime(), attempts = 0, last_error = '' WHERE item_id = $2`,
+		next.UTC().Format(sqliteTimeLayout),
+		itemID,
+	)
+}
+
+func failMetadataJob(_ context.Context, tx db.WTx, itemID uuid.UUID, next time.Time, reason string) error {
+	return tx.Exec(
+		`UPDATE metadata_jobs SET next_run = $1, last_run = datetime(), attempts = attempts + 1, last_error = $2
+		WHERE item_id = $3`,
+		next.UTC().Format(sqliteTimeLayout),
+		reason,
+		itemID,
+	)
+}
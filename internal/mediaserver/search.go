@@ -0,0 +1,177 @@
+package mediaserver
+
+import (
+	"context"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/csnewman/cathode/internal/db"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultSearchLimit = 30
+	maxSearchLimit     = 100
+	// searchMaxCorrections caps how many alternative spellings are tried for each word.
+	searchMaxCorrections = 5
+)
+
+type searchResults struct {
+	items []catalogItem
+	// fuzzy is set when nothing matched the query as typed, and the results are for corrected spellings.
+	fuzzy bool
+}
+
+// Search finds items whose titles, original titles, overviews or cast match the query. Every word must match, with the
+// last letters of each word treated as a prefix. When nothing matches, words are replaced by similarly spelt terms
+// from the index.
+func (l *LibraryManager) Search(
+	ctx context.Context,
+	query string,
+	libraryID uuid.NullUUID,
+	limit int,
+) (searchResults, error) {
+	words := searchWords(query)
+	if len(words) == 0 {
+		return searchResults{}, nil
+	}
+
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+
+	limit = min(limit, maxSearchLimit)
+
+	return db.ReadWithData(ctx, l.db, func(ctx context.Context, tx db.RTx) (searchResults, error) {
+		clauses := make([]string, len(words))
+
+		for i, w := range words {
+			clauses[i] = ftsPrefix(w)
+		}
+
+		items, err := searchItems(ctx, tx, strings.Join(clauses, " "), libraryID, limit)
+		if err != nil || len(items) > 0 {
+			return searchResults{items: items}, err
+		}
+
+		corrected := false
+
+		for i, w := range words {
+			alts, err := searchCorrections(ctx, tx, w)
+			if err != nil {
+				return searchResults{}, err
+			}
+
+			if len(alts) == 0 {
+				continue
+			}
+
+			corrected = true
+
+			for _, alt := range alts {
+				clauses[i] += " OR " + ftsTerm(alt)
+			}
+
+			clauses[i] = "(" + clauses[i] + ")"
+		}
+
+		if !corrected {
+			return searchResults{}, nil
+		}
+
+		items, err = searchItems(ctx, tx, strings.Join(clauses, " "), libraryID, limit)
+
+		return searchResults{items: items, fuzzy: true}, err
+	})
+}
+
+// searchCorrections returns indexed terms within a small edit distance of a word. Only terms sharing the first letter
+// are considered, which keeps the candidate set small.
+func searchCorrections(ctx context.Context, tx db.RTx, word string) ([]string, error) {
+	n := utf8.RuneCountInString(word)
+
+	var maxDist int
+
+	switch {
+	case n <= 3:
+		return nil, nil
+	case n <= 6:
+		maxDist = 1
+	default:
+		maxDist = 2
+	}
+
+	first, _ := utf8.DecodeRuneInString(word)
+
+	terms, err := getSearchTerms(ctx, tx, string(first), n-maxDist, n+maxDist)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []string
+
+	for _, t := range terms {
+		if t != word && editDistance(word, t) <= maxDist {
+			res = append(res, t)
+
+			if len(res) == searchMaxCorrections {
+				break
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// searchWords splits a query into lower case words, dropping punctuation.
+func searchWords(query string) []string {
+	return strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// ftsTerm quotes a word for use in an FTS5 query. Words only contain letters and numbers, so need no escaping.
+func ftsTerm(word string) string {
+	return `"` + word + `"`
+}
+
+func ftsPrefix(word string) string {
+	return ftsTerm(word) + "*"
+}
+
+// editDistance returns the optimal string alignment distance between two strings. This is the Levenshtein distance,
+// extended to count swapping two adjacent letters as a single edit, as that is a common typo.
+func editDistance(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	// Rows for the previous two prefixes of a are kept, the older one being needed for transpositions
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+
+		prev2, prev, cur = prev, cur, prev2
+	}
+
+	return prev[len(rb)]
+}
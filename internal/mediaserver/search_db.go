@@ -0,0 +1,56 @@
+package mediaserver
+
+import (
+	"context"
+	"strings"
+
+	"github.com/csnewman/cathode/internal/db"
+	"github.com/google/uuid"
+)
+
+// searchItems returns available items matching an FTS5 query, best matches first. Titles are weighted above original
+// titles, cast and overviews.
+func searchItems(
+	_ context.Context,
+	tx db.RTx,
+	match string,
+	libraryID uuid.NullUUID,
+	limit int,
+) ([]catalogItem, error) {
+	var (
+		args sqlArgs
+		sb   strings.Builder
+	)
+
+	sb.WriteString(`
+		SELECT ` + itemColumns + `, ` + catalogColumns + `, added FROM (
+			SELECT rowid AS search_rowid, bm25(item_search, 10.0, 5.0, 1.0, 2.0) AS search_rank
+			FROM item_search WHERE item_search MATCH ` + args.add(match) + `
+		) m
+		JOIN items i ON i.search_rowid = m.search_rowid
+		WHERE (
+			(i.kind = 'show' AND ` + showAvailableExpr + `)
+			OR (i.kind <> 'show' AND i.missing_since IS NULL)
+		)`)
+
+	if libraryID.Valid {
+		sb.WriteString(` AND i.library_id = ` + args.add(libraryID.UUID))
+	}
+
+	sb.WriteString(` ORDER BY m.search_rank LIMIT ` + args.add(limit))
+
+	return queryCatalogItems(tx, sb.String(), args...)
+}
+
+// getSearchTerms returns indexed terms that start with prefix and have a length within the given range.
+func getSearchTerms(_ context.Context, tx db.RTx, prefix string, minLen int, maxLen int) ([]string, error) {
+	// Terms are ordered, so every term starting with prefix sorts between it and the prefix with a maximal rune appended
+	return queryStrings(
+		tx,
+		`SELECT term FROM item_search_vocab WHERE term >= $1 AND term < $2 AND length(term) BETWEEN $3 AND $4`,
+		prefix,
+		prefix+"\U0010FFFF",
+		minLen,
+		maxLen,
+	)
+}
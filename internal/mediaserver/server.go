@@ -2,33 +2,84 @@ package mediaserver
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"time"
 
 	"github.com/csnewman/cathode/internal/db"
+	"github.com/csnewman/cathode/mediaserver"
 )
 
+const (
+	// drainTimeout bounds how long shutdown waits for in-flight requests to finish.
+	drainTimeout = 15 * time.Second
+	// taskStopTimeout bounds how long shutdown then waits for background tasks to stop.
+	taskStopTimeout = 5 * time.Second
+)
+
+var errListenersStopped = errors.New("all listeners stopped")
+
 type Server struct {
-	logger  *slog.Logger
-	db      *db.DB
-	network *NetworkManager
+	logger   *slog.Logger
+	db       *db.DB
+	network  *NetworkManager
+	library  *LibraryManager
+	images   *ImageCache
+	auth     *AuthManager
+	watch    *WatchManager
+	playback *PlaybackManager
+	plugins  *PluginManager
+	events   *EventBus
 }
 
-func New(logger *slog.Logger) (*Server, error) {
-	db, err := db.Open("data/db.sqlite3")
+func New(logger *slog.Logger, cfg Config) (*Server, error) {
+	if err := os.MkdirAll(cfg.DataDir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create data dir: %w", err)
+	}
+
+	db, err := db.Open(filepath.Join(cfg.DataDir, "db.sqlite3"))
 	if err != nil {
 		return nil, err
 	}
 
-	nm, err := NewNetworkManager(logger, db)
+	images, err := NewImageCache(logger, db, filepath.Join(cfg.DataDir, "images"))
+	if err != nil {
+		return nil, err
+	}
+
+	events := NewEventBus(logger)
+
+	library := NewLibraryManager(logger, db, mediaserver.NewMoveDB(), images, events)
+
+	auth := NewAuthManager(logger, db)
+
+	watch := NewWatchManager(logger, db, cfg.WatchedThreshold)
+
+	playback := NewPlaybackManager(logger, db, filepath.Join(cfg.DataDir, "transcode"), events)
+
+	plugins := NewPluginManager(logger, db, cfg.Discovery.Name, events)
+	registerPluginMethods(plugins, library)
+
+	nm, err := NewNetworkManager(logger, db, cfg, library, images, auth, watch, playback, plugins, events)
 	if err != nil {
 		return nil, err
 	}
 
 	return &Server{
-		logger:  logger,
-		db:      db,
-		network: nm,
+		logger:   logger,
+		db:       db,
+		network:  nm,
+		library:  library,
+		images:   images,
+		auth:     auth,
+		watch:    watch,
+		playback: playback,
+		plugins:  plugins,
+		events:   events,
 	}, nil
 }
 
@@ -37,9 +88,97 @@ func (s *Server) Run(ctx context.Context) error {
 		return fmt.Errorf("failed to migrate db: %w", err)
 	}
 
-	s.network.Refresh(ctx)
+	if err := s.auth.Bootstrap(ctx); err != nil {
+		return fmt.Errorf("failed to create initial user: %w", err)
+	}
+
+	if err := s.network.Refresh(ctx); err != nil {
+		return fmt.Errorf("failed to load certificates: %w", err)
+	}
+
+	// Background tasks outlive the root context, so that in-flight requests can still use them while draining
+	taskCtx, cancelTasks := context.WithCancel(context.Background())
+	defer cancelTasks()
+
+	tasks := newTaskTracker()
+
+	tasks.Go("images", func() { s.images.Run(taskCtx) })
+	tasks.Go("library", func() { s.library.Run(taskCtx) })
+	tasks.Go("library-refresh", func() { s.library.RunRefresh(taskCtx) })
+	tasks.Go("library-watch", func() { s.library.Watch(taskCtx) })
+	tasks.Go("playback", func() { s.playback.Run(taskCtx) })
+	tasks.Go("certificates", func() { s.network.RunRenewal(taskCtx) })
+	tasks.Go("discovery", func() { s.network.RunDiscovery(taskCtx) })
+	tasks.Go("mdns", func() { s.network.RunAdvertise(taskCtx) })
+	tasks.Go("port-mapping", func() { s.network.RunPortMapping(taskCtx) })
+
+	if err := s.library.RequestScanAll(ctx); err != nil {
+		s.logger.Error("Failed to queue library scans", "err", err)
+	}
+
+	// Plugins are disconnected as shutdown begins, while the listeners can still deliver the close to them
+	tasks.Go("plugins", func() { s.plugins.Run(ctx) })
+
+	// Event streams would otherwise hold their requests open until the drain times out
+	tasks.Go("events", func() { s.events.Run(ctx) })
 
-	s.network.Run(ctx)
+	networkDone := make(chan struct{})
+
+	tasks.Go("network", func() {
+		defer close(networkDone)
+
+		s.network.Run(ctx)
+	})
+
+	var err error
+
+	select {
+	case <-ctx.Done():
+	case <-networkDone:
+		err = errListenersStopped
+	}
+
+	s.logger.Info("Shutting down", "timeout", drainTimeout+taskStopTimeout)
+
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancelDrain()
+
+	s.network.Shutdown(drainCtx)
+
+	cancelTasks()
+
+	stopCtx, cancelStop := context.WithTimeout(context.Background(), taskStopTimeout)
+	defer cancelStop()
+
+	if running := tasks.Wait(stopCtx); len(running) > 0 {
+		s.logger.Warn("Shutdown deadline reached with tasks still running", "tasks", running)
+	}
+
+	if closeErr := s.db.Close(); closeErr != nil {
+		s.logger.Error("Failed to close db", "err", closeErr)
+	}
+
+	return err
+}
+
+// serverVersion returns the module version the server was built from, which is "dev" for local builds.
+func serverVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+
+	return "dev"
+}
+
+// serverName returns the configured name of the server, or the host name if there is none.
+func serverName(configured string) string {
+	if configured != "" {
+		return configured
+	}
+
+	if host, err := os.Hostname(); err == nil && host != "" {
+		return host
+	}
 
-	return nil
+	return "Cathode"
 }
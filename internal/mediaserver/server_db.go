@@ -0,0 +1,23 @@
+package mediaserver
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/csnewman/cathode/internal/db"
+	"github.com/google/uuid"
+)
+
+// getServerID returns the ID generated for the server when its database was created.
+func getServerID(_ context.Context, tx db.RTx) (uuid.UUID, error) {
+	var id uuid.UUID
+
+	err := tx.QueryRow(`SELECT id FROM server`).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return uuid.UUID{}, fmt.Errorf("%w: server id", errNotFound)
+	}
+
+	return id, err
+}
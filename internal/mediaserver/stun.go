@@ -0,0 +1,158 @@
+package mediaserver
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"time"
+)
+
+var errInvalidSTUNResponse = errors.New("invalid stun response")
+
+const (
+	stunBindingRequest  = 0x0001
+	stunBindingResponse = 0x0101
+	stunMagicCookie     = 0x2112a442
+	stunHeaderLen       = 20
+
+	stunAttrMappedAddress    = 0x0001
+	stunAttrXORMappedAddress = 0x0020
+
+	// stunTimeout bounds a single probe, including resolving the server.
+	stunTimeout = 3 * time.Second
+)
+
+// stunProbe asks a STUN server for the address requests from this host appear to come from, using a binding request
+// (RFC 5389). The network is udp4 or udp6, so that each address family can be probed.
+func stunProbe(ctx context.Context, network string, server string) (netip.Addr, error) {
+	ctx, cancel := context.WithTimeout(ctx, stunTimeout)
+	defer cancel()
+
+	var d net.Dialer
+
+	conn, err := d.DialContext(ctx, network, server)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return netip.Addr{}, err
+		}
+	}
+
+	req := make([]byte, stunHeaderLen)
+	binary.BigEndian.PutUint16(req[0:], stunBindingRequest)
+	binary.BigEndian.PutUint32(req[4:], stunMagicCookie)
+
+	if _, err := rand.Read(req[8:stunHeaderLen]); err != nil {
+		return netip.Addr{}, err
+	}
+
+	if _, err := conn.Write(req); err != nil {
+		return netip.Addr{}, err
+	}
+
+	buf := make([]byte, 1500)
+
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return netip.Addr{}, err
+		}
+
+		res := buf[:n]
+
+		// Packets that do not answer the request are skipped
+		if len(res) < stunHeaderLen || string(res[8:stunHeaderLen]) != string(req[8:stunHeaderLen]) {
+			continue
+		}
+
+		return parseSTUNResponse(res)
+	}
+}
+
+func parseSTUNResponse(res []byte) (netip.Addr, error) {
+	if binary.BigEndian.Uint16(res[0:]) != stunBindingResponse {
+		return netip.Addr{}, fmt.Errorf("%w: unexpected type %#04x", errInvalidSTUNResponse, binary.BigEndian.Uint16(res))
+	}
+
+	length := int(binary.BigEndian.Uint16(res[2:]))
+	if stunHeaderLen+length > len(res) {
+		return netip.Addr{}, fmt.Errorf("%w: truncated", errInvalidSTUNResponse)
+	}
+
+	var mapped netip.Addr
+
+	attrs := res[stunHeaderLen : stunHeaderLen+length]
+
+	for len(attrs) >= 4 {
+		typ := binary.BigEndian.Uint16(attrs[0:])
+		alen := int(binary.BigEndian.Uint16(attrs[2:]))
+
+		if 4+alen > len(attrs) {
+			return netip.Addr{}, fmt.Errorf("%w: truncated attribute", errInvalidSTUNResponse)
+		}
+
+		value := attrs[4 : 4+alen]
+
+		switch typ {
+		case stunAttrXORMappedAddress:
+			// The XOR mapping is preferred, as some NATs rewrite addresses found in packets
+			return parseSTUNAddress(value, res[4:stunHeaderLen])
+		case stunAttrMappedAddress:
+			mapped, _ = parseSTUNAddress(value, nil)
+		}
+
+		// Attributes are padded to a multiple of four bytes
+		attrs = attrs[min(len(attrs), 4+(alen+3)&^3):]
+	}
+
+	if !mapped.IsValid() {
+		return netip.Addr{}, fmt.Errorf("%w: no mapped address", errInvalidSTUNResponse)
+	}
+
+	return mapped, nil
+}
+
+// parseSTUNAddress parses the value of an address attribute. The address is XORed with key, the magic cookie and
+// transaction ID, when given.
+func parseSTUNAddress(value []byte, key []byte) (netip.Addr, error) {
+	if len(value) < 4 {
+		return netip.Addr{}, fmt.Errorf("%w: short address", errInvalidSTUNResponse)
+	}
+
+	var size int
+
+	switch value[1] {
+	case 0x01:
+		size = net.IPv4len
+	case 0x02:
+		size = net.IPv6len
+	default:
+		return netip.Addr{}, fmt.Errorf("%w: unknown address family %#02x", errInvalidSTUNResponse, value[1])
+	}
+
+	if len(value) < 4+size {
+		return netip.Addr{}, fmt.Errorf("%w: short address", errInvalidSTUNResponse)
+	}
+
+	ip := make([]byte, size)
+	copy(ip, value[4:4+size])
+
+	if key != nil {
+		for i := range ip {
+			ip[i] ^= key[i]
+		}
+	}
+
+	addr, _ := netip.AddrFromSlice(ip)
+
+	return addr, nil
+}
@@ -0,0 +1,79 @@
+package mediaserver
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// taskTracker runs named background goroutines, so that shutdown can wait for them and report any that overrun.
+type taskTracker struct {
+	mu      sync.Mutex
+	running map[string]int
+	done    chan struct{}
+}
+
+func newTaskTracker() *taskTracker {
+	return &taskTracker{
+		running: make(map[string]int),
+		done:    make(chan struct{}, 1),
+	}
+}
+
+// Go runs fn in a new goroutine, tracked under name until it returns.
+func (t *taskTracker) Go(name string, fn func()) {
+	t.mu.Lock()
+	t.running[name]++
+	t.mu.Unlock()
+
+	go func() {
+		defer t.finish(name)
+
+		fn()
+	}()
+}
+
+func (t *taskTracker) finish(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.running[name]--
+	if t.running[name] == 0 {
+		delete(t.running, name)
+	}
+
+	select {
+	case t.done <- struct{}{}:
+	default:
+	}
+}
+
+// Wait blocks until every task has returned or the context is done, returning the names of the tasks still running.
+func (t *taskTracker) Wait(ctx context.Context) []string {
+	for {
+		if running := t.Running(); len(running) == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return t.Running()
+		case <-t.done:
+		}
+	}
+}
+
+// Running returns the names of the running tasks, sorted.
+func (t *taskTracker) Running() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	res := make([]string, 0, len(t.running))
+	for name := range t.running {
+		res = append(res, name)
+	}
+
+	slices.Sort(res)
+
+	return res
+}
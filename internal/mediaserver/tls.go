@@ -0,0 +1,129 @@
+package mediaserver
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	selfSignedCertFile = "self-signed.crt"
+	selfSignedKeyFile  = "self-signed.key"
+	// selfSignedLifetime is the longest validity Apple platforms accept for a server certificate.
+	selfSignedLifetime = 825 * 24 * time.Hour
+	// selfSignedRenewal is how long before expiry a new self-signed certificate is generated.
+	selfSignedRenewal = 30 * 24 * time.Hour
+)
+
+func loadCertificateFiles(cfg TLSConfig) (*tls.Certificate, error) {
+	cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load certificate: %w", err)
+	}
+
+	return &cert, nil
+}
+
+// selfSignedCertificate loads the self-signed certificate from dir, generating a new one if there is none or it is
+// about to expire. The certificate is kept between restarts, so that clients only need to trust it once.
+func selfSignedCertificate(dir string) (*tls.Certificate, error) {
+	certPath := filepath.Join(dir, selfSignedCertFile)
+	keyPath := filepath.Join(dir, selfSignedKeyFile)
+
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err == nil {
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		if err == nil && time.Until(leaf.NotAfter) > selfSignedRenewal {
+			return &cert, nil
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to load self-signed certificate: %w", err)
+	}
+
+	certPEM, keyPEM, err := generateSelfSigned()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.WriteFile(certPath, certPEM, 0o644); err != nil { //nolint:gosec
+		return nil, fmt.Errorf("failed to write self-signed certificate: %w", err)
+	}
+
+	if err := os.WriteFile(keyPath, keyPEM, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write self-signed key: %w", err)
+	}
+
+	cert, err = tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse self-signed certificate: %w", err)
+	}
+
+	return &cert, nil
+}
+
+// generateSelfSigned creates a certificate for the host name and every local address, returning the PEM encoded
+// certificate and key.
+func generateSelfSigned() ([]byte, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate serial: %w", err)
+	}
+
+	now := time.Now()
+
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: "Cathode Media Server"},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(selfSignedLifetime),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     []string{"localhost"},
+	}
+
+	if host, err := os.Hostname(); err == nil && host != "localhost" {
+		tmpl.DNSNames = append(tmpl.DNSNames, host, host+".local")
+	}
+
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list addresses: %w", err)
+	}
+
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ipNet.IP)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create certificate: %w", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode key: %w", err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	return certPEM, keyPEM, nil
+}
@@ -0,0 +1,394 @@
+package mediaserver
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/csnewman/cathode/internal/db"
+	"github.com/google/uuid"
+)
+
+var errNotPlayable = errors.New("item is not playable")
+
+const (
+	// defaultWatchedThreshold is the fraction of an item that must be played for it to count as watched, leaving
+	// credits unwatched.
+	defaultWatchedThreshold = 0.9
+	// minResumePosition is how far into an item playback must get before a resume point is kept.
+	minResumePosition = 30 * time.Second
+	// historyGap is how long progress reports can stop for before playing the item again counts as a new viewing.
+	historyGap = 30 * time.Minute
+
+	defaultFeedSize = 20
+	maxFeedSize     = 100
+)
+
+// WatchManager tracks each user's progress through items, using progress reports from players.
+type WatchManager struct {
+	logger           *slog.Logger
+	db               *db.DB
+	watchedThreshold float64
+}
+
+func NewWatchManager(logger *slog.Logger, db *db.DB, watchedThreshold float64) *WatchManager {
+	return &WatchManager{
+		logger:           logger,
+		db:               db,
+		watchedThreshold: watchedThreshold,
+	}
+}
+
+// progressReport is a player's position within an item. Positions are reported while playing, as well as on pausing,
+// seeking and stopping.
+type progressReport struct {
+	position      time.Duration
+	duration      time.Duration
+	paused        bool
+	audioTrack    sql.NullInt64
+	subtitleTrack sql.NullInt64
+}
+
+// itemWatch is a user's progress through an item. For shows and seasons it summarises their episodes.
+type itemWatch struct {
+	watchState
+	kind itemKind
+	// unwatched is the number of available episodes not yet watched, for shows and seasons.
+	unwatched int
+}
+
+// ReportProgress records a progress report for a movie or episode, updating the resume point, watched flag and
+// history.
+func (m *WatchManager) ReportProgress(
+	ctx context.Context,
+	p principal,
+	itemID uuid.UUID,
+	r progressReport,
+) (watchState, error) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	return db.WriteWithData(ctx, m.db, func(ctx context.Context, tx db.WTx) (watchState, error) {
+		it, err := getItem(ctx, tx, itemID)
+		if err != nil {
+			return watchState{}, err
+		}
+
+		if it.kind != itemKindMovie && it.kind != itemKindEpisode {
+			return watchState{}, fmt.Errorf("%w: %v is a %v", errNotPlayable, itemID, it.kind)
+		}
+
+		state, err := getWatchState(ctx, tx, p.user.id, itemID)
+		if errors.Is(err, errNotFound) {
+			state = watchState{itemID: itemID}
+		} else if err != nil {
+			return watchState{}, err
+		}
+
+		h, err := getLatestHistory(ctx, tx, p.user.id, itemID)
+		if errors.Is(err, errNotFound) || (err == nil && now.Sub(h.updated) > historyGap) {
+			h = historyEntry{
+				id:      uuid.New(),
+				userID:  p.user.id,
+				itemID:  itemID,
+				started: now,
+				updated: now,
+			}
+		} else if err != nil {
+			return watchState{}, err
+		}
+
+		// Time is only counted as watched while playing
+		if !h.paused {
+			h.watched += now.Sub(h.updated)
+		}
+
+		h.updated = now
+		h.position = r.position
+		h.paused = r.paused
+
+		if p.device.Valid {
+			h.deviceID = p.device
+		}
+
+		completed := r.duration > 0 && float64(r.position) >= float64(r.duration)*m.watchedThreshold
+
+		// Each viewing only counts once, however many reports follow the threshold
+		if completed && !h.completed {
+			h.completed = true
+			state.watched = true
+			state.playCount++
+
+			m.logger.Info("Item watched", "user", p.user.username, "item", it.name)
+		}
+
+		state.position = r.position
+		if completed || r.position < minResumePosition {
+			state.position = 0
+		}
+
+		state.duration = r.duration
+		state.audioTrack = r.audioTrack
+		state.subtitleTrack = r.subtitleTrack
+		state.lastPlayed = now
+
+		if err := upsertWatchState(ctx, tx, p.user.id, state); err != nil {
+			return watchState{}, err
+		}
+
+		if err := upsertHistory(ctx, tx, h); err != nil {
+			return watchState{}, err
+		}
+
+		return state, nil
+	})
+}
+
+// SetWatched marks an item as watched or not, returning its new state. Shows and seasons mark all of their episodes.
+func (m *WatchManager) SetWatched(
+	ctx context.Context,
+	userID uuid.UUID,
+	itemID uuid.UUID,
+	watched bool,
+) (itemWatch, error) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	return db.WriteWithData(ctx, m.db, func(ctx context.Context, tx db.WTx) (itemWatch, error) {
+		it, err := getItem(ctx, tx, itemID)
+		if err != nil {
+			return itemWatch{}, err
+		}
+
+		if watched {
+			err = markWatched(ctx, tx, userID, it, now)
+		} else {
+			err = markUnwatched(ctx, tx, userID, it)
+		}
+
+		if err != nil {
+			return itemWatch{}, err
+		}
+
+		states, err := getItemWatches(ctx, tx, userID, map[uuid.UUID]itemKind{it.id: it.kind})
+		if err != nil {
+			return itemWatch{}, err
+		}
+
+		return states[it.id], nil
+	})
+}
+
+// States returns a user's progress through each of the given items.
+func (m *WatchManager) States(
+	ctx context.Context,
+	userID uuid.UUID,
+	items map[uuid.UUID]itemKind,
+) (map[uuid.UUID]itemWatch, error) {
+	return db.ReadWithData(ctx, m.db, func(ctx context.Context, tx db.RTx) (map[uuid.UUID]itemWatch, error) {
+		return getItemWatches(ctx, tx, userID, items)
+	})
+}
+
+func getItemWatches(
+	ctx context.Context,
+	tx db.RTx,
+	userID uuid.UUID,
+	items map[uuid.UUID]itemKind,
+) (map[uuid.UUID]itemWatch, error) {
+	var playable, seasons, shows []uuid.UUID
+
+	for id, kind := range items {
+		switch kind {
+		case itemKindShow:
+			shows = append(shows, id)
+		case itemKindSeason:
+			seasons = append(seasons, id)
+		default:
+			playable = append(playable, id)
+		}
+	}
+
+	res := make(map[uuid.UUID]itemWatch, len(items))
+
+	states, err := getWatchStates(ctx, tx, userID, playable)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range playable {
+		res[id] = itemWatch{watchState: states[id], kind: items[id]}
+	}
+
+	for kind, ids := range map[itemKind][]uuid.UUID{itemKindShow: shows, itemKindSeason: seasons} {
+		counts, err := getUnwatchedCounts(ctx, tx, userID, kind, ids)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, id := range ids {
+			res[id] = itemWatch{
+				watchState: watchState{watched: counts[id] == 0},
+				kind:       kind,
+				unwatched:  counts[id],
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// historyPage is a page of a user's history, with a cursor for the following page if there is one.
+type historyPage struct {
+	entries []historyEntry
+	items   map[uuid.UUID]catalogItem
+	next    string
+}
+
+// History returns a page of a user's viewings, most recent first, along with the items viewed.
+func (m *WatchManager) History(ctx context.Context, userID uuid.UUID, cursor string, limit int) (historyPage, error) {
+	var after *itemCursor
+
+	if cursor != "" {
+		c, err := decodeCursor(cursor)
+		if err != nil {
+			return historyPage{}, err
+		}
+
+		after = c
+	}
+
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+
+	limit = min(limit, maxPageSize)
+
+	return db.ReadWithData(ctx, m.db, func(ctx context.Context, tx db.RTx) (historyPage, error) {
+		entries, more, err := getHistory(ctx, tx, userID, after, limit)
+		if err != nil {
+			return historyPage{}, err
+		}
+
+		ids := make([]uuid.UUID, 0, len(entries))
+		for _, h := range entries {
+			ids = append(ids, h.itemID)
+		}
+
+		items, err := getCatalogItems(ctx, tx, ids)
+		if err != nil {
+			return historyPage{}, err
+		}
+
+		page := historyPage{
+			entries: entries,
+			items:   make(map[uuid.UUID]catalogItem, len(items)),
+		}
+
+		for _, it := range items {
+			page.items[it.id] = it
+		}
+
+		if more {
+			last := entries[len(entries)-1]
+
+			page.next = encodeCursor(&itemCursor{
+				Str: last.started.UTC().Format(sqliteTimeLayout),
+				ID:  last.id,
+			})
+		}
+
+		return page, nil
+	})
+}
+
+// ContinueWatching returns the movies and episodes a user has a resume point in, most recently played first.
+func (m *WatchManager) ContinueWatching(
+	ctx context.Context,
+	userID uuid.UUID,
+	libraryID uuid.NullUUID,
+	limit int,
+) ([]catalogItem, error) {
+	limit = feedSize(limit)
+
+	return db.ReadWithData(ctx, m.db, func(ctx context.Context, tx db.RTx) ([]catalogItem, error) {
+		ids, err := getResumableItems(ctx, tx, userID, libraryID, limit)
+		if err != nil {
+			return nil, err
+		}
+
+		return getCatalogItems(ctx, tx, ids)
+	})
+}
+
+// NextUp returns the episode following the furthest watched episode of each show a user is watching, ordered by when
+// the show was last watched. Shows whose next episode has a resume point are left out, as they appear in
+// ContinueWatching instead.
+func (m *WatchManager) NextUp(
+	ctx context.Context,
+	userID uuid.UUID,
+	libraryID uuid.NullUUID,
+	limit int,
+) ([]catalogItem, error) {
+	limit = feedSize(limit)
+
+	return db.ReadWithData(ctx, m.db, func(ctx context.Context, tx db.RTx) ([]catalogItem, error) {
+		shows, err := getRecentShows(ctx, tx, userID, libraryID)
+		if err != nil {
+			return nil, err
+		}
+
+		var ids []uuid.UUID
+
+		for _, showID := range shows {
+			if len(ids) == limit {
+				break
+			}
+
+			episodes, err := getShowProgress(ctx, tx, userID, showID)
+			if err != nil {
+				return nil, err
+			}
+
+			if next, ok := nextEpisode(episodes); ok {
+				ids = append(ids, next)
+			}
+		}
+
+		return getCatalogItems(ctx, tx, ids)
+	})
+}
+
+// nextEpisode returns the first available, unwatched episode after the furthest watched one.
+func nextEpisode(episodes []episodeProgress) (uuid.UUID, bool) {
+	last := -1
+
+	for i, ep := range episodes {
+		if ep.watched {
+			last = i
+		}
+	}
+
+	for _, ep := range episodes[last+1:] {
+		if ep.missing {
+			continue
+		}
+
+		if ep.resumable {
+			return uuid.UUID{}, false
+		}
+
+		return ep.id, true
+	}
+
+	return uuid.UUID{}, false
+}
+
+func feedSize(limit int) int {
+	if limit <= 0 {
+		return defaultFeedSize
+	}
+
+	return min(limit, maxFeedSize)
+}
@@ -0,0 +1,486 @@
+package mediaserver
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/csnewman/cathode/internal/db"
+	"github.com/google/uuid"
+)
+
+// watchState is a user's progress through a movie or episode.
+type watchState struct {
+	itemID        uuid.UUID
+	position      time.Duration
+	duration      time.Duration
+	watched       bool
+	playCount     int
+	audioTrack    sql.NullInt64
+	subtitleTrack sql.NullInt64
+	lastPlayed    time.Time
+}
+
+// historyEntry is a single viewing of a movie or episode, spanning the progress reports made while it was played.
+type historyEntry struct {
+	id        uuid.UUID
+	userID    uuid.UUID
+	itemID    uuid.UUID
+	deviceID  uuid.NullUUID
+	started   time.Time
+	updated   time.Time
+	position  time.Duration
+	watched   time.Duration
+	paused    bool
+	completed bool
+}
+
+const watchStateColumns = `item_id, position_ms, duration_ms, watched, play_count, audio_track, subtitle_track,
+	last_played`
+
+func scanWatchState(row interface{ Scan(dest ...any) error }) (watchState, error) {
+	var (
+		res        watchState
+		position   int64
+		duration   int64
+		lastPlayed string
+	)
+
+	err := row.Scan(
+		&res.itemID,
+		&position,
+		&duration,
+		&res.watched,
+		&res.playCount,
+		&res.audioTrack,
+		&res.subtitleTrack,
+		&lastPlayed,
+	)
+	if err != nil {
+		return watchState{}, err
+	}
+
+	res.position = time.Duration(position) * time.Millisecond
+	res.duration = time.Duration(duration) * time.Millisecond
+	res.lastPlayed = parseSQLiteTime(lastPlayed)
+
+	return res, nil
+}
+
+func getWatchState(_ context.Context, tx db.RTx, userID uuid.UUID, itemID uuid.UUID) (watchState, error) {
+	res, err := scanWatchState(tx.QueryRow(
+		`SELECT `+watchStateColumns+` FROM watch_state WHERE user_id = $1 AND item_id = $2`,
+		userID,
+		itemID,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return watchState{}, fmt.Errorf("%w: watch state %v", errNotFound, itemID)
+	}
+
+	return res, err
+}
+
+// getWatchStates returns the watch state of each of the given items which has one.
+func getWatchStates(
+	_ context.Context,
+	tx db.RTx,
+	userID uuid.UUID,
+	itemIDs []uuid.UUID,
+) (map[uuid.UUID]watchState, error) {
+	res := make(map[uuid.UUID]watchState)
+
+	if len(itemIDs) == 0 {
+		return res, nil
+	}
+
+	var args sqlArgs
+
+	query := `SELECT ` + watchStateColumns + ` FROM watch_state WHERE user_id = ` + args.add(userID) +
+		` AND item_id IN (` + args.list(itemIDs) + `)`
+
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		s, err := scanWatchState(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		res[s.itemID] = s
+	}
+
+	return res, rows.Err()
+}
+
+// getUnwatchedCounts returns the number of available episodes not watched by the user, for each of the given shows or
+// seasons that has any.
+func getUnwatchedCounts(
+	_ context.Context,
+	tx db.RTx,
+	userID uuid.UUID,
+	kind itemKind,
+	parentIDs []uuid.UUID,
+) (map[uuid.UUID]int, error) {
+	res := make(map[uuid.UUID]int)
+
+	if len(parentIDs) == 0 {
+		return res, nil
+	}
+
+	var (
+		args sqlArgs
+		sb   strings.Builder
+	)
+
+	if kind == itemKindShow {
+		sb.WriteString(`SELECT s.parent_id, COUNT(*) FROM items e JOIN items s ON s.id = e.parent_id WHERE `)
+	} else {
+		sb.WriteString(`SELECT e.parent_id, COUNT(*) FROM items e WHERE `)
+	}
+
+	sb.WriteString(`NOT EXISTS (
+		SELECT 1 FROM watch_state w WHERE w.item_id = e.id AND w.user_id = ` + args.add(userID) + ` AND w.watched = 1
+	)`)
+	sb.WriteString(` AND e.kind = ` + args.add(string(itemKindEpisode)) + ` AND e.missing_since IS NULL`)
+
+	if kind == itemKindShow {
+		sb.WriteString(` AND s.parent_id IN (` + args.list(parentIDs) + `) GROUP BY s.parent_id`)
+	} else {
+		sb.WriteString(` AND e.parent_id IN (` + args.list(parentIDs) + `) GROUP BY e.parent_id`)
+	}
+
+	rows, err := tx.Query(sb.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id    uuid.UUID
+			count int
+		)
+
+		if err := rows.Scan(&id, &count); err != nil {
+			return nil, err
+		}
+
+		res[id] = count
+	}
+
+	return res, rows.Err()
+}
+
+func upsertWatchState(_ context.Context, tx db.WTx, userID uuid.UUID, s watchState) error {
+	return tx.Exec(`
+		INSERT INTO watch_state (
+			user_id, item_id, position_ms, duration_ms, watched, play_count, audio_track, subtitle_track, last_played
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (user_id, item_id) DO UPDATE SET
+			position_ms = excluded.position_ms,
+			duration_ms = excluded.duration_ms,
+			watched = excluded.watched,
+			play_count = excluded.play_count,
+			audio_track = excluded.audio_track,
+			subtitle_track = excluded.subtitle_track,
+			last_played = excluded.last_played`,
+		userID,
+		s.itemID,
+		s.position.Milliseconds(),
+		s.duration.Milliseconds(),
+		s.watched,
+		s.playCount,
+		s.audioTrack,
+		s.subtitleTrack,
+		s.lastPlayed.UTC().Format(sqliteTimeLayout),
+	)
+}
+
+// watchTargetsExpr matches the playable items covered by an item: itself for movies and episodes, or the available
+// episodes of a show or season.
+func watchTargetsExpr(args *sqlArgs, it item) string {
+	switch it.kind {
+	case itemKindShow:
+		return `e.kind = 'episode' AND e.missing_since IS NULL
+			AND e.parent_id IN (SELECT id FROM items WHERE parent_id = ` + args.add(it.id) + `)`
+	case itemKindSeason:
+		return `e.kind = 'episode' AND e.missing_since IS NULL AND e.parent_id = ` + args.add(it.id)
+	default:
+		return `e.id = ` + args.add(it.id)
+	}
+}
+
+// markWatched marks an item, or the episodes of a show or season, as watched. Items that were not already watched have
+// their play count incremented.
+func markWatched(_ context.Context, tx db.WTx, userID uuid.UUID, it item, now time.Time) error {
+	var args sqlArgs
+
+	query := `
+		INSERT INTO watch_state (user_id, item_id, watched, play_count, last_played)
+		SELECT ` + args.add(userID) + `, e.id, 1, 1, ` + args.add(now.UTC().Format(sqliteTimeLayout)) + `
+		FROM items e WHERE ` + watchTargetsExpr(&args, it) + `
+		ON CONFLICT (user_id, item_id) DO UPDATE SET
+			position_ms = 0,
+			play_count = CASE WHEN watched = 1 THEN play_count ELSE play_count + 1 END,
+			watched = 1,
+			last_played = excluded.last_played`
+
+	return tx.Exec(query, args...)
+}
+
+// markUnwatched clears the watched flag and resume point of an item, or the episodes of a show or season.
+func markUnwatched(_ context.Context, tx db.WTx, userID uuid.UUID, it item) error {
+	var args sqlArgs
+
+	query := `UPDATE watch_state SET watched = 0, position_ms = 0 WHERE user_id = ` + args.add(userID) +
+		` AND item_id IN (SELECT e.id FROM items e WHERE ` + watchTargetsExpr(&args, it) + `)`
+
+	return tx.Exec(query, args...)
+}
+
+const historyColumns = `id, user_id, item_id, device_id, started, updated, position_ms, watched_ms, paused, completed`
+
+func scanHistoryEntry(row interface{ Scan(dest ...any) error }) (historyEntry, error) {
+	var (
+		res      historyEntry
+		started  string
+		updated  string
+		position int64
+		watched  int64
+	)
+
+	err := row.Scan(
+		&res.id,
+		&res.userID,
+		&res.itemID,
+		&res.deviceID,
+		&started,
+		&updated,
+		&position,
+		&watched,
+		&res.paused,
+		&res.completed,
+	)
+	if err != nil {
+		return historyEntry{}, err
+	}
+
+	res.started = parseSQLiteTime(started)
+	res.updated = parseSQLiteTime(updated)
+	res.position = time.Duration(position) * time.Millisecond
+	res.watched = time.Duration(watched) * time.Millisecond
+
+	return res, nil
+}
+
+// getLatestHistory returns the most recently updated viewing of an item by a user.
+func getLatestHistory(_ context.Context, tx db.RTx, userID uuid.UUID, itemID uuid.UUID) (historyEntry, error) {
+	res, err := scanHistoryEntry(tx.QueryRow(
+		`SELECT `+historyColumns+` FROM watch_history WHERE user_id = $1 AND item_id = $2
+		ORDER BY updated DESC LIMIT 1`,
+		userID,
+		itemID,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return historyEntry{}, fmt.Errorf("%w: history for %v", errNotFound, itemID)
+	}
+
+	return res, err
+}
+
+func upsertHistory(_ context.Context, tx db.WTx, h historyEntry) error {
+	return tx.Exec(`
+		INSERT INTO watch_history (
+			id, user_id, item_id, device_id, started, updated, position_ms, watched_ms, paused, completed
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (id) DO UPDATE SET
+			device_id = excluded.device_id,
+			updated = excluded.updated,
+			position_ms = excluded.position_ms,
+			watched_ms = excluded.watched_ms,
+			paused = excluded.paused,
+			completed = excluded.completed`,
+		h.id,
+		h.userID,
+		h.itemID,
+		h.deviceID,
+		h.started.UTC().Format(sqliteTimeLayout),
+		h.updated.UTC().Format(sqliteTimeLayout),
+		h.position.Milliseconds(),
+		h.watched.Milliseconds(),
+		h.paused,
+		h.completed,
+	)
+}
+
+// getHistory returns up to limit viewings by a user, most recent first, along with whether more follow. The cursor
+// holds the start time and id of the last entry of the previous page.
+func getHistory(
+	_ context.Context,
+	tx db.RTx,
+	userID uuid.UUID,
+	after *itemCursor,
+	limit int,
+) ([]historyEntry, bool, error) {
+	var (
+		args sqlArgs
+		sb   strings.Builder
+	)
+
+	sb.WriteString(`SELECT ` + historyColumns + ` FROM watch_history WHERE user_id = ` + args.add(userID))
+
+	if after != nil {
+		sb.WriteString(` AND (started < ` + args.add(after.Str))
+		sb.WriteString(` OR (started = ` + args.add(after.Str) + ` AND id < ` + args.add(after.ID) + `))`)
+	}
+
+	sb.WriteString(` ORDER BY started DESC, id DESC LIMIT ` + args.add(limit+1))
+
+	rows, err := tx.Query(sb.String(), args...)
+	if err != nil {
+		return nil, false, err
+	}
+
+	defer rows.Close()
+
+	var entries []historyEntry
+
+	for rows.Next() {
+		h, err := scanHistoryEntry(rows)
+		if err != nil {
+			return nil, false, err
+		}
+
+		entries = append(entries, h)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+
+	if len(entries) > limit {
+		return entries[:limit], true, nil
+	}
+
+	return entries, false, nil
+}
+
+// getResumableItems returns the available movies and episodes with a resume point, most recently played first.
+func getResumableItems(
+	_ context.Context,
+	tx db.RTx,
+	userID uuid.UUID,
+	libraryID uuid.NullUUID,
+	limit int,
+) ([]uuid.UUID, error) {
+	var (
+		args sqlArgs
+		sb   strings.Builder
+	)
+
+	sb.WriteString(`SELECT w.item_id FROM watch_state w JOIN items i ON i.id = w.item_id`)
+	sb.WriteString(` WHERE w.user_id = ` + args.add(userID) + ` AND w.position_ms > 0 AND i.missing_since IS NULL`)
+
+	if libraryID.Valid {
+		sb.WriteString(` AND i.library_id = ` + args.add(libraryID.UUID))
+	}
+
+	sb.WriteString(` ORDER BY w.last_played DESC LIMIT ` + args.add(limit))
+
+	return queryUUIDs(tx, sb.String(), args...)
+}
+
+// getRecentShows returns the shows a user has watched episodes of, most recently watched first.
+func getRecentShows(_ context.Context, tx db.RTx, userID uuid.UUID, libraryID uuid.NullUUID) ([]uuid.UUID, error) {
+	var (
+		args sqlArgs
+		sb   strings.Builder
+	)
+
+	sb.WriteString(`
+		SELECT s.parent_id FROM watch_state w
+		JOIN items e ON e.id = w.item_id
+		JOIN items s ON s.id = e.parent_id
+		WHERE w.user_id = ` + args.add(userID) + ` AND w.watched = 1 AND e.kind = 'episode'`)
+
+	if libraryID.Valid {
+		sb.WriteString(` AND e.library_id = ` + args.add(libraryID.UUID))
+	}
+
+	sb.WriteString(` GROUP BY s.parent_id ORDER BY MAX(w.last_played) DESC`)
+
+	return queryUUIDs(tx, sb.String(), args...)
+}
+
+// episodeProgress is the watch state of an episode, in the order of a show.
+type episodeProgress struct {
+	id        uuid.UUID
+	missing   bool
+	watched   bool
+	resumable bool
+}
+
+// getShowProgress returns the regular episodes of a show in order, along with the user's progress through each.
+// Specials are left out, as they sit outside the show's order.
+func getShowProgress(_ context.Context, tx db.RTx, userID uuid.UUID, showID uuid.UUID) ([]episodeProgress, error) {
+	rows, err := tx.Query(`
+		SELECT e.id, e.missing_since IS NOT NULL, COALESCE(w.watched, 0), COALESCE(w.position_ms, 0) > 0
+		FROM items e
+		JOIN items s ON s.id = e.parent_id
+		LEFT JOIN watch_state w ON w.item_id = e.id AND w.user_id = $1
+		WHERE s.parent_id = $2 AND e.kind = 'episode' AND s.season_number > 0
+		ORDER BY e.season_number, e.episode_number, e.name`,
+		userID,
+		showID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var res []episodeProgress
+
+	for rows.Next() {
+		var ep episodeProgress
+
+		if err := rows.Scan(&ep.id, &ep.missing, &ep.watched, &ep.resumable); err != nil {
+			return nil, err
+		}
+
+		res = append(res, ep)
+	}
+
+	return res, rows.Err()
+}
+
+func queryUUIDs(tx db.RTx, query string, args ...any) ([]uuid.UUID, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var res []uuid.UUID
+
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		res = append(res, id)
+	}
+
+	return res, rows.Err()
+}
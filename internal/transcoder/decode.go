@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/csnewman/ffmpeg-go"
 )
@@ -32,6 +33,8 @@ func OpenSrcFile(logger *slog.Logger, path string) (*SrcFile, error) {
 	}
 
 	if _, err := ffmpeg.AVFormatFindStreamInfo(ctx, nil); err != nil {
+		ffmpeg.AVFormatCloseInput(&ctx)
+
 		return nil, fmt.Errorf("failed to find streams: %w", err)
 	}
 
@@ -66,11 +69,23 @@ func (f *SrcFile) Read() (*ffmpeg.AVPacket, error) {
 	return f.pkt, nil
 }
 
+// Close frees the input. It is safe to call more than once.
+func (f *SrcFile) Close() {
+	if f.pkt != nil {
+		ffmpeg.AVPacketFree(&f.pkt)
+	}
+
+	if f.ctx != nil {
+		ffmpeg.AVFormatCloseInput(&f.ctx)
+	}
+}
+
 type DecStream struct {
 	Index    int
 	Type     ffmpeg.AVMediaType
 	DecCtx   *ffmpeg.AVCodecContext
 	DecFrame *ffmpeg.AVFrame
+	Timebase *ffmpeg.AVRational
 }
 
 func (f *SrcFile) OpenAVStream(id int) (*DecStream, error) {
@@ -97,6 +112,8 @@ func (f *SrcFile) OpenAVStream(id int) (*DecStream, error) {
 	codecCtx := ffmpeg.AVCodecAllocContext3(dec)
 
 	if _, err := ffmpeg.AVCodecParametersToContext(codecCtx, stream.Codecpar()); err != nil {
+		ffmpeg.AVCodecFreeContext(&codecCtx)
+
 		return nil, fmt.Errorf("failed to copy codec params: %w", err)
 	}
 
@@ -108,6 +125,8 @@ func (f *SrcFile) OpenAVStream(id int) (*DecStream, error) {
 	}
 
 	if _, err := ffmpeg.AVCodecOpen2(codecCtx, dec, nil); err != nil {
+		ffmpeg.AVCodecFreeContext(&codecCtx)
+
 		return nil, fmt.Errorf("failed to open decoder: %w", err)
 	}
 
@@ -118,6 +137,7 @@ func (f *SrcFile) OpenAVStream(id int) (*DecStream, error) {
 		Type:     cType,
 		DecCtx:   codecCtx,
 		DecFrame: frame,
+		Timebase: stream.TimeBase(),
 	}, nil
 }
 
@@ -141,3 +161,23 @@ func (s *DecStream) Receive() (*ffmpeg.AVFrame, error) {
 
 	return s.DecFrame, nil
 }
+
+// Close frees the decoder. It is safe to call more than once.
+func (s *DecStream) Close() {
+	if s.DecFrame != nil {
+		ffmpeg.AVFrameFree(&s.DecFrame)
+	}
+
+	if s.DecCtx != nil {
+		ffmpeg.AVCodecFreeContext(&s.DecCtx)
+	}
+}
+
+// timestamp converts a timestamp in the stream timebase to a duration, treating missing timestamps as zero.
+func (s *DecStream) timestamp(ts int64) time.Duration {
+	if ts == ffmpeg.AVNoptsValue {
+		return 0
+	}
+
+	return time.Duration(float64(ts) * ffmpeg.AVQ2D(s.Timebase) * float64(time.Second))
+}
@@ -31,6 +31,8 @@ func OpenDstFile(logger *slog.Logger, path string, segmented bool) (*DstFile, er
 	var pb *ffmpeg.AVIOContext
 
 	if _, err := ffmpeg.AVIOOpen(&pb, namePtr, ffmpeg.AVIOFlagWrite); err != nil {
+		ffmpeg.AVFormatFreeContext(ctx)
+
 		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 
@@ -141,6 +143,30 @@ func (f *DstFile) WriteTrailer() error {
 	return nil
 }
 
+// Close frees the output and its streams, closing the current file if still open. It is safe to call more than once.
+func (f *DstFile) Close() {
+	for _, stream := range f.encStreams {
+		stream.Close()
+	}
+
+	f.encStreams = nil
+
+	if f.ctx == nil {
+		return
+	}
+
+	if pb := f.ctx.Pb(); pb != nil {
+		if _, err := ffmpeg.AVIOClose(pb); err != nil {
+			f.logger.Warn("Failed to close output file", "err", err)
+		}
+
+		f.ctx.SetPb(nil)
+	}
+
+	ffmpeg.AVFormatFreeContext(f.ctx)
+	f.ctx = nil
+}
+
 type EncStream struct {
 	File     *DstFile
 	Index    int
@@ -245,3 +271,14 @@ func (s *EncStream) Write(frame *ffmpeg.AVFrame) error {
 
 	return nil
 }
+
+// Close frees the encoder. The stream itself is freed with its file.
+func (s *EncStream) Close() {
+	if s.EncPkt != nil {
+		ffmpeg.AVPacketFree(&s.EncPkt)
+	}
+
+	if s.EncCtx != nil {
+		ffmpeg.AVCodecFreeContext(&s.EncCtx)
+	}
+}
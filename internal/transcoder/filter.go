@@ -14,9 +14,16 @@ type Filter struct {
 	FilteredFrame *ffmpeg.AVFrame
 }
 
-func newFilter(in *DecStream, out *EncStream, filterSpec string) (*Filter, error) {
+func newFilter(in *DecStream, out *EncStream, filterSpec string) (_ *Filter, err error) {
 	filterGraph := ffmpeg.AVFilterGraphAlloc()
 
+	// The filter contexts belong to the graph, so are freed with it
+	defer func() {
+		if err != nil {
+			ffmpeg.AVFilterGraphFree(&filterGraph)
+		}
+	}()
+
 	var (
 		bufferSrcCtx  *ffmpeg.AVFilterContext
 		bufferSinkCtx *ffmpeg.AVFilterContext
@@ -241,3 +248,14 @@ func (f *Filter) Read() (*ffmpeg.AVFrame, error) {
 
 	return f.FilteredFrame, nil
 }
+
+// Close frees the filter graph. It is safe to call more than once.
+func (f *Filter) Close() {
+	if f.FilteredFrame != nil {
+		ffmpeg.AVFrameFree(&f.FilteredFrame)
+	}
+
+	if f.FilterGraph != nil {
+		ffmpeg.AVFilterGraphFree(&f.FilterGraph)
+	}
+}
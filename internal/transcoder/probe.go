@@ -0,0 +1,70 @@
+package transcoder
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/csnewman/ffmpeg-go"
+)
+
+// Probe describes a media file, read without decoding any streams.
+type Probe struct {
+	Format   string
+	Duration time.Duration
+	Tags     map[string]string
+	// VideoCodec is the codec of the first video stream, empty when there is none.
+	VideoCodec string
+}
+
+func ProbeFile(path string) (*Probe, error) {
+	urlPtr := ffmpeg.ToCStr(path)
+	defer urlPtr.Free()
+
+	var ctx *ffmpeg.AVFormatContext
+
+	if _, err := ffmpeg.AVFormatOpenInput(&ctx, urlPtr, nil, nil); err != nil {
+		return nil, fmt.Errorf("failed to open input: %w", err)
+	}
+
+	defer ffmpeg.AVFormatCloseInput(&ctx)
+
+	res := &Probe{
+		Format: ctx.Iformat().Name().String(),
+		Tags:   make(map[string]string),
+	}
+
+	if d := ctx.Duration(); d > 0 {
+		res.Duration = time.Duration(d) * time.Second / ffmpeg.AVTimeBase
+	}
+
+	streams := ctx.Streams()
+
+	for i := uint(0); i < ctx.NbStreams(); i++ {
+		par := streams.Get(uintptr(i)).Codecpar()
+
+		if par.CodecType() == ffmpeg.AVMediaTypeVideo {
+			res.VideoCodec = ffmpeg.AVCodecGetName(par.CodecId()).String()
+
+			break
+		}
+	}
+
+	meta := ctx.Metadata()
+
+	for entry := ffmpeg.AVDictIterate(meta, nil); entry != nil; entry = ffmpeg.AVDictIterate(meta, entry) {
+		res.Tags[strings.ToLower(entry.Key().String())] = entry.Value().String()
+	}
+
+	return res, nil
+}
+
+// ReadTags returns the container level metadata of a media file, with lower case keys.
+func ReadTags(path string) (map[string]string, error) {
+	p, err := ProbeFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.Tags, nil
+}
@@ -3,17 +3,30 @@ package transcoder
 import (
 	"errors"
 	"fmt"
-	"log"
 	"log/slog"
+	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/csnewman/ffmpeg-go"
 )
 
-var ErrNoMore = errors.New("no more data available")
+var (
+	ErrNoMore  = errors.New("no more data available")
+	ErrStopped = errors.New("session stopped")
+)
+
+// SegmentLength is the target length of each output segment. Segments are cut at the first packet after the target,
+// so may run slightly longer.
+const SegmentLength = 6 * time.Second
+
+// InitSegment is the name of the file holding the output header, which precedes every segment.
+const InitSegment = "init.mp4"
 
 type Session struct {
 	logger *slog.Logger
 	path   string
+	dir    string
 
 	input  *SrcFile
 	output *DstFile
@@ -21,15 +34,49 @@ type Session struct {
 	videoIn     *DecStream
 	videoOut    *EncStream
 	videoFilter *Filter
+
+	// forceKeyframe makes the next encoded frame a keyframe, so that each segment can be decoded on its own.
+	forceKeyframe bool
+
+	stop     chan struct{}
+	stopOnce sync.Once
+
+	mu       sync.Mutex
+	started  time.Time
+	position time.Duration
+	segments []time.Duration
+	done     bool
 }
 
-func Open(logger *slog.Logger, path string) *Session {
+// Stats describes the progress of a session.
+type Stats struct {
+	// Speed is how many seconds of media are transcoded per second of running.
+	Speed float64
+	// Position is how far into the source the transcode has reached.
+	Position time.Duration
+	// Segment is the number of the segment being written, starting from 1.
+	Segment int
+	// Segments holds the length of each complete segment.
+	Segments []time.Duration
+	// Done is set once the whole source has been transcoded.
+	Done bool
+}
+
+// Open prepares a session transcoding the file at path, writing the init segment and numbered segments to dir.
+func Open(logger *slog.Logger, path string, dir string) *Session {
 	return &Session{
 		logger: logger,
 		path:   path,
+		dir:    dir,
+		stop:   make(chan struct{}),
 	}
 }
 
+// SegmentName returns the file name of the given segment, numbered from 1.
+func SegmentName(n int) string {
+	return fmt.Sprintf("seg%d.m4s", n)
+}
+
 func (s *Session) Start() error {
 	var err error
 
@@ -50,7 +97,7 @@ func (s *Session) Start() error {
 		return fmt.Errorf("failed to open src video stream: %w", err)
 	}
 
-	s.output, err = OpenDstFile(s.logger, "init.mp4", true)
+	s.output, err = OpenDstFile(s.logger, filepath.Join(s.dir, InitSegment), true)
 	if err != nil {
 		return fmt.Errorf("failed to open dst file ctx: %w", err)
 	}
@@ -70,7 +117,7 @@ func (s *Session) Start() error {
 		return fmt.Errorf("failed to write dst header: %w", err)
 	}
 
-	if err := s.output.NewSegment("seg1.m4s"); err != nil {
+	if err := s.output.NewSegment(filepath.Join(s.dir, SegmentName(1))); err != nil {
 		return fmt.Errorf("failed to open segement: %w", err)
 	}
 
@@ -85,119 +132,206 @@ func (s *Session) Start() error {
 	return nil
 }
 
-var justFlushed = false //nolint:gochecknoglobals
-
+// Run transcodes the source until it ends or the session is stopped, in which case ErrStopped is returned. The session
+// must be closed once Run returns.
 func (s *Session) Run() error {
-	i := 0
+	s.mu.Lock()
+	s.started = time.Now()
+	s.mu.Unlock()
 
-	for {
-		i++
-
-		if i == 2000 {
-			if err := s.output.Write(nil); err != nil {
-				log.Panicln(err)
-			}
+	var segmentStart time.Duration
 
-			justFlushed = true
-
-			if err := s.output.NewSegment("seg2.m4s"); err != nil {
-				return fmt.Errorf("failed to open segement: %w", err)
-			}
-		}
-
-		if i > 3000 {
-			break
+	for {
+		select {
+		case <-s.stop:
+			return ErrStopped
+		default:
 		}
 
 		pkt, err := s.input.Read()
-		if errors.Is(err, ffmpeg.AVErrorEOF) {
-			log.Println("End of file")
+		if errors.Is(err, ErrNoMore) {
+			s.logger.Debug("End of file")
 
 			break
 		} else if err != nil {
-			return err
+			return fmt.Errorf("failed to read src: %w", err)
 		}
 
 		idx := pkt.StreamIndex()
 
 		if idx == s.videoIn.Index {
-			s.processAVPacket(pkt, s.videoIn, s.videoFilter, s.videoOut)
+			pos := s.videoIn.timestamp(pkt.Pts())
+
+			if pos-segmentStart >= SegmentLength {
+				if err := s.output.Write(nil); err != nil {
+					ffmpeg.AVPacketUnref(pkt)
+
+					return fmt.Errorf("failed to flush segment: %w", err)
+				}
+
+				s.forceKeyframe = true
+
+				// Segments are numbered from one, and the segment being finished is not listed yet
+				s.mu.Lock()
+				next := len(s.segments) + 2
+				s.mu.Unlock()
+
+				if err := s.output.NewSegment(filepath.Join(s.dir, SegmentName(next))); err != nil {
+					ffmpeg.AVPacketUnref(pkt)
+
+					return fmt.Errorf("failed to open segement: %w", err)
+				}
+
+				// The previous segment is only complete once NewSegment has flushed and closed it
+				s.mu.Lock()
+				s.segments = append(s.segments, pos-segmentStart)
+				s.mu.Unlock()
+
+				segmentStart = pos
+			}
+
+			if err := s.processAVPacket(pkt, s.videoIn, s.videoFilter, s.videoOut); err != nil {
+				ffmpeg.AVPacketUnref(pkt)
+
+				return err
+			}
+
+			s.mu.Lock()
+			s.position = max(s.position, pos)
+			s.mu.Unlock()
 		}
 
 		ffmpeg.AVPacketUnref(pkt)
 	}
 
-	s.flushAV(s.videoFilter, s.videoOut)
+	if err := s.flushAV(s.videoFilter, s.videoOut); err != nil {
+		return err
+	}
 
 	if err := s.output.WriteTrailer(); err != nil {
-		return err
+		return fmt.Errorf("failed to finish output: %w", err)
 	}
 
+	s.mu.Lock()
+	s.segments = append(s.segments, s.position-segmentStart)
+	s.done = true
+	s.mu.Unlock()
+
 	return nil
 }
 
-func (s *Session) processAVPacket(packet *ffmpeg.AVPacket, in *DecStream, filter *Filter, out *EncStream) {
-	err := in.Send(packet)
-	if err != nil {
-		log.Panicln(err)
+// Stop ends a running transcode. It is safe to call more than once.
+func (s *Session) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+}
+
+// Close frees the input, output, filter and codec contexts of the session. It must not be called while Run is running,
+// and is safe to call more than once.
+func (s *Session) Close() {
+	if s.videoFilter != nil {
+		s.videoFilter.Close()
+	}
+
+	// The output frees its encoder streams
+	if s.output != nil {
+		s.output.Close()
+	}
+
+	if s.videoIn != nil {
+		s.videoIn.Close()
+	}
+
+	if s.input != nil {
+		s.input.Close()
+	}
+}
+
+// Stats returns a snapshot of the progress of the session.
+func (s *Session) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res := Stats{
+		Position: s.position,
+		Segment:  len(s.segments) + 1,
+		Segments: append([]time.Duration(nil), s.segments...),
+		Done:     s.done,
+	}
+
+	if s.done {
+		res.Segment = len(s.segments)
+	}
+
+	if elapsed := time.Since(s.started); !s.started.IsZero() && elapsed > 0 {
+		res.Speed = s.position.Seconds() / elapsed.Seconds()
+	}
+
+	return res
+}
+
+func (s *Session) processAVPacket(packet *ffmpeg.AVPacket, in *DecStream, filter *Filter, out *EncStream) error {
+	if err := in.Send(packet); err != nil {
+		return fmt.Errorf("failed to send packet to decoder: %w", err)
 	}
 
 	for {
 		frame, err := in.Receive()
 		if errors.Is(err, ErrNoMore) {
-			break
+			return nil
 		} else if err != nil {
-			log.Panicln(err)
+			return fmt.Errorf("failed to receive frame from decoder: %w", err)
 		}
 
 		if err := filter.Write(frame); err != nil {
-			log.Panicln(err)
+			return fmt.Errorf("failed to send frame to filter: %w", err)
 		}
 
-		for {
-			filtered, err := filter.Read()
-			if errors.Is(err, ErrNoMore) {
-				break
-			} else if err != nil {
-				log.Panicln(err)
-			}
-
-			if justFlushed {
-				filtered.SetPictType(ffmpeg.AVPictureTypeI)
-
-				justFlushed = false
-			}
-
-			if err := out.Write(filtered); err != nil {
-				log.Panicln(err)
-			}
-
-			ffmpeg.AVFrameUnref(filtered)
+		if err := s.drainFilter(filter, out); err != nil {
+			return err
 		}
 	}
 }
 
-func (s *Session) flushAV(filter *Filter, out *EncStream) {
-	if err := filter.Write(nil); err != nil {
-		log.Panicln(err)
-	}
-
+// drainFilter encodes every frame the filter has ready.
+func (s *Session) drainFilter(filter *Filter, out *EncStream) error {
 	for {
 		filtered, err := filter.Read()
 		if errors.Is(err, ErrNoMore) {
-			break
+			return nil
 		} else if err != nil {
-			log.Panicln(err)
+			return fmt.Errorf("failed to receive frame from filter: %w", err)
 		}
 
-		if err := out.Write(filtered); err != nil {
-			log.Panicln(err)
+		if s.forceKeyframe {
+			filtered.SetPictType(ffmpeg.AVPictureTypeI)
+
+			s.forceKeyframe = false
 		}
 
+		err = out.Write(filtered)
+
 		ffmpeg.AVFrameUnref(filtered)
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Session) flushAV(filter *Filter, out *EncStream) error {
+	if err := filter.Write(nil); err != nil {
+		return fmt.Errorf("failed to flush filter: %w", err)
+	}
+
+	if err := s.drainFilter(filter, out); err != nil {
+		return err
 	}
 
 	if err := out.Write(nil); err != nil {
-		log.Panicln(err)
+		return fmt.Errorf("failed to flush encoder: %w", err)
 	}
+
+	return nil
 }
@@ -8,12 +8,14 @@ import (
 	"compress/gzip"
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/go-chi/chi/v5"
@@ -22,380 +24,5541 @@ import (
 	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
+const (
+	AccessTokenScopes = "accessToken.Scopes"
+	SessionScopes     = "session.Scopes"
+)
+
+// Defines values for ACMEProvider.
+const (
+	Letsencrypt        ACMEProvider = "letsencrypt"
+	LetsencryptStaging ACMEProvider = "letsencrypt-staging"
+	Zerossl            ACMEProvider = "zerossl"
+)
+
+// Defines values for AddressScope.
+const (
+	Domain   AddressScope = "domain"
+	Lan      AddressScope = "lan"
+	Loopback AddressScope = "loopback"
+	Public   AddressScope = "public"
+)
+
+// Defines values for CertificateEventSource.
+const (
+	Acme CertificateEventSource = "acme"
+	Dsdm CertificateEventSource = "dsdm"
+)
+
+// Defines values for CertificateKeyType.
+const (
+	Ec256   CertificateKeyType = "ec256"
+	Ec384   CertificateKeyType = "ec384"
+	Rsa2048 CertificateKeyType = "rsa2048"
+	Rsa4096 CertificateKeyType = "rsa4096"
+)
+
+// Defines values for EventType.
+const (
+	CertificateRenewed EventType = "certificate.renewed"
+	EventsDropped      EventType = "events.dropped"
+	ItemAdded          EventType = "item.added"
+	ItemRemoved        EventType = "item.removed"
+	ItemUpdated        EventType = "item.updated"
+	LibraryScan        EventType = "library.scan"
+	SessionStarted     EventType = "session.started"
+	SessionStopped     EventType = "session.stopped"
+)
+
+// Defines values for ItemKind.
+const (
+	Episode ItemKind = "episode"
+	Movie   ItemKind = "movie"
+	Season  ItemKind = "season"
+	Show    ItemKind = "show"
+)
+
+// Defines values for ItemSort.
+const (
+	ItemSortAdded    ItemSort = "added"
+	ItemSortName     ItemSort = "name"
+	ItemSortReleased ItemSort = "released"
+	ItemSortYear     ItemSort = "year"
+)
+
+// Defines values for PlaybackPlan.
+const (
+	Copy      PlaybackPlan = "copy"
+	Transcode PlaybackPlan = "transcode"
+)
+
+// Defines values for PluginAuth.
+const (
+	ApiKey      PluginAuth = "api-key"
+	SignedToken PluginAuth = "signed-token"
+)
+
+// Defines values for PluginScope.
+const (
+	LibraryRead    PluginScope = "library:read"
+	MetadataSource PluginScope = "metadata:source"
+	MetadataWrite  PluginScope = "metadata:write"
+	PlaybackEvents PluginScope = "playback:events"
+)
+
+// Defines values for ScanEventState.
+const (
+	ScanEventStateCompleted ScanEventState = "completed"
+	ScanEventStateFailed    ScanEventState = "failed"
+	ScanEventStateRunning   ScanEventState = "running"
+	ScanEventStateStarted   ScanEventState = "started"
+)
+
+// Defines values for SessionEventReason.
+const (
+	SessionEventReasonFailed     SessionEventReason = "failed"
+	SessionEventReasonIdle       SessionEventReason = "idle"
+	SessionEventReasonTerminated SessionEventReason = "terminated"
+)
+
+// Defines values for SortOrder.
+const (
+	Asc  SortOrder = "asc"
+	Desc SortOrder = "desc"
+)
+
+// Defines values for UserRole.
+const (
+	UserRoleAdmin UserRole = "admin"
+	UserRoleGuest UserRole = "guest"
+	UserRoleUser  UserRole = "user"
+)
+
+// Defines values for GetImageParamsFormat.
+const (
+	Jpeg GetImageParamsFormat = "jpeg"
+	Webp GetImageParamsFormat = "webp"
+)
+
+// ACMEProvider Certificate authority certificates are requested from. If issuance fails, ZeroSSL and Let's Encrypt fall back to
+// each other, while staging has no fallback.
+type ACMEProvider string
+
+// AddressScope Where an address can be reached from. Domain addresses use a domain configured for ACME certificates.
+type AddressScope string
+
+// ApprovePairingRequest defines model for ApprovePairingRequest.
+type ApprovePairingRequest struct {
+	Code string `json:"code"`
+}
+
+// CertificateEvent A certificate that was issued or renewed.
+type CertificateEvent struct {
+	Domains []string               `json:"domains"`
+	Expires time.Time              `json:"expires"`
+	Source  CertificateEventSource `json:"source"`
+}
+
+// CertificateEventSource defines model for CertificateEvent.Source.
+type CertificateEventSource string
+
+// CertificateKeyType defines model for CertificateKeyType.
+type CertificateKeyType string
+
+// CreateLibraryRequest defines model for CreateLibraryRequest.
+type CreateLibraryRequest struct {
+	Name         string `json:"name"`
+	OnlineLookup *bool  `json:"onlineLookup,omitempty"`
+	Root         string `json:"root"`
+}
+
+// CreateUserRequest defines model for CreateUserRequest.
+type CreateUserRequest struct {
+	Password string   `json:"password"`
+	Role     UserRole `json:"role"`
+	Username string   `json:"username"`
+}
+
+// DSDMServer defines model for DSDMServer.
+type DSDMServer struct {
+	KeyType CertificateKeyType `json:"keyType"`
+
+	// Provider Certificate authority certificates are requested from. If issuance fails, ZeroSSL and Let's Encrypt fall back to
+	// each other, while staging has no fallback.
+	Provider ACMEProvider `json:"provider"`
+
+	// Server Base URL of the server.
+	Server string `json:"server"`
+}
+
+// DSDMServerList defines model for DSDMServerList.
+type DSDMServerList struct {
+	Items []DSDMServer `json:"items"`
+}
+
+// Device defines model for Device.
+type Device struct {
+	Created  time.Time          `json:"created"`
+	Id       openapi_types.UUID `json:"id"`
+	LastSeen time.Time          `json:"lastSeen"`
+	Name     string             `json:"name"`
+}
+
+// DeviceList defines model for DeviceList.
+type DeviceList struct {
+	Devices []Device `json:"devices"`
+}
+
+// DroppedEvent defines model for DroppedEvent.
+type DroppedEvent struct {
+	// Count Number of events dropped.
+	Count int `json:"count"`
+}
+
 // ErrorResponse defines model for ErrorResponse.
 type ErrorResponse struct {
 	Error   string `json:"error"`
 	Message string `json:"message"`
 }
 
-// ServerInterface represents all server handlers.
-type ServerInterface interface {
-	// Plugin Transport Connect
-	// (CONNECT /plugin/transport)
-	ConnectPluginTransport(w http.ResponseWriter, r *http.Request)
-	// Transcode Manifest M3U8
-	// (GET /transcode/{transcodeId}/manifest.m3u8)
-	GetTranscodeManifestM3u8(w http.ResponseWriter, r *http.Request, transcodeId openapi_types.UUID)
-	// Transcode Segment
-	// (GET /transcode/{transcodeId}/{segment})
-	GetTranscodeSegment(w http.ResponseWriter, r *http.Request, transcodeId openapi_types.UUID, segment string)
+// EventType defines model for EventType.
+type EventType string
+
+// GenreList defines model for GenreList.
+type GenreList struct {
+	Genres []string `json:"genres"`
 }
 
-// Unimplemented server implementation that returns http.StatusNotImplemented for each endpoint.
+// HistoryEntry A single viewing of a movie or episode.
+type HistoryEntry struct {
+	// Completed Set when the viewing passed the watched threshold.
+	Completed bool `json:"completed"`
 
-type Unimplemented struct{}
+	// DeviceId Paired device the item was played on, if any.
+	DeviceId *openapi_types.UUID `json:"deviceId,omitempty"`
+	Id       openapi_types.UUID  `json:"id"`
 
-// Plugin Transport Connect
-// (CONNECT /plugin/transport)
-func (_ Unimplemented) ConnectPluginTransport(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusNotImplemented)
+	// Item Summary of an item, as shown in lists.
+	Item Item `json:"item"`
+
+	// Position Last reported position in seconds.
+	Position float64   `json:"position"`
+	Started  time.Time `json:"started"`
+
+	// Updated When progress was last reported.
+	Updated time.Time `json:"updated"`
+
+	// WatchedTime Seconds spent playing, excluding time paused.
+	WatchedTime float64 `json:"watchedTime"`
 }
 
-// Transcode Manifest M3U8
-// (GET /transcode/{transcodeId}/manifest.m3u8)
-func (_ Unimplemented) GetTranscodeManifestM3u8(w http.ResponseWriter, r *http.Request, transcodeId openapi_types.UUID) {
-	w.WriteHeader(http.StatusNotImplemented)
+// HistoryPage defines model for HistoryPage.
+type HistoryPage struct {
+	Entries []HistoryEntry `json:"entries"`
+
+	// NextCursor Cursor for the next page. Omitted on the last page.
+	NextCursor *string `json:"nextCursor,omitempty"`
 }
 
-// Transcode Segment
-// (GET /transcode/{transcodeId}/{segment})
-func (_ Unimplemented) GetTranscodeSegment(w http.ResponseWriter, r *http.Request, transcodeId openapi_types.UUID, segment string) {
-	w.WriteHeader(http.StatusNotImplemented)
+// Item Summary of an item, as shown in lists.
+type Item struct {
+	Added         time.Time           `json:"added"`
+	BackdropImage *openapi_types.UUID `json:"backdropImage,omitempty"`
+	EpisodeNumber *int                `json:"episodeNumber,omitempty"`
+	Id            openapi_types.UUID  `json:"id"`
+	Kind          ItemKind            `json:"kind"`
+	LogoImage     *openapi_types.UUID `json:"logoImage,omitempty"`
+	Name          string              `json:"name"`
+
+	// PosterImage Image ID of the poster, or still for episodes.
+	PosterImage *openapi_types.UUID `json:"posterImage,omitempty"`
+
+	// ReleaseDate Release or air date, formatted as YYYY-MM-DD.
+	ReleaseDate  *string `json:"releaseDate,omitempty"`
+	SeasonNumber *int    `json:"seasonNumber,omitempty"`
+
+	// WatchState The current user's progress through an item.
+	WatchState *WatchState `json:"watchState,omitempty"`
+	Year       *int        `json:"year,omitempty"`
 }
 
-// ServerInterfaceWrapper converts contexts to parameters.
-type ServerInterfaceWrapper struct {
-	Handler            ServerInterface
-	HandlerMiddlewares []MiddlewareFunc
-	ErrorHandlerFunc   func(w http.ResponseWriter, r *http.Request, err error)
+// ItemDetails defines model for ItemDetails.
+type ItemDetails struct {
+	Added            time.Time           `json:"added"`
+	BackdropImage    *openapi_types.UUID `json:"backdropImage,omitempty"`
+	Cast             []string            `json:"cast"`
+	EpisodeNumber    *int                `json:"episodeNumber,omitempty"`
+	Genres           []string            `json:"genres"`
+	Id               openapi_types.UUID  `json:"id"`
+	Kind             ItemKind            `json:"kind"`
+	LibraryId        openapi_types.UUID  `json:"libraryId"`
+	LogoImage        *openapi_types.UUID `json:"logoImage,omitempty"`
+	Name             string              `json:"name"`
+	OriginalLanguage string              `json:"originalLanguage"`
+	OriginalName     string              `json:"originalName"`
+	Overview         string              `json:"overview"`
+
+	// ParentId The show of a season, or the season of an episode.
+	ParentId    *openapi_types.UUID `json:"parentId,omitempty"`
+	PosterImage *openapi_types.UUID `json:"posterImage,omitempty"`
+
+	// ProviderIds Identifiers of the item with each metadata provider.
+	ProviderIds map[string]string `json:"providerIds"`
+
+	// ReleaseDate Release or air date, formatted as YYYY-MM-DD.
+	ReleaseDate  *string `json:"releaseDate,omitempty"`
+	SeasonNumber *int    `json:"seasonNumber,omitempty"`
+
+	// Status Production status of a show.
+	Status *string `json:"status,omitempty"`
+
+	// WatchState The current user's progress through an item.
+	WatchState *WatchState `json:"watchState,omitempty"`
+	Year       *int        `json:"year,omitempty"`
 }
 
-type MiddlewareFunc func(http.Handler) http.Handler
+// ItemEvent An item that was added, updated or removed.
+type ItemEvent struct {
+	Id        openapi_types.UUID `json:"id"`
+	Kind      *ItemKind          `json:"kind,omitempty"`
+	LibraryId openapi_types.UUID `json:"libraryId"`
 
-// ConnectPluginTransport operation middleware
-func (siw *ServerInterfaceWrapper) ConnectPluginTransport(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+	// ParentId The show of a season, or the season of an episode.
+	ParentId *openapi_types.UUID `json:"parentId,omitempty"`
+}
 
-	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.ConnectPluginTransport(w, r)
-	}))
+// ItemKind defines model for ItemKind.
+type ItemKind string
 
-	for _, middleware := range siw.HandlerMiddlewares {
-		handler = middleware(handler)
-	}
+// ItemList defines model for ItemList.
+type ItemList struct {
+	Items []Item `json:"items"`
+}
 
-	handler.ServeHTTP(w, r.WithContext(ctx))
+// ItemPage defines model for ItemPage.
+type ItemPage struct {
+	Items []Item `json:"items"`
+
+	// NextCursor Cursor for the next page. Omitted on the last page.
+	NextCursor *string `json:"nextCursor,omitempty"`
 }
 
-// GetTranscodeManifestM3u8 operation middleware
-func (siw *ServerInterfaceWrapper) GetTranscodeManifestM3u8(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+// ItemSort defines model for ItemSort.
+type ItemSort string
 
-	var err error
+// Library defines model for Library.
+type Library struct {
+	Created time.Time          `json:"created"`
+	Id      openapi_types.UUID `json:"id"`
+	Name    string             `json:"name"`
 
-	// ------------- Path parameter "transcodeId" -------------
-	var transcodeId openapi_types.UUID
+	// OnlineLookup Whether online metadata providers are queried for this library.
+	OnlineLookup bool `json:"onlineLookup"`
 
-	err = runtime.BindStyledParameterWithOptions("simple", "transcodeId", chi.URLParam(r, "transcodeId"), &transcodeId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
-	if err != nil {
-		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "transcodeId", Err: err})
-		return
-	}
+	// Root Absolute path of the library root on the server.
+	Root string `json:"root"`
+}
 
-	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.GetTranscodeManifestM3u8(w, r, transcodeId)
-	}))
+// LibraryList defines model for LibraryList.
+type LibraryList struct {
+	Libraries []Library `json:"libraries"`
+}
 
-	for _, middleware := range siw.HandlerMiddlewares {
-		handler = middleware(handler)
-	}
+// LoginRequest defines model for LoginRequest.
+type LoginRequest struct {
+	Password string `json:"password"`
+	Username string `json:"username"`
+}
 
-	handler.ServeHTTP(w, r.WithContext(ctx))
+// LoginResponse defines model for LoginResponse.
+type LoginResponse struct {
+	// Expires When the session expires if unused. Each use extends it.
+	Expires time.Time `json:"expires"`
+
+	// Token Session token, to be sent as a bearer token.
+	Token string `json:"token"`
+	User  User   `json:"user"`
 }
 
-// GetTranscodeSegment operation middleware
-func (siw *ServerInterfaceWrapper) GetTranscodeSegment(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+// PairedDevice defines model for PairedDevice.
+type PairedDevice struct {
+	Device Device `json:"device"`
 
-	var err error
+	// LinkKey X25519 public key of the server, base64 encoded. Only set when the device provided a link key, which together
+	// with this key derives the link secret.
+	LinkKey *[]byte `json:"linkKey,omitempty"`
 
-	// ------------- Path parameter "transcodeId" -------------
-	var transcodeId openapi_types.UUID
+	// Token Device token, to be sent as a bearer token. It does not expire, but can be revoked.
+	Token string `json:"token"`
+	User  User   `json:"user"`
+}
 
-	err = runtime.BindStyledParameterWithOptions("simple", "transcodeId", chi.URLParam(r, "transcodeId"), &transcodeId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
-	if err != nil {
-		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "transcodeId", Err: err})
-		return
-	}
+// Pairing defines model for Pairing.
+type Pairing struct {
+	// Code Code to display, formatted for reading.
+	Code    string    `json:"code"`
+	Expires time.Time `json:"expires"`
 
-	// ------------- Path parameter "segment" -------------
-	var segment string
+	// PollInterval Seconds to wait between polls.
+	PollInterval int `json:"pollInterval"`
 
-	err = runtime.BindStyledParameterWithOptions("simple", "segment", chi.URLParam(r, "segment"), &segment, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
-	if err != nil {
-		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "segment", Err: err})
-		return
-	}
+	// PollToken Secret used to poll for the device token. Must not be displayed.
+	PollToken string `json:"pollToken"`
+}
 
-	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.GetTranscodeSegment(w, r, transcodeId, segment)
-	}))
+// PlaybackPlan Whether the source is played as is, or transcoded.
+type PlaybackPlan string
 
-	for _, middleware := range siw.HandlerMiddlewares {
-		handler = middleware(handler)
-	}
+// PlaybackSession defines model for PlaybackSession.
+type PlaybackSession struct {
+	Id openapi_types.UUID `json:"id"`
 
-	handler.ServeHTTP(w, r.WithContext(ctx))
+	// Manifest Path of the HLS manifest, relative to the API root. Only set when transcoding.
+	Manifest *string `json:"manifest,omitempty"`
+
+	// Plan Whether the source is played as is, or transcoded.
+	Plan PlaybackPlan `json:"plan"`
+
+	// Stream Path of the original file, relative to the API root. Only set when playing directly.
+	Stream *string `json:"stream,omitempty"`
 }
 
-type UnescapedCookieParamError struct {
-	ParamName string
-	Err       error
+// PlaybackSessionList defines model for PlaybackSessionList.
+type PlaybackSessionList struct {
+	Items []PlaybackSessionStatus `json:"items"`
 }
 
-func (e *UnescapedCookieParamError) Error() string {
-	return fmt.Sprintf("error unescaping cookie parameter '%s'", e.ParamName)
+// PlaybackSessionStatus defines model for PlaybackSessionStatus.
+type PlaybackSessionStatus struct {
+	// Bandwidth Bytes per second served to the client, averaged over the last few seconds.
+	Bandwidth float64 `json:"bandwidth"`
+
+	// DeviceId Paired device playing the item, if any.
+	DeviceId   *openapi_types.UUID `json:"deviceId,omitempty"`
+	DeviceName *string             `json:"deviceName,omitempty"`
+	Id         openapi_types.UUID  `json:"id"`
+
+	// Item Summary of an item, as shown in lists.
+	Item Item `json:"item"`
+
+	// LastActive When the client last requested media.
+	LastActive time.Time `json:"lastActive"`
+
+	// Plan Whether the source is played as is, or transcoded.
+	Plan PlaybackPlan `json:"plan"`
+
+	// RemoteAddress Address of the client's most recent request.
+	RemoteAddress string `json:"remoteAddress"`
+
+	// Segment Number of the segment being transcoded, starting from 1. Only set when transcoding.
+	Segment *int      `json:"segment,omitempty"`
+	Started time.Time `json:"started"`
+
+	// TranscodeSpeed Seconds of media transcoded per second. Only set when transcoding.
+	TranscodeSpeed *float64 `json:"transcodeSpeed,omitempty"`
+	User           User     `json:"user"`
 }
 
-func (e *UnescapedCookieParamError) Unwrap() error {
-	return e.Err
+// Plugin defines model for Plugin.
+type Plugin struct {
+	// Auth How the plugin authenticates.
+	Auth PluginAuth `json:"auth"`
+
+	// Connection A connected plugin, as described by the plugin itself.
+	Connection *PluginConnection  `json:"connection,omitempty"`
+	Created    time.Time          `json:"created"`
+	Enabled    bool               `json:"enabled"`
+	Id         openapi_types.UUID `json:"id"`
+
+	// LastSeen When the plugin last connected. Missing if it never has.
+	LastSeen *time.Time    `json:"lastSeen,omitempty"`
+	Name     string        `json:"name"`
+	Scopes   []PluginScope `json:"scopes"`
 }
 
-type UnmarshalingParamError struct {
-	ParamName string
-	Err       error
+// PluginAuth How the plugin authenticates.
+type PluginAuth string
+
+// PluginConnection A connected plugin, as described by the plugin itself.
+type PluginConnection struct {
+	Connected     time.Time `json:"connected"`
+	Name          string    `json:"name"`
+	RemoteAddress string    `json:"remoteAddress"`
+
+	// Scopes Scopes declared by the plugin, which are those granted to the connection.
+	Scopes  []PluginScope `json:"scopes"`
+	Version string        `json:"version"`
 }
 
-func (e *UnmarshalingParamError) Error() string {
-	return fmt.Sprintf("Error unmarshaling parameter %s as JSON: %s", e.ParamName, e.Err.Error())
+// PluginList defines model for PluginList.
+type PluginList struct {
+	Plugins []Plugin `json:"plugins"`
 }
 
-func (e *UnmarshalingParamError) Unwrap() error {
-	return e.Err
+// PluginScope Permission granted to a plugin. library:read allows reading libraries and items, metadata:write allows changing
+// item metadata, playback:events allows receiving playback notifications and metadata:source allows the server to
+// use the plugin as a metadata source.
+type PluginScope string
+
+// PollPairingRequest defines model for PollPairingRequest.
+type PollPairingRequest struct {
+	PollToken string `json:"pollToken"`
 }
 
-type RequiredParamError struct {
-	ParamName string
+// ProgressReport defines model for ProgressReport.
+type ProgressReport struct {
+	AudioTrack *int `json:"audioTrack,omitempty"`
+
+	// Duration Duration of the item in seconds.
+	Duration float64 `json:"duration"`
+	Paused   *bool   `json:"paused,omitempty"`
+
+	// Position Playback position in seconds.
+	Position float64 `json:"position"`
+
+	// SubtitleTrack Omitted when subtitles are off.
+	SubtitleTrack *int `json:"subtitleTrack,omitempty"`
 }
 
-func (e *RequiredParamError) Error() string {
-	return fmt.Sprintf("Query argument %s is required, but not found", e.ParamName)
+// RegisterPluginRequest defines model for RegisterPluginRequest.
+type RegisterPluginRequest struct {
+	Name string `json:"name"`
+
+	// PublicKey Base64 encoded Ed25519 public key, for plugins authenticating with signed tokens.
+	PublicKey *[]byte       `json:"publicKey,omitempty"`
+	Scopes    []PluginScope `json:"scopes"`
 }
 
-type RequiredHeaderError struct {
-	ParamName string
-	Err       error
+// RegisteredPlugin defines model for RegisteredPlugin.
+type RegisteredPlugin struct {
+	// ApiKey API key of the plugin, when it was not registered with a public key.
+	ApiKey *string `json:"apiKey,omitempty"`
+	Plugin Plugin  `json:"plugin"`
 }
 
-func (e *RequiredHeaderError) Error() string {
-	return fmt.Sprintf("Header parameter %s is required, but not found", e.ParamName)
+// ScanEvent Progress of a library scan, published at most once a second while running.
+type ScanEvent struct {
+	// Failed Files that could not be imported.
+	Failed int `json:"failed"`
+
+	// Imported New or changed files imported so far.
+	Imported  int                `json:"imported"`
+	LibraryId openapi_types.UUID `json:"libraryId"`
+
+	// Missing Items whose files have gone, known once the scan completes.
+	Missing int `json:"missing"`
+
+	// Scanned Media files scanned so far.
+	Scanned int            `json:"scanned"`
+	State   ScanEventState `json:"state"`
 }
 
-func (e *RequiredHeaderError) Unwrap() error {
-	return e.Err
+// ScanEventState defines model for ScanEvent.State.
+type ScanEventState string
+
+// SearchResults Search results, keyed by media type. Shows and episodes are both tv.
+type SearchResults struct {
+	// Fuzzy Set when nothing matched as typed, and the results are for similar spellings.
+	Fuzzy bool   `json:"fuzzy"`
+	Movie []Item `json:"movie"`
+	Tv    []Item `json:"tv"`
 }
 
-type InvalidParamFormatError struct {
-	ParamName string
-	Err       error
+// ServerAddress defines model for ServerAddress.
+type ServerAddress struct {
+	// Http3 Whether HTTP/3 is also served on the port.
+	Http3 bool `json:"http3"`
+
+	// Ip IP address the URL resolves to. Not set for domain addresses.
+	Ip *string `json:"ip,omitempty"`
+
+	// Scope Where an address can be reached from. Domain addresses use a domain configured for ACME certificates.
+	Scope AddressScope `json:"scope"`
+	Url   string       `json:"url"`
 }
 
-func (e *InvalidParamFormatError) Error() string {
-	return fmt.Sprintf("Invalid format for parameter %s: %s", e.ParamName, e.Err.Error())
+// ServerAddressList defines model for ServerAddressList.
+type ServerAddressList struct {
+	Items []ServerAddress `json:"items"`
 }
 
-func (e *InvalidParamFormatError) Unwrap() error {
-	return e.Err
+// ServerEvent Something that happened on the server. Only the property matching the type is set.
+type ServerEvent struct {
+	// Certificate A certificate that was issued or renewed.
+	Certificate *CertificateEvent `json:"certificate,omitempty"`
+	Dropped     *DroppedEvent     `json:"dropped,omitempty"`
+
+	// Id Numbers events in the order they were published, restarting with the server. Missing for dropped events.
+	Id *int64 `json:"id,omitempty"`
+
+	// Item An item that was added, updated or removed.
+	Item *ItemEvent `json:"item,omitempty"`
+
+	// Scan Progress of a library scan, published at most once a second while running.
+	Scan *ScanEvent `json:"scan,omitempty"`
+
+	// Session A playback session that started or stopped.
+	Session *SessionEvent `json:"session,omitempty"`
+	Time    time.Time     `json:"time"`
+	Type    EventType     `json:"type"`
 }
 
-type TooManyValuesForParamError struct {
-	ParamName string
-	Count     int
+// SessionEvent A playback session that started or stopped.
+type SessionEvent struct {
+	DeviceId *openapi_types.UUID `json:"deviceId,omitempty"`
+	Id       openapi_types.UUID  `json:"id"`
+	ItemId   openapi_types.UUID  `json:"itemId"`
+
+	// Plan Whether the source is played as is, or transcoded.
+	Plan PlaybackPlan `json:"plan"`
+
+	// Reason Why the session stopped. Failed sessions stopped because their transcode failed.
+	Reason *SessionEventReason `json:"reason,omitempty"`
+	UserId openapi_types.UUID  `json:"userId"`
 }
 
-func (e *TooManyValuesForParamError) Error() string {
-	return fmt.Sprintf("Expected one value for %s, got %d", e.ParamName, e.Count)
+// SessionEventReason Why the session stopped. Failed sessions stopped because their transcode failed.
+type SessionEventReason string
+
+// SortOrder defines model for SortOrder.
+type SortOrder string
+
+// StartPairingRequest defines model for StartPairingRequest.
+type StartPairingRequest struct {
+	// DeviceName Name to show in the list of paired devices.
+	DeviceName string `json:"deviceName"`
+
+	// LinkKey X25519 public key of the device, base64 encoded. When provided, a secret is negotiated for the encrypted link
+	// protocol, which is served at `/v1`.
+	LinkKey *[]byte `json:"linkKey,omitempty"`
 }
 
-// Handler creates http.Handler with routing matching OpenAPI spec.
-func Handler(si ServerInterface) http.Handler {
-	return HandlerWithOptions(si, ChiServerOptions{})
+// StartPlaybackRequest defines model for StartPlaybackRequest.
+type StartPlaybackRequest struct {
+	// VideoCodecs Video codecs the client can decode, using ffmpeg codec names such as h264 and hevc.
+	VideoCodecs []string `json:"videoCodecs"`
 }
 
-type ChiServerOptions struct {
-	BaseURL          string
-	BaseRouter       chi.Router
-	Middlewares      []MiddlewareFunc
-	ErrorHandlerFunc func(w http.ResponseWriter, r *http.Request, err error)
+// UpdateLibraryRequest defines model for UpdateLibraryRequest.
+type UpdateLibraryRequest struct {
+	Name         *string `json:"name,omitempty"`
+	OnlineLookup *bool   `json:"onlineLookup,omitempty"`
 }
 
-// HandlerFromMux creates http.Handler with routing matching OpenAPI spec based on the provided mux.
-func HandlerFromMux(si ServerInterface, r chi.Router) http.Handler {
-	return HandlerWithOptions(si, ChiServerOptions{
-		BaseRouter: r,
-	})
+// UpdatePluginRequest defines model for UpdatePluginRequest.
+type UpdatePluginRequest struct {
+	Enabled *bool          `json:"enabled,omitempty"`
+	Name    *string        `json:"name,omitempty"`
+	Scopes  *[]PluginScope `json:"scopes,omitempty"`
 }
 
-func HandlerFromMuxWithBaseURL(si ServerInterface, r chi.Router, baseURL string) http.Handler {
-	return HandlerWithOptions(si, ChiServerOptions{
-		BaseURL:    baseURL,
-		BaseRouter: r,
-	})
+// UpdateUserRequest defines model for UpdateUserRequest.
+type UpdateUserRequest struct {
+	Password *string   `json:"password,omitempty"`
+	Role     *UserRole `json:"role,omitempty"`
+}
+
+// User defines model for User.
+type User struct {
+	Created  time.Time          `json:"created"`
+	Id       openapi_types.UUID `json:"id"`
+	Role     UserRole           `json:"role"`
+	Username string             `json:"username"`
+}
+
+// UserList defines model for UserList.
+type UserList struct {
+	Users []User `json:"users"`
+}
+
+// UserRole defines model for UserRole.
+type UserRole string
+
+// WatchState The current user's progress through an item.
+type WatchState struct {
+	// AudioTrack Audio track last played, to restore when resuming.
+	AudioTrack *int `json:"audioTrack,omitempty"`
+
+	// Duration Duration in seconds, as last reported by a player.
+	Duration   *float64   `json:"duration,omitempty"`
+	LastPlayed *time.Time `json:"lastPlayed,omitempty"`
+
+	// PlayCount Number of times a movie or episode has been watched to the end.
+	PlayCount int `json:"playCount"`
+
+	// Position Resume point in seconds. Omitted when playback should start from the beginning.
+	Position *float64 `json:"position,omitempty"`
+
+	// SubtitleTrack Subtitle track last shown, to restore when resuming.
+	SubtitleTrack *int `json:"subtitleTrack,omitempty"`
+
+	// UnwatchedEpisodes Number of available episodes of a show or season not yet watched.
+	UnwatchedEpisodes *int `json:"unwatchedEpisodes,omitempty"`
+
+	// Watched Set for shows and seasons once every episode has been watched.
+	Watched bool `json:"watched"`
+}
+
+// AddedSince defines model for AddedSince.
+type AddedSince = time.Time
+
+// Cursor defines model for Cursor.
+type Cursor = string
+
+// FeedLimit defines model for FeedLimit.
+type FeedLimit = int
+
+// Genre defines model for Genre.
+type Genre = string
+
+// ItemId Item ID.
+type ItemId = openapi_types.UUID
+
+// LibraryFilter defines model for LibraryFilter.
+type LibraryFilter = openapi_types.UUID
+
+// LibraryId Library ID.
+type LibraryId = openapi_types.UUID
+
+// Limit defines model for Limit.
+type Limit = int
+
+// Order defines model for Order.
+type Order = SortOrder
+
+// PluginId Plugin ID.
+type PluginId = openapi_types.UUID
+
+// Sort defines model for Sort.
+type Sort = ItemSort
+
+// Unwatched defines model for Unwatched.
+type Unwatched = bool
+
+// UserId User ID.
+type UserId = openapi_types.UUID
+
+// Year defines model for Year.
+type Year = int
+
+// ContinueWatchingParams defines parameters for ContinueWatching.
+type ContinueWatchingParams struct {
+	// LibraryId Only return items from this library.
+	LibraryId *LibraryFilter `form:"libraryId,omitempty" json:"libraryId,omitempty"`
+
+	// Limit Maximum number of items to return.
+	Limit *FeedLimit `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// StreamEventsParams defines parameters for StreamEvents.
+type StreamEventsParams struct {
+	// Types Types of event to receive. Every type is sent when omitted.
+	Types *[]EventType `form:"types,omitempty" json:"types,omitempty"`
+}
+
+// ListHistoryParams defines parameters for ListHistory.
+type ListHistoryParams struct {
+	// Cursor Opaque cursor from a previous page.
+	Cursor *Cursor `form:"cursor,omitempty" json:"cursor,omitempty"`
+
+	// Limit Maximum number of items to return.
+	Limit *Limit `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// GetImageParams defines parameters for GetImage.
+type GetImageParams struct {
+	// Width Desired width in pixels. The width is rounded up to a supported size and images are never upscaled.
+	Width *int `form:"width,omitempty" json:"width,omitempty"`
+
+	// Format Output format. When omitted, WebP is used if the Accept header allows it, otherwise JPEG.
+	Format *GetImageParamsFormat `form:"format,omitempty" json:"format,omitempty"`
+}
+
+// GetImageParamsFormat defines parameters for GetImage.
+type GetImageParamsFormat string
+
+// StreamItemParams defines parameters for StreamItem.
+type StreamItemParams struct {
+	// Session Playback session the stream belongs to, keeping the session alive and counting its bandwidth.
+	Session *openapi_types.UUID `form:"session,omitempty" json:"session,omitempty"`
+}
+
+// ListMoviesParams defines parameters for ListMovies.
+type ListMoviesParams struct {
+	// Genre Only return items with this genre.
+	Genre *Genre `form:"genre,omitempty" json:"genre,omitempty"`
+
+	// Year Only return items released in this year.
+	Year *Year `form:"year,omitempty" json:"year,omitempty"`
+
+	// Unwatched Only return items that have not been watched.
+	Unwatched *Unwatched `form:"unwatched,omitempty" json:"unwatched,omitempty"`
+
+	// AddedSince Only return items added at or after this time. Combine with sorting by added for recently added rows.
+	AddedSince *AddedSince `form:"addedSince,omitempty" json:"addedSince,omitempty"`
+
+	// Sort Sort field. Shows sort by when their latest episode was added when sorting by added.
+	Sort *Sort `form:"sort,omitempty" json:"sort,omitempty"`
+
+	// Order Sort direction.
+	Order *Order `form:"order,omitempty" json:"order,omitempty"`
+
+	// Cursor Opaque cursor from a previous page.
+	Cursor *Cursor `form:"cursor,omitempty" json:"cursor,omitempty"`
+
+	// Limit Maximum number of items to return.
+	Limit *Limit `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// ListShowsParams defines parameters for ListShows.
+type ListShowsParams struct {
+	// Genre Only return items with this genre.
+	Genre *Genre `form:"genre,omitempty" json:"genre,omitempty"`
+
+	// Year Only return items released in this year.
+	Year *Year `form:"year,omitempty" json:"year,omitempty"`
+
+	// Unwatched Only return items that have not been watched.
+	Unwatched *Unwatched `form:"unwatched,omitempty" json:"unwatched,omitempty"`
+
+	// AddedSince Only return items added at or after this time. Combine with sorting by added for recently added rows.
+	AddedSince *AddedSince `form:"addedSince,omitempty" json:"addedSince,omitempty"`
+
+	// Sort Sort field. Shows sort by when their latest episode was added when sorting by added.
+	Sort *Sort `form:"sort,omitempty" json:"sort,omitempty"`
+
+	// Order Sort direction.
+	Order *Order `form:"order,omitempty" json:"order,omitempty"`
+
+	// Cursor Opaque cursor from a previous page.
+	Cursor *Cursor `form:"cursor,omitempty" json:"cursor,omitempty"`
+
+	// Limit Maximum number of items to return.
+	Limit *Limit `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// NextUpParams defines parameters for NextUp.
+type NextUpParams struct {
+	// LibraryId Only return items from this library.
+	LibraryId *LibraryFilter `form:"libraryId,omitempty" json:"libraryId,omitempty"`
+
+	// Limit Maximum number of items to return.
+	Limit *FeedLimit `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// SearchParams defines parameters for Search.
+type SearchParams struct {
+	// Query Search text.
+	Query string `form:"query" json:"query"`
+
+	// LibraryId Only search within this library.
+	LibraryId *openapi_types.UUID `form:"libraryId,omitempty" json:"libraryId,omitempty"`
+
+	// Limit Maximum number of items to return across all groups.
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// SetDsdmServerJSONRequestBody defines body for SetDsdmServer for application/json ContentType.
+type SetDsdmServerJSONRequestBody = DSDMServer
+
+// RegisterPluginJSONRequestBody defines body for RegisterPlugin for application/json ContentType.
+type RegisterPluginJSONRequestBody = RegisterPluginRequest
+
+// UpdatePluginJSONRequestBody defines body for UpdatePlugin for application/json ContentType.
+type UpdatePluginJSONRequestBody = UpdatePluginRequest
+
+// LoginJSONRequestBody defines body for Login for application/json ContentType.
+type LoginJSONRequestBody = LoginRequest
+
+// StartPlaybackJSONRequestBody defines body for StartPlayback for application/json ContentType.
+type StartPlaybackJSONRequestBody = StartPlaybackRequest
+
+// ReportProgressJSONRequestBody defines body for ReportProgress for application/json ContentType.
+type ReportProgressJSONRequestBody = ProgressReport
+
+// CreateLibraryJSONRequestBody defines body for CreateLibrary for application/json ContentType.
+type CreateLibraryJSONRequestBody = CreateLibraryRequest
+
+// UpdateLibraryJSONRequestBody defines body for UpdateLibrary for application/json ContentType.
+type UpdateLibraryJSONRequestBody = UpdateLibraryRequest
+
+// StartPairingJSONRequestBody defines body for StartPairing for application/json ContentType.
+type StartPairingJSONRequestBody = StartPairingRequest
+
+// ApprovePairingJSONRequestBody defines body for ApprovePairing for application/json ContentType.
+type ApprovePairingJSONRequestBody = ApprovePairingRequest
+
+// PollPairingJSONRequestBody defines body for PollPairing for application/json ContentType.
+type PollPairingJSONRequestBody = PollPairingRequest
+
+// CreateUserJSONRequestBody defines body for CreateUser for application/json ContentType.
+type CreateUserJSONRequestBody = CreateUserRequest
+
+// UpdateUserJSONRequestBody defines body for UpdateUser for application/json ContentType.
+type UpdateUserJSONRequestBody = UpdateUserRequest
+
+// ServerInterface represents all server handlers.
+type ServerInterface interface {
+	// List DSDM Servers
+	// (GET /admin/dsdm-servers)
+	ListDsdmServers(w http.ResponseWriter, r *http.Request)
+	// Set DSDM Server
+	// (PUT /admin/dsdm-servers)
+	SetDsdmServer(w http.ResponseWriter, r *http.Request)
+	// List Plugins
+	// (GET /admin/plugins)
+	ListPlugins(w http.ResponseWriter, r *http.Request)
+	// Register Plugin
+	// (POST /admin/plugins)
+	RegisterPlugin(w http.ResponseWriter, r *http.Request)
+	// Revoke Plugin
+	// (DELETE /admin/plugins/{pluginId})
+	RevokePlugin(w http.ResponseWriter, r *http.Request, pluginId PluginId)
+	// Update Plugin
+	// (PATCH /admin/plugins/{pluginId})
+	UpdatePlugin(w http.ResponseWriter, r *http.Request, pluginId PluginId)
+	// List Playback Sessions
+	// (GET /admin/sessions)
+	ListPlaybackSessions(w http.ResponseWriter, r *http.Request)
+	// Terminate Playback Session
+	// (DELETE /admin/sessions/{sessionId})
+	TerminatePlaybackSession(w http.ResponseWriter, r *http.Request, sessionId openapi_types.UUID)
+	// Login
+	// (POST /auth/login)
+	Login(w http.ResponseWriter, r *http.Request)
+	// Logout
+	// (POST /auth/logout)
+	Logout(w http.ResponseWriter, r *http.Request)
+	// Get Current User
+	// (GET /auth/me)
+	GetCurrentUser(w http.ResponseWriter, r *http.Request)
+	// Continue Watching
+	// (GET /continue-watching)
+	ContinueWatching(w http.ResponseWriter, r *http.Request, params ContinueWatchingParams)
+	// List Devices
+	// (GET /devices)
+	ListDevices(w http.ResponseWriter, r *http.Request)
+	// Revoke Device
+	// (DELETE /devices/{deviceId})
+	RevokeDevice(w http.ResponseWriter, r *http.Request, deviceId openapi_types.UUID)
+	// Stream Events
+	// (GET /events)
+	StreamEvents(w http.ResponseWriter, r *http.Request, params StreamEventsParams)
+	// Watch History
+	// (GET /history)
+	ListHistory(w http.ResponseWriter, r *http.Request, params ListHistoryParams)
+	// Get Image
+	// (GET /images/{imageId})
+	GetImage(w http.ResponseWriter, r *http.Request, imageId openapi_types.UUID, params GetImageParams)
+	// Get Item
+	// (GET /items/{itemId})
+	GetItem(w http.ResponseWriter, r *http.Request, itemId ItemId)
+	// Start Playback
+	// (POST /items/{itemId}/playback)
+	StartPlayback(w http.ResponseWriter, r *http.Request, itemId ItemId)
+	// Report Progress
+	// (POST /items/{itemId}/progress)
+	ReportProgress(w http.ResponseWriter, r *http.Request, itemId ItemId)
+	// Refresh Item
+	// (POST /items/{itemId}/refresh)
+	RefreshItem(w http.ResponseWriter, r *http.Request, itemId ItemId)
+	// Stream Item
+	// (GET /items/{itemId}/stream)
+	StreamItem(w http.ResponseWriter, r *http.Request, itemId ItemId, params StreamItemParams)
+	// Mark Unwatched
+	// (DELETE /items/{itemId}/watched)
+	MarkUnwatched(w http.ResponseWriter, r *http.Request, itemId ItemId)
+	// Mark Watched
+	// (PUT /items/{itemId}/watched)
+	MarkWatched(w http.ResponseWriter, r *http.Request, itemId ItemId)
+	// List Libraries
+	// (GET /libraries)
+	ListLibraries(w http.ResponseWriter, r *http.Request)
+	// Create Library
+	// (POST /libraries)
+	CreateLibrary(w http.ResponseWriter, r *http.Request)
+	// Get Library
+	// (GET /libraries/{libraryId})
+	GetLibrary(w http.ResponseWriter, r *http.Request, libraryId LibraryId)
+	// Update Library
+	// (PATCH /libraries/{libraryId})
+	UpdateLibrary(w http.ResponseWriter, r *http.Request, libraryId LibraryId)
+	// List Genres
+	// (GET /libraries/{libraryId}/genres)
+	ListGenres(w http.ResponseWriter, r *http.Request, libraryId LibraryId)
+	// List Movies
+	// (GET /libraries/{libraryId}/movies)
+	ListMovies(w http.ResponseWriter, r *http.Request, libraryId LibraryId, params ListMoviesParams)
+	// Scan Library
+	// (POST /libraries/{libraryId}/scan)
+	ScanLibrary(w http.ResponseWriter, r *http.Request, libraryId LibraryId)
+	// List Shows
+	// (GET /libraries/{libraryId}/shows)
+	ListShows(w http.ResponseWriter, r *http.Request, libraryId LibraryId, params ListShowsParams)
+	// Next Up
+	// (GET /next-up)
+	NextUp(w http.ResponseWriter, r *http.Request, params NextUpParams)
+	// Start Pairing
+	// (POST /pairing)
+	StartPairing(w http.ResponseWriter, r *http.Request)
+	// Approve Pairing
+	// (POST /pairing/approve)
+	ApprovePairing(w http.ResponseWriter, r *http.Request)
+	// Poll Pairing
+	// (POST /pairing/poll)
+	PollPairing(w http.ResponseWriter, r *http.Request)
+	// Plugin Transport Connect
+	// (CONNECT /plugin/transport)
+	ConnectPluginTransport(w http.ResponseWriter, r *http.Request)
+	// Search
+	// (GET /search)
+	Search(w http.ResponseWriter, r *http.Request, params SearchParams)
+	// List Episodes
+	// (GET /seasons/{itemId}/episodes)
+	ListEpisodes(w http.ResponseWriter, r *http.Request, itemId ItemId)
+	// List Server Addresses
+	// (GET /server/addresses)
+	ListServerAddresses(w http.ResponseWriter, r *http.Request)
+	// List Seasons
+	// (GET /shows/{itemId}/seasons)
+	ListSeasons(w http.ResponseWriter, r *http.Request, itemId ItemId)
+	// Transcode Manifest M3U8
+	// (GET /transcode/{transcodeId}/manifest.m3u8)
+	GetTranscodeManifestM3u8(w http.ResponseWriter, r *http.Request, transcodeId openapi_types.UUID)
+	// Transcode Segment
+	// (GET /transcode/{transcodeId}/{segment})
+	GetTranscodeSegment(w http.ResponseWriter, r *http.Request, transcodeId openapi_types.UUID, segment string)
+	// List Users
+	// (GET /users)
+	ListUsers(w http.ResponseWriter, r *http.Request)
+	// Create User
+	// (POST /users)
+	CreateUser(w http.ResponseWriter, r *http.Request)
+	// Delete User
+	// (DELETE /users/{userId})
+	DeleteUser(w http.ResponseWriter, r *http.Request, userId UserId)
+	// Update User
+	// (PATCH /users/{userId})
+	UpdateUser(w http.ResponseWriter, r *http.Request, userId UserId)
+}
+
+// Unimplemented server implementation that returns http.StatusNotImplemented for each endpoint.
+
+type Unimplemented struct{}
+
+// List DSDM Servers
+// (GET /admin/dsdm-servers)
+func (_ Unimplemented) ListDsdmServers(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Set DSDM Server
+// (PUT /admin/dsdm-servers)
+func (_ Unimplemented) SetDsdmServer(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// List Plugins
+// (GET /admin/plugins)
+func (_ Unimplemented) ListPlugins(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Register Plugin
+// (POST /admin/plugins)
+func (_ Unimplemented) RegisterPlugin(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Revoke Plugin
+// (DELETE /admin/plugins/{pluginId})
+func (_ Unimplemented) RevokePlugin(w http.ResponseWriter, r *http.Request, pluginId PluginId) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Update Plugin
+// (PATCH /admin/plugins/{pluginId})
+func (_ Unimplemented) UpdatePlugin(w http.ResponseWriter, r *http.Request, pluginId PluginId) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// List Playback Sessions
+// (GET /admin/sessions)
+func (_ Unimplemented) ListPlaybackSessions(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Terminate Playback Session
+// (DELETE /admin/sessions/{sessionId})
+func (_ Unimplemented) TerminatePlaybackSession(w http.ResponseWriter, r *http.Request, sessionId openapi_types.UUID) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Login
+// (POST /auth/login)
+func (_ Unimplemented) Login(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Logout
+// (POST /auth/logout)
+func (_ Unimplemented) Logout(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Get Current User
+// (GET /auth/me)
+func (_ Unimplemented) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Continue Watching
+// (GET /continue-watching)
+func (_ Unimplemented) ContinueWatching(w http.ResponseWriter, r *http.Request, params ContinueWatchingParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// List Devices
+// (GET /devices)
+func (_ Unimplemented) ListDevices(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Revoke Device
+// (DELETE /devices/{deviceId})
+func (_ Unimplemented) RevokeDevice(w http.ResponseWriter, r *http.Request, deviceId openapi_types.UUID) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Stream Events
+// (GET /events)
+func (_ Unimplemented) StreamEvents(w http.ResponseWriter, r *http.Request, params StreamEventsParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Watch History
+// (GET /history)
+func (_ Unimplemented) ListHistory(w http.ResponseWriter, r *http.Request, params ListHistoryParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Get Image
+// (GET /images/{imageId})
+func (_ Unimplemented) GetImage(w http.ResponseWriter, r *http.Request, imageId openapi_types.UUID, params GetImageParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Get Item
+// (GET /items/{itemId})
+func (_ Unimplemented) GetItem(w http.ResponseWriter, r *http.Request, itemId ItemId) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Start Playback
+// (POST /items/{itemId}/playback)
+func (_ Unimplemented) StartPlayback(w http.ResponseWriter, r *http.Request, itemId ItemId) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Report Progress
+// (POST /items/{itemId}/progress)
+func (_ Unimplemented) ReportProgress(w http.ResponseWriter, r *http.Request, itemId ItemId) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Refresh Item
+// (POST /items/{itemId}/refresh)
+func (_ Unimplemented) RefreshItem(w http.ResponseWriter, r *http.Request, itemId ItemId) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Stream Item
+// (GET /items/{itemId}/stream)
+func (_ Unimplemented) StreamItem(w http.ResponseWriter, r *http.Request, itemId ItemId, params StreamItemParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Mark Unwatched
+// (DELETE /items/{itemId}/watched)
+func (_ Unimplemented) MarkUnwatched(w http.ResponseWriter, r *http.Request, itemId ItemId) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Mark Watched
+// (PUT /items/{itemId}/watched)
+func (_ Unimplemented) MarkWatched(w http.ResponseWriter, r *http.Request, itemId ItemId) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// List Libraries
+// (GET /libraries)
+func (_ Unimplemented) ListLibraries(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Create Library
+// (POST /libraries)
+func (_ Unimplemented) CreateLibrary(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Get Library
+// (GET /libraries/{libraryId})
+func (_ Unimplemented) GetLibrary(w http.ResponseWriter, r *http.Request, libraryId LibraryId) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Update Library
+// (PATCH /libraries/{libraryId})
+func (_ Unimplemented) UpdateLibrary(w http.ResponseWriter, r *http.Request, libraryId LibraryId) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// List Genres
+// (GET /libraries/{libraryId}/genres)
+func (_ Unimplemented) ListGenres(w http.ResponseWriter, r *http.Request, libraryId LibraryId) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// List Movies
+// (GET /libraries/{libraryId}/movies)
+func (_ Unimplemented) ListMovies(w http.ResponseWriter, r *http.Request, libraryId LibraryId, params ListMoviesParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Scan Library
+// (POST /libraries/{libraryId}/scan)
+func (_ Unimplemented) ScanLibrary(w http.ResponseWriter, r *http.Request, libraryId LibraryId) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// List Shows
+// (GET /libraries/{libraryId}/shows)
+func (_ Unimplemented) ListShows(w http.ResponseWriter, r *http.Request, libraryId LibraryId, params ListShowsParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Next Up
+// (GET /next-up)
+func (_ Unimplemented) NextUp(w http.ResponseWriter, r *http.Request, params NextUpParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Start Pairing
+// (POST /pairing)
+func (_ Unimplemented) StartPairing(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Approve Pairing
+// (POST /pairing/approve)
+func (_ Unimplemented) ApprovePairing(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Poll Pairing
+// (POST /pairing/poll)
+func (_ Unimplemented) PollPairing(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Plugin Transport Connect
+// (CONNECT /plugin/transport)
+func (_ Unimplemented) ConnectPluginTransport(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Search
+// (GET /search)
+func (_ Unimplemented) Search(w http.ResponseWriter, r *http.Request, params SearchParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// List Episodes
+// (GET /seasons/{itemId}/episodes)
+func (_ Unimplemented) ListEpisodes(w http.ResponseWriter, r *http.Request, itemId ItemId) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// List Server Addresses
+// (GET /server/addresses)
+func (_ Unimplemented) ListServerAddresses(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// List Seasons
+// (GET /shows/{itemId}/seasons)
+func (_ Unimplemented) ListSeasons(w http.ResponseWriter, r *http.Request, itemId ItemId) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Transcode Manifest M3U8
+// (GET /transcode/{transcodeId}/manifest.m3u8)
+func (_ Unimplemented) GetTranscodeManifestM3u8(w http.ResponseWriter, r *http.Request, transcodeId openapi_types.UUID) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Transcode Segment
+// (GET /transcode/{transcodeId}/{segment})
+func (_ Unimplemented) GetTranscodeSegment(w http.ResponseWriter, r *http.Request, transcodeId openapi_types.UUID, segment string) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// List Users
+// (GET /users)
+func (_ Unimplemented) ListUsers(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Create User
+// (POST /users)
+func (_ Unimplemented) CreateUser(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Delete User
+// (DELETE /users/{userId})
+func (_ Unimplemented) DeleteUser(w http.ResponseWriter, r *http.Request, userId UserId) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Update User
+// (PATCH /users/{userId})
+func (_ Unimplemented) UpdateUser(w http.ResponseWriter, r *http.Request, userId UserId) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// ServerInterfaceWrapper converts contexts to parameters.
+type ServerInterfaceWrapper struct {
+	Handler            ServerInterface
+	HandlerMiddlewares []MiddlewareFunc
+	ErrorHandlerFunc   func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+type MiddlewareFunc func(http.Handler) http.Handler
+
+// ListDsdmServers operation middleware
+func (siw *ServerInterfaceWrapper) ListDsdmServers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{"admin"})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ListDsdmServers(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// SetDsdmServer operation middleware
+func (siw *ServerInterfaceWrapper) SetDsdmServer(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{"admin"})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.SetDsdmServer(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// ListPlugins operation middleware
+func (siw *ServerInterfaceWrapper) ListPlugins(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{"admin"})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ListPlugins(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// RegisterPlugin operation middleware
+func (siw *ServerInterfaceWrapper) RegisterPlugin(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{"admin"})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.RegisterPlugin(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// RevokePlugin operation middleware
+func (siw *ServerInterfaceWrapper) RevokePlugin(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "pluginId" -------------
+	var pluginId PluginId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "pluginId", chi.URLParam(r, "pluginId"), &pluginId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "pluginId", Err: err})
+		return
+	}
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{"admin"})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.RevokePlugin(w, r, pluginId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// UpdatePlugin operation middleware
+func (siw *ServerInterfaceWrapper) UpdatePlugin(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "pluginId" -------------
+	var pluginId PluginId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "pluginId", chi.URLParam(r, "pluginId"), &pluginId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "pluginId", Err: err})
+		return
+	}
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{"admin"})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.UpdatePlugin(w, r, pluginId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// ListPlaybackSessions operation middleware
+func (siw *ServerInterfaceWrapper) ListPlaybackSessions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{"admin"})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ListPlaybackSessions(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// TerminatePlaybackSession operation middleware
+func (siw *ServerInterfaceWrapper) TerminatePlaybackSession(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "sessionId" -------------
+	var sessionId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "sessionId", chi.URLParam(r, "sessionId"), &sessionId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "sessionId", Err: err})
+		return
+	}
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{"admin"})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.TerminatePlaybackSession(w, r, sessionId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// Login operation middleware
+func (siw *ServerInterfaceWrapper) Login(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.Login(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// Logout operation middleware
+func (siw *ServerInterfaceWrapper) Logout(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.Logout(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// GetCurrentUser operation middleware
+func (siw *ServerInterfaceWrapper) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetCurrentUser(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// ContinueWatching operation middleware
+func (siw *ServerInterfaceWrapper) ContinueWatching(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ContinueWatchingParams
+
+	// ------------- Optional query parameter "libraryId" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "libraryId", r.URL.Query(), &params.LibraryId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "libraryId", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ContinueWatching(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// ListDevices operation middleware
+func (siw *ServerInterfaceWrapper) ListDevices(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ListDevices(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// RevokeDevice operation middleware
+func (siw *ServerInterfaceWrapper) RevokeDevice(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "deviceId" -------------
+	var deviceId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "deviceId", chi.URLParam(r, "deviceId"), &deviceId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "deviceId", Err: err})
+		return
+	}
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.RevokeDevice(w, r, deviceId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// StreamEvents operation middleware
+func (siw *ServerInterfaceWrapper) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{})
+
+	ctx = context.WithValue(ctx, AccessTokenScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params StreamEventsParams
+
+	// ------------- Optional query parameter "types" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "types", r.URL.Query(), &params.Types)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "types", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.StreamEvents(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// ListHistory operation middleware
+func (siw *ServerInterfaceWrapper) ListHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListHistoryParams
+
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", r.URL.Query(), &params.Cursor)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "cursor", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ListHistory(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// GetImage operation middleware
+func (siw *ServerInterfaceWrapper) GetImage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "imageId" -------------
+	var imageId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "imageId", chi.URLParam(r, "imageId"), &imageId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "imageId", Err: err})
+		return
+	}
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{})
+
+	ctx = context.WithValue(ctx, AccessTokenScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetImageParams
+
+	// ------------- Optional query parameter "width" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "width", r.URL.Query(), &params.Width)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "width", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "format" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "format", r.URL.Query(), &params.Format)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "format", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetImage(w, r, imageId, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// GetItem operation middleware
+func (siw *ServerInterfaceWrapper) GetItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "itemId" -------------
+	var itemId ItemId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "itemId", chi.URLParam(r, "itemId"), &itemId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "itemId", Err: err})
+		return
+	}
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetItem(w, r, itemId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// StartPlayback operation middleware
+func (siw *ServerInterfaceWrapper) StartPlayback(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "itemId" -------------
+	var itemId ItemId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "itemId", chi.URLParam(r, "itemId"), &itemId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "itemId", Err: err})
+		return
+	}
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.StartPlayback(w, r, itemId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// ReportProgress operation middleware
+func (siw *ServerInterfaceWrapper) ReportProgress(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "itemId" -------------
+	var itemId ItemId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "itemId", chi.URLParam(r, "itemId"), &itemId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "itemId", Err: err})
+		return
+	}
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ReportProgress(w, r, itemId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// RefreshItem operation middleware
+func (siw *ServerInterfaceWrapper) RefreshItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "itemId" -------------
+	var itemId ItemId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "itemId", chi.URLParam(r, "itemId"), &itemId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "itemId", Err: err})
+		return
+	}
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{"admin"})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.RefreshItem(w, r, itemId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// StreamItem operation middleware
+func (siw *ServerInterfaceWrapper) StreamItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "itemId" -------------
+	var itemId ItemId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "itemId", chi.URLParam(r, "itemId"), &itemId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "itemId", Err: err})
+		return
+	}
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{})
+
+	ctx = context.WithValue(ctx, AccessTokenScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params StreamItemParams
+
+	// ------------- Optional query parameter "session" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "session", r.URL.Query(), &params.Session)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "session", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.StreamItem(w, r, itemId, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// MarkUnwatched operation middleware
+func (siw *ServerInterfaceWrapper) MarkUnwatched(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "itemId" -------------
+	var itemId ItemId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "itemId", chi.URLParam(r, "itemId"), &itemId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "itemId", Err: err})
+		return
+	}
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.MarkUnwatched(w, r, itemId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// MarkWatched operation middleware
+func (siw *ServerInterfaceWrapper) MarkWatched(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "itemId" -------------
+	var itemId ItemId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "itemId", chi.URLParam(r, "itemId"), &itemId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "itemId", Err: err})
+		return
+	}
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.MarkWatched(w, r, itemId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// ListLibraries operation middleware
+func (siw *ServerInterfaceWrapper) ListLibraries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ListLibraries(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// CreateLibrary operation middleware
+func (siw *ServerInterfaceWrapper) CreateLibrary(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{"admin"})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.CreateLibrary(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// GetLibrary operation middleware
+func (siw *ServerInterfaceWrapper) GetLibrary(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "libraryId" -------------
+	var libraryId LibraryId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "libraryId", chi.URLParam(r, "libraryId"), &libraryId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "libraryId", Err: err})
+		return
+	}
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetLibrary(w, r, libraryId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// UpdateLibrary operation middleware
+func (siw *ServerInterfaceWrapper) UpdateLibrary(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "libraryId" -------------
+	var libraryId LibraryId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "libraryId", chi.URLParam(r, "libraryId"), &libraryId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "libraryId", Err: err})
+		return
+	}
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{"admin"})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.UpdateLibrary(w, r, libraryId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// ListGenres operation middleware
+func (siw *ServerInterfaceWrapper) ListGenres(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "libraryId" -------------
+	var libraryId LibraryId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "libraryId", chi.URLParam(r, "libraryId"), &libraryId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "libraryId", Err: err})
+		return
+	}
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ListGenres(w, r, libraryId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// ListMovies operation middleware
+func (siw *ServerInterfaceWrapper) ListMovies(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "libraryId" -------------
+	var libraryId LibraryId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "libraryId", chi.URLParam(r, "libraryId"), &libraryId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "libraryId", Err: err})
+		return
+	}
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListMoviesParams
+
+	// ------------- Optional query parameter "genre" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "genre", r.URL.Query(), &params.Genre)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "genre", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "year" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "year", r.URL.Query(), &params.Year)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "year", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "unwatched" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "unwatched", r.URL.Query(), &params.Unwatched)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "unwatched", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "addedSince" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "addedSince", r.URL.Query(), &params.AddedSince)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "addedSince", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "sort" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sort", r.URL.Query(), &params.Sort)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "sort", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "order" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "order", r.URL.Query(), &params.Order)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "order", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", r.URL.Query(), &params.Cursor)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "cursor", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ListMovies(w, r, libraryId, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// ScanLibrary operation middleware
+func (siw *ServerInterfaceWrapper) ScanLibrary(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "libraryId" -------------
+	var libraryId LibraryId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "libraryId", chi.URLParam(r, "libraryId"), &libraryId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "libraryId", Err: err})
+		return
+	}
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{"admin"})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ScanLibrary(w, r, libraryId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// ListShows operation middleware
+func (siw *ServerInterfaceWrapper) ListShows(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "libraryId" -------------
+	var libraryId LibraryId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "libraryId", chi.URLParam(r, "libraryId"), &libraryId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "libraryId", Err: err})
+		return
+	}
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListShowsParams
+
+	// ------------- Optional query parameter "genre" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "genre", r.URL.Query(), &params.Genre)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "genre", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "year" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "year", r.URL.Query(), &params.Year)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "year", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "unwatched" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "unwatched", r.URL.Query(), &params.Unwatched)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "unwatched", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "addedSince" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "addedSince", r.URL.Query(), &params.AddedSince)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "addedSince", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "sort" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sort", r.URL.Query(), &params.Sort)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "sort", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "order" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "order", r.URL.Query(), &params.Order)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "order", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", r.URL.Query(), &params.Cursor)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "cursor", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ListShows(w, r, libraryId, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// NextUp operation middleware
+func (siw *ServerInterfaceWrapper) NextUp(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params NextUpParams
+
+	// ------------- Optional query parameter "libraryId" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "libraryId", r.URL.Query(), &params.LibraryId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "libraryId", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.NextUp(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// StartPairing operation middleware
+func (siw *ServerInterfaceWrapper) StartPairing(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.StartPairing(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// ApprovePairing operation middleware
+func (siw *ServerInterfaceWrapper) ApprovePairing(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ApprovePairing(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// PollPairing operation middleware
+func (siw *ServerInterfaceWrapper) PollPairing(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PollPairing(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// ConnectPluginTransport operation middleware
+func (siw *ServerInterfaceWrapper) ConnectPluginTransport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ConnectPluginTransport(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// Search operation middleware
+func (siw *ServerInterfaceWrapper) Search(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params SearchParams
+
+	// ------------- Required query parameter "query" -------------
+
+	if paramValue := r.URL.Query().Get("query"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "query"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "query", r.URL.Query(), &params.Query)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "query", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "libraryId" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "libraryId", r.URL.Query(), &params.LibraryId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "libraryId", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.Search(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// ListEpisodes operation middleware
+func (siw *ServerInterfaceWrapper) ListEpisodes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "itemId" -------------
+	var itemId ItemId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "itemId", chi.URLParam(r, "itemId"), &itemId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "itemId", Err: err})
+		return
+	}
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ListEpisodes(w, r, itemId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// ListServerAddresses operation middleware
+func (siw *ServerInterfaceWrapper) ListServerAddresses(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ListServerAddresses(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// ListSeasons operation middleware
+func (siw *ServerInterfaceWrapper) ListSeasons(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "itemId" -------------
+	var itemId ItemId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "itemId", chi.URLParam(r, "itemId"), &itemId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "itemId", Err: err})
+		return
+	}
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ListSeasons(w, r, itemId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// GetTranscodeManifestM3u8 operation middleware
+func (siw *ServerInterfaceWrapper) GetTranscodeManifestM3u8(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "transcodeId" -------------
+	var transcodeId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "transcodeId", chi.URLParam(r, "transcodeId"), &transcodeId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "transcodeId", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetTranscodeManifestM3u8(w, r, transcodeId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// GetTranscodeSegment operation middleware
+func (siw *ServerInterfaceWrapper) GetTranscodeSegment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "transcodeId" -------------
+	var transcodeId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "transcodeId", chi.URLParam(r, "transcodeId"), &transcodeId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "transcodeId", Err: err})
+		return
+	}
+
+	// ------------- Path parameter "segment" -------------
+	var segment string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "segment", chi.URLParam(r, "segment"), &segment, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "segment", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetTranscodeSegment(w, r, transcodeId, segment)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// ListUsers operation middleware
+func (siw *ServerInterfaceWrapper) ListUsers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{"admin"})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ListUsers(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// CreateUser operation middleware
+func (siw *ServerInterfaceWrapper) CreateUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{"admin"})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.CreateUser(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// DeleteUser operation middleware
+func (siw *ServerInterfaceWrapper) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "userId" -------------
+	var userId UserId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userId", chi.URLParam(r, "userId"), &userId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "userId", Err: err})
+		return
+	}
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{"admin"})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.DeleteUser(w, r, userId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// UpdateUser operation middleware
+func (siw *ServerInterfaceWrapper) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "userId" -------------
+	var userId UserId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userId", chi.URLParam(r, "userId"), &userId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "userId", Err: err})
+		return
+	}
+
+	ctx = context.WithValue(ctx, SessionScopes, []string{"admin"})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.UpdateUser(w, r, userId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+type UnescapedCookieParamError struct {
+	ParamName string
+	Err       error
+}
+
+func (e *UnescapedCookieParamError) Error() string {
+	return fmt.Sprintf("error unescaping cookie parameter '%s'", e.ParamName)
+}
+
+func (e *UnescapedCookieParamError) Unwrap() error {
+	return e.Err
+}
+
+type UnmarshalingParamError struct {
+	ParamName string
+	Err       error
+}
+
+func (e *UnmarshalingParamError) Error() string {
+	return fmt.Sprintf("Error unmarshaling parameter %s as JSON: %s", e.ParamName, e.Err.Error())
+}
+
+func (e *UnmarshalingParamError) Unwrap() error {
+	return e.Err
+}
+
+type RequiredParamError struct {
+	ParamName string
+}
+
+func (e *RequiredParamError) Error() string {
+	return fmt.Sprintf("Query argument %s is required, but not found", e.ParamName)
+}
+
+type RequiredHeaderError struct {
+	ParamName string
+	Err       error
+}
+
+func (e *RequiredHeaderError) Error() string {
+	return fmt.Sprintf("Header parameter %s is required, but not found", e.ParamName)
+}
+
+func (e *RequiredHeaderError) Unwrap() error {
+	return e.Err
+}
+
+type InvalidParamFormatError struct {
+	ParamName string
+	Err       error
+}
+
+func (e *InvalidParamFormatError) Error() string {
+	return fmt.Sprintf("Invalid format for parameter %s: %s", e.ParamName, e.Err.Error())
+}
+
+func (e *InvalidParamFormatError) Unwrap() error {
+	return e.Err
+}
+
+type TooManyValuesForParamError struct {
+	ParamName string
+	Count     int
+}
+
+func (e *TooManyValuesForParamError) Error() string {
+	return fmt.Sprintf("Expected one value for %s, got %d", e.ParamName, e.Count)
+}
+
+// Handler creates http.Handler with routing matching OpenAPI spec.
+func Handler(si ServerInterface) http.Handler {
+	return HandlerWithOptions(si, ChiServerOptions{})
+}
+
+type ChiServerOptions struct {
+	BaseURL          string
+	BaseRouter       chi.Router
+	Middlewares      []MiddlewareFunc
+	ErrorHandlerFunc func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// HandlerFromMux creates http.Handler with routing matching OpenAPI spec based on the provided mux.
+func HandlerFromMux(si ServerInterface, r chi.Router) http.Handler {
+	return HandlerWithOptions(si, ChiServerOptions{
+		BaseRouter: r,
+	})
+}
+
+func HandlerFromMuxWithBaseURL(si ServerInterface, r chi.Router, baseURL string) http.Handler {
+	return HandlerWithOptions(si, ChiServerOptions{
+		BaseURL:    baseURL,
+		BaseRouter: r,
+	})
+}
+
+// HandlerWithOptions creates http.Handler with additional options
+func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handler {
+	r := options.BaseRouter
+
+	if r == nil {
+		r = chi.NewRouter()
+	}
+	if options.ErrorHandlerFunc == nil {
+		options.ErrorHandlerFunc = func(w http.ResponseWriter, r *http.Request, err error) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	}
+	wrapper := ServerInterfaceWrapper{
+		Handler:            si,
+		HandlerMiddlewares: options.Middlewares,
+		ErrorHandlerFunc:   options.ErrorHandlerFunc,
+	}
+
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/admin/dsdm-servers", wrapper.ListDsdmServers)
+	})
+	r.Group(func(r chi.Router) {
+		r.Put(options.BaseURL+"/admin/dsdm-servers", wrapper.SetDsdmServer)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/admin/plugins", wrapper.ListPlugins)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/admin/plugins", wrapper.RegisterPlugin)
+	})
+	r.Group(func(r chi.Router) {
+		r.Delete(options.BaseURL+"/admin/plugins/{pluginId}", wrapper.RevokePlugin)
+	})
+	r.Group(func(r chi.Router) {
+		r.Patch(options.BaseURL+"/admin/plugins/{pluginId}", wrapper.UpdatePlugin)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/admin/sessions", wrapper.ListPlaybackSessions)
+	})
+	r.Group(func(r chi.Router) {
+		r.Delete(options.BaseURL+"/admin/sessions/{sessionId}", wrapper.TerminatePlaybackSession)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/auth/login", wrapper.Login)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/auth/logout", wrapper.Logout)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/auth/me", wrapper.GetCurrentUser)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/continue-watching", wrapper.ContinueWatching)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/devices", wrapper.ListDevices)
+	})
+	r.Group(func(r chi.Router) {
+		r.Delete(options.BaseURL+"/devices/{deviceId}", wrapper.RevokeDevice)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/events", wrapper.StreamEvents)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/history", wrapper.ListHistory)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/images/{imageId}", wrapper.GetImage)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/items/{itemId}", wrapper.GetItem)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/items/{itemId}/playback", wrapper.StartPlayback)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/items/{itemId}/progress", wrapper.ReportProgress)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/items/{itemId}/refresh", wrapper.RefreshItem)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/items/{itemId}/stream", wrapper.StreamItem)
+	})
+	r.Group(func(r chi.Router) {
+		r.Delete(options.BaseURL+"/items/{itemId}/watched", wrapper.MarkUnwatched)
+	})
+	r.Group(func(r chi.Router) {
+		r.Put(options.BaseURL+"/items/{itemId}/watched", wrapper.MarkWatched)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/libraries", wrapper.ListLibraries)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/libraries", wrapper.CreateLibrary)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/libraries/{libraryId}", wrapper.GetLibrary)
+	})
+	r.Group(func(r chi.Router) {
+		r.Patch(options.BaseURL+"/libraries/{libraryId}", wrapper.UpdateLibrary)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/libraries/{libraryId}/genres", wrapper.ListGenres)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/libraries/{libraryId}/movies", wrapper.ListMovies)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/libraries/{libraryId}/scan", wrapper.ScanLibrary)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/libraries/{libraryId}/shows", wrapper.ListShows)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/next-up", wrapper.NextUp)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/pairing", wrapper.StartPairing)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/pairing/approve", wrapper.ApprovePairing)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/pairing/poll", wrapper.PollPairing)
+	})
+	r.Group(func(r chi.Router) {
+		r.Connect(options.BaseURL+"/plugin/transport", wrapper.ConnectPluginTransport)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/search", wrapper.Search)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/seasons/{itemId}/episodes", wrapper.ListEpisodes)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/server/addresses", wrapper.ListServerAddresses)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/shows/{itemId}/seasons", wrapper.ListSeasons)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/transcode/{transcodeId}/manifest.m3u8", wrapper.GetTranscodeManifestM3u8)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/transcode/{transcodeId}/{segment}", wrapper.GetTranscodeSegment)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/users", wrapper.ListUsers)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/users", wrapper.CreateUser)
+	})
+	r.Group(func(r chi.Router) {
+		r.Delete(options.BaseURL+"/users/{userId}", wrapper.DeleteUser)
+	})
+	r.Group(func(r chi.Router) {
+		r.Patch(options.BaseURL+"/users/{userId}", wrapper.UpdateUser)
+	})
+
+	return r
+}
+
+type ListDsdmServersRequestObject struct {
+}
+
+type ListDsdmServersResponseObject interface {
+	VisitListDsdmServersResponse(w http.ResponseWriter) error
+}
+
+type ListDsdmServers200JSONResponse DSDMServerList
+
+func (response ListDsdmServers200JSONResponse) VisitListDsdmServersResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type SetDsdmServerRequestObject struct {
+	Body *SetDsdmServerJSONRequestBody
+}
+
+type SetDsdmServerResponseObject interface {
+	VisitSetDsdmServerResponse(w http.ResponseWriter) error
+}
+
+type SetDsdmServer200JSONResponse DSDMServer
+
+func (response SetDsdmServer200JSONResponse) VisitSetDsdmServerResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type SetDsdmServer400JSONResponse ErrorResponse
+
+func (response SetDsdmServer400JSONResponse) VisitSetDsdmServerResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListPluginsRequestObject struct {
+}
+
+type ListPluginsResponseObject interface {
+	VisitListPluginsResponse(w http.ResponseWriter) error
+}
+
+type ListPlugins200JSONResponse PluginList
+
+func (response ListPlugins200JSONResponse) VisitListPluginsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type RegisterPluginRequestObject struct {
+	Body *RegisterPluginJSONRequestBody
+}
+
+type RegisterPluginResponseObject interface {
+	VisitRegisterPluginResponse(w http.ResponseWriter) error
+}
+
+type RegisterPlugin201JSONResponse RegisteredPlugin
+
+func (response RegisterPlugin201JSONResponse) VisitRegisterPluginResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(201)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type RegisterPlugin400JSONResponse ErrorResponse
+
+func (response RegisterPlugin400JSONResponse) VisitRegisterPluginResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type RevokePluginRequestObject struct {
+	PluginId PluginId `json:"pluginId"`
+}
+
+type RevokePluginResponseObject interface {
+	VisitRevokePluginResponse(w http.ResponseWriter) error
+}
+
+type RevokePlugin204Response struct {
+}
+
+func (response RevokePlugin204Response) VisitRevokePluginResponse(w http.ResponseWriter) error {
+	w.WriteHeader(204)
+	return nil
+}
+
+type RevokePlugin404JSONResponse ErrorResponse
+
+func (response RevokePlugin404JSONResponse) VisitRevokePluginResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type UpdatePluginRequestObject struct {
+	PluginId PluginId `json:"pluginId"`
+	Body     *UpdatePluginJSONRequestBody
+}
+
+type UpdatePluginResponseObject interface {
+	VisitUpdatePluginResponse(w http.ResponseWriter) error
+}
+
+type UpdatePlugin200JSONResponse Plugin
+
+func (response UpdatePlugin200JSONResponse) VisitUpdatePluginResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type UpdatePlugin404JSONResponse ErrorResponse
+
+func (response UpdatePlugin404JSONResponse) VisitUpdatePluginResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListPlaybackSessionsRequestObject struct {
+}
+
+type ListPlaybackSessionsResponseObject interface {
+	VisitListPlaybackSessionsResponse(w http.ResponseWriter) error
+}
+
+type ListPlaybackSessions200JSONResponse PlaybackSessionList
+
+func (response ListPlaybackSessions200JSONResponse) VisitListPlaybackSessionsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type TerminatePlaybackSessionRequestObject struct {
+	SessionId openapi_types.UUID `json:"sessionId"`
+}
+
+type TerminatePlaybackSessionResponseObject interface {
+	VisitTerminatePlaybackSessionResponse(w http.ResponseWriter) error
+}
+
+type TerminatePlaybackSession204Response struct {
+}
+
+func (response TerminatePlaybackSession204Response) VisitTerminatePlaybackSessionResponse(w http.ResponseWriter) error {
+	w.WriteHeader(204)
+	return nil
+}
+
+type TerminatePlaybackSession404JSONResponse ErrorResponse
+
+func (response TerminatePlaybackSession404JSONResponse) VisitTerminatePlaybackSessionResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type LoginRequestObject struct {
+	Body *LoginJSONRequestBody
+}
+
+type LoginResponseObject interface {
+	VisitLoginResponse(w http.ResponseWriter) error
+}
+
+type Login200JSONResponse LoginResponse
+
+func (response Login200JSONResponse) VisitLoginResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type Login401JSONResponse ErrorResponse
+
+func (response Login401JSONResponse) VisitLoginResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(401)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type LogoutRequestObject struct {
+}
+
+type LogoutResponseObject interface {
+	VisitLogoutResponse(w http.ResponseWriter) error
+}
+
+type Logout204Response struct {
+}
+
+func (response Logout204Response) VisitLogoutResponse(w http.ResponseWriter) error {
+	w.WriteHeader(204)
+	return nil
+}
+
+type GetCurrentUserRequestObject struct {
+}
+
+type GetCurrentUserResponseObject interface {
+	VisitGetCurrentUserResponse(w http.ResponseWriter) error
+}
+
+type GetCurrentUser200JSONResponse User
+
+func (response GetCurrentUser200JSONResponse) VisitGetCurrentUserResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ContinueWatchingRequestObject struct {
+	Params ContinueWatchingParams
+}
+
+type ContinueWatchingResponseObject interface {
+	VisitContinueWatchingResponse(w http.ResponseWriter) error
+}
+
+type ContinueWatching200JSONResponse ItemList
+
+func (response ContinueWatching200JSONResponse) VisitContinueWatchingResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListDevicesRequestObject struct {
+}
+
+type ListDevicesResponseObject interface {
+	VisitListDevicesResponse(w http.ResponseWriter) error
+}
+
+type ListDevices200JSONResponse DeviceList
+
+func (response ListDevices200JSONResponse) VisitListDevicesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type RevokeDeviceRequestObject struct {
+	DeviceId openapi_types.UUID `json:"deviceId"`
+}
+
+type RevokeDeviceResponseObject interface {
+	VisitRevokeDeviceResponse(w http.ResponseWriter) error
+}
+
+type RevokeDevice204Response struct {
+}
+
+func (response RevokeDevice204Response) VisitRevokeDeviceResponse(w http.ResponseWriter) error {
+	w.WriteHeader(204)
+	return nil
+}
+
+type RevokeDevice404JSONResponse ErrorResponse
+
+func (response RevokeDevice404JSONResponse) VisitRevokeDeviceResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type StreamEventsRequestObject struct {
+	Params StreamEventsParams
+}
+
+type StreamEventsResponseObject interface {
+	VisitStreamEventsResponse(w http.ResponseWriter) error
+}
+
+type StreamEvents200TexteventStreamResponse struct {
+	Body          io.Reader
+	ContentLength int64
+}
+
+func (response StreamEvents200TexteventStreamResponse) VisitStreamEventsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "text/event-stream")
+	if response.ContentLength != 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	w.WriteHeader(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(w, response.Body)
+	return err
+}
+
+type StreamEvents503JSONResponse ErrorResponse
+
+func (response StreamEvents503JSONResponse) VisitStreamEventsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListHistoryRequestObject struct {
+	Params ListHistoryParams
+}
+
+type ListHistoryResponseObject interface {
+	VisitListHistoryResponse(w http.ResponseWriter) error
+}
+
+type ListHistory200JSONResponse HistoryPage
+
+func (response ListHistory200JSONResponse) VisitListHistoryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListHistory400JSONResponse ErrorResponse
+
+func (response ListHistory400JSONResponse) VisitListHistoryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetImageRequestObject struct {
+	ImageId openapi_types.UUID `json:"imageId"`
+	Params  GetImageParams
+}
+
+type GetImageResponseObject interface {
+	VisitGetImageResponse(w http.ResponseWriter) error
+}
+
+type GetImage200ImagejpegResponse struct {
+	Body          io.Reader
+	ContentLength int64
+}
+
+func (response GetImage200ImagejpegResponse) VisitGetImageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "image/jpeg")
+	if response.ContentLength != 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	w.WriteHeader(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(w, response.Body)
+	return err
+}
+
+type GetImage200ImagewebpResponse struct {
+	Body          io.Reader
+	ContentLength int64
+}
+
+func (response GetImage200ImagewebpResponse) VisitGetImageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "image/webp")
+	if response.ContentLength != 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	w.WriteHeader(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(w, response.Body)
+	return err
+}
+
+type GetImage304Response struct {
+}
+
+func (response GetImage304Response) VisitGetImageResponse(w http.ResponseWriter) error {
+	w.WriteHeader(304)
+	return nil
+}
+
+type GetImage400JSONResponse ErrorResponse
+
+func (response GetImage400JSONResponse) VisitGetImageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetImage404JSONResponse ErrorResponse
+
+func (response GetImage404JSONResponse) VisitGetImageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetImage502JSONResponse ErrorResponse
+
+func (response GetImage502JSONResponse) VisitGetImageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(502)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetItemRequestObject struct {
+	ItemId ItemId `json:"itemId"`
+}
+
+type GetItemResponseObject interface {
+	VisitGetItemResponse(w http.ResponseWriter) error
+}
+
+type GetItem200JSONResponse ItemDetails
+
+func (response GetItem200JSONResponse) VisitGetItemResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetItem404JSONResponse ErrorResponse
+
+func (response GetItem404JSONResponse) VisitGetItemResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type StartPlaybackRequestObject struct {
+	ItemId ItemId `json:"itemId"`
+	Body   *StartPlaybackJSONRequestBody
+}
+
+type StartPlaybackResponseObject interface {
+	VisitStartPlaybackResponse(w http.ResponseWriter) error
+}
+
+type StartPlayback201JSONResponse PlaybackSession
+
+func (response StartPlayback201JSONResponse) VisitStartPlaybackResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(201)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type StartPlayback400JSONResponse ErrorResponse
+
+func (response StartPlayback400JSONResponse) VisitStartPlaybackResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type StartPlayback404JSONResponse ErrorResponse
+
+func (response StartPlayback404JSONResponse) VisitStartPlaybackResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ReportProgressRequestObject struct {
+	ItemId ItemId `json:"itemId"`
+	Body   *ReportProgressJSONRequestBody
+}
+
+type ReportProgressResponseObject interface {
+	VisitReportProgressResponse(w http.ResponseWriter) error
+}
+
+type ReportProgress200JSONResponse WatchState
+
+func (response ReportProgress200JSONResponse) VisitReportProgressResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ReportProgress400JSONResponse ErrorResponse
+
+func (response ReportProgress400JSONResponse) VisitReportProgressResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ReportProgress404JSONResponse ErrorResponse
+
+func (response ReportProgress404JSONResponse) VisitReportProgressResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type RefreshItemRequestObject struct {
+	ItemId ItemId `json:"itemId"`
+}
+
+type RefreshItemResponseObject interface {
+	VisitRefreshItemResponse(w http.ResponseWriter) error
+}
+
+type RefreshItem202Response struct {
+}
+
+func (response RefreshItem202Response) VisitRefreshItemResponse(w http.ResponseWriter) error {
+	w.WriteHeader(202)
+	return nil
+}
+
+type RefreshItem404JSONResponse ErrorResponse
+
+func (response RefreshItem404JSONResponse) VisitRefreshItemResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type StreamItemRequestObject struct {
+	ItemId ItemId `json:"itemId"`
+	Params StreamItemParams
+}
+
+type StreamItemResponseObject interface {
+	VisitStreamItemResponse(w http.ResponseWriter) error
+}
+
+type StreamItem200VideoResponse struct {
+	Body          io.Reader
+	ContentType   string
+	ContentLength int64
+}
+
+func (response StreamItem200VideoResponse) VisitStreamItemResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", response.ContentType)
+	if response.ContentLength != 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	w.WriteHeader(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(w, response.Body)
+	return err
+}
+
+type StreamItem206VideoResponse struct {
+	Body          io.Reader
+	ContentType   string
+	ContentLength int64
+}
+
+func (response StreamItem206VideoResponse) VisitStreamItemResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", response.ContentType)
+	if response.ContentLength != 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	w.WriteHeader(206)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(w, response.Body)
+	return err
+}
+
+type StreamItem400JSONResponse ErrorResponse
+
+func (response StreamItem400JSONResponse) VisitStreamItemResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type StreamItem404JSONResponse ErrorResponse
+
+func (response StreamItem404JSONResponse) VisitStreamItemResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type StreamItem410JSONResponse ErrorResponse
+
+func (response StreamItem410JSONResponse) VisitStreamItemResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(410)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type MarkUnwatchedRequestObject struct {
+	ItemId ItemId `json:"itemId"`
+}
+
+type MarkUnwatchedResponseObject interface {
+	VisitMarkUnwatchedResponse(w http.ResponseWriter) error
+}
+
+type MarkUnwatched200JSONResponse WatchState
+
+func (response MarkUnwatched200JSONResponse) VisitMarkUnwatchedResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type MarkUnwatched404JSONResponse ErrorResponse
+
+func (response MarkUnwatched404JSONResponse) VisitMarkUnwatchedResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type MarkWatchedRequestObject struct {
+	ItemId ItemId `json:"itemId"`
+}
+
+type MarkWatchedResponseObject interface {
+	VisitMarkWatchedResponse(w http.ResponseWriter) error
+}
+
+type MarkWatched200JSONResponse WatchState
+
+func (response MarkWatched200JSONResponse) VisitMarkWatchedResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type MarkWatched404JSONResponse ErrorResponse
+
+func (response MarkWatched404JSONResponse) VisitMarkWatchedResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListLibrariesRequestObject struct {
+}
+
+type ListLibrariesResponseObject interface {
+	VisitListLibrariesResponse(w http.ResponseWriter) error
+}
+
+type ListLibraries200JSONResponse LibraryList
+
+func (response ListLibraries200JSONResponse) VisitListLibrariesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type CreateLibraryRequestObject struct {
+	Body *CreateLibraryJSONRequestBody
+}
+
+type CreateLibraryResponseObject interface {
+	VisitCreateLibraryResponse(w http.ResponseWriter) error
+}
+
+type CreateLibrary201JSONResponse Library
+
+func (response CreateLibrary201JSONResponse) VisitCreateLibraryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(201)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type CreateLibrary400JSONResponse ErrorResponse
+
+func (response CreateLibrary400JSONResponse) VisitCreateLibraryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type CreateLibrary409JSONResponse ErrorResponse
+
+func (response CreateLibrary409JSONResponse) VisitCreateLibraryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(409)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetLibraryRequestObject struct {
+	LibraryId LibraryId `json:"libraryId"`
+}
+
+type GetLibraryResponseObject interface {
+	VisitGetLibraryResponse(w http.ResponseWriter) error
+}
+
+type GetLibrary200JSONResponse Library
+
+func (response GetLibrary200JSONResponse) VisitGetLibraryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetLibrary404JSONResponse ErrorResponse
+
+func (response GetLibrary404JSONResponse) VisitGetLibraryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type UpdateLibraryRequestObject struct {
+	LibraryId LibraryId `json:"libraryId"`
+	Body      *UpdateLibraryJSONRequestBody
+}
+
+type UpdateLibraryResponseObject interface {
+	VisitUpdateLibraryResponse(w http.ResponseWriter) error
+}
+
+type UpdateLibrary200JSONResponse Library
+
+func (response UpdateLibrary200JSONResponse) VisitUpdateLibraryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type UpdateLibrary404JSONResponse ErrorResponse
+
+func (response UpdateLibrary404JSONResponse) VisitUpdateLibraryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListGenresRequestObject struct {
+	LibraryId LibraryId `json:"libraryId"`
+}
+
+type ListGenresResponseObject interface {
+	VisitListGenresResponse(w http.ResponseWriter) error
+}
+
+type ListGenres200JSONResponse GenreList
+
+func (response ListGenres200JSONResponse) VisitListGenresResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListGenres404JSONResponse ErrorResponse
+
+func (response ListGenres404JSONResponse) VisitListGenresResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListMoviesRequestObject struct {
+	LibraryId LibraryId `json:"libraryId"`
+	Params    ListMoviesParams
+}
+
+type ListMoviesResponseObject interface {
+	VisitListMoviesResponse(w http.ResponseWriter) error
+}
+
+type ListMovies200JSONResponse ItemPage
+
+func (response ListMovies200JSONResponse) VisitListMoviesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListMovies400JSONResponse ErrorResponse
+
+func (response ListMovies400JSONResponse) VisitListMoviesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListMovies404JSONResponse ErrorResponse
+
+func (response ListMovies404JSONResponse) VisitListMoviesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ScanLibraryRequestObject struct {
+	LibraryId LibraryId `json:"libraryId"`
+}
+
+type ScanLibraryResponseObject interface {
+	VisitScanLibraryResponse(w http.ResponseWriter) error
+}
+
+type ScanLibrary202Response struct {
+}
+
+func (response ScanLibrary202Response) VisitScanLibraryResponse(w http.ResponseWriter) error {
+	w.WriteHeader(202)
+	return nil
+}
+
+type ScanLibrary404JSONResponse ErrorResponse
+
+func (response ScanLibrary404JSONResponse) VisitScanLibraryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListShowsRequestObject struct {
+	LibraryId LibraryId `json:"libraryId"`
+	Params    ListShowsParams
+}
+
+type ListShowsResponseObject interface {
+	VisitListShowsResponse(w http.ResponseWriter) error
+}
+
+type ListShows200JSONResponse ItemPage
+
+func (response ListShows200JSONResponse) VisitListShowsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListShows400JSONResponse ErrorResponse
+
+func (response ListShows400JSONResponse) VisitListShowsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListShows404JSONResponse ErrorResponse
+
+func (response ListShows404JSONResponse) VisitListShowsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type NextUpRequestObject struct {
+	Params NextUpParams
+}
+
+type NextUpResponseObject interface {
+	VisitNextUpResponse(w http.ResponseWriter) error
+}
+
+type NextUp200JSONResponse ItemList
+
+func (response NextUp200JSONResponse) VisitNextUpResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type StartPairingRequestObject struct {
+	Body *StartPairingJSONRequestBody
+}
+
+type StartPairingResponseObject interface {
+	VisitStartPairingResponse(w http.ResponseWriter) error
+}
+
+type StartPairing201JSONResponse Pairing
+
+func (response StartPairing201JSONResponse) VisitStartPairingResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(201)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type StartPairing400JSONResponse ErrorResponse
+
+func (response StartPairing400JSONResponse) VisitStartPairingResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type StartPairing429JSONResponse ErrorResponse
+
+func (response StartPairing429JSONResponse) VisitStartPairingResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(429)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ApprovePairingRequestObject struct {
+	Body *ApprovePairingJSONRequestBody
+}
+
+type ApprovePairingResponseObject interface {
+	VisitApprovePairingResponse(w http.ResponseWriter) error
+}
+
+type ApprovePairing200JSONResponse Device
+
+func (response ApprovePairing200JSONResponse) VisitApprovePairingResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ApprovePairing404JSONResponse ErrorResponse
+
+func (response ApprovePairing404JSONResponse) VisitApprovePairingResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PollPairingRequestObject struct {
+	Body *PollPairingJSONRequestBody
+}
+
+type PollPairingResponseObject interface {
+	VisitPollPairingResponse(w http.ResponseWriter) error
+}
+
+type PollPairing200JSONResponse PairedDevice
+
+func (response PollPairing200JSONResponse) VisitPollPairingResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PollPairing202Response struct {
+}
+
+func (response PollPairing202Response) VisitPollPairingResponse(w http.ResponseWriter) error {
+	w.WriteHeader(202)
+	return nil
+}
+
+type PollPairing404JSONResponse ErrorResponse
+
+func (response PollPairing404JSONResponse) VisitPollPairingResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ConnectPluginTransportRequestObject struct {
+}
+
+type ConnectPluginTransportResponseObject interface {
+	VisitConnectPluginTransportResponse(w http.ResponseWriter) error
+}
+
+type ConnectPluginTransport401JSONResponse ErrorResponse
+
+func (response ConnectPluginTransport401JSONResponse) VisitConnectPluginTransportResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(401)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ConnectPluginTransport403JSONResponse ErrorResponse
+
+func (response ConnectPluginTransport403JSONResponse) VisitConnectPluginTransportResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(403)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type SearchRequestObject struct {
+	Params SearchParams
+}
+
+type SearchResponseObject interface {
+	VisitSearchResponse(w http.ResponseWriter) error
+}
+
+type Search200JSONResponse SearchResults
+
+func (response Search200JSONResponse) VisitSearchResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListEpisodesRequestObject struct {
+	ItemId ItemId `json:"itemId"`
+}
+
+type ListEpisodesResponseObject interface {
+	VisitListEpisodesResponse(w http.ResponseWriter) error
+}
+
+type ListEpisodes200JSONResponse ItemList
+
+func (response ListEpisodes200JSONResponse) VisitListEpisodesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListEpisodes404JSONResponse ErrorResponse
+
+func (response ListEpisodes404JSONResponse) VisitListEpisodesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListServerAddressesRequestObject struct {
+}
+
+type ListServerAddressesResponseObject interface {
+	VisitListServerAddressesResponse(w http.ResponseWriter) error
+}
+
+type ListServerAddresses200JSONResponse ServerAddressList
+
+func (response ListServerAddresses200JSONResponse) VisitListServerAddressesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListSeasonsRequestObject struct {
+	ItemId ItemId `json:"itemId"`
+}
+
+type ListSeasonsResponseObject interface {
+	VisitListSeasonsResponse(w http.ResponseWriter) error
+}
+
+type ListSeasons200JSONResponse ItemList
+
+func (response ListSeasons200JSONResponse) VisitListSeasonsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListSeasons404JSONResponse ErrorResponse
+
+func (response ListSeasons404JSONResponse) VisitListSeasonsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetTranscodeManifestM3u8RequestObject struct {
+	TranscodeId openapi_types.UUID `json:"transcodeId"`
+}
+
+type GetTranscodeManifestM3u8ResponseObject interface {
+	VisitGetTranscodeManifestM3u8Response(w http.ResponseWriter) error
+}
+
+type GetTranscodeManifestM3u8200ApplicationvndAppleMpegurlResponse struct {
+	Body          io.Reader
+	ContentLength int64
+}
+
+func (response GetTranscodeManifestM3u8200ApplicationvndAppleMpegurlResponse) VisitGetTranscodeManifestM3u8Response(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+	if response.ContentLength != 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	w.WriteHeader(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(w, response.Body)
+	return err
+}
+
+type GetTranscodeManifestM3u8404JSONResponse ErrorResponse
+
+func (response GetTranscodeManifestM3u8404JSONResponse) VisitGetTranscodeManifestM3u8Response(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetTranscodeManifestM3u8410JSONResponse ErrorResponse
+
+func (response GetTranscodeManifestM3u8410JSONResponse) VisitGetTranscodeManifestM3u8Response(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(410)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetTranscodeSegmentRequestObject struct {
+	TranscodeId openapi_types.UUID `json:"transcodeId"`
+	Segment     string             `json:"segment"`
+}
+
+type GetTranscodeSegmentResponseObject interface {
+	VisitGetTranscodeSegmentResponse(w http.ResponseWriter) error
+}
+
+type GetTranscodeSegment200VideoResponse struct {
+	Body          io.Reader
+	ContentType   string
+	ContentLength int64
+}
+
+func (response GetTranscodeSegment200VideoResponse) VisitGetTranscodeSegmentResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", response.ContentType)
+	if response.ContentLength != 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	w.WriteHeader(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(w, response.Body)
+	return err
+}
+
+type GetTranscodeSegment404JSONResponse ErrorResponse
+
+func (response GetTranscodeSegment404JSONResponse) VisitGetTranscodeSegmentResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetTranscodeSegment410JSONResponse ErrorResponse
+
+func (response GetTranscodeSegment410JSONResponse) VisitGetTranscodeSegmentResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(410)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListUsersRequestObject struct {
+}
+
+type ListUsersResponseObject interface {
+	VisitListUsersResponse(w http.ResponseWriter) error
+}
+
+type ListUsers200JSONResponse UserList
+
+func (response ListUsers200JSONResponse) VisitListUsersResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type CreateUserRequestObject struct {
+	Body *CreateUserJSONRequestBody
+}
+
+type CreateUserResponseObject interface {
+	VisitCreateUserResponse(w http.ResponseWriter) error
+}
+
+type CreateUser201JSONResponse User
+
+func (response CreateUser201JSONResponse) VisitCreateUserResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(201)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type CreateUser409JSONResponse ErrorResponse
+
+func (response CreateUser409JSONResponse) VisitCreateUserResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(409)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type DeleteUserRequestObject struct {
+	UserId UserId `json:"userId"`
+}
+
+type DeleteUserResponseObject interface {
+	VisitDeleteUserResponse(w http.ResponseWriter) error
+}
+
+type DeleteUser204Response struct {
+}
+
+func (response DeleteUser204Response) VisitDeleteUserResponse(w http.ResponseWriter) error {
+	w.WriteHeader(204)
+	return nil
+}
+
+type DeleteUser404JSONResponse ErrorResponse
+
+func (response DeleteUser404JSONResponse) VisitDeleteUserResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type DeleteUser409JSONResponse ErrorResponse
+
+func (response DeleteUser409JSONResponse) VisitDeleteUserResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(409)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type UpdateUserRequestObject struct {
+	UserId UserId `json:"userId"`
+	Body   *UpdateUserJSONRequestBody
+}
+
+type UpdateUserResponseObject interface {
+	VisitUpdateUserResponse(w http.ResponseWriter) error
+}
+
+type UpdateUser200JSONResponse User
+
+func (response UpdateUser200JSONResponse) VisitUpdateUserResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type UpdateUser404JSONResponse ErrorResponse
+
+func (response UpdateUser404JSONResponse) VisitUpdateUserResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type UpdateUser409JSONResponse ErrorResponse
+
+func (response UpdateUser409JSONResponse) VisitUpdateUserResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(409)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+// StrictServerInterface represents all server handlers.
+type StrictServerInterface interface {
+	// List DSDM Servers
+	// (GET /admin/dsdm-servers)
+	ListDsdmServers(ctx context.Context, request ListDsdmServersRequestObject) (ListDsdmServersResponseObject, error)
+	// Set DSDM Server
+	// (PUT /admin/dsdm-servers)
+	SetDsdmServer(ctx context.Context, request SetDsdmServerRequestObject) (SetDsdmServerResponseObject, error)
+	// List Plugins
+	// (GET /admin/plugins)
+	ListPlugins(ctx context.Context, request ListPluginsRequestObject) (ListPluginsResponseObject, error)
+	// Register Plugin
+	// (POST /admin/plugins)
+	RegisterPlugin(ctx context.Context, request RegisterPluginRequestObject) (RegisterPluginResponseObject, error)
+	// Revoke Plugin
+	// (DELETE /admin/plugins/{pluginId})
+	RevokePlugin(ctx context.Context, request RevokePluginRequestObject) (RevokePluginResponseObject, error)
+	// Update Plugin
+	// (PATCH /admin/plugins/{pluginId})
+	UpdatePlugin(ctx context.Context, request UpdatePluginRequestObject) (UpdatePluginResponseObject, error)
+	// List Playback Sessions
+	// (GET /admin/sessions)
+	ListPlaybackSessions(ctx context.Context, request ListPlaybackSessionsRequestObject) (ListPlaybackSessionsResponseObject, error)
+	// Terminate Playback Session
+	// (DELETE /admin/sessions/{sessionId})
+	TerminatePlaybackSession(ctx context.Context, request TerminatePlaybackSessionRequestObject) (TerminatePlaybackSessionResponseObject, error)
+	// Login
+	// (POST /auth/login)
+	Login(ctx context.Context, request LoginRequestObject) (LoginResponseObject, error)
+	// Logout
+	// (POST /auth/logout)
+	Logout(ctx context.Context, request LogoutRequestObject) (LogoutResponseObject, error)
+	// Get Current User
+	// (GET /auth/me)
+	GetCurrentUser(ctx context.Context, request GetCurrentUserRequestObject) (GetCurrentUserResponseObject, error)
+	// Continue Watching
+	// (GET /continue-watching)
+	ContinueWatching(ctx context.Context, request ContinueWatchingRequestObject) (ContinueWatchingResponseObject, error)
+	// List Devices
+	// (GET /devices)
+	ListDevices(ctx context.Context, request ListDevicesRequestObject) (ListDevicesResponseObject, error)
+	// Revoke Device
+	// (DELETE /devices/{deviceId})
+	RevokeDevice(ctx context.Context, request RevokeDeviceRequestObject) (RevokeDeviceResponseObject, error)
+	// Stream Events
+	// (GET /events)
+	StreamEvents(ctx context.Context, request StreamEventsRequestObject) (StreamEventsResponseObject, error)
+	// Watch History
+	// (GET /history)
+	ListHistory(ctx context.Context, request ListHistoryRequestObject) (ListHistoryResponseObject, error)
+	// Get Image
+	// (GET /images/{imageId})
+	GetImage(ctx context.Context, request GetImageRequestObject) (GetImageResponseObject, error)
+	// Get Item
+	// (GET /items/{itemId})
+	GetItem(ctx context.Context, request GetItemRequestObject) (GetItemResponseObject, error)
+	// Start Playback
+	// (POST /items/{itemId}/playback)
+	StartPlayback(ctx context.Context, request StartPlaybackRequestObject) (StartPlaybackResponseObject, error)
+	// Report Progress
+	// (POST /items/{itemId}/progress)
+	ReportProgress(ctx context.Context, request ReportProgressRequestObject) (ReportProgressResponseObject, error)
+	// Refresh Item
+	// (POST /items/{itemId}/refresh)
+	RefreshItem(ctx context.Context, request RefreshItemRequestObject) (RefreshItemResponseObject, error)
+	// Stream Item
+	// (GET /items/{itemId}/stream)
+	StreamItem(ctx context.Context, request StreamItemRequestObject) (StreamItemResponseObject, error)
+	// Mark Unwatched
+	// (DELETE /items/{itemId}/watched)
+	MarkUnwatched(ctx context.Context, request MarkUnwatchedRequestObject) (MarkUnwatchedResponseObject, error)
+	// Mark Watched
+	// (PUT /items/{itemId}/watched)
+	MarkWatched(ctx context.Context, request MarkWatchedRequestObject) (MarkWatchedResponseObject, error)
+	// List Libraries
+	// (GET /libraries)
+	ListLibraries(ctx context.Context, request ListLibrariesRequestObject) (ListLibrariesResponseObject, error)
+	// Create Library
+	// (POST /libraries)
+	CreateLibrary(ctx context.Context, request CreateLibraryRequestObject) (CreateLibraryResponseObject, error)
+	// Get Library
+	// (GET /libraries/{libraryId})
+	GetLibrary(ctx context.Context, request GetLibraryRequestObject) (GetLibraryResponseObject, error)
+	// Update Library
+	// (PATCH /libraries/{libraryId})
+	UpdateLibrary(ctx context.Context, request UpdateLibraryRequestObject) (UpdateLibraryResponseObject, error)
+	// List Genres
+	// (GET /libraries/{libraryId}/genres)
+	ListGenres(ctx context.Context, request ListGenresRequestObject) (ListGenresResponseObject, error)
+	// List Movies
+	// (GET /libraries/{libraryId}/movies)
+	ListMovies(ctx context.Context, request ListMoviesRequestObject) (ListMoviesResponseObject, error)
+	// Scan Library
+	// (POST /libraries/{libraryId}/scan)
+	ScanLibrary(ctx context.Context, request ScanLibraryRequestObject) (ScanLibraryResponseObject, error)
+	// List Shows
+	// (GET /libraries/{libraryId}/shows)
+	ListShows(ctx context.Context, request ListShowsRequestObject) (ListShowsResponseObject, error)
+	// Next Up
+	// (GET /next-up)
+	NextUp(ctx context.Context, request NextUpRequestObject) (NextUpResponseObject, error)
+	// Start Pairing
+	// (POST /pairing)
+	StartPairing(ctx context.Context, request StartPairingRequestObject) (StartPairingResponseObject, error)
+	// Approve Pairing
+	// (POST /pairing/approve)
+	ApprovePairing(ctx context.Context, request ApprovePairingRequestObject) (ApprovePairingResponseObject, error)
+	// Poll Pairing
+	// (POST /pairing/poll)
+	PollPairing(ctx context.Context, request PollPairingRequestObject) (PollPairingResponseObject, error)
+	// Plugin Transport Connect
+	// (CONNECT /plugin/transport)
+	ConnectPluginTransport(ctx context.Context, request ConnectPluginTransportRequestObject) (ConnectPluginTransportResponseObject, error)
+	// Search
+	// (GET /search)
+	Search(ctx context.Context, request SearchRequestObject) (SearchResponseObject, error)
+	// List Episodes
+	// (GET /seasons/{itemId}/episodes)
+	ListEpisodes(ctx context.Context, request ListEpisodesRequestObject) (ListEpisodesResponseObject, error)
+	// List Server Addresses
+	// (GET /server/addresses)
+	ListServerAddresses(ctx context.Context, request ListServerAddressesRequestObject) (ListServerAddressesResponseObject, error)
+	// List Seasons
+	// (GET /shows/{itemId}/seasons)
+	ListSeasons(ctx context.Context, request ListSeasonsRequestObject) (ListSeasonsResponseObject, error)
+	// Transcode Manifest M3U8
+	// (GET /transcode/{transcodeId}/manifest.m3u8)
+	GetTranscodeManifestM3u8(ctx context.Context, request GetTranscodeManifestM3u8RequestObject) (GetTranscodeManifestM3u8ResponseObject, error)
+	// Transcode Segment
+	// (GET /transcode/{transcodeId}/{segment})
+	GetTranscodeSegment(ctx context.Context, request GetTranscodeSegmentRequestObject) (GetTranscodeSegmentResponseObject, error)
+	// List Users
+	// (GET /users)
+	ListUsers(ctx context.Context, request ListUsersRequestObject) (ListUsersResponseObject, error)
+	// Create User
+	// (POST /users)
+	CreateUser(ctx context.Context, request CreateUserRequestObject) (CreateUserResponseObject, error)
+	// Delete User
+	// (DELETE /users/{userId})
+	DeleteUser(ctx context.Context, request DeleteUserRequestObject) (DeleteUserResponseObject, error)
+	// Update User
+	// (PATCH /users/{userId})
+	UpdateUser(ctx context.Context, request UpdateUserRequestObject) (UpdateUserResponseObject, error)
+}
+
+type StrictHandlerFunc = strictnethttp.StrictHTTPHandlerFunc
+type StrictMiddlewareFunc = strictnethttp.StrictHTTPMiddlewareFunc
+
+type StrictHTTPServerOptions struct {
+	RequestErrorHandlerFunc  func(w http.ResponseWriter, r *http.Request, err error)
+	ResponseErrorHandlerFunc func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+func NewStrictHandler(ssi StrictServerInterface, middlewares []StrictMiddlewareFunc) ServerInterface {
+	return &strictHandler{ssi: ssi, middlewares: middlewares, options: StrictHTTPServerOptions{
+		RequestErrorHandlerFunc: func(w http.ResponseWriter, r *http.Request, err error) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		},
+		ResponseErrorHandlerFunc: func(w http.ResponseWriter, r *http.Request, err error) {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		},
+	}}
+}
+
+func NewStrictHandlerWithOptions(ssi StrictServerInterface, middlewares []StrictMiddlewareFunc, options StrictHTTPServerOptions) ServerInterface {
+	return &strictHandler{ssi: ssi, middlewares: middlewares, options: options}
+}
+
+type strictHandler struct {
+	ssi         StrictServerInterface
+	middlewares []StrictMiddlewareFunc
+	options     StrictHTTPServerOptions
+}
+
+// ListDsdmServers operation middleware
+func (sh *strictHandler) ListDsdmServers(w http.ResponseWriter, r *http.Request) {
+	var request ListDsdmServersRequestObject
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.ListDsdmServers(ctx, request.(ListDsdmServersRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ListDsdmServers")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(ListDsdmServersResponseObject); ok {
+		if err := validResponse.VisitListDsdmServersResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// SetDsdmServer operation middleware
+func (sh *strictHandler) SetDsdmServer(w http.ResponseWriter, r *http.Request) {
+	var request SetDsdmServerRequestObject
+
+	var body SetDsdmServerJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		sh.options.RequestErrorHandlerFunc(w, r, fmt.Errorf("can't decode JSON body: %w", err))
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.SetDsdmServer(ctx, request.(SetDsdmServerRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "SetDsdmServer")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(SetDsdmServerResponseObject); ok {
+		if err := validResponse.VisitSetDsdmServerResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// ListPlugins operation middleware
+func (sh *strictHandler) ListPlugins(w http.ResponseWriter, r *http.Request) {
+	var request ListPluginsRequestObject
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.ListPlugins(ctx, request.(ListPluginsRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ListPlugins")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(ListPluginsResponseObject); ok {
+		if err := validResponse.VisitListPluginsResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// RegisterPlugin operation middleware
+func (sh *strictHandler) RegisterPlugin(w http.ResponseWriter, r *http.Request) {
+	var request RegisterPluginRequestObject
+
+	var body RegisterPluginJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		sh.options.RequestErrorHandlerFunc(w, r, fmt.Errorf("can't decode JSON body: %w", err))
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.RegisterPlugin(ctx, request.(RegisterPluginRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "RegisterPlugin")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(RegisterPluginResponseObject); ok {
+		if err := validResponse.VisitRegisterPluginResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// RevokePlugin operation middleware
+func (sh *strictHandler) RevokePlugin(w http.ResponseWriter, r *http.Request, pluginId PluginId) {
+	var request RevokePluginRequestObject
+
+	request.PluginId = pluginId
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.RevokePlugin(ctx, request.(RevokePluginRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "RevokePlugin")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(RevokePluginResponseObject); ok {
+		if err := validResponse.VisitRevokePluginResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// UpdatePlugin operation middleware
+func (sh *strictHandler) UpdatePlugin(w http.ResponseWriter, r *http.Request, pluginId PluginId) {
+	var request UpdatePluginRequestObject
+
+	request.PluginId = pluginId
+
+	var body UpdatePluginJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		sh.options.RequestErrorHandlerFunc(w, r, fmt.Errorf("can't decode JSON body: %w", err))
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.UpdatePlugin(ctx, request.(UpdatePluginRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "UpdatePlugin")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(UpdatePluginResponseObject); ok {
+		if err := validResponse.VisitUpdatePluginResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// ListPlaybackSessions operation middleware
+func (sh *strictHandler) ListPlaybackSessions(w http.ResponseWriter, r *http.Request) {
+	var request ListPlaybackSessionsRequestObject
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.ListPlaybackSessions(ctx, request.(ListPlaybackSessionsRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ListPlaybackSessions")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(ListPlaybackSessionsResponseObject); ok {
+		if err := validResponse.VisitListPlaybackSessionsResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// TerminatePlaybackSession operation middleware
+func (sh *strictHandler) TerminatePlaybackSession(w http.ResponseWriter, r *http.Request, sessionId openapi_types.UUID) {
+	var request TerminatePlaybackSessionRequestObject
+
+	request.SessionId = sessionId
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.TerminatePlaybackSession(ctx, request.(TerminatePlaybackSessionRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "TerminatePlaybackSession")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(TerminatePlaybackSessionResponseObject); ok {
+		if err := validResponse.VisitTerminatePlaybackSessionResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// Login operation middleware
+func (sh *strictHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var request LoginRequestObject
+
+	var body LoginJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		sh.options.RequestErrorHandlerFunc(w, r, fmt.Errorf("can't decode JSON body: %w", err))
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.Login(ctx, request.(LoginRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "Login")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(LoginResponseObject); ok {
+		if err := validResponse.VisitLoginResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// Logout operation middleware
+func (sh *strictHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	var request LogoutRequestObject
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.Logout(ctx, request.(LogoutRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "Logout")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(LogoutResponseObject); ok {
+		if err := validResponse.VisitLogoutResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetCurrentUser operation middleware
+func (sh *strictHandler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
+	var request GetCurrentUserRequestObject
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.GetCurrentUser(ctx, request.(GetCurrentUserRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetCurrentUser")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(GetCurrentUserResponseObject); ok {
+		if err := validResponse.VisitGetCurrentUserResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// ContinueWatching operation middleware
+func (sh *strictHandler) ContinueWatching(w http.ResponseWriter, r *http.Request, params ContinueWatchingParams) {
+	var request ContinueWatchingRequestObject
+
+	request.Params = params
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.ContinueWatching(ctx, request.(ContinueWatchingRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ContinueWatching")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(ContinueWatchingResponseObject); ok {
+		if err := validResponse.VisitContinueWatchingResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// ListDevices operation middleware
+func (sh *strictHandler) ListDevices(w http.ResponseWriter, r *http.Request) {
+	var request ListDevicesRequestObject
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.ListDevices(ctx, request.(ListDevicesRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ListDevices")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(ListDevicesResponseObject); ok {
+		if err := validResponse.VisitListDevicesResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// RevokeDevice operation middleware
+func (sh *strictHandler) RevokeDevice(w http.ResponseWriter, r *http.Request, deviceId openapi_types.UUID) {
+	var request RevokeDeviceRequestObject
+
+	request.DeviceId = deviceId
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.RevokeDevice(ctx, request.(RevokeDeviceRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "RevokeDevice")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(RevokeDeviceResponseObject); ok {
+		if err := validResponse.VisitRevokeDeviceResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// StreamEvents operation middleware
+func (sh *strictHandler) StreamEvents(w http.ResponseWriter, r *http.Request, params StreamEventsParams) {
+	var request StreamEventsRequestObject
+
+	request.Params = params
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.StreamEvents(ctx, request.(StreamEventsRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "StreamEvents")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(StreamEventsResponseObject); ok {
+		if err := validResponse.VisitStreamEventsResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// ListHistory operation middleware
+func (sh *strictHandler) ListHistory(w http.ResponseWriter, r *http.Request, params ListHistoryParams) {
+	var request ListHistoryRequestObject
+
+	request.Params = params
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.ListHistory(ctx, request.(ListHistoryRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ListHistory")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(ListHistoryResponseObject); ok {
+		if err := validResponse.VisitListHistoryResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetImage operation middleware
+func (sh *strictHandler) GetImage(w http.ResponseWriter, r *http.Request, imageId openapi_types.UUID, params GetImageParams) {
+	var request GetImageRequestObject
+
+	request.ImageId = imageId
+	request.Params = params
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.GetImage(ctx, request.(GetImageRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetImage")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(GetImageResponseObject); ok {
+		if err := validResponse.VisitGetImageResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetItem operation middleware
+func (sh *strictHandler) GetItem(w http.ResponseWriter, r *http.Request, itemId ItemId) {
+	var request GetItemRequestObject
+
+	request.ItemId = itemId
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.GetItem(ctx, request.(GetItemRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetItem")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(GetItemResponseObject); ok {
+		if err := validResponse.VisitGetItemResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// StartPlayback operation middleware
+func (sh *strictHandler) StartPlayback(w http.ResponseWriter, r *http.Request, itemId ItemId) {
+	var request StartPlaybackRequestObject
+
+	request.ItemId = itemId
+
+	var body StartPlaybackJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		sh.options.RequestErrorHandlerFunc(w, r, fmt.Errorf("can't decode JSON body: %w", err))
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.StartPlayback(ctx, request.(StartPlaybackRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "StartPlayback")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(StartPlaybackResponseObject); ok {
+		if err := validResponse.VisitStartPlaybackResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// ReportProgress operation middleware
+func (sh *strictHandler) ReportProgress(w http.ResponseWriter, r *http.Request, itemId ItemId) {
+	var request ReportProgressRequestObject
+
+	request.ItemId = itemId
+
+	var body ReportProgressJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		sh.options.RequestErrorHandlerFunc(w, r, fmt.Errorf("can't decode JSON body: %w", err))
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.ReportProgress(ctx, request.(ReportProgressRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ReportProgress")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(ReportProgressResponseObject); ok {
+		if err := validResponse.VisitReportProgressResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// RefreshItem operation middleware
+func (sh *strictHandler) RefreshItem(w http.ResponseWriter, r *http.Request, itemId ItemId) {
+	var request RefreshItemRequestObject
+
+	request.ItemId = itemId
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.RefreshItem(ctx, request.(RefreshItemRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "RefreshItem")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(RefreshItemResponseObject); ok {
+		if err := validResponse.VisitRefreshItemResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// StreamItem operation middleware
+func (sh *strictHandler) StreamItem(w http.ResponseWriter, r *http.Request, itemId ItemId, params StreamItemParams) {
+	var request StreamItemRequestObject
+
+	request.ItemId = itemId
+	request.Params = params
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.StreamItem(ctx, request.(StreamItemRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "StreamItem")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(StreamItemResponseObject); ok {
+		if err := validResponse.VisitStreamItemResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// MarkUnwatched operation middleware
+func (sh *strictHandler) MarkUnwatched(w http.ResponseWriter, r *http.Request, itemId ItemId) {
+	var request MarkUnwatchedRequestObject
+
+	request.ItemId = itemId
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.MarkUnwatched(ctx, request.(MarkUnwatchedRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "MarkUnwatched")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(MarkUnwatchedResponseObject); ok {
+		if err := validResponse.VisitMarkUnwatchedResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// MarkWatched operation middleware
+func (sh *strictHandler) MarkWatched(w http.ResponseWriter, r *http.Request, itemId ItemId) {
+	var request MarkWatchedRequestObject
+
+	request.ItemId = itemId
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.MarkWatched(ctx, request.(MarkWatchedRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "MarkWatched")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(MarkWatchedResponseObject); ok {
+		if err := validResponse.VisitMarkWatchedResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// ListLibraries operation middleware
+func (sh *strictHandler) ListLibraries(w http.ResponseWriter, r *http.Request) {
+	var request ListLibrariesRequestObject
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.ListLibraries(ctx, request.(ListLibrariesRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ListLibraries")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(ListLibrariesResponseObject); ok {
+		if err := validResponse.VisitListLibrariesResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// CreateLibrary operation middleware
+func (sh *strictHandler) CreateLibrary(w http.ResponseWriter, r *http.Request) {
+	var request CreateLibraryRequestObject
+
+	var body CreateLibraryJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		sh.options.RequestErrorHandlerFunc(w, r, fmt.Errorf("can't decode JSON body: %w", err))
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.CreateLibrary(ctx, request.(CreateLibraryRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "CreateLibrary")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(CreateLibraryResponseObject); ok {
+		if err := validResponse.VisitCreateLibraryResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetLibrary operation middleware
+func (sh *strictHandler) GetLibrary(w http.ResponseWriter, r *http.Request, libraryId LibraryId) {
+	var request GetLibraryRequestObject
+
+	request.LibraryId = libraryId
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.GetLibrary(ctx, request.(GetLibraryRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetLibrary")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(GetLibraryResponseObject); ok {
+		if err := validResponse.VisitGetLibraryResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// UpdateLibrary operation middleware
+func (sh *strictHandler) UpdateLibrary(w http.ResponseWriter, r *http.Request, libraryId LibraryId) {
+	var request UpdateLibraryRequestObject
+
+	request.LibraryId = libraryId
+
+	var body UpdateLibraryJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		sh.options.RequestErrorHandlerFunc(w, r, fmt.Errorf("can't decode JSON body: %w", err))
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.UpdateLibrary(ctx, request.(UpdateLibraryRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "UpdateLibrary")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(UpdateLibraryResponseObject); ok {
+		if err := validResponse.VisitUpdateLibraryResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// ListGenres operation middleware
+func (sh *strictHandler) ListGenres(w http.ResponseWriter, r *http.Request, libraryId LibraryId) {
+	var request ListGenresRequestObject
+
+	request.LibraryId = libraryId
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.ListGenres(ctx, request.(ListGenresRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ListGenres")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(ListGenresResponseObject); ok {
+		if err := validResponse.VisitListGenresResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// ListMovies operation middleware
+func (sh *strictHandler) ListMovies(w http.ResponseWriter, r *http.Request, libraryId LibraryId, params ListMoviesParams) {
+	var request ListMoviesRequestObject
+
+	request.LibraryId = libraryId
+	request.Params = params
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.ListMovies(ctx, request.(ListMoviesRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ListMovies")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(ListMoviesResponseObject); ok {
+		if err := validResponse.VisitListMoviesResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// ScanLibrary operation middleware
+func (sh *strictHandler) ScanLibrary(w http.ResponseWriter, r *http.Request, libraryId LibraryId) {
+	var request ScanLibraryRequestObject
+
+	request.LibraryId = libraryId
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.ScanLibrary(ctx, request.(ScanLibraryRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ScanLibrary")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(ScanLibraryResponseObject); ok {
+		if err := validResponse.VisitScanLibraryResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// ListShows operation middleware
+func (sh *strictHandler) ListShows(w http.ResponseWriter, r *http.Request, libraryId LibraryId, params ListShowsParams) {
+	var request ListShowsRequestObject
+
+	request.LibraryId = libraryId
+	request.Params = params
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.ListShows(ctx, request.(ListShowsRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ListShows")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(ListShowsResponseObject); ok {
+		if err := validResponse.VisitListShowsResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// NextUp operation middleware
+func (sh *strictHandler) NextUp(w http.ResponseWriter, r *http.Request, params NextUpParams) {
+	var request NextUpRequestObject
+
+	request.Params = params
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.NextUp(ctx, request.(NextUpRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "NextUp")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(NextUpResponseObject); ok {
+		if err := validResponse.VisitNextUpResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// StartPairing operation middleware
+func (sh *strictHandler) StartPairing(w http.ResponseWriter, r *http.Request) {
+	var request StartPairingRequestObject
+
+	var body StartPairingJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		sh.options.RequestErrorHandlerFunc(w, r, fmt.Errorf("can't decode JSON body: %w", err))
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.StartPairing(ctx, request.(StartPairingRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "StartPairing")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(StartPairingResponseObject); ok {
+		if err := validResponse.VisitStartPairingResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// ApprovePairing operation middleware
+func (sh *strictHandler) ApprovePairing(w http.ResponseWriter, r *http.Request) {
+	var request ApprovePairingRequestObject
+
+	var body ApprovePairingJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		sh.options.RequestErrorHandlerFunc(w, r, fmt.Errorf("can't decode JSON body: %w", err))
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.ApprovePairing(ctx, request.(ApprovePairingRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ApprovePairing")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(ApprovePairingResponseObject); ok {
+		if err := validResponse.VisitApprovePairingResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
 }
 
-// HandlerWithOptions creates http.Handler with additional options
-func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handler {
-	r := options.BaseRouter
+// PollPairing operation middleware
+func (sh *strictHandler) PollPairing(w http.ResponseWriter, r *http.Request) {
+	var request PollPairingRequestObject
 
-	if r == nil {
-		r = chi.NewRouter()
+	var body PollPairingJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		sh.options.RequestErrorHandlerFunc(w, r, fmt.Errorf("can't decode JSON body: %w", err))
+		return
 	}
-	if options.ErrorHandlerFunc == nil {
-		options.ErrorHandlerFunc = func(w http.ResponseWriter, r *http.Request, err error) {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-		}
+	request.Body = &body
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.PollPairing(ctx, request.(PollPairingRequestObject))
 	}
-	wrapper := ServerInterfaceWrapper{
-		Handler:            si,
-		HandlerMiddlewares: options.Middlewares,
-		ErrorHandlerFunc:   options.ErrorHandlerFunc,
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "PollPairing")
 	}
 
-	r.Group(func(r chi.Router) {
-		r.Connect(options.BaseURL+"/plugin/transport", wrapper.ConnectPluginTransport)
-	})
-	r.Group(func(r chi.Router) {
-		r.Get(options.BaseURL+"/transcode/{transcodeId}/manifest.m3u8", wrapper.GetTranscodeManifestM3u8)
-	})
-	r.Group(func(r chi.Router) {
-		r.Get(options.BaseURL+"/transcode/{transcodeId}/{segment}", wrapper.GetTranscodeSegment)
-	})
+	response, err := handler(r.Context(), w, r, request)
 
-	return r
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(PollPairingResponseObject); ok {
+		if err := validResponse.VisitPollPairingResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
 }
 
-type ConnectPluginTransportRequestObject struct {
-}
+// ConnectPluginTransport operation middleware
+func (sh *strictHandler) ConnectPluginTransport(w http.ResponseWriter, r *http.Request) {
+	var request ConnectPluginTransportRequestObject
 
-type ConnectPluginTransportResponseObject interface {
-	VisitConnectPluginTransportResponse(w http.ResponseWriter) error
-}
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.ConnectPluginTransport(ctx, request.(ConnectPluginTransportRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ConnectPluginTransport")
+	}
 
-type GetTranscodeManifestM3u8RequestObject struct {
-	TranscodeId openapi_types.UUID `json:"transcodeId"`
-}
+	response, err := handler(r.Context(), w, r, request)
 
-type GetTranscodeManifestM3u8ResponseObject interface {
-	VisitGetTranscodeManifestM3u8Response(w http.ResponseWriter) error
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(ConnectPluginTransportResponseObject); ok {
+		if err := validResponse.VisitConnectPluginTransportResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
 }
 
-type GetTranscodeManifestM3u8200ApplicationvndAppleMpegurlResponse struct {
-	Body          io.Reader
-	ContentLength int64
-}
+// Search operation middleware
+func (sh *strictHandler) Search(w http.ResponseWriter, r *http.Request, params SearchParams) {
+	var request SearchRequestObject
 
-func (response GetTranscodeManifestM3u8200ApplicationvndAppleMpegurlResponse) VisitGetTranscodeManifestM3u8Response(w http.ResponseWriter) error {
-	w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
-	if response.ContentLength != 0 {
-		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
-	}
-	w.WriteHeader(200)
+	request.Params = params
 
-	if closer, ok := response.Body.(io.ReadCloser); ok {
-		defer closer.Close()
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.Search(ctx, request.(SearchRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "Search")
 	}
-	_, err := io.Copy(w, response.Body)
-	return err
-}
 
-type GetTranscodeSegmentRequestObject struct {
-	TranscodeId openapi_types.UUID `json:"transcodeId"`
-	Segment     string             `json:"segment"`
-}
+	response, err := handler(r.Context(), w, r, request)
 
-type GetTranscodeSegmentResponseObject interface {
-	VisitGetTranscodeSegmentResponse(w http.ResponseWriter) error
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(SearchResponseObject); ok {
+		if err := validResponse.VisitSearchResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
 }
 
-type GetTranscodeSegment200VideoResponse struct {
-	Body          io.Reader
-	ContentType   string
-	ContentLength int64
-}
+// ListEpisodes operation middleware
+func (sh *strictHandler) ListEpisodes(w http.ResponseWriter, r *http.Request, itemId ItemId) {
+	var request ListEpisodesRequestObject
 
-func (response GetTranscodeSegment200VideoResponse) VisitGetTranscodeSegmentResponse(w http.ResponseWriter) error {
-	w.Header().Set("Content-Type", response.ContentType)
-	if response.ContentLength != 0 {
-		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
-	}
-	w.WriteHeader(200)
+	request.ItemId = itemId
 
-	if closer, ok := response.Body.(io.ReadCloser); ok {
-		defer closer.Close()
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.ListEpisodes(ctx, request.(ListEpisodesRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ListEpisodes")
 	}
-	_, err := io.Copy(w, response.Body)
-	return err
-}
 
-// StrictServerInterface represents all server handlers.
-type StrictServerInterface interface {
-	// Plugin Transport Connect
-	// (CONNECT /plugin/transport)
-	ConnectPluginTransport(ctx context.Context, request ConnectPluginTransportRequestObject) (ConnectPluginTransportResponseObject, error)
-	// Transcode Manifest M3U8
-	// (GET /transcode/{transcodeId}/manifest.m3u8)
-	GetTranscodeManifestM3u8(ctx context.Context, request GetTranscodeManifestM3u8RequestObject) (GetTranscodeManifestM3u8ResponseObject, error)
-	// Transcode Segment
-	// (GET /transcode/{transcodeId}/{segment})
-	GetTranscodeSegment(ctx context.Context, request GetTranscodeSegmentRequestObject) (GetTranscodeSegmentResponseObject, error)
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(ListEpisodesResponseObject); ok {
+		if err := validResponse.VisitListEpisodesResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
 }
 
-type StrictHandlerFunc = strictnethttp.StrictHTTPHandlerFunc
-type StrictMiddlewareFunc = strictnethttp.StrictHTTPMiddlewareFunc
+// ListServerAddresses operation middleware
+func (sh *strictHandler) ListServerAddresses(w http.ResponseWriter, r *http.Request) {
+	var request ListServerAddressesRequestObject
 
-type StrictHTTPServerOptions struct {
-	RequestErrorHandlerFunc  func(w http.ResponseWriter, r *http.Request, err error)
-	ResponseErrorHandlerFunc func(w http.ResponseWriter, r *http.Request, err error)
-}
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.ListServerAddresses(ctx, request.(ListServerAddressesRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ListServerAddresses")
+	}
 
-func NewStrictHandler(ssi StrictServerInterface, middlewares []StrictMiddlewareFunc) ServerInterface {
-	return &strictHandler{ssi: ssi, middlewares: middlewares, options: StrictHTTPServerOptions{
-		RequestErrorHandlerFunc: func(w http.ResponseWriter, r *http.Request, err error) {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-		},
-		ResponseErrorHandlerFunc: func(w http.ResponseWriter, r *http.Request, err error) {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-		},
-	}}
-}
+	response, err := handler(r.Context(), w, r, request)
 
-func NewStrictHandlerWithOptions(ssi StrictServerInterface, middlewares []StrictMiddlewareFunc, options StrictHTTPServerOptions) ServerInterface {
-	return &strictHandler{ssi: ssi, middlewares: middlewares, options: options}
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(ListServerAddressesResponseObject); ok {
+		if err := validResponse.VisitListServerAddressesResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
 }
 
-type strictHandler struct {
-	ssi         StrictServerInterface
-	middlewares []StrictMiddlewareFunc
-	options     StrictHTTPServerOptions
-}
+// ListSeasons operation middleware
+func (sh *strictHandler) ListSeasons(w http.ResponseWriter, r *http.Request, itemId ItemId) {
+	var request ListSeasonsRequestObject
 
-// ConnectPluginTransport operation middleware
-func (sh *strictHandler) ConnectPluginTransport(w http.ResponseWriter, r *http.Request) {
-	var request ConnectPluginTransportRequestObject
+	request.ItemId = itemId
 
 	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
-		return sh.ssi.ConnectPluginTransport(ctx, request.(ConnectPluginTransportRequestObject))
+		return sh.ssi.ListSeasons(ctx, request.(ListSeasonsRequestObject))
 	}
 	for _, middleware := range sh.middlewares {
-		handler = middleware(handler, "ConnectPluginTransport")
+		handler = middleware(handler, "ListSeasons")
 	}
 
 	response, err := handler(r.Context(), w, r, request)
 
 	if err != nil {
 		sh.options.ResponseErrorHandlerFunc(w, r, err)
-	} else if validResponse, ok := response.(ConnectPluginTransportResponseObject); ok {
-		if err := validResponse.VisitConnectPluginTransportResponse(w); err != nil {
+	} else if validResponse, ok := response.(ListSeasonsResponseObject); ok {
+		if err := validResponse.VisitListSeasonsResponse(w); err != nil {
 			sh.options.ResponseErrorHandlerFunc(w, r, err)
 		}
 	} else if response != nil {
@@ -456,19 +5619,297 @@ func (sh *strictHandler) GetTranscodeSegment(w http.ResponseWriter, r *http.Requ
 	}
 }
 
+// ListUsers operation middleware
+func (sh *strictHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	var request ListUsersRequestObject
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.ListUsers(ctx, request.(ListUsersRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ListUsers")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(ListUsersResponseObject); ok {
+		if err := validResponse.VisitListUsersResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// CreateUser operation middleware
+func (sh *strictHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	var request CreateUserRequestObject
+
+	var body CreateUserJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		sh.options.RequestErrorHandlerFunc(w, r, fmt.Errorf("can't decode JSON body: %w", err))
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.CreateUser(ctx, request.(CreateUserRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "CreateUser")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(CreateUserResponseObject); ok {
+		if err := validResponse.VisitCreateUserResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// DeleteUser operation middleware
+func (sh *strictHandler) DeleteUser(w http.ResponseWriter, r *http.Request, userId UserId) {
+	var request DeleteUserRequestObject
+
+	request.UserId = userId
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.DeleteUser(ctx, request.(DeleteUserRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "DeleteUser")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(DeleteUserResponseObject); ok {
+		if err := validResponse.VisitDeleteUserResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// UpdateUser operation middleware
+func (sh *strictHandler) UpdateUser(w http.ResponseWriter, r *http.Request, userId UserId) {
+	var request UpdateUserRequestObject
+
+	request.UserId = userId
+
+	var body UpdateUserJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		sh.options.RequestErrorHandlerFunc(w, r, fmt.Errorf("can't decode JSON body: %w", err))
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.UpdateUser(ctx, request.(UpdateUserRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "UpdateUser")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(UpdateUserResponseObject); ok {
+		if err := validResponse.VisitUpdateUserResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/+RTTW8aMRD9K9Ycq2UhIU1gr01VcUCtklQ9RDkYexZc4Y96vKgR2v9ejVl2ExJVyaGn",
-	"3ozNe2/em7d7UN4G79AlgmoPpDZoZT5+jtHHG6TgHSFfhOgDxmQwPyM/8yE9BoQKKEXj1tAWYJFIrvGV",
-	"t7aAiL8aE1FDdd9RDICHApJJW0Y8Fy+ORH71E1WClpmMq33W6CCfZNp4jWKJ2khxi3GHTL7DSMY7qOCs",
-	"nPB4PqCTwUAF03JSnkMBQaZN9jQO22Zt3DhF6Sj4mPhSeedYtNqDRlLRhHTg+xrQkZDiB67ujgCxkoRa",
-	"rIw2glJEaUvIklEyaqF50APht6zVI4GzOfglqFyz3RZAjbUyPkIFhz+LQacjyZke5lVe43jfHxe6HVvp",
-	"TI2USjttZmxgja/4uMHUREcibVAsp99nAh0TaHGEi9pHkTaGRM/+0tUXTHfH12UHXLIs5xulxYSRoLo/",
-	"VV9cC19n7Z5cEBLvjEXwt7Qh73eqzq9w8nEymk0vL0cX89lsNJ9Pz0bzWs31ldRncnUJXAuo8kahACct",
-	"I59kAk8bmGKDRVf5l7H0bsTimiepfbQyQQVNY/RQyb7bD88WuIfzyaSrT0KXY5chbI3KkY13Tpf8G0sb",
-	"cN3E7fD18akXWxnHDXgp1xYn8942SiFRmSsxNGewcdxKXvLfi7MnXFt0qX1TaSigMrVBLTrYuwtze8D9",
-	"N10pTgm6AARP8dyKcSaVNly8Pi71wb111FOl99d4ZzT68Yd/X9hjK9q2bf8EAAD//1wWQ8apBgAA",
+	"H4sIAAAAAAAC/+x9e5MTOfLgV1H4LmIjLsruB83zPxaYGW5hl6PhNze3PXErV6VtLWWVV5K78U7w3S8y",
+	"U1KpbJVdBrphbucvaFeVlMpMpVL5/G1UNstVo0E7O3ry22gljVyCA0N/Pa0qqC6VLgH/qsCWRq2cavTo",
+	"yehvut4IA25ttFAOllZIfFtIJxoj5MyBEW6hrHBqCRPxrFlOlQZxo9xC2MY4pediuvEfzRojDJSgXR1+",
+	"Ms2NnYyKkcLJ/rUGsxkVIy2XMHoyki1cxciWC1hKBHDWmKV0oyejSjoY47yjYuQ2K/zEOqP0fPTpUzF6",
+	"tja2MZkVreS/1iBKeixmplkKKVYGrlWztmIl59AHD3/SgWV32h8AqldqqdzuzK/lR7VcL4VeL6dgRDPz",
+	"GHWNx3DfxDWNl85bwUyuazd6cn5ajJY87ujJ2Sn+pbT/K2JFaQdzMATfj6DNIDoTCYm0c/ykDzZ6eAAn",
+	"Lx0sX1a7k758jkhwC6ApcQb4KJerGj9/WF1cXDy6OB0/rsrT8dlZdTaenl/cH9+fzaoSHl7MZHUeQFpJ",
+	"t2ghUjxbMTLwr7UyUI2eOLOGLvo6YDhYipfPcf7IW+u1qrJs9UpNjTSbH1TtwAzBI3EY4bHmT/upTI8J",
+	"9Ay3H4JoP4KTuVscn84ezWR1fzquHpfT8cWDx7OxPHtwf/zw9NGDhw/PHz2+fwp5HKewDkWzh/MITN/Z",
+	"Lrqf7qLzAbvob6bKUf+yMU5UykCJP/QB0tDHKSD/3cBs9GT0305aMX3CT+0JjsnT4cRv6vVc6f20XtE7",
+	"XVLfr07hfHq/HD+a3ZPji/IMxo+nD6vxuXwwu4BH5Vl1b5on9SrMOJzSDORgQuMKe3A5U1BXE3G5aG4s",
+	"nSd4mNwsQONClRG1dGCdgJWyTQXiRobzid7ZPoAmV7qHJPjmYIqgvCCYEfj3+ka6cgHVEFngFtKJhbwG",
+	"oRsnpgBa+K/7eGUdh8+I2GnT1CA1w2HB7GeLtQXTZYrH0/PZA2SFC3mvGl/M7sP40fShHJ+V59U9uJjd",
+	"lw96mGLNsw1nCQRvMEP8AnKQaDVQg7RQCaVZwm5Amj5M4rMcEttt/Sk8Za3o2esXb0xzrbI7/RkYp2aq",
+	"lA6EXLtFY5TbiLL91QppQCB6wDrUfUyznIiXM6GsXUtdgphJVdtC/B8wzeXlKyF1JV6B+5MVL3RpNisn",
+	"ZrKuxVSWH4RrrjTIciEatwBTiJuFqkFYJ+fI3gtphW7odXybuRw0Sq+/j/4NprG2HhWjGpwFHrr719gP",
+	"NPq1GDnliDU6q9+hUYE6owFrL8tmldEmfl6AASG1kPyaKKUWU0SHLBcRGc+bpVTxHbDIoUKKin8uGz1T",
+	"87XxeiMC1MFvd5l106xw8bgyiQ9W62mtylEx4uE6a0thz61ttTLNNbyRCn95yyQktdk0K4QAiEHKpqKl",
+	"L5V+BXruFulhkXBzu0X+zt+koGSniqM0039C6RCmhN9eXIPOyMunKXZY0qA4RHaDSpDmreGGJU13IYwh",
+	"+i/tq4wSFyGSxsgN/g0fV8rw90MU8mJkm7XhG0agWWWr5agYyXLJONmLOf95EaFtQUjwuYOm/aj8C2ze",
+	"bVYdoKA8v/8ABy/vPboYFSNj5fnpxSP+38Xp4wc904WhMit/ZkA68OpPLzuxmDrATsWo0bXS8KppPqxX",
+	"HR2G5e/2yVCMTNO4Y9mUYPGfpsvNLSSHYXoPRX7valfS2pvGVFuQPcqs2DQ1HDqSaS5871NBZ1NEpvwY",
+	"hn5wURyFgzhM0QLrgdlBSbrUDD6eXz5/fQnmGswuIj60LLhvgRlO+0T7OJ5P+77uSHPcixGYrgz5s7Qg",
+	"3r99FVQGfq+rNCycW9knJyfXZ5Nqo1nZnWhwo4M7mCdNoC7i6hOMJsjai8pXKsdXUYDF/+zDSzLXjojb",
+	"gp7Hy8JJkORghWtVwi6MJfFMNVx0qmrATRAPPusuAfTwgcM22U84ms1vhQB7MluKE15xLy7yNKvo2RFU",
+	"41kOUSwMuwNfL71Ms1pBFc/X7eN+nTt2/xqvoYDfWVHxKJNR9uLYVQZwxBS8FIAMgC+MacxbsKtG2wxf",
+	"AT7OHt5LsFbOB5Cah2g/SIDrTp6DDsHePk7JsEOXr1HBf6xXlXTtnwaWzTX9GUwUtiQFzoK1eIm2Thp+",
+	"v/2F0ITc2ErFiVdvRsWICTHxhOisIYKY2QxkGctzKBm5jlKQtvDqB0hAaWfLoPInZV1jNi+0M5ucpmeV",
+	"ntcgrhXc4AWgmQkpls21AtTz/GV4V8/DrVSDy11VL8HFa3UcFo89qOgnfwkVbmHALpo6Ze9E1+Atl7uG",
+	"ooYLleAXosmPNNRVLTeooepCqJmQejPgkjhYJuIsQ+7z+O6qsYrB3TFdSeuEgVWDnCjCe3jttFA2urId",
+	"kKtmPa0TFmNLFc4QeHmwgA6bJXfD0mJlmjldrxCNdQpjF559M3jKvlNLyLEFrU7YFWhHhFJ6Xgj4WNbr",
+	"ClkERxUrubZQDUJB7mQhErW4aRedUKQLZ5GwcrKlOrumf1e98ZJwS3hqZ9QRh1Bnssz9SMNH1+eCeOZ9",
+	"D42hnYCvsuNB/G2pnKPNQE+IpsElcUBye/h38UHrzaDjpd8aWyRfL5dopUWZwnaWQkgr7KK5IYavlXV2",
+	"V7awiB/M13hVR/n8cumJcXAje6nGx23OfDNYJHxQuhoiEv6C76Fa1cyb4XD2qFTEyw5MHGjLSoc/i8SE",
+	"Sy8XKM6tU3VNvOJRYAfJR28bey5dZrq3/BCHl8oIJFUheEjkPmnFL7/88sv49evx8+eT3OgWpG30PmLQ",
+	"fr10fvp9uP65ffNTwWa6zIg5yUGUjKops2DC/y9ZsGQZ/zk4NL/tyoFbZ+RSsoZxhKnlMOsfr6Dc3nZJ",
+	"3VGH7yxfZ3M1Rs2VlvUrqedrOd//0l97R7kGg8pP9uFKGtAup9u8w5vyorlhPYy3Bm1dvkLjn16cJqrZ",
+	"wbVuyYvD7/tb9csq8DEdnbJ+0+Hvne+2BFEFGlVqMDb1zbI3mEzQS3Cykk6KMOEkt8e+tfixTrq1zeih",
+	"pqnW5J0T/Ion2qK5mfSqR7csxlJnalekdTg2w+UJy0YZ4CVMlyF6pGOQgj1Css/SzHpBa2OmsQvh9Ta2",
+	"NdOtbldP+C6Ezt1u5f0k36JI790/rja5XNOVb1SMEOJR2BWjeGBsj/0X5q0ddOCzr2FNC/eo4+xocfae",
+	"RefV9a8B2d0r6dnF79PPW9e4t/IHyRBYwP/pnZvB0BIco9tTXbKbe4cDvEn/7kyV/Qf5jntj69brFmAE",
+	"v7V7FLHTFR2/yrsNtwNv+v0jWzJuapt67fBy6xZbQTQCPwnUb03kw42oNOXWWlvTakKzQJcMd/hH+X3L",
+	"gB5znQ0zHdq+7ci7YPbt4lfNXOlBnqBd+0fiyzneXZOCmMLQD2OvabX1dmbsMMwHZJwU/k00Za012UXE",
+	"C1Sb1hYEfHSA5hTlhttnXPMBdM4yw9PR40K4Bt3rFrRD7UmKKUgDhp9m9RpE1xCn2g6eGZ7W+eqH2kV1",
+	"v6GYbYF93pEq/j7MB1Ar/eEvkDGT/u/z+/fPHgsOBBAfYNP1ahViKi08uBCgywaDgwQFmNjUEsqwBPFS",
+	"CSlwNhyLYjDKhXDNnETSlW7jJXGqCoy6BuvFhv4gLJQGHActRMpPN+4YovOiB9FcvHSiasBSoBHTqhDT",
+	"tWsjMa6bDx0vxVfkjSq4gbZZo0P5Hs5AIHpjLbaO6KZCbIhKWbRNpncIDjWWaKTsui+f/vnZ8/GLH378",
+	"Kbfyo4MaVk1dv9QOzLWs+82nrhE3UjkxBXcDoAV+ZXMOIh7wXd+WRw5CQVLhiPhm1E6qhDMm4vXaOh9h",
+	"FnCTJXUuOCUFId3lnZVukRQHy1Gzlhu0j7yppe4/y2lHUnCHUNEZQIErrHgbqS1v0ETtKZsVnYrhYQei",
+	"dNoMzcJzL0IzuuUwHWYptZr5I23b2dHqDD+9uhThzUIYqKVT18S1+PTpm5ekTewIH78wz767fOdRum+L",
+	"dvBAV2IDcrkf2nDDFDNVw3BwvWfAh77Wm4HqEK0iQ7lAmT085V/5GteWrSEv2XJw9D0mB9nhBVxGO0V3",
+	"CVOpqxtVuUUmSGPjwIoVGO984vOsCjQqawXaFUJeg5FzvKZcg2kvKjO4OdJpNdSpF5gg2IyOcufxGL2W",
+	"uVvw9iE2npbI3Xu0OkZm8K2FWM4lVEoOV+E+Z7MaWDYOfKRi5nrCD8K2ZTD/ZMWysc6n2AR4ewxo8yXs",
+	"D2dgTYleE1MgwkZRXAjy1uGPlN5wNkx+de1zx3lC4+SXK8h7sPmsbWZMngTaZK8cgHPAbvhs5Yg4lr6O",
+	"Ls8Vn1Ct5zPhyW0WKBKR0C91vDjJyh2Myc/4O9ZucWg9/O1TfBMdGI3WnOAw7Ltn7fufiuMtCqDltIYq",
+	"F/b+WZFRPTud0xx4p/sV4p3gtbIW2VxhmonQgLJ0Ie3wzd9r6bBls4JjTimEj2OWD55Nia3BT1MwoVt0",
+	"Zu0NPEk/+zxd5w6kn5qbFIU4EWjno7QTpU2u1PgDbBAoNddQjfm+sDP/U4Y0o7htsVMuBjqQzkNDzmt+",
+	"aQoVZoIkkCpnoZ7lAmX8IF8cSZcR5HsYYUui0e+igrKWZhv2cP+UBoRbNBbE3EjtEjUgomniBc6XsVgx",
+	"ugYT9OVB0cPh/YQHt0Vai+gdJkjI3MuOee2PEXTszjq4qcKwO5D2a3otOndVJzBLxSachHAy5G4FU+MT",
+	"A7ISsq4x/8nfZ0W0wVHOCK2xiLbQJzdGOQiflAupMbXjSuNr8aVCrPy58cRHLcYpSlDXOEl4Ae+RHGKn",
+	"Gs0zxqn8tc1/25pWKF9lbaEjFdBEEb70F76tFI5kyRSCmC6IT8sU5PQVHm6XNL0pHm+auj6U39G5iu/n",
+	"+PbVFITdOXJc4mO43lLkVu58rlTzzmBqy5Pf2rTE05xSVa2NzAvG5/5Jx6d7KHwtM1mrB3HQV8c3MZO1",
+	"zaYg9AfWBfVleEzdXqDsekrYj/jayiDzjhtODvTvss+gmc0m+dH7XLdJbFrEe0r9Ll0zlH8Lc2UdGGbV",
+	"L80LYTNn1hD6546dU7yotg2jZDjzG9Wm5zcKAk7lpwOb7Ut2MsSEeYvqTVezSXCex+ge1EPVqxavVBaX",
+	"aAFJTMntYQyoTpA3HA1vJk7A+JMJtnsMOgGOIUdV9mjKYSIuMIOEy1LqHg9/YF52gwe3ly2lLnghdsE1",
+	"IOi+2Wg8BII1gnMTzVprf6nqIhazHnP3tx9UDT5BtmzWdRXsl2rZRrTuSrzwNHORhRs0H9IBiPZgGj68",
+	"LiymS5r8mMfFEiz5gpCvKmDFDelmPDsl/s4bDYX4oDGckhBHxyZa5UNMa49lGN/RuZW+pusuT+Ff2rs+",
+	"G6JZwsHb3j891ToBtkUg2cEUvU4BA5qkhTohVRywRV7CuS1X5lgWpCkXb8Gua5fTmOmxMPy8wM3GerO3",
+	"CGxWEHLJUY8J8ZR0AEwbdN9cZxh2/e9/b/ZEzOvGLVBOLn2IvLQ0UVXQHEhdDw9Ng4LWqqWqpRF2BXWt",
+	"9NzmHdMc3fGlIQ7u+uvGb4SYE3c9KjxuUup1CJSlICqIyXWoi+yFc6t7/b6Cn969e3NyTyjUV20TDJ/e",
+	"E4/slcekyoQSvHwTk5PdgpPeDNimJqddMxF/bRyZiZBg1VaictejdPb4fHL24NHkbHJ22nsSHszQS3OS",
+	"0cxkavY7b6fdnT0+H589eDQ+G5+djq8vJnJaVjCbbGfjPXl0cXHvoBUeZwkAFh75HWqmxDpEza9hi+/O",
+	"eLQNfheeXqB7Tr7LZgm8n32thtUKdMtjPtqDrYj4g1/shrd/MH/jnMilFlzGutAmLR2R9cnwopLv05oO",
+	"OcjTXLJoKcsZe23IXFPa+4AqdhhsxA0YaA/8AndIsPt6d3eLkmApow3Dk/uBt5zeSrsHF/nTfKD5Pi4K",
+	"T5eDLBUPlE8xhezgN/xa/Mz57JiBpuoB+bxtJtqOE92np6kl7LL2npMxgThjF4vXeRsCR5C9/cnPaQYx",
+	"bTEXjzFQGzrCTzNwxM9znlAgZOYY2XRidcKaxQ+kkITfbXggplBKb8dQiStasAKT2jZVxe4CMEul5QG9",
+	"qQj1UwbVd8o6EV6GzKmXOUdqhxty3BJrCnViC6UtkyXxX4jBztDx08yyLpGfDtlVuq6+LYkkl+Rsphhc",
+	"L49qhXeMmVil3kY6g5Ps/rPzR8WhK/LxkUI8126kUMi+o6iggu8+BhyKfA3zxinpYvwhCF9mBSqKBLrS",
+	"K9O4pmzqYMNVNmgy0ol/nFyf/WNQmFA23ZnQmpIrQ5EcQ9Brfhv1Ug6X22DQTZlRvv8LH4qSnqa+U7za",
+	"VIC/F2LNJ8RsuYI5vyrwMm+FXaMt24rF+YML0psXcF12DNfH5dumkO5gY2uZGXS8p0j2W6rYkSneFODL",
+	"ztsL3wHD0V7H2UDYv7IVp7vOg2Yafu3b1BHZgfZAjY/3Nlfd47bip7+kJsrAEybGKteQdxa+t/kSHfh7",
+	"/i6Aww5nJhr/0EbnIbeg6tP8I0YS44eslmQe8575OdF3a7y3TZ0l1s+d5KDdTJJybQyKQBz7T7ZN13YL",
+	"06zni5Bgm8mm7Rj9t3Q5fIa6SPnBpx5QyFzBBRCtawywYcKAXS97AzAG+ApaUzx5UTsJ5lRTj6c2AyMn",
+	"8Ps3BOsR8ZW13Dw7VHgDP7aZCghUGC0tshd8o6CrvujLPkcFmjSWIFaN0i71UYiOT6HVshdkwyT9OlQA",
+	"BTGFuYpm0QEYO+DOuPSPU2agPO1jeSEWGXzBiLP7sC2vparxZGntZzGFji4SnCWF5tsN2sja+oY9ScJ9",
+	"9SjIVBaNdTysZZspXIPZ9JI5ZwTaEhth4pTDkk2fbOwdMYJ0gXJtlNtcoqTyO7Yswdp3+4P18WacBOqK",
+	"uboGHdTc929fsR9mijWRwWCwZTCIS629OWoBsgJji6gxkY5/6R2xurrSbOuEGpZ098ZAcIVow5LLZQkr",
+	"14YSoMjxfl1UsinOxdFef//2Fdsrl8hCtfoA9cYHnNfNfL6vlCZj4v+GWPAgvdmf0r2C70lp4E1TN+QM",
+	"95EQdBFAuPFM8i5r3tRUOFC360Hb64ZeE6p90ZtrNeDKrjQOFxZCZw4xDEiTHmtoE+PSkErPGjo+Q+Uv",
+	"6RbIfWx+jxWrYpTE6GxySurfCrRcqdGT0b3J6eSc8lPcgpjmhA6fk8pWyzGbUejnObicAHJro1mzxtJT",
+	"3u5ixfONfk5mv33VJ/2JU7Bxhzng/dtXuFMi0vA+OsKj87mtlpcenGJkfCYHgXZ+euoDZJw3NMjVqvbR",
+	"ASf/9LfuYWVUtwpoEZK3BRzx0qSz6UZP/v5by0ThAP/1068oL6l2hV8GoykshFykLhtJiWdHgtKi9Rsx",
+	"uqn0ZEgxI2GEV0Sy8flc0I9+9zQaJuKp0HDjx0L2a6kgr3RCI2GdkWq+cELeyE0o6hknbjoj++EQ2xve",
+	"G8pZTkqkwkeyZkbuUvMSEmL6aq1g3Z+banMLZGQSdivCfroTBtrPPMXo4ivO2q2DlZn4XRsHg14FZYXS",
+	"17JW1WfwMZ6DCRvTAF5qJGFOewUGn5WJQzr4rBNRgKK7ELJugmEXmauNIPPRj8omkZFZyfHGw3SLRE9i",
+	"rr6+xAjw+/ILOaQyGm0M1So4fovPThIXfKrzzXkShtwbEdAJmeTHHGlxpX3gRYj9M+oaX8EoAvE3twBz",
+	"o/joC3EJXK4f6QJVYl9q2trJSbnkQKSc6OjGUtyS7MgHbAwSI2dfHYgYK5Hhq/adbyNPEl5RHGEiBcmU",
+	"TDTPZzB/WJ5IAky6Yubkt1AH/hPvihpyt97n9Lv1bm8ctI07a3dMpWwQLrxthsiXt5SxGNkxbWHy9zyG",
+	"21dOYtn8T7/ucNJFbpv77Eii9cXd0ZrhJALPmrWuPouWCLtoo1pXeJnJrZFMr4VgO6Gl24my/H+ZnBId",
+	"XYjFWhqzqhyqPNtx10jMlsxQXekQIqVsmIb8TnVjaT4a2D8nM3FOKKV2wy/lga8vzXJWzTtWifolWFcd",
+	"+n2xNCM2I5z8h0OVIEmpNTu+0EI0dQWYG6cM50vlNJtOns0tqzi7mYS3oet4LMQlZRB78pv/3wG5f+ma",
+	"lfUGwY6bmSPUbes7nYgf1oaCefwWtNFPFr5Bv2lQkvxv49axeqUBWa4QtmHryJavyUFdx34X4maxSYDy",
+	"/t6MZHkXxt/NQd2SMv1dV7pL7wYJ3SvPH8Lp/dPxo3sPHowvHj96NH78+N7Z+PGsfFw9lNWZnD7It9qI",
+	"BDiqAcsWGYZ13hh0NkZMfYPjMZiIvkSYRPh3doDfAGu3OCHDEycB5K4BzxZQfkB+D44S4vPgiyq8po3K",
+	"TeRgb9ZyDb2Wr9KxJXaa21O8O+VQ7viM6tYHOXhUnd2tph0p2phIUL7Dl42h6LptluuK1mauthipWbt+",
+	"TnpB9SESX1GQHhxjUMq6bm+MVbf+R1v3AXUmzfERWT5CEIbs7Vdk2xXNOqwyXRcO0i5sCQcP3SiEU+E+",
+	"BbQyUIzlDqQ/gnvGaPC+xVvjQZ+he+BMjav/EZzwkAn/aTE6QUiUXsP4xsf+HcQIeai2go89bU3iYCrS",
+	"PO56E4pi9KgnzzwYPwcojlWMuy3tPhUHP2h7HH769RaJFCvDDSdUwIWIyCBKJR0GDnKsfzeEG4V0ysSV",
+	"22M193PcpsGzbV8wHCVsCvfApdg4+S2E9u1V7d6TYLFR/BTBnCn97d2GsjNP8bi1Yik3vrxQxCWZyTc9",
+	"yOMLaywKNFDd4qG7StZ5eR8eyYvp+OHsrBpfwD05fjx9UI5Pq0ezc3kG98uHPf3MAiaO0LEY4K+pWX0z",
+	"q4NfyrZWtW1S4NeYh3wOZt+GuqRaMzGyWFqOJ+Zw6m4wdYgKYwv3+BJ3GTk1ra+c5ntRkJsXP6Mx2f0i",
+	"LfLfleaf2IrdvqIq/4JQPhsj+Olo/PCwkk5S59olw2p8Ua0VGNVUCo9g8n1+AFjh6Fc6sYw3K+R8MvnT",
+	"kgUVeLtpq8LhdMIu1jgRuea5Zy5bZvnaYkO4gAE/8uRKX+mg7AYcGmAzLgGH7iHebiHLZM9Jy4tPXU9+",
+	"TBoPk3V5rIl45gFyTSNs3dxwIEEJeG32TndGXUCS1KLbbiPgnoIhSuDt2lgQS2Wtz4q50nHBM3Dox9ab",
+	"EHQPm1CyipDwZ3aG24wPXND1MnWA+zthcK+jIFLaOpAYyevt8p6JUi81FYzcXOkoebKuNCLvi5B6vFdK",
+	"YVy3jS1hEhxOEFqzSfIDtM8iajiMpK95IX5gO90LB4VQJVHmO3FUh09uBx8db/RxWzuqv8/vXj3+/um9",
+	"b+KBQyQv1o6OKtx/ey+Mv6Lu04nk+Pu2P443uWcDkoQL7rJwULeQVCM2Vuzphob5liu2o/nts0f53g5H",
+	"63q+2u0AJe8OFLy0QcX35sH1/cF3vLeRFUjDFIEOxApqKeeoV9G/Xq06wBMlN8SUxt005oOgLwvi1Lrh",
+	"ag+KSs0SKyC3TASVhPfnwYpLvJNzz6p/40gaxeo4hKpfxZs2ilCDRlA8qdDR++KdnNPrKCenEEDxPsZ6",
+	"E5yOfEjxIdcK1i2BeqV3JaqI3MSBTbS4GJnUF9qUE74/gqNVD1cPaa6tFsjybHoKF+X4fPawGl9MH5yN",
+	"H1enML5XnslHswfTc3h82tNRnOl5TEtx389jUHnwXc3SKvYTV+iK12KlPkJtmQL+RysMampQifWKK4fY",
+	"9SokN6t/s0FKtZzClYvWK1vKel/0Fg3fOWhiX+x7jy4ONsbeqbiwdqu183U5vU3DH3WF+Bmmb9jdA5VQ",
+	"TLenFKLmeSFUFlGuEE30dv/PNy9+7DsnPa5T+EOg7z9XMB8VoxuYrnKpzIflHGHzhIbpiJo2TUPpTrHm",
+	"lsL+W5r7yG/3SsV7uWsEZq4um0rN1LfyVvOqgqc6MubdX2x4G6b3GtREzu8WGyz2OkUNgnz3rZDpGuJL",
+	"4nyZfoKGKlq0P5AcLPE8ojyxT4PMH7N1XYuK+0IkjeUKCsUtYg+ETpu5XWHt69sdpZq8JChv36QUml58",
+	"T05ShKv//k1UpeT8XaKeBL9Tv42Zsp6y3jk8lTOdA/k+u12TNxR3bW+33uvmN7i/i1+3eWCp1GbPflKU",
+	"kTwmPHLIh0hur3+ysWruJHoo8XLt78/uprnSS6XXzptQm3WseGkLutP7qpV8deFgg5iBwWkMreuR0jCy",
+	"t74kYezLOPrrO3Gy2Wx3HDy17S7N8Da3SP5GRxEXugohUzuc/p1tdKJo9Evmt7tn4f7t/hbKxniv0mqn",
+	"vla+VyjNCSaxROH+6JrAOD45NoGUmiPq0W5CFjw8HOADhUH7dHKM4iFZEmefQtksY3xY6vEIZqxAsKU0",
+	"H7iaSkjeCdVyrnQcjjqUppXn/mQzrUrFW7/bqWhIMOO1RXO3xWKn89WVljVabrIhkjhsqJX0vUmHrQJk",
+	"d+zkTbthfWc3+9+XSGDyichlOZlgYIa83i8S/tca1uR3FGpJ+UEuaZ7jv046fk7Ei1gaKUm94pQSehmq",
+	"2PkiVgJRnK6V3yn01VdWC89zso/X8i9ccPWd0PK46E1eQK+611piD6rynWL+ecHPhpnghggWGZKIqLHF",
+	"ev7irdRzaEO2KIEqXuyKyANsVFpTeCdaLl7Oxvyla4K8t3IGOOJec9J++zxDzYrm55iT2Ir7RdxYHAy5",
+	"cq1PKPXE4PETygSFV+mEYR8NZiIGt2os+N1n7bAxRi1jURjsidwWv4TXk//xVQ0V56cPbmmWN9I4JWvh",
+	"h2bWMB1u/eOYCaKp8KLARNbr2kYuzu48T4rBwLqZbYgnJZdrdnB+HYdNrzjtZCDnAyBeS/PBhqNR+AKf",
+	"/rtClDVIE3ZsqtKmlQbDCYpKLRo1vYKpuj2du0IKp32vk0Tl786YcoyO993oU4hW0eK1Ly10h+h3Q/Cf",
+	"/yD3LZA7YJUEQKc34oCkAX5/s5MvmffLvoqj32YEbdJv8chAsBa+QTmOmMnc6XqJzP0vf5vgsrXNzPcz",
+	"3IpKJKtP28DyNq64nTm+kQEsrPB7M3wRtaL64TVy1ucbs/EwPb47mJ5GPpK1AVltOLfdtl1aqbHY8Tcm",
+	"xrJo6ZDu8ZPfYlHiId54NGPVkPaL3XFttBz9WUG2ty2k97DjN5PQHqb9/o1XbQPanoRFzgIL1j4K6ekW",
+	"KJ/05At+LZLdVsbg5wiw/0yO+YykwcNy4cQ3zh9iUuFXOWJhuuHeK22dSq8ooESjijQWr1sOjRBZXeFH",
+	"nvc7lSQE3WEV43uTJaTleMzuoTlnYhwRtMcfbNF6Inar/FPVJF8LGf8PH8t6TVVD38RoHIp6ZRZaSXr1",
+	"SrNLxFdo8NFnZFBqKy3LJQSO4lsGVjzP2LcQB695gV/AXIdDBAnNQ178BeSgmMP0Xnbw5adVBdWl0uUg",
+	"EKj1/YD3uLbtp+L3FUmJbPi7CaP8LkWG3y97REYoNr7fxcIBNOFmlMqKXTN0KfWtqJPnuWZyUn8zr8jX",
+	"OM5pAQMOc6oYeIRcp/d/P2KdTEx/SPU/pPofUn2IVOftQuICa+WN16uDogG3JL4by4tS936M9ceEHkxI",
+	"C8XpQygOavwYqBzcejMqcGGjk6CNsvEGYnSeJtZEiqeTOk54E4rt+u8Td3wmWZgkTw0zyjMKKckipCTn",
+	"5Mhf4aN7v/pPThNGDIj3K2aMFVfHPxg/6d9L8vATjzNIq+oNFiwVSrfVWaV491/s6vbf+Ny2EAEVzgVy",
+	"xfuOrleaS6wii1FSH2g6GqC2cLMAQxwpV1iQEqj60muQmoOzkpIA2JKSjWsxQ9cnWnKvpLrmHydXmprZ",
+	"SOoM79dog09+BZqSUFZgQvujNlTLV82J73j8KBv7v2znQS6kvdKuaTC2c9Mfb+kJcptRk92OCHcdNOkX",
+	"+L3ZjLE9Ragx1xHs53doKH7n2aPlRRRwibgN9bSVDSy5vySHD6qMOG+3/InfRf1b/ym/4IMpPYNHzY0j",
+	"VnjzWjXXYXf5LahiNMv+6gF+kttl++4k38jWGDPIvx+D0l+bHQHGieZUjbCC7aPD4zHPTyhW9wXmbleZ",
+	"8HI59oFMIaCK5p5BqzRcaqecJ37ezSvnKFk/mG4Ehh+B8R6XnNxNehXfEv9luiHfdW08KujRz4JPA645",
+	"YOk8n1m1ASdk+uadcuu7hEmqBti9R2QVDR2vAj6uuAzQPpGIxNjiYKqtd0L5GaERtS9GkOmjvAK6Q/8M",
+	"03fhgxjKQ1mmUc+gYcXbN89E6HrkI8L5QeNHQuyZpg4BcxxCoSuL2tAC6roRqgLt1GzjIy+gnhUts8fy",
+	"2NregIlq8g3UZbOEiXgmURlSWoCikFX2gyK03D+fghArEI2mC7syAtuzTlV8TwbQbLHdED1UQvAhf92V",
+	"sLKUVHCgV1ELqqSTcyOXFvU3KnDBLyztfIXmgdhr6hI6fdTxIZouQubKzMgl6/lX+s1uB2doT6u02lgb",
+	"j5JWaU0KBntEKWdjaWHKFaKvY1niWCuaChKH6Zdr60QFZe2RG4qGUnEHDaEAMc5OtGUk4csG/slFRBUX",
+	"jKGOubpxV5qkYVJ11C3yCTvPmGcZlMic28EYd15VLEruYEJqTFfDuuvyCJ6b4kETSrIekBv8VbvpPbpZ",
+	"hlhq/tpfE4YeIx9Q2/WijU+OP1yDwUoIthAl3n+TOlm+TxgFm6Q+t6aUtYiWwFBfA4u2XWniQ+rL6bNV",
+	"KC2GfkDFbGVgpj6C9WnJ3T6+tghterHiygpq5z8nnjYKqlBaZCJ8u9srjc/mplmvtrsOF2IKARawvq5D",
+	"7vrDGDyQ5c5vCSzP0RcZHP7sz1ff2/grk8jNlWdoYtz1oYR4YtzOwdFpCT04RrnYjcajHHShYxca5gLX",
+	"eC1IyNI0llp9MAVsP0RL1U0Qj/0P750Wbbb72emhZPfbtGl0+ygPN2zwd3E7WirhGmJdIenuc9AEttXc",
+	"h8bqxMDFjUk0yXu4Yz+h7zUX+Lvzb1+23ZP6rZoRrZ7OqPucxO7Ug8hLDX4SzcnbewwaOClULKV2bGBF",
+	"xWl8RbwaUET7jtOxcBR6aYwsO0WybDiwfe8Un383k9ZReu8bLl0fF9CWumrBiZYkOnYUWbZupKny1XzJ",
+	"U5L2gr7deMzdttNHRmXyAKIFlgmL1uJ2+/r9PIi8sVtW6MzV2brWM9menXvpJ/tj4w7duOgUOOCM8Dgl",
+	"4sZM+JPf4n8pMCVkvS/vrR8NovXre+8fhetCTJpvwyqTytuo+MUXaoq+ZGaZ+xSqNjvfNmImDetNCivs",
+	"6cpbGuiTmwX11nKwbFXI9mvsnt+qvBqAuuSGet1aUhX2n15d+v6BLDW4EhxZLiKMoXp4gPAJTW6krppl",
+	"vWk7nSR1rguWHGyaC8U7uU5Bp+V1SPotrjRdK6xPOYtqXb4M0buwyNcextdIpsFliSKOvnaV8ISHjihT",
+	"FFfz2WUs9+3qa11N8G+YLFcwX5v6q6aUffPC47+PdKlu7fNI78C+JD32S6Tf/O4bVj/GrqCk0kNh02Yl",
+	"0VPb2iXCbi86OZEvn4vOvpzs3Y2XPNd/zEYsdu+EjOyQmdIuRWnlJsvVRR5cGxE3FNTtmb6PBNNvIg0o",
+	"j9Jj4/8XwRB2EomE2LB5QJ4UvtvRMkMl/byC+d7ecnvJ2Aj667dOYdh7E6jYhxvSp/I+P34nlrm/rdyo",
+	"tGn5HTu5+0rsdz3cj79Rdwc8jiS13fjchKO2DwDtkpPf8J+B7dG4cQjbN30DCBVzsjNHHX/nueW4C9l7",
+	"gmpg3zOe5xv4tRDKbRn6LXiD+AKNG2R+Pph/nmcQxqII/et70pmeJV3VYqORxnDXYrq3k+QQ9F70p4UX",
+	"2TkWE4tDHeF+FuJ0mC9nodtKgjpaUt1lM5D/wN3ATf/EDZUDq4EdccLXif/sbKwW1b0VHX799P8GAERD",
+	"JU2u1gAA",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
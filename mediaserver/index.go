@@ -1,20 +1,106 @@
 package mediaserver
 
+import "errors"
+
+// ErrNotFound is returned by an Index that holds no information for the requested entry.
+var ErrNotFound = errors.New("not found")
+
 type MediaType string
 
 const (
-	MediaTypeTv    MediaType = "tv"
-	MediaTypeMovie MediaType = "movie"
+	MediaTypeTv         MediaType = "tv"
+	MediaTypeMovie      MediaType = "movie"
+	MediaTypeMusic      MediaType = "music"
+	MediaTypePerson     MediaType = "person"
+	MediaTypeCollection MediaType = "collection"
+)
+
+// Well known provider names, used as keys in IDs.
+const (
+	ProviderTMDB  = "tmdb"
+	ProviderIMDB  = "imdb"
+	ProviderLocal = "local"
 )
 
+// IDs maps a provider name to the identifier that provider uses for an entry.
+type IDs map[string]string
+
 type SearchResult struct {
 	Type         MediaType
+	IDs          IDs
+	Name         string
 	OriginalName string
 	OriginalLang string
 	Overview     string
+	ReleaseDate  string
+	PosterURL    string
+	BackdropURL  string
 	Popularity   float32
 }
 
+type ShowStatus string
+
+const (
+	ShowStatusUnknown ShowStatus = ""
+	ShowStatusOngoing ShowStatus = "ongoing"
+	ShowStatusEnded   ShowStatus = "ended"
+)
+
+// Details is the full record for a movie or show.
+type Details struct {
+	SearchResult
+	Genres  []string
+	Cast    []string
+	Status  ShowStatus
+	LogoURL string
+}
+
+// EpisodeRef identifies a single episode of a show.
+type EpisodeRef struct {
+	// Show holds the IDs of the show.
+	Show IDs
+	// Episode holds any IDs already known for the episode itself.
+	Episode IDs
+	Season  int
+	Number  int
+}
+
+type EpisodeDetails struct {
+	IDs      IDs
+	Season   int
+	Number   int
+	Name     string
+	Overview string
+	AirDate  string
+	StillURL string
+}
+
 type Index interface {
 	Search(name string) ([]*SearchResult, error)
+
+	// Details returns the full record for the entry identified by ids, or ErrNotFound if the index does not know it.
+	Details(t MediaType, ids IDs) (*Details, error)
+
+	// Episode returns the record for a single episode, or ErrNotFound if the index does not know it.
+	Episode(ref EpisodeRef) (*EpisodeDetails, error)
 }
+
+// Identification describes what a media file contains, as determined from the file and its surroundings.
+type Identification struct {
+	Type MediaType
+	// IDs of the movie, or of the show for episodes.
+	IDs IDs
+	// Name of the movie, or of the show for episodes.
+	Name string
+	Year string
+	// Episode is set when the file is an episode of a show.
+	Episode *EpisodeRef
+}
+
+// PathIndex is implemented by indexes that can identify media directly from files on disk.
+type PathIndex interface {
+	Identify(path string) (*Identification, error)
+}
+
+// TagReader extracts the container level metadata tags of a media file. Keys are lower case.
+type TagReader func(path string) (map[string]string, error)
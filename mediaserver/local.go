@@ -0,0 +1,709 @@
+package mediaserver
+
+import (
+	"errors"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// LocalIndex is an offline Index backed by Kodi style .nfo files stored alongside the media, and by the tags embedded
+// in the media files themselves.
+type LocalIndex struct {
+	roots []string
+
+	// TagReader is used to read embedded container tags. Tags are ignored when nil.
+	TagReader TagReader
+
+	// ErrorHandler is notified of paths that could not be read while loading the index. Such paths are skipped.
+	ErrorHandler func(path string, err error)
+
+	mu       sync.Mutex
+	loaded   bool
+	entries  []*SearchResult
+	episodes map[localEpisodeKey]string
+}
+
+type localEpisodeKey struct {
+	show   string
+	season int
+	number int
+}
+
+var (
+	episodeNameRe   = regexp.MustCompile(`(?i)^(.*?)[\s._-]*s(\d{1,2})[\s._-]*e(\d{1,3})`)
+	episodeAltRe    = regexp.MustCompile(`(?i)^(.*?)[\s._-]*\b(\d{1,2})x(\d{2,3})\b`)
+	yearNameRe      = regexp.MustCompile(`^(.*?)[\s._(\[]+((?:19|20)\d{2})(?:[\s._)\]]|$)`)
+	seasonDirNameRe = regexp.MustCompile(`(?i)^(season|series|s)[\s._-]*\d+$|^specials$`)
+)
+
+func NewLocalIndex(roots ...string) *LocalIndex {
+	return &LocalIndex{
+		roots: roots,
+	}
+}
+
+// Reload rescans the roots for .nfo files.
+func (l *LocalIndex) Reload() error {
+	found := newLocalEntries()
+
+	for _, root := range l.roots {
+		l.walk(found, root, true)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries = found.entries
+	l.episodes = found.episodes
+	l.loaded = true
+
+	return nil
+}
+
+// Invalidate discards the index, so it is rescanned when next used.
+func (l *LocalIndex) Invalidate() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.loaded = false
+	l.entries = nil
+	l.episodes = nil
+}
+
+// Refresh rescans only the given changed paths, rather than the whole roots. Directories, and paths that no longer
+// exist, are rescanned recursively. For files, only the directory containing them is rescanned, except for show .nfo
+// files whose episodes may be below them. An index that has not been loaded yet is left to load in full when used.
+func (l *LocalIndex) Refresh(paths ...string) {
+	l.mu.Lock()
+	loaded := l.loaded
+	l.mu.Unlock()
+
+	if !loaded {
+		return
+	}
+
+	for _, path := range paths {
+		dir := path
+		recursive := true
+
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			dir = filepath.Dir(path)
+			recursive = strings.EqualFold(filepath.Base(path), nfoRootTvShow+".nfo")
+		}
+
+		found := newLocalEntries()
+		l.walk(found, dir, recursive)
+
+		l.mu.Lock()
+
+		if !l.loaded {
+			l.mu.Unlock()
+
+			return
+		}
+
+		l.entries = slices.DeleteFunc(l.entries, func(e *SearchResult) bool {
+			return localPathUnder(e.IDs[ProviderLocal], dir, recursive)
+		})
+		l.entries = append(l.entries, found.entries...)
+
+		for key, p := range l.episodes {
+			if localPathUnder(p, dir, recursive) {
+				delete(l.episodes, key)
+			}
+		}
+
+		maps.Copy(l.episodes, found.episodes)
+
+		l.mu.Unlock()
+	}
+}
+
+// localPathUnder reports whether path is within dir, either directly or, when recursive, at any depth.
+func localPathUnder(path string, dir string, recursive bool) bool {
+	if !recursive {
+		return filepath.Dir(path) == dir
+	}
+
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+}
+
+// localEntries holds the records found while walking for .nfo files.
+type localEntries struct {
+	entries  []*SearchResult
+	episodes map[localEpisodeKey]string
+}
+
+func newLocalEntries() *localEntries {
+	return &localEntries{
+		episodes: make(map[localEpisodeKey]string),
+	}
+}
+
+// walk adds the .nfo files found under dir, descending into subdirectories only when recursive.
+func (l *LocalIndex) walk(found *localEntries, dir string, recursive bool) {
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Like invalid files, unreadable directories are skipped rather than failing the whole index. A directory
+			// that no longer exists simply has nothing left to index.
+			if l.ErrorHandler != nil && !errors.Is(err, fs.ErrNotExist) {
+				l.ErrorHandler(path, err)
+			}
+
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if d.IsDir() {
+			if path != dir && !recursive {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if !strings.EqualFold(filepath.Ext(path), ".nfo") {
+			return nil
+		}
+
+		doc, err := readNFO(path)
+		if err != nil {
+			// Invalid files are skipped rather than failing the whole index
+			return nil //nolint:nilerr
+		}
+
+		switch doc.XMLName.Local {
+		case nfoRootMovie, nfoRootTvShow:
+			found.entries = append(found.entries, localResult(path, doc))
+
+		case nfoRootEpisode:
+			show := l.findUp(filepath.Dir(path), nfoRootTvShow+".nfo")
+			season, number, ok := doc.episodeNumbers()
+
+			if show != "" && ok {
+				found.episodes[localEpisodeKey{show: show, season: season, number: number}] = path
+			}
+		}
+
+		return nil
+	})
+}
+
+func (l *LocalIndex) ensureLoaded() error {
+	l.mu.Lock()
+	loaded := l.loaded
+	l.mu.Unlock()
+
+	if loaded {
+		return nil
+	}
+
+	return l.Reload()
+}
+
+func (l *LocalIndex) Search(name string) ([]*SearchResult, error) {
+	if err := l.ensureLoaded(); err != nil {
+		return nil, err
+	}
+
+	query := normaliseName(name)
+	if query == "" {
+		return nil, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var (
+		exact   []*SearchResult
+		partial []*SearchResult
+	)
+
+	for _, entry := range l.entries {
+		n := normaliseName(entry.Name)
+		on := normaliseName(entry.OriginalName)
+
+		switch {
+		case query == n || query == on:
+			exact = append(exact, copyResult(entry))
+		case strings.Contains(n, query) || strings.Contains(on, query):
+			partial = append(partial, copyResult(entry))
+		}
+	}
+
+	sort.SliceStable(partial, func(i, j int) bool {
+		return len(partial[i].Name) < len(partial[j].Name)
+	})
+
+	return append(exact, partial...), nil
+}
+
+// Details reads the record referenced by the local ID, which is either the path of an .nfo file or of a media file
+// containing embedded tags.
+func (l *LocalIndex) Details(t MediaType, ids IDs) (*Details, error) {
+	path := ids[ProviderLocal]
+	if path == "" {
+		return nil, ErrNotFound
+	}
+
+	if !strings.EqualFold(filepath.Ext(path), ".nfo") {
+		return l.tagDetails(t, path)
+	}
+
+	doc, err := readNFO(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	if mt, ok := doc.mediaType(); !ok || mt != t {
+		return nil, ErrNotFound
+	}
+
+	logo := findLocalArtwork(filepath.Dir(path), "", "clearlogo", "logo")
+	if logo == "" {
+		logo = doc.logo()
+	}
+
+	return &Details{
+		SearchResult: *localResult(path, doc),
+		Genres:       doc.genres(),
+		Cast:         doc.cast(),
+		Status:       doc.status(),
+		LogoURL:      logo,
+	}, nil
+}
+
+func (l *LocalIndex) Episode(ref EpisodeRef) (*EpisodeDetails, error) {
+	path := ref.Episode[ProviderLocal]
+
+	if path == "" && ref.Show[ProviderLocal] != "" {
+		if err := l.ensureLoaded(); err != nil {
+			return nil, err
+		}
+
+		l.mu.Lock()
+		path = l.episodes[localEpisodeKey{show: ref.Show[ProviderLocal], season: ref.Season, number: ref.Number}]
+		l.mu.Unlock()
+	}
+
+	if path == "" {
+		return nil, ErrNotFound
+	}
+
+	if !strings.EqualFold(filepath.Ext(path), ".nfo") {
+		return l.tagEpisode(path)
+	}
+
+	doc, err := readNFO(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	if doc.XMLName.Local != nfoRootEpisode {
+		return nil, ErrNotFound
+	}
+
+	season, number, _ := doc.episodeNumbers()
+
+	dir := filepath.Dir(path)
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+
+	res := &EpisodeDetails{
+		IDs:      doc.ids(),
+		Season:   season,
+		Number:   number,
+		Name:     strings.TrimSpace(doc.Title),
+		Overview: doc.overview(),
+		AirDate:  doc.releaseDate(),
+		StillURL: findLocalArtwork(dir, base, "thumb"),
+	}
+
+	res.IDs[ProviderLocal] = path
+
+	if res.StillURL == "" {
+		res.StillURL = doc.poster()
+	}
+
+	return res, nil
+}
+
+// Identify determines what a media file contains. Sidecar .nfo files take precedence, followed by the Kodi folder
+// layout, embedded tags and finally the file name.
+func (l *LocalIndex) Identify(path string) (*Identification, error) {
+	dir := filepath.Dir(path)
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+
+	if sidecar := filepath.Join(dir, base+".nfo"); fileExists(sidecar) {
+		if doc, err := readNFO(sidecar); err == nil {
+			switch doc.XMLName.Local {
+			case nfoRootMovie:
+				return nfoIdentification(MediaTypeMovie, sidecar, doc), nil
+			case nfoRootEpisode:
+				if season, number, ok := doc.episodeNumbers(); ok {
+					return l.episodeIdentification(path, doc.ShowTitle, season, number, IDs{
+						ProviderLocal: sidecar,
+					}), nil
+				}
+			}
+		}
+	}
+
+	if movie := filepath.Join(dir, nfoRootMovie+".nfo"); fileExists(movie) {
+		if doc, err := readNFO(movie); err == nil && doc.XMLName.Local == nfoRootMovie {
+			return nfoIdentification(MediaTypeMovie, movie, doc), nil
+		}
+	}
+
+	if l.TagReader != nil {
+		if res := l.tagIdentification(path); res != nil {
+			return res, nil
+		}
+	}
+
+	if show, season, number, ok := parseEpisodeName(base); ok {
+		return l.episodeIdentification(path, show, season, number, IDs{}), nil
+	}
+
+	name, year := parseMovieName(base)
+
+	return &Identification{
+		Type: MediaTypeMovie,
+		IDs:  IDs{},
+		Name: name,
+		Year: year,
+	}, nil
+}
+
+func (l *LocalIndex) episodeIdentification(
+	path string,
+	show string,
+	season int,
+	number int,
+	episodeIDs IDs,
+) *Identification {
+	res := &Identification{
+		Type: MediaTypeTv,
+		IDs:  IDs{},
+		Name: strings.TrimSpace(show),
+		Episode: &EpisodeRef{
+			Episode: episodeIDs,
+			Season:  season,
+			Number:  number,
+		},
+	}
+
+	if showNFO := l.findUp(filepath.Dir(path), nfoRootTvShow+".nfo"); showNFO != "" {
+		if doc, err := readNFO(showNFO); err == nil && doc.XMLName.Local == nfoRootTvShow {
+			show := nfoIdentification(MediaTypeTv, showNFO, doc)
+			show.Episode = res.Episode
+
+			return show
+		}
+	}
+
+	if res.Name == "" {
+		res.Name = showNameFromPath(path)
+	}
+
+	return res
+}
+
+func (l *LocalIndex) tagIdentification(path string) *Identification {
+	tags, err := l.TagReader(path)
+	if err != nil {
+		return nil
+	}
+
+	season, serr := strconv.Atoi(tags["season_number"])
+	number, eerr := strconv.Atoi(tags["episode_sort"])
+
+	if show := tags["show"]; show != "" && serr == nil && eerr == nil {
+		return l.episodeIdentification(path, show, season, number, IDs{
+			ProviderLocal: path,
+		})
+	}
+
+	title := strings.TrimSpace(tags["title"])
+	if title == "" {
+		return nil
+	}
+
+	return &Identification{
+		Type: MediaTypeMovie,
+		IDs: IDs{
+			ProviderLocal: path,
+		},
+		Name: title,
+		Year: releaseYear(tagDate(tags)),
+	}
+}
+
+func (l *LocalIndex) tagDetails(t MediaType, path string) (*Details, error) {
+	if l.TagReader == nil || t != MediaTypeMovie {
+		return nil, ErrNotFound
+	}
+
+	tags, err := l.TagReader(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	title := strings.TrimSpace(tags["title"])
+	if title == "" {
+		return nil, ErrNotFound
+	}
+
+	dir := filepath.Dir(path)
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+
+	res := &Details{
+		SearchResult: SearchResult{
+			Type: MediaTypeMovie,
+			IDs: IDs{
+				ProviderLocal: path,
+			},
+			Name:         title,
+			OriginalName: title,
+			Overview:     tagDescription(tags),
+			ReleaseDate:  tagDate(tags),
+			PosterURL:    findLocalArtwork(dir, base, "poster", "folder"),
+			BackdropURL:  findLocalArtwork(dir, base, "fanart", "backdrop"),
+		},
+	}
+
+	if g := strings.TrimSpace(tags["genre"]); g != "" {
+		res.Genres = []string{g}
+	}
+
+	return res, nil
+}
+
+func (l *LocalIndex) tagEpisode(path string) (*EpisodeDetails, error) {
+	if l.TagReader == nil {
+		return nil, ErrNotFound
+	}
+
+	tags, err := l.TagReader(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	season, _ := strconv.Atoi(tags["season_number"])
+	number, _ := strconv.Atoi(tags["episode_sort"])
+
+	dir := filepath.Dir(path)
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+
+	return &EpisodeDetails{
+		IDs: IDs{
+			ProviderLocal: path,
+		},
+		Season:   season,
+		Number:   number,
+		Name:     strings.TrimSpace(tags["title"]),
+		Overview: tagDescription(tags),
+		AirDate:  tagDate(tags),
+		StillURL: findLocalArtwork(dir, base, "thumb"),
+	}, nil
+}
+
+// findUp searches dir and its parents for the named file, stopping at the root containing dir.
+func (l *LocalIndex) findUp(dir string, name string) string {
+	root := ""
+
+	for _, r := range l.roots {
+		if rel, err := filepath.Rel(r, dir); err == nil && !strings.HasPrefix(rel, "..") {
+			root = filepath.Clean(r)
+
+			break
+		}
+	}
+
+	for {
+		p := filepath.Join(dir, name)
+		if fileExists(p) {
+			return p
+		}
+
+		parent := filepath.Dir(dir)
+		if dir == root || parent == dir {
+			return ""
+		}
+
+		dir = parent
+	}
+}
+
+// localResult converts a movie.nfo or tvshow.nfo document into a result.
+func localResult(path string, doc *nfoDocument) *SearchResult {
+	mt, _ := doc.mediaType()
+	dir := filepath.Dir(path)
+
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if base == nfoRootMovie || base == nfoRootTvShow {
+		base = ""
+	}
+
+	ids := doc.ids()
+	ids[ProviderLocal] = path
+
+	name := strings.TrimSpace(doc.Title)
+	if name == "" {
+		name = filepath.Base(dir)
+	}
+
+	res := &SearchResult{
+		Type:         mt,
+		IDs:          ids,
+		Name:         name,
+		OriginalName: strings.TrimSpace(doc.OriginalTitle),
+		Overview:     doc.overview(),
+		ReleaseDate:  doc.releaseDate(),
+		PosterURL:    findLocalArtwork(dir, base, "poster", "folder"),
+		BackdropURL:  findLocalArtwork(dir, base, "fanart", "backdrop"),
+	}
+
+	if res.OriginalName == "" {
+		res.OriginalName = res.Name
+	}
+
+	if res.PosterURL == "" {
+		res.PosterURL = doc.poster()
+	}
+
+	if res.BackdropURL == "" {
+		res.BackdropURL = doc.backdrop()
+	}
+
+	return res
+}
+
+func nfoIdentification(t MediaType, path string, doc *nfoDocument) *Identification {
+	res := localResult(path, doc)
+
+	return &Identification{
+		Type: t,
+		IDs:  res.IDs,
+		Name: res.Name,
+		Year: releaseYear(res.ReleaseDate),
+	}
+}
+
+func (d *nfoDocument) episodeNumbers() (int, int, bool) {
+	season, err := strconv.Atoi(strings.TrimSpace(d.Season))
+	if err != nil {
+		return 0, 0, false
+	}
+
+	number, err := strconv.Atoi(strings.TrimSpace(d.Episode))
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return season, number, true
+}
+
+func tagDate(tags map[string]string) string {
+	date := strings.TrimSpace(tags["date"])
+	if len(date) > 10 {
+		date = date[:10]
+	}
+
+	return date
+}
+
+func tagDescription(tags map[string]string) string {
+	for _, k := range []string{"description", "synopsis", "comment"} {
+		if v := strings.TrimSpace(tags[k]); v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
+
+// parseEpisodeName extracts the show name, season and episode from names such as "Show.Name.S01E02.1080p".
+func parseEpisodeName(base string) (string, int, int, bool) {
+	m := episodeNameRe.FindStringSubmatch(base)
+	if m == nil {
+		m = episodeAltRe.FindStringSubmatch(base)
+	}
+
+	if m == nil {
+		return "", 0, 0, false
+	}
+
+	season, _ := strconv.Atoi(m[2])
+	number, _ := strconv.Atoi(m[3])
+
+	return cleanName(m[1]), season, number, true
+}
+
+// parseMovieName extracts the title and year from names such as "Movie Name (2009)" or "Movie.Name.2009.1080p".
+func parseMovieName(base string) (string, string) {
+	if m := yearNameRe.FindStringSubmatch(base); m != nil && cleanName(m[1]) != "" {
+		return cleanName(m[1]), m[2]
+	}
+
+	return cleanName(base), ""
+}
+
+// showNameFromPath guesses the show name from the folder structure, skipping season folders.
+func showNameFromPath(path string) string {
+	dir := filepath.Dir(path)
+
+	for i := 0; i < 2; i++ {
+		name := filepath.Base(dir)
+		if !seasonDirNameRe.MatchString(name) {
+			n, _ := parseMovieName(name)
+
+			return n
+		}
+
+		dir = filepath.Dir(dir)
+	}
+
+	return ""
+}
+
+func cleanName(name string) string {
+	name = strings.NewReplacer(".", " ", "_", " ").Replace(name)
+
+	return strings.Trim(strings.Join(strings.Fields(name), " "), " -")
+}
+
+func fileExists(path string) bool {
+	st, err := os.Stat(path)
+
+	return err == nil && !st.IsDir()
+}
+
+func copyResult(r *SearchResult) *SearchResult {
+	c := *r
+
+	c.IDs = make(IDs, len(r.IDs))
+	for k, v := range r.IDs {
+		c.IDs[k] = v
+	}
+
+	return &c
+}
@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
 	"time"
 
 	tmdb "github.com/cyruzin/golang-tmdb"
+	"golang.org/x/time/rate"
 )
 
 // TMDBAPIKey for themoviedb.org. This key has no value or permissions and does not need to be treated as a secret.
@@ -15,11 +17,25 @@ const TMDBAPIKey = "6af65a7bd8013744b97fae599a76bf4b" //nolint:gosec
 
 var ErrUnexpectedFormat = errors.New("unexpected format")
 
+// TMDB request limits. TMDB allows roughly 50 requests per second per IP, so this leaves headroom for other clients
+// on the same network.
+const (
+	TMDBRateLimit rate.Limit = 20
+	TMDBRateBurst            = 20
+)
+
 type MovieDB struct {
 	c *tmdb.Client
 }
 
+// NewMoveDB creates a MovieDB client with its own limiter. All requests made through the client share the limiter.
 func NewMoveDB() *MovieDB {
+	return NewMovieDBWithLimiter(rate.NewLimiter(TMDBRateLimit, TMDBRateBurst))
+}
+
+// NewMovieDBWithLimiter creates a MovieDB client whose requests are throttled by limiter, allowing a single limiter to
+// be shared between several clients.
+func NewMovieDBWithLimiter(limiter *rate.Limiter) *MovieDB {
 	c, err := tmdb.Init(TMDBAPIKey)
 	if err != nil {
 		// No error is possible
@@ -37,10 +53,13 @@ func NewMoveDB() *MovieDB {
 
 	c.SetClientConfig(http.Client{
 		Timeout: time.Second * 30,
-		Transport: &http.Transport{
-			MaxIdleConns:    10,
-			IdleConnTimeout: 15 * time.Second,
-			Proxy:           http.ProxyURL(proxyURL),
+		Transport: &rateLimitedTransport{
+			limiter: limiter,
+			base: &http.Transport{
+				MaxIdleConns:    10,
+				IdleConnTimeout: 15 * time.Second,
+				Proxy:           http.ProxyURL(proxyURL),
+			},
 		},
 	})
 
@@ -59,21 +78,33 @@ func (m *MovieDB) Search(name string) ([]*SearchResult, error) {
 
 	for _, result := range res.Results {
 		sr := &SearchResult{
+			IDs: IDs{
+				ProviderTMDB: strconv.FormatInt(result.ID, 10),
+			},
 			OriginalLang: result.OriginalLanguage,
 			Overview:     result.Overview,
+			BackdropURL:  imageURL(result.BackdropPath),
 			Popularity:   result.Popularity,
 		}
 
 		switch result.MediaType {
 		case "tv":
 			sr.Type = MediaTypeTv
+			sr.Name = result.Name
 			sr.OriginalName = result.OriginalName
+			sr.ReleaseDate = result.FirstAirDate
+			sr.PosterURL = imageURL(result.PosterPath)
 		case "movie":
 			sr.Type = MediaTypeMovie
+			sr.Name = result.Title
 			sr.OriginalName = result.OriginalTitle
+			sr.ReleaseDate = result.ReleaseDate
+			sr.PosterURL = imageURL(result.PosterPath)
 		case "person":
-			// Unsupported
-			continue
+			sr.Type = MediaTypePerson
+			sr.Name = result.Name
+			sr.OriginalName = result.Name
+			sr.PosterURL = imageURL(result.ProfilePath)
 		default:
 			return nil, fmt.Errorf("%w: unknown media type %s", ErrUnexpectedFormat, result.MediaType)
 		}
@@ -83,3 +114,179 @@ func (m *MovieDB) Search(name string) ([]*SearchResult, error) {
 
 	return mapped, nil
 }
+
+// imageURL converts a TMDB image path into an absolute URL for the original size.
+func imageURL(path string) string {
+	if path == "" {
+		return ""
+	}
+
+	return tmdb.GetImageURL(path, tmdb.Original)
+}
+
+func (m *MovieDB) Details(t MediaType, ids IDs) (*Details, error) {
+	id, err := tmdbID(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := map[string]string{
+		"append_to_response":     "credits,images",
+		"include_image_language": "en,null",
+	}
+
+	switch t {
+	case MediaTypeMovie:
+		res, err := m.c.GetMovieDetails(id, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		d := &Details{
+			SearchResult: SearchResult{
+				Type: MediaTypeMovie,
+				IDs: IDs{
+					ProviderTMDB: strconv.FormatInt(res.ID, 10),
+				},
+				Name:         res.Title,
+				OriginalName: res.OriginalTitle,
+				OriginalLang: res.OriginalLanguage,
+				Overview:     res.Overview,
+				ReleaseDate:  res.ReleaseDate,
+				PosterURL:    imageURL(res.PosterPath),
+				BackdropURL:  imageURL(res.BackdropPath),
+				Popularity:   res.Popularity,
+			},
+		}
+
+		if res.IMDbID != "" {
+			d.IDs[ProviderIMDB] = res.IMDbID
+		}
+
+		for _, g := range res.Genres {
+			d.Genres = append(d.Genres, g.Name)
+		}
+
+		if res.MovieCreditsAppend != nil && res.Credits.MovieCredits != nil {
+			for _, c := range res.Credits.Cast {
+				d.Cast = append(d.Cast, c.Name)
+			}
+		}
+
+		if res.MovieImagesAppend != nil && res.Images != nil && len(res.Images.Logos) > 0 {
+			d.LogoURL = imageURL(res.Images.Logos[0].FilePath)
+		}
+
+		return d, nil
+
+	case MediaTypeTv:
+		res, err := m.c.GetTVDetails(id, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		d := &Details{
+			SearchResult: SearchResult{
+				Type: MediaTypeTv,
+				IDs: IDs{
+					ProviderTMDB: strconv.FormatInt(res.ID, 10),
+				},
+				Name:         res.Name,
+				OriginalName: res.OriginalName,
+				OriginalLang: res.OriginalLanguage,
+				Overview:     res.Overview,
+				ReleaseDate:  res.FirstAirDate,
+				PosterURL:    imageURL(res.PosterPath),
+				BackdropURL:  imageURL(res.BackdropPath),
+				Popularity:   res.Popularity,
+			},
+			Status: tmdbShowStatus(res.Status, res.InProduction),
+		}
+
+		for _, g := range res.Genres {
+			d.Genres = append(d.Genres, g.Name)
+		}
+
+		if res.TVCreditsAppend != nil && res.Credits.TVCredits != nil {
+			for _, c := range res.Credits.Cast {
+				d.Cast = append(d.Cast, c.Name)
+			}
+		}
+
+		if res.TVImagesAppend != nil && res.Images != nil && len(res.Images.Logos) > 0 {
+			d.LogoURL = imageURL(res.Images.Logos[0].FilePath)
+		}
+
+		return d, nil
+
+	default:
+		return nil, ErrNotFound
+	}
+}
+
+func (m *MovieDB) Episode(ref EpisodeRef) (*EpisodeDetails, error) {
+	id, err := tmdbID(ref.Show)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := m.c.GetTVEpisodeDetails(id, ref.Season, ref.Number, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EpisodeDetails{
+		IDs: IDs{
+			ProviderTMDB: strconv.FormatInt(res.ID, 10),
+		},
+		Season:   res.SeasonNumber,
+		Number:   res.EpisodeNumber,
+		Name:     res.Name,
+		Overview: res.Overview,
+		AirDate:  res.AirDate,
+		StillURL: imageURL(res.StillPath),
+	}, nil
+}
+
+func tmdbID(ids IDs) (int, error) {
+	raw, ok := ids[ProviderTMDB]
+	if !ok {
+		return 0, ErrNotFound
+	}
+
+	id, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid tmdb id %q", ErrUnexpectedFormat, raw)
+	}
+
+	return id, nil
+}
+
+func tmdbShowStatus(status string, inProduction bool) ShowStatus {
+	switch status {
+	case "Ended", "Canceled":
+		return ShowStatusEnded
+	case "Returning Series", "In Production", "Planned", "Pilot":
+		return ShowStatusOngoing
+	}
+
+	if inProduction {
+		return ShowStatusOngoing
+	}
+
+	return ShowStatusUnknown
+}
+
+// rateLimitedTransport waits for a token from a limiter before sending each request, including retries.
+type rateLimitedTransport struct {
+	limiter *rate.Limiter
+	base    http.RoundTripper
+}
+
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+
+	return t.base.RoundTrip(req)
+}
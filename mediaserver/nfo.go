@@ -0,0 +1,240 @@
+package mediaserver
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Root element names used by Kodi style .nfo files.
+const (
+	nfoRootMovie   = "movie"
+	nfoRootTvShow  = "tvshow"
+	nfoRootEpisode = "episodedetails"
+)
+
+type nfoUniqueID struct {
+	Type    string `xml:"type,attr"`
+	Default bool   `xml:"default,attr"`
+	Value   string `xml:",chardata"`
+}
+
+type nfoThumb struct {
+	Aspect string `xml:"aspect,attr"`
+	Value  string `xml:",chardata"`
+}
+
+type nfoActor struct {
+	Name string `xml:"name"`
+	Role string `xml:"role"`
+}
+
+type nfoDocument struct {
+	XMLName       xml.Name
+	Title         string        `xml:"title"`
+	OriginalTitle string        `xml:"originaltitle"`
+	ShowTitle     string        `xml:"showtitle"`
+	Plot          string        `xml:"plot"`
+	Outline       string        `xml:"outline"`
+	Premiered     string        `xml:"premiered"`
+	Aired         string        `xml:"aired"`
+	Year          string        `xml:"year"`
+	Season        string        `xml:"season"`
+	Episode       string        `xml:"episode"`
+	Status        string        `xml:"status"`
+	UniqueIDs     []nfoUniqueID `xml:"uniqueid"`
+	TMDBID        string        `xml:"tmdbid"`
+	IMDBID        string        `xml:"imdbid"`
+	Genres        []string      `xml:"genre"`
+	Actors        []nfoActor    `xml:"actor"`
+	Thumbs        []nfoThumb    `xml:"thumb"`
+	Fanart        []nfoThumb    `xml:"fanart>thumb"`
+}
+
+// readNFO parses a Kodi style .nfo file. Kodi allows a scraper URL to trail the XML document, so anything after the
+// root element is ignored.
+func readNFO(path string) (*nfoDocument, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	var doc nfoDocument
+
+	dec := xml.NewDecoder(f)
+	dec.Strict = false
+
+	if err := dec.Decode(&doc); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: empty nfo", ErrUnexpectedFormat)
+		}
+
+		return nil, fmt.Errorf("%w: %w", ErrUnexpectedFormat, err)
+	}
+
+	return &doc, nil
+}
+
+func (d *nfoDocument) mediaType() (MediaType, bool) {
+	switch d.XMLName.Local {
+	case nfoRootMovie:
+		return MediaTypeMovie, true
+	case nfoRootTvShow:
+		return MediaTypeTv, true
+	default:
+		return "", false
+	}
+}
+
+func (d *nfoDocument) ids() IDs {
+	ids := make(IDs)
+
+	for _, id := range d.UniqueIDs {
+		v := strings.TrimSpace(id.Value)
+		if id.Type == "" || v == "" {
+			continue
+		}
+
+		ids[strings.ToLower(id.Type)] = v
+	}
+
+	if v := strings.TrimSpace(d.TMDBID); v != "" {
+		ids[ProviderTMDB] = v
+	}
+
+	if v := strings.TrimSpace(d.IMDBID); v != "" {
+		ids[ProviderIMDB] = v
+	}
+
+	return ids
+}
+
+func (d *nfoDocument) releaseDate() string {
+	for _, v := range []string{d.Premiered, d.Aired, d.Year} {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
+
+func (d *nfoDocument) genres() []string {
+	var res []string
+
+	for _, g := range d.Genres {
+		// Some scrapers store every genre in a single element
+		for _, part := range strings.Split(g, "/") {
+			if part = strings.TrimSpace(part); part != "" {
+				res = append(res, part)
+			}
+		}
+	}
+
+	return res
+}
+
+func (d *nfoDocument) cast() []string {
+	var res []string
+
+	for _, a := range d.Actors {
+		if n := strings.TrimSpace(a.Name); n != "" {
+			res = append(res, n)
+		}
+	}
+
+	return res
+}
+
+func (d *nfoDocument) status() ShowStatus {
+	switch strings.ToLower(strings.TrimSpace(d.Status)) {
+	case "continuing", "returning series", "in production":
+		return ShowStatusOngoing
+	case "ended", "canceled", "cancelled":
+		return ShowStatusEnded
+	default:
+		return ShowStatusUnknown
+	}
+}
+
+func (d *nfoDocument) overview() string {
+	if d.Plot != "" {
+		return strings.TrimSpace(d.Plot)
+	}
+
+	return strings.TrimSpace(d.Outline)
+}
+
+func (d *nfoDocument) poster() string {
+	for _, t := range d.Thumbs {
+		if t.Aspect == "poster" && strings.TrimSpace(t.Value) != "" {
+			return strings.TrimSpace(t.Value)
+		}
+	}
+
+	for _, t := range d.Thumbs {
+		if t.Aspect == "" && strings.TrimSpace(t.Value) != "" {
+			return strings.TrimSpace(t.Value)
+		}
+	}
+
+	return ""
+}
+
+func (d *nfoDocument) logo() string {
+	for _, t := range d.Thumbs {
+		if t.Aspect == "clearlogo" && strings.TrimSpace(t.Value) != "" {
+			return strings.TrimSpace(t.Value)
+		}
+	}
+
+	return ""
+}
+
+func (d *nfoDocument) backdrop() string {
+	for _, t := range d.Fanart {
+		if v := strings.TrimSpace(t.Value); v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
+
+// findLocalArtwork looks for artwork stored next to a media file, following the Kodi naming conventions.
+func findLocalArtwork(dir string, base string, kinds ...string) string {
+	for _, kind := range kinds {
+		candidates := []string{kind}
+		if base != "" {
+			candidates = append([]string{base + "-" + kind}, candidates...)
+		}
+
+		for _, c := range candidates {
+			for _, ext := range []string{".jpg", ".jpeg", ".png", ".webp"} {
+				p := filepath.Join(dir, c+ext)
+
+				if st, err := os.Stat(p); err == nil && !st.IsDir() {
+					return fileURL(p)
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+func fileURL(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(abs)}).String()
+}
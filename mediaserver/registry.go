@@ -0,0 +1,418 @@
+package mediaserver
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+var ErrDuplicateProvider = errors.New("duplicate provider")
+
+// Field identifies a single piece of metadata that can be merged between providers.
+type Field string
+
+const (
+	FieldName         Field = "name"
+	FieldOriginalName Field = "original_name"
+	FieldOriginalLang Field = "original_lang"
+	FieldOverview     Field = "overview"
+	FieldReleaseDate  Field = "release_date"
+	FieldPoster       Field = "poster"
+	FieldBackdrop     Field = "backdrop"
+	FieldPopularity   Field = "popularity"
+	FieldGenres       Field = "genres"
+	FieldCast         Field = "cast"
+	FieldStatus       Field = "status"
+	FieldStill        Field = "still"
+	FieldLogo         Field = "logo"
+)
+
+// Provider is an Index registered with a Registry.
+type Provider struct {
+	// Name uniquely identifies the provider within the registry.
+	Name string
+	// Index is queried for results.
+	Index Index
+	// Priority orders providers. Higher priority providers win when several supply the same field.
+	Priority int
+	// Fields restricts the fields this provider may supply. All fields are accepted when empty.
+	Fields []Field
+}
+
+func (p *Provider) supplies(f Field) bool {
+	if len(p.Fields) == 0 {
+		return true
+	}
+
+	for _, field := range p.Fields {
+		if field == f {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Registry chains several Index implementations together, merging their results field by field.
+//
+// The registry itself implements Index, allowing registries to be nested.
+type Registry struct {
+	mu        sync.RWMutex
+	providers []*Provider
+
+	// ErrorHandler is notified when an individual provider fails. Errors are only returned to the caller when no
+	// provider succeeds.
+	ErrorHandler func(provider string, err error)
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) Register(p Provider) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.providers {
+		if existing.Name == p.Name {
+			return fmt.Errorf("%w: %s", ErrDuplicateProvider, p.Name)
+		}
+	}
+
+	r.providers = append(r.providers, &p)
+
+	sort.SliceStable(r.providers, func(i, j int) bool {
+		return r.providers[i].Priority > r.providers[j].Priority
+	})
+
+	return nil
+}
+
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, p := range r.providers {
+		if p.Name == name {
+			r.providers = append(r.providers[:i], r.providers[i+1:]...)
+
+			return
+		}
+	}
+}
+
+// Providers returns the registered providers in priority order.
+func (r *Registry) Providers() []Provider {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	res := make([]Provider, 0, len(r.providers))
+	for _, p := range r.providers {
+		res = append(res, *p)
+	}
+
+	return res
+}
+
+func (r *Registry) Search(name string) ([]*SearchResult, error) {
+	providers := r.Providers()
+
+	found := make([][]*SearchResult, len(providers))
+	errs := make([]error, len(providers))
+
+	r.each(providers, func(i int, p *Provider) {
+		found[i], errs[i] = p.Index.Search(name)
+	})
+
+	if err := r.collect(providers, errs); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var m merger
+
+	for i := range providers {
+		for _, res := range found[i] {
+			m.add(&providers[i], res)
+		}
+	}
+
+	return m.results, nil
+}
+
+// Details queries every provider, merging the records they know about field by field. IDs discovered by one provider
+// are not fed back into the others.
+func (r *Registry) Details(t MediaType, ids IDs) (*Details, error) {
+	providers := r.Providers()
+
+	found := make([]*Details, len(providers))
+	errs := make([]error, len(providers))
+
+	r.each(providers, func(i int, p *Provider) {
+		found[i], errs[i] = p.Index.Details(t, ids)
+	})
+
+	if err := r.collect(providers, errs); err != nil {
+		return nil, err
+	}
+
+	res := &Details{
+		SearchResult: SearchResult{
+			Type: t,
+			IDs:  make(IDs),
+		},
+	}
+
+	mergeIDs(res.IDs, ids)
+
+	for i := range providers {
+		d := found[i]
+		if d == nil {
+			continue
+		}
+
+		p := &providers[i]
+		mergeResult(p, &res.SearchResult, &d.SearchResult)
+		mergeStrings(p, FieldGenres, &res.Genres, d.Genres)
+		mergeStrings(p, FieldCast, &res.Cast, d.Cast)
+		mergeString(p, FieldLogo, &res.LogoURL, d.LogoURL)
+
+		if res.Status == ShowStatusUnknown && p.supplies(FieldStatus) {
+			res.Status = d.Status
+		}
+	}
+
+	return res, nil
+}
+
+func (r *Registry) Episode(ref EpisodeRef) (*EpisodeDetails, error) {
+	providers := r.Providers()
+
+	found := make([]*EpisodeDetails, len(providers))
+	errs := make([]error, len(providers))
+
+	r.each(providers, func(i int, p *Provider) {
+		found[i], errs[i] = p.Index.Episode(ref)
+	})
+
+	if err := r.collect(providers, errs); err != nil {
+		return nil, err
+	}
+
+	res := &EpisodeDetails{
+		IDs:    make(IDs),
+		Season: ref.Season,
+		Number: ref.Number,
+	}
+
+	mergeIDs(res.IDs, ref.Episode)
+
+	for i := range providers {
+		e := found[i]
+		if e == nil {
+			continue
+		}
+
+		p := &providers[i]
+		mergeIDs(res.IDs, e.IDs)
+		mergeString(p, FieldName, &res.Name, e.Name)
+		mergeString(p, FieldOverview, &res.Overview, e.Overview)
+		mergeString(p, FieldReleaseDate, &res.AirDate, e.AirDate)
+		mergeString(p, FieldStill, &res.StillURL, e.StillURL)
+	}
+
+	return res, nil
+}
+
+// Identify asks each provider implementing PathIndex in turn, returning the first identification.
+func (r *Registry) Identify(path string) (*Identification, error) {
+	for _, p := range r.Providers() {
+		pi, ok := p.Index.(PathIndex)
+		if !ok {
+			continue
+		}
+
+		res, err := pi.Identify(path)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("%s: %w", p.Name, err)
+		}
+
+		return res, nil
+	}
+
+	return nil, ErrNotFound
+}
+
+func (r *Registry) each(providers []Provider, fn func(i int, p *Provider)) {
+	var wg sync.WaitGroup
+
+	for i := range providers {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			fn(i, &providers[i])
+		}(i)
+	}
+
+	wg.Wait()
+}
+
+// collect reports provider errors, ignoring ErrNotFound. An error is only returned when no provider succeeded.
+func (r *Registry) collect(providers []Provider, errs []error) error {
+	var (
+		failed    []error
+		succeeded bool
+	)
+
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			succeeded = true
+		case errors.Is(err, ErrNotFound):
+			continue
+		default:
+			if r.ErrorHandler != nil {
+				r.ErrorHandler(providers[i].Name, err)
+			}
+
+			failed = append(failed, fmt.Errorf("%s: %w", providers[i].Name, err))
+		}
+	}
+
+	switch {
+	case succeeded:
+		return nil
+	case len(failed) > 0:
+		return errors.Join(failed...)
+	default:
+		return ErrNotFound
+	}
+}
+
+// merger combines results from providers that are added in descending priority order.
+type merger struct {
+	results []*SearchResult
+}
+
+func (m *merger) add(p *Provider, res *SearchResult) {
+	target := m.find(res)
+	if target == nil {
+		target = &SearchResult{
+			Type: res.Type,
+			IDs:  make(IDs),
+		}
+
+		m.results = append(m.results, target)
+	}
+
+	mergeResult(p, target, res)
+}
+
+func mergeResult(p *Provider, target *SearchResult, res *SearchResult) {
+	mergeIDs(target.IDs, res.IDs)
+	mergeString(p, FieldName, &target.Name, res.Name)
+	mergeString(p, FieldOriginalName, &target.OriginalName, res.OriginalName)
+	mergeString(p, FieldOriginalLang, &target.OriginalLang, res.OriginalLang)
+	mergeString(p, FieldOverview, &target.Overview, res.Overview)
+	mergeString(p, FieldReleaseDate, &target.ReleaseDate, res.ReleaseDate)
+	mergeString(p, FieldPoster, &target.PosterURL, res.PosterURL)
+	mergeString(p, FieldBackdrop, &target.BackdropURL, res.BackdropURL)
+
+	if target.Popularity == 0 && p.supplies(FieldPopularity) {
+		target.Popularity = res.Popularity
+	}
+}
+
+func mergeIDs(dst IDs, src IDs) {
+	for k, v := range src {
+		if _, ok := dst[k]; !ok && v != "" {
+			dst[k] = v
+		}
+	}
+}
+
+func mergeStrings(p *Provider, f Field, dst *[]string, val []string) {
+	if len(*dst) == 0 && p.supplies(f) {
+		*dst = val
+	}
+}
+
+func mergeString(p *Provider, f Field, dst *string, val string) {
+	if *dst == "" && p.supplies(f) {
+		*dst = val
+	}
+}
+
+// find locates an existing result describing the same entry. Results match if they share an ID, or failing that, if
+// their normalised names and release years agree.
+func (m *merger) find(res *SearchResult) *SearchResult {
+	for _, existing := range m.results {
+		if existing.Type != res.Type {
+			continue
+		}
+
+		for k, v := range res.IDs {
+			if v != "" && existing.IDs[k] == v {
+				return existing
+			}
+		}
+	}
+
+	for _, existing := range m.results {
+		if existing.Type != res.Type {
+			continue
+		}
+
+		if !sameName(existing, res) {
+			continue
+		}
+
+		ey := releaseYear(existing.ReleaseDate)
+		ry := releaseYear(res.ReleaseDate)
+
+		if ey == "" || ry == "" || ey == ry {
+			return existing
+		}
+	}
+
+	return nil
+}
+
+func sameName(a *SearchResult, b *SearchResult) bool {
+	if a.Name != "" && normaliseName(a.Name) == normaliseName(b.Name) {
+		return true
+	}
+
+	return a.OriginalName != "" && normaliseName(a.OriginalName) == normaliseName(b.OriginalName)
+}
+
+func releaseYear(date string) string {
+	if len(date) < 4 {
+		return ""
+	}
+
+	return date[:4]
+}
+
+// normaliseName lowercases a name and strips everything but letters and digits.
+func normaliseName(name string) string {
+	var sb strings.Builder
+
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(unicode.ToLower(r))
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,117 @@
+package plugin
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenExpired = errors.New("token expired")
+)
+
+// Scopes a plugin may be granted by an admin, and must declare in its hello to use.
+const (
+	// ScopeLibraryRead allows reading libraries and items.
+	ScopeLibraryRead = "library:read"
+	// ScopeMetadataWrite allows changing the metadata of items, including requesting it be refreshed.
+	ScopeMetadataWrite = "metadata:write"
+	// ScopePlaybackEvents allows receiving notifications about playback.
+	ScopePlaybackEvents = "playback:events"
+	// ScopeMetadataSource allows the server to call the plugin for metadata.
+	ScopeMetadataSource = "metadata:source"
+)
+
+// Scopes lists every scope.
+var Scopes = []string{ScopeLibraryRead, ScopeMetadataWrite, ScopePlaybackEvents, ScopeMetadataSource}
+
+const (
+	// MaxTokenLifetime bounds how long a signed token may be valid for, limiting the use of a leaked token.
+	MaxTokenLifetime = 10 * time.Minute
+	// tokenClockSkew is allowed between the clocks of plugins and the server.
+	tokenClockSkew = time.Minute
+	// tokenLifetime is how long tokens signed by Dial are valid for.
+	tokenLifetime = time.Minute
+)
+
+type tokenClaims struct {
+	ID      string `msgpack:"id"`
+	Expires int64  `msgpack:"exp"`
+}
+
+// SignToken returns a token authenticating the plugin with the ID, which is valid for the lifetime. The public half of
+// the key must have been registered with the server for the plugin.
+func SignToken(id string, key ed25519.PrivateKey, lifetime time.Duration) (string, error) {
+	if lifetime > MaxTokenLifetime {
+		return "", fmt.Errorf("%w: lifetime exceeds %v", ErrInvalidToken, MaxTokenLifetime)
+	}
+
+	claims, err := msgpack.Marshal(tokenClaims{
+		ID:      id,
+		Expires: time.Now().Add(lifetime).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	sig := ed25519.Sign(key, claims)
+
+	return base64.RawURLEncoding.EncodeToString(claims) + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// IsSignedToken reports whether the token was made by SignToken, rather than being an API key.
+func IsSignedToken(token string) bool {
+	return strings.Contains(token, ".")
+}
+
+// VerifyToken checks a token made by SignToken, returning the ID of the plugin. The signature is verified with the key
+// returned by lookup for the ID, and tokens valid for longer than MaxTokenLifetime are rejected.
+func VerifyToken(token string, now time.Time, lookup func(id string) (ed25519.PublicKey, error)) (string, error) {
+	claimsStr, sigStr, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", ErrInvalidToken
+	}
+
+	claims, err := base64.RawURLEncoding.DecodeString(claimsStr)
+	if err != nil {
+		return "", ErrInvalidToken
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(sigStr)
+	if err != nil {
+		return "", ErrInvalidToken
+	}
+
+	var c tokenClaims
+
+	if err := msgpack.Unmarshal(claims, &c); err != nil || c.ID == "" {
+		return "", ErrInvalidToken
+	}
+
+	key, err := lookup(c.ID)
+	if err != nil {
+		return "", err
+	}
+
+	if len(key) != ed25519.PublicKeySize || !ed25519.Verify(key, claims, sig) {
+		return "", ErrInvalidToken
+	}
+
+	expires := time.Unix(c.Expires, 0)
+
+	if now.After(expires.Add(tokenClockSkew)) {
+		return "", ErrTokenExpired
+	}
+
+	if expires.Sub(now) > MaxTokenLifetime+tokenClockSkew {
+		return "", fmt.Errorf("%w: lifetime exceeds %v", ErrInvalidToken, MaxTokenLifetime)
+	}
+
+	return c.ID, nil
+}
@@ -0,0 +1,183 @@
+package plugin
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+	"github.com/quic-go/webtransport-go"
+)
+
+var errUnexpectedResponse = errors.New("unexpected response from server")
+
+// keepAlivePeriod keeps idle connections open, as servers close connections that are idle for 30 seconds.
+const keepAlivePeriod = 15 * time.Second
+
+// Config configures a plugin.
+type Config struct {
+	Info Info
+	// Handler answers calls made by the server. It may be nil, in which case only MethodPing is answered.
+	Handler *Mux
+	// OnMessage is called with notifications and events sent by the server. It is called from the reader of the
+	// connection, so must not block.
+	OnMessage func(Message)
+	// TLSConfig is used to connect to the server. The system roots are trusted when nil.
+	TLSConfig *tls.Config
+	// Header is sent with the request opening the session.
+	Header http.Header
+	// APIKey authenticates the plugin, as created by an admin.
+	APIKey string
+	// SigningKey authenticates the plugin with a token signed for Info.ID, used instead of an API key when the plugin
+	// was registered with the public half of the key.
+	SigningKey ed25519.PrivateKey
+}
+
+// Client is a plugin connected to a server.
+type Client struct {
+	*Peer
+	Welcome Welcome
+
+	dialer *webtransport.Dialer
+}
+
+// Dial connects to the server at the URL, such as https://example.com:8443, and registers the plugin with it. Calls
+// made by the server are answered from when the handshake completes until the client is closed.
+func Dial(ctx context.Context, serverURL string, cfg Config) (*Client, error) {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid server url: %w", err)
+	}
+
+	dialer := &webtransport.Dialer{
+		RoundTripper: &http3.RoundTripper{
+			TLSClientConfig: cfg.TLSConfig,
+			QuicConfig: &quic.Config{
+				KeepAlivePeriod: keepAlivePeriod,
+				EnableDatagrams: true,
+			},
+		},
+	}
+
+	header := cfg.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+
+	token := cfg.APIKey
+
+	if cfg.SigningKey != nil {
+		if token, err = SignToken(cfg.Info.ID, cfg.SigningKey, tokenLifetime); err != nil {
+			return nil, err
+		}
+	}
+
+	if token != "" {
+		header.Set("Authorization", "Bearer "+token)
+	}
+
+	c, err := handshake(ctx, dialer, u.JoinPath(TransportPath).String(), header, cfg)
+	if err != nil {
+		_ = dialer.Close()
+
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func handshake(
+	ctx context.Context,
+	dialer *webtransport.Dialer,
+	transportURL string,
+	header http.Header,
+	cfg Config,
+) (*Client, error) {
+	ctx, cancel := context.WithTimeout(ctx, handshakeTimeout)
+	defer cancel()
+
+	rsp, session, err := dialer.Dial(ctx, transportURL, header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open session: %w", err)
+	}
+
+	streamer, ok := rsp.Body.(http3.HTTPStreamer)
+	if !ok {
+		return nil, errUnexpectedResponse
+	}
+
+	hijacker, ok := rsp.Body.(http3.Hijacker)
+	if !ok {
+		return nil, errUnexpectedResponse
+	}
+
+	conn, ok := hijacker.StreamCreator().(quic.Connection)
+	if !ok {
+		return nil, errUnexpectedResponse
+	}
+
+	fail := func(err error) (*Client, error) {
+		_ = session.CloseWithError(0, "")
+
+		return nil, err
+	}
+
+	control, err := session.OpenStreamSync(ctx)
+	if err != nil {
+		return fail(fmt.Errorf("failed to open control stream: %w", err))
+	}
+
+	deadline, _ := ctx.Deadline()
+	if err := control.SetDeadline(deadline); err != nil {
+		return fail(err)
+	}
+
+	hello, err := newMessage(messageHello, "", cfg.Info)
+	if err != nil {
+		return fail(err)
+	}
+
+	hello.Protocol = ProtocolVersion
+
+	if err := writeFrame(control, hello); err != nil {
+		return fail(fmt.Errorf("failed to write hello: %w", err))
+	}
+
+	msg, err := readMessage(control, messageWelcome)
+	if err != nil {
+		return fail(fmt.Errorf("handshake failed: %w", err))
+	}
+
+	var welcome Welcome
+
+	if err := decodeBody(msg.Body, &welcome); err != nil {
+		return fail(fmt.Errorf("invalid welcome: %w", err))
+	}
+
+	if err := control.SetDeadline(time.Time{}); err != nil {
+		return fail(err)
+	}
+
+	return &Client{
+		Peer:    newPeer(session, streamer.HTTPStream().StreamID(), conn, control, cfg.Handler, cfg.OnMessage),
+		Welcome: welcome,
+		dialer:  dialer,
+	}, nil
+}
+
+// Close closes the connection to the server.
+func (c *Client) Close() error {
+	err := c.Peer.Close()
+
+	if closeErr := c.dialer.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
@@ -0,0 +1,112 @@
+package plugin
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/quicvarint"
+)
+
+// datagramQueueLen is how many datagrams are buffered for a session before further ones are dropped.
+const datagramQueueLen = 64
+
+var (
+	datagramConnsMu sync.Mutex
+	datagramConns   = make(map[quic.Connection]*datagramConn)
+)
+
+// datagramConn demultiplexes the datagrams received on a connection between its WebTransport sessions. Datagrams are
+// prefixed with the quarter stream ID of their session, as HTTP/3 datagrams (RFC 9297) are.
+type datagramConn struct {
+	conn quic.Connection
+
+	mu       sync.Mutex
+	sessions map[uint64]chan []byte
+}
+
+// datagramsFor returns the demultiplexer of the connection, which reads datagrams until the connection is closed.
+func datagramsFor(conn quic.Connection) *datagramConn {
+	datagramConnsMu.Lock()
+	defer datagramConnsMu.Unlock()
+
+	if d, ok := datagramConns[conn]; ok {
+		return d
+	}
+
+	d := &datagramConn{
+		conn:     conn,
+		sessions: make(map[uint64]chan []byte),
+	}
+
+	datagramConns[conn] = d
+
+	go d.read()
+
+	return d
+}
+
+func (d *datagramConn) read() {
+	defer func() {
+		datagramConnsMu.Lock()
+		delete(datagramConns, d.conn)
+		datagramConnsMu.Unlock()
+	}()
+
+	for {
+		b, err := d.conn.ReceiveDatagram(d.conn.Context())
+		if err != nil {
+			return
+		}
+
+		r := bytes.NewReader(b)
+
+		id, err := quicvarint.Read(r)
+		if err != nil {
+			continue
+		}
+
+		d.mu.Lock()
+		ch, ok := d.sessions[id]
+		d.mu.Unlock()
+
+		if !ok {
+			continue
+		}
+
+		// Datagrams may be lost anyway, so a slow reader drops them rather than blocking other sessions
+		select {
+		case ch <- b[len(b)-r.Len():]:
+		default:
+		}
+	}
+}
+
+// register returns the datagrams received for the session, and a function that stops receiving them.
+func (d *datagramConn) register(session quic.StreamID) (<-chan []byte, func()) {
+	id := uint64(session) / 4
+	ch := make(chan []byte, datagramQueueLen)
+
+	d.mu.Lock()
+	d.sessions[id] = ch
+	d.mu.Unlock()
+
+	return ch, func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+
+		if d.sessions[id] == ch {
+			delete(d.sessions, id)
+		}
+	}
+}
+
+func (d *datagramConn) send(session quic.StreamID, payload []byte) error {
+	if !d.conn.ConnectionState().SupportsDatagrams {
+		return ErrDatagramsUnsupported
+	}
+
+	b := quicvarint.Append(make([]byte, 0, 8+len(payload)), uint64(session)/4)
+
+	return d.conn.SendDatagram(append(b, payload...))
+}
@@ -0,0 +1,73 @@
+package plugin
+
+import "time"
+
+// Event types a plugin may subscribe to with MethodSubscribeEvents. Events are sent as notifications, with the topic
+// set to the type and an Event as the body.
+const (
+	// EventItemAdded is sent when an item appears in a library. It requires ScopeLibraryRead.
+	EventItemAdded = "item.added"
+	// EventItemUpdated is sent when the file or metadata of an item changes. It requires ScopeLibraryRead.
+	EventItemUpdated = "item.updated"
+	// EventItemRemoved is sent when an item disappears from a library. It requires ScopeLibraryRead.
+	EventItemRemoved = "item.removed"
+	// EventLibraryScan reports the progress of a library scan. It requires ScopeLibraryRead.
+	EventLibraryScan = "library.scan"
+	// EventSessionStarted is sent when playback starts. It requires ScopePlaybackEvents.
+	EventSessionStarted = "session.started"
+	// EventSessionStopped is sent when playback ends. It requires ScopePlaybackEvents.
+	EventSessionStopped = "session.stopped"
+	// EventsDropped is sent in place of events the plugin was too slow to receive, so that it knows to resynchronise.
+	// It is sent to every subscription.
+	EventsDropped = "events.dropped"
+)
+
+// SubscribeRequest selects the events sent to a plugin.
+type SubscribeRequest struct {
+	Types []string `msgpack:"types"`
+}
+
+// Event is something that happened on the server. Only the field matching the type is set.
+type Event struct {
+	// ID numbers events in the order they were published, restarting with the server. Dropped events have no ID.
+	ID      uint64        `msgpack:"id,omitempty"`
+	Type    string        `msgpack:"type"`
+	Time    time.Time     `msgpack:"time"`
+	Item    *ItemEvent    `msgpack:"item,omitempty"`
+	Scan    *ScanEvent    `msgpack:"scan,omitempty"`
+	Session *SessionEvent `msgpack:"session,omitempty"`
+	Dropped *DroppedEvent `msgpack:"dropped,omitempty"`
+}
+
+// ItemEvent identifies an item that was added, updated or removed. The kind is not known for every removed item.
+type ItemEvent struct {
+	ID        string `msgpack:"id"`
+	LibraryID string `msgpack:"libraryId"`
+	ParentID  string `msgpack:"parentId,omitempty"`
+	Kind      string `msgpack:"kind,omitempty"`
+}
+
+// ScanEvent reports the progress of a library scan, with State being started, running, completed or failed.
+type ScanEvent struct {
+	LibraryID string `msgpack:"libraryId"`
+	State     string `msgpack:"state"`
+	Scanned   int    `msgpack:"scanned"`
+	Imported  int    `msgpack:"imported"`
+	Failed    int    `msgpack:"failed"`
+	Missing   int    `msgpack:"missing"`
+}
+
+// SessionEvent describes a playback session. Reason is set when a session stops, being idle, terminated or failed.
+type SessionEvent struct {
+	ID       string `msgpack:"id"`
+	UserID   string `msgpack:"userId"`
+	ItemID   string `msgpack:"itemId"`
+	DeviceID string `msgpack:"deviceId,omitempty"`
+	Plan     string `msgpack:"plan"`
+	Reason   string `msgpack:"reason,omitempty"`
+}
+
+// DroppedEvent counts the events that were dropped.
+type DroppedEvent struct {
+	Count int `msgpack:"count"`
+}
@@ -0,0 +1,43 @@
+package plugin
+
+// Methods the server answers, and the scope each requires.
+const (
+	// MethodListLibraries returns every Library, ordered by name. It requires ScopeLibraryRead.
+	MethodListLibraries = "libraries.list"
+	// MethodGetItem returns the Item with the ID in an ItemRequest. It requires ScopeLibraryRead.
+	MethodGetItem = "items.get"
+	// MethodRefreshItem queues the metadata of the item with the ID in an ItemRequest to be refreshed. It requires
+	// ScopeMetadataWrite.
+	MethodRefreshItem = "items.refresh"
+	// MethodSubscribeEvents replaces the events sent to the plugin with those of the types in a SubscribeRequest, or
+	// every type its scopes allow when none are given. Each type requires the scope documented with it.
+	MethodSubscribeEvents = "events.subscribe"
+)
+
+// Library is a library on the server.
+type Library struct {
+	ID   string `msgpack:"id"`
+	Name string `msgpack:"name"`
+}
+
+// ItemRequest identifies an item.
+type ItemRequest struct {
+	ID string `msgpack:"id"`
+}
+
+// Item is a movie, show, season or episode.
+type Item struct {
+	ID        string `msgpack:"id"`
+	LibraryID string `msgpack:"libraryId"`
+	// ParentID is the show of a season, or the season of an episode.
+	ParentID string   `msgpack:"parentId,omitempty"`
+	Kind     string   `msgpack:"kind"`
+	Name     string   `msgpack:"name"`
+	Overview string   `msgpack:"overview,omitempty"`
+	Year     int      `msgpack:"year,omitempty"`
+	Season   int      `msgpack:"season,omitempty"`
+	Episode  int      `msgpack:"episode,omitempty"`
+	Genres   []string `msgpack:"genres,omitempty"`
+	// IDs maps metadata providers to the ID of the item with them.
+	IDs map[string]string `msgpack:"ids,omitempty"`
+}
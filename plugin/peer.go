@@ -0,0 +1,359 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/webtransport-go"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+const (
+	// handshakeTimeout bounds the exchange of the hello and welcome.
+	handshakeTimeout = 10 * time.Second
+	// requestReadTimeout bounds reading a request once its stream has been opened.
+	requestReadTimeout = 30 * time.Second
+)
+
+// Handler answers a call, returning the body of the response. Returning an *Error passes it to the caller, while other
+// errors are reported to the caller as internal errors.
+type Handler func(ctx context.Context, req *Request) (any, error)
+
+// Request is a call made by the other side.
+type Request struct {
+	Method string
+	// Peer is the connection the call was made on.
+	Peer *Peer
+	body msgpack.RawMessage
+}
+
+// Decode decodes the body of the request into v. The error is an *Error, so may be returned by handlers as is.
+func (r *Request) Decode(v any) error {
+	if err := decodeBody(r.body, v); err != nil {
+		return &Error{
+			Code:    CodeBadRequest,
+			Message: fmt.Sprintf("invalid body: %v", err),
+		}
+	}
+
+	return nil
+}
+
+// Message is a notification or event sent by the other side.
+type Message struct {
+	Topic string
+	// Datagram is set for events, which unlike notifications may be lost or arrive out of order.
+	Datagram bool
+	// Peer is the connection the message arrived on.
+	Peer *Peer
+	body msgpack.RawMessage
+}
+
+// Decode decodes the body of the message into v.
+func (m Message) Decode(v any) error {
+	return decodeBody(m.body, v)
+}
+
+// Mux routes calls to the handler registered for their method. Handlers may be registered at any time.
+type Mux struct {
+	mu       sync.RWMutex
+	handlers map[string]Handler
+}
+
+func NewMux() *Mux {
+	return &Mux{
+		handlers: make(map[string]Handler),
+	}
+}
+
+// Handle registers the handler for the method, replacing any existing one.
+func (m *Mux) Handle(method string, h Handler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.handlers[method] = h
+}
+
+func (m *Mux) lookup(method string) (Handler, bool) {
+	if m == nil {
+		return nil, false
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	h, ok := m.handlers[method]
+
+	return h, ok
+}
+
+// Peer is an established connection between a plugin and a server, through which either side may call the other.
+type Peer struct {
+	session   *webtransport.Session
+	id        quic.StreamID
+	datagrams *datagramConn
+	control   webtransport.Stream
+	handler   *Mux
+	onMessage func(Message)
+
+	// controlMu serialises writes to the control stream.
+	controlMu sync.Mutex
+}
+
+func newPeer(
+	session *webtransport.Session,
+	id quic.StreamID,
+	conn quic.Connection,
+	control webtransport.Stream,
+	handler *Mux,
+	onMessage func(Message),
+) *Peer {
+	p := &Peer{
+		session:   session,
+		id:        id,
+		datagrams: datagramsFor(conn),
+		control:   control,
+		handler:   handler,
+		onMessage: onMessage,
+	}
+
+	go p.acceptCalls()
+	go p.readControl()
+	go p.readDatagrams()
+
+	return p
+}
+
+// Done is closed once the connection has been closed by either side.
+func (p *Peer) Done() <-chan struct{} {
+	return p.session.Context().Done()
+}
+
+// Close closes the connection, failing any calls in progress.
+func (p *Peer) Close() error {
+	return p.session.CloseWithError(0, "")
+}
+
+func (p *Peer) RemoteAddr() net.Addr {
+	return p.session.RemoteAddr()
+}
+
+// Call calls the method on the other side, decoding the body of the response into res unless it is nil. Errors
+// returned by the other side are an *Error.
+func (p *Peer) Call(ctx context.Context, method string, req any, res any) error {
+	msg, err := newMessage(messageRequest, method, req)
+	if err != nil {
+		return err
+	}
+
+	str, err := p.session.OpenStreamSync(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to open stream: %w", err)
+	}
+
+	// Resetting the stream tells the other side the call was abandoned
+	stop := context.AfterFunc(ctx, func() {
+		str.CancelRead(0)
+		str.CancelWrite(0)
+	})
+	defer stop()
+
+	if err := writeFrame(str, msg); err != nil {
+		return p.callErr(ctx, err)
+	}
+
+	if err := str.Close(); err != nil {
+		return p.callErr(ctx, err)
+	}
+
+	reply, err := readMessage(str, messageResponse)
+	if err != nil {
+		return p.callErr(ctx, err)
+	}
+
+	if err := decodeBody(reply.Body, res); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+
+	return nil
+}
+
+// callErr prefers the context error, as cancelling a call surfaces as a stream error.
+func (p *Peer) callErr(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	return err
+}
+
+// Notify sends a notification on the control stream, which arrives reliably and in order with other notifications.
+func (p *Peer) Notify(topic string, body any) error {
+	msg, err := newMessage(messageNotify, topic, body)
+	if err != nil {
+		return err
+	}
+
+	p.controlMu.Lock()
+	defer p.controlMu.Unlock()
+
+	return writeFrame(p.control, msg)
+}
+
+// SendEvent sends an event as a datagram, which is faster than a notification but may be lost. The event must fit in a
+// single packet, and the connection must support datagrams, as offered by CapabilityDatagrams.
+func (p *Peer) SendEvent(topic string, body any) error {
+	msg, err := newMessage(messageEvent, topic, body)
+	if err != nil {
+		return err
+	}
+
+	b, err := msgpack.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	return p.datagrams.send(p.id, b)
+}
+
+// SupportsDatagrams reports whether events can be sent on the connection.
+func (p *Peer) SupportsDatagrams() bool {
+	return p.datagrams.conn.ConnectionState().SupportsDatagrams
+}
+
+func (p *Peer) acceptCalls() {
+	ctx := p.session.Context()
+
+	for {
+		str, err := p.session.AcceptStream(ctx)
+		if err != nil {
+			return
+		}
+
+		go p.serveCall(ctx, str)
+	}
+}
+
+func (p *Peer) serveCall(ctx context.Context, str webtransport.Stream) {
+	if err := str.SetReadDeadline(time.Now().Add(requestReadTimeout)); err != nil {
+		str.CancelWrite(0)
+
+		return
+	}
+
+	msg, err := readMessage(str, messageRequest)
+	if err != nil {
+		str.CancelRead(0)
+		str.CancelWrite(0)
+
+		return
+	}
+
+	reply := message{Type: messageResponse}
+
+	res, err := p.dispatch(ctx, &Request{
+		Method: msg.Name,
+		Peer:   p,
+		body:   msg.Body,
+	})
+	if err == nil && res != nil {
+		reply.Body, err = msgpack.Marshal(res)
+	}
+
+	if err != nil {
+		reply.Error = toError(err)
+	}
+
+	if err := writeFrame(str, reply); err != nil {
+		str.CancelWrite(0)
+
+		return
+	}
+
+	_ = str.Close()
+}
+
+func (p *Peer) dispatch(ctx context.Context, req *Request) (res any, err error) {
+	if req.Method == MethodPing {
+		return nil, nil
+	}
+
+	h, ok := p.handler.lookup(req.Method)
+	if !ok {
+		return nil, &Error{
+			Code:    CodeUnknownMethod,
+			Message: fmt.Sprintf("unknown method %q", req.Method),
+		}
+	}
+
+	defer func() {
+		if rvr := recover(); rvr != nil {
+			err = fmt.Errorf("handler panicked: %v", rvr)
+		}
+	}()
+
+	return h(ctx, req)
+}
+
+// readControl reads notifications until the control stream ends, which ends the connection.
+func (p *Peer) readControl() {
+	defer p.Close()
+
+	for {
+		msg, err := readFrame(p.control)
+		if err != nil {
+			return
+		}
+
+		if msg.Type == messageNotify && p.onMessage != nil {
+			p.onMessage(Message{
+				Topic: msg.Name,
+				Peer:  p,
+				body:  msg.Body,
+			})
+		}
+	}
+}
+
+func (p *Peer) readDatagrams() {
+	ch, unregister := p.datagrams.register(p.id)
+	defer unregister()
+
+	for {
+		select {
+		case <-p.Done():
+			return
+		case b := <-ch:
+			var msg message
+
+			if err := msgpack.Unmarshal(b, &msg); err != nil || msg.Type != messageEvent || p.onMessage == nil {
+				continue
+			}
+
+			p.onMessage(Message{
+				Topic:    msg.Name,
+				Datagram: true,
+				Peer:     p,
+				body:     msg.Body,
+			})
+		}
+	}
+}
+
+// toError converts an error to be sent to the other side, hiding the details of errors that are not an *Error.
+func toError(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+
+	return &Error{
+		Code:    CodeInternal,
+		Message: "An internal error has occurred",
+	}
+}
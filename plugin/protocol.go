@@ -0,0 +1,204 @@
+// Package plugin implements the RPC protocol plugins use to talk to a media server over WebTransport, and a client for
+// writing plugins in Go.
+//
+// A plugin opens a session at TransportPath and then a control stream, on which it sends a hello identifying itself.
+// The server answers with a welcome, or an error before closing the session. The control stream then carries
+// notifications in both directions. Each call is made on a new bidirectional stream, carrying one request and one
+// response, so that calls in either direction are multiplexed without blocking each other. Events that may be lost
+// are sent as datagrams.
+//
+// Messages on streams are msgpack encoded, with each preceded by its length as a 4 byte big endian integer. Datagrams
+// carry a single message without a length.
+package plugin
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+var (
+	ErrFrameTooLarge        = errors.New("frame too large")
+	ErrUnexpectedMessage    = errors.New("unexpected message")
+	ErrDatagramsUnsupported = errors.New("datagrams are not supported by the connection")
+)
+
+const (
+	// ProtocolVersion is the version of the protocol implemented by this package. Servers reject plugins speaking
+	// another version.
+	ProtocolVersion = 1
+
+	// TransportPath is the path of the WebTransport endpoint on the server.
+	TransportPath = "/api/v1/plugin/transport"
+
+	// maxFrameSize bounds the messages sent on streams.
+	maxFrameSize = 16 << 20
+)
+
+// Capabilities offered by the server in its welcome.
+const (
+	// CapabilityDatagrams is offered when the connection supports datagrams, so that events can be sent.
+	CapabilityDatagrams = "datagrams"
+)
+
+// MethodPing is answered by both sides with an empty response, so that either can check the other is responsive.
+const MethodPing = "ping"
+
+// Error codes.
+const (
+	CodeBadRequest         = "bad-request"
+	CodeUnknownMethod      = "unknown-method"
+	CodeUnsupportedVersion = "unsupported-version"
+	CodeForbidden          = "forbidden"
+	CodeNotFound           = "not-found"
+	CodeInternal           = "internal-error"
+)
+
+// Error is an error returned by the other side, either in answer to a call or when rejecting a hello.
+type Error struct {
+	Code    string `msgpack:"code"`
+	Message string `msgpack:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%v: %v", e.Code, e.Message)
+}
+
+// Info identifies a plugin to the server.
+type Info struct {
+	// ID is the ID an admin registered the plugin with. It may be left empty when authenticating with an API key, as
+	// the key identifies the plugin. A second connection with the same ID replaces the first.
+	ID      string `msgpack:"id"`
+	Name    string `msgpack:"name"`
+	Version string `msgpack:"version"`
+	// Capabilities describe what the plugin provides, such as the methods the server may call.
+	Capabilities []string `msgpack:"capabilities"`
+	// Scopes are the permissions the plugin needs, which must have been granted to it by an admin. Only these are
+	// granted to the connection.
+	Scopes []string `msgpack:"scopes"`
+}
+
+// Welcome is the answer of the server to a hello.
+type Welcome struct {
+	// PluginID is the ID the plugin is registered with.
+	PluginID      string   `msgpack:"pluginId"`
+	ServerID      string   `msgpack:"serverId"`
+	ServerName    string   `msgpack:"serverName"`
+	ServerVersion string   `msgpack:"serverVersion"`
+	Capabilities  []string `msgpack:"capabilities"`
+}
+
+type messageType uint8
+
+const (
+	messageHello messageType = iota + 1
+	messageWelcome
+	messageRequest
+	messageResponse
+	messageNotify
+	messageEvent
+)
+
+type message struct {
+	Type     messageType `msgpack:"type"`
+	Protocol int         `msgpack:"protocol,omitempty"`
+	// Name is the method of a request, or the topic of a notification or event.
+	Name  string             `msgpack:"name,omitempty"`
+	Body  msgpack.RawMessage `msgpack:"body,omitempty"`
+	Error *Error             `msgpack:"error,omitempty"`
+}
+
+func newMessage(t messageType, name string, body any) (message, error) {
+	msg := message{
+		Type: t,
+		Name: name,
+	}
+
+	if body != nil {
+		b, err := msgpack.Marshal(body)
+		if err != nil {
+			return msg, fmt.Errorf("failed to encode body: %w", err)
+		}
+
+		msg.Body = b
+	}
+
+	return msg, nil
+}
+
+// decodeBody decodes the body of a message into v, leaving v untouched when there is no body.
+func decodeBody(body msgpack.RawMessage, v any) error {
+	if len(body) == 0 || v == nil {
+		return nil
+	}
+
+	return msgpack.Unmarshal(body, v)
+}
+
+func writeFrame(w io.Writer, msg message) error {
+	b, err := msgpack.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	if len(b) > maxFrameSize {
+		return fmt.Errorf("%w: %d bytes", ErrFrameTooLarge, len(b))
+	}
+
+	// The length and message are written together, so that a frame is never split across writes
+	buf := make([]byte, 4+len(b))
+	binary.BigEndian.PutUint32(buf, uint32(len(b)))
+	copy(buf[4:], b)
+
+	_, err = w.Write(buf)
+
+	return err
+}
+
+func readFrame(r io.Reader) (message, error) {
+	var (
+		msg message
+		hdr [4]byte
+	)
+
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return msg, err
+	}
+
+	size := binary.BigEndian.Uint32(hdr[:])
+	if size > maxFrameSize {
+		return msg, fmt.Errorf("%w: %d bytes", ErrFrameTooLarge, size)
+	}
+
+	b := make([]byte, size)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return msg, err
+	}
+
+	if err := msgpack.Unmarshal(b, &msg); err != nil {
+		return msg, fmt.Errorf("invalid message: %w", err)
+	}
+
+	return msg, nil
+}
+
+// readMessage reads the next frame, requiring it to be of the given type. An error message is returned as an *Error.
+func readMessage(r io.Reader, t messageType) (message, error) {
+	msg, err := readFrame(r)
+	if err != nil {
+		return msg, err
+	}
+
+	if msg.Error != nil {
+		return msg, msg.Error
+	}
+
+	if msg.Type != t {
+		return msg, fmt.Errorf("%w: type %d", ErrUnexpectedMessage, msg.Type)
+	}
+
+	return msg, nil
+}
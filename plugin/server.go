@@ -0,0 +1,136 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+	"github.com/quic-go/webtransport-go"
+)
+
+var errNotHTTP3 = errors.New("request was not made over http/3")
+
+// rejectLinger is how long the session is kept open after rejecting a hello, so that the plugin receives the error.
+const rejectLinger = time.Second
+
+// AcceptConfig configures the server side of connections.
+type AcceptConfig struct {
+	// Welcome is called with the identity of the plugin, returning the welcome to send or an error to reject the
+	// plugin with. CapabilityDatagrams is added to the welcome when the connection supports datagrams.
+	Welcome func(info Info) (Welcome, error)
+	// Handler answers calls made by the plugin. It may be nil, in which case only MethodPing is answered.
+	Handler *Mux
+	// OnMessage is called with notifications and events sent by the plugin. It is called from the reader of the
+	// connection, so must not block.
+	OnMessage func(Message)
+}
+
+// Accept completes the handshake on a session upgraded from the request, returning the connection and the identity of
+// the plugin. The request and response writer must be those the session was upgraded from.
+func Accept(
+	ctx context.Context,
+	w http.ResponseWriter,
+	r *http.Request,
+	session *webtransport.Session,
+	cfg AcceptConfig,
+) (*Peer, Info, error) {
+	var info Info
+
+	// Datagrams are sent on the connection itself, so the session needs to be related to it
+	streamer, ok := r.Body.(http3.HTTPStreamer)
+	if !ok {
+		return nil, info, errNotHTTP3
+	}
+
+	hijacker, ok := w.(http3.Hijacker)
+	if !ok {
+		return nil, info, errNotHTTP3
+	}
+
+	conn, ok := hijacker.StreamCreator().(quic.Connection)
+	if !ok {
+		return nil, info, errNotHTTP3
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, handshakeTimeout)
+	defer cancel()
+
+	control, err := session.AcceptStream(ctx)
+	if err != nil {
+		return nil, info, fmt.Errorf("failed to accept control stream: %w", err)
+	}
+
+	deadline, _ := ctx.Deadline()
+	if err := control.SetDeadline(deadline); err != nil {
+		return nil, info, err
+	}
+
+	hello, err := readMessage(control, messageHello)
+	if err != nil {
+		return nil, info, fmt.Errorf("failed to read hello: %w", err)
+	}
+
+	if hello.Protocol != ProtocolVersion {
+		err := &Error{
+			Code:    CodeUnsupportedVersion,
+			Message: fmt.Sprintf("protocol version %d is not supported, expected %d", hello.Protocol, ProtocolVersion),
+		}
+
+		return nil, info, reject(session, control, err)
+	}
+
+	if err := decodeBody(hello.Body, &info); err != nil {
+		return nil, info, reject(session, control, &Error{
+			Code:    CodeBadRequest,
+			Message: fmt.Sprintf("invalid hello: %v", err),
+		})
+	}
+
+	welcome, err := cfg.Welcome(info)
+	if err != nil {
+		return nil, info, reject(session, control, err)
+	}
+
+	if conn.ConnectionState().SupportsDatagrams && !slices.Contains(welcome.Capabilities, CapabilityDatagrams) {
+		welcome.Capabilities = append(welcome.Capabilities, CapabilityDatagrams)
+	}
+
+	msg, err := newMessage(messageWelcome, "", welcome)
+	if err != nil {
+		return nil, info, err
+	}
+
+	msg.Protocol = ProtocolVersion
+
+	if err := writeFrame(control, msg); err != nil {
+		return nil, info, fmt.Errorf("failed to write welcome: %w", err)
+	}
+
+	if err := control.SetDeadline(time.Time{}); err != nil {
+		return nil, info, err
+	}
+
+	return newPeer(session, streamer.HTTPStream().StreamID(), conn, control, cfg.Handler, cfg.OnMessage), info, nil
+}
+
+// reject sends the error to the plugin and closes the session, returning the error.
+func reject(session *webtransport.Session, control webtransport.Stream, err error) error {
+	if writeErr := writeFrame(control, message{Error: toError(err)}); writeErr == nil {
+		_ = control.Close()
+
+		// The plugin closes the session once it has read the error
+		select {
+		case <-session.Context().Done():
+		case <-time.After(rejectLinger):
+		}
+	}
+
+	_ = session.CloseWithError(0, "")
+
+	return err
+}
@@ -1,52 +1,189 @@
 import 'package:cryptography/cryptography.dart';
 import 'package:http/http.dart' as http;
 import "package:msgpack_dart/msgpack_dart.dart" as mp;
+import 'dart:convert';
+import 'dart:math';
 import 'dart:typed_data';
 
-final aesCtr = AesCtr.with128bits(
-  macAlgorithm: Hmac.sha256(),
-);
+// The wire format is described in internal/mediaserver/link.go.
+
+const _nonceLength = 16;
+const _macLength = 32;
+
+final _x25519 = X25519();
+final _hmac = Hmac.sha256();
+final _aesCtr = AesCtr.with128bits(macAlgorithm: MacAlgorithm.empty);
+final _random = Random.secure();
+
+class LinkException implements Exception {
+  final int status;
+  final String message;
+
+  LinkException(this.status, this.message);
+
+  @override
+  String toString() => "LinkException($status): $message";
+}
+
+class LinkResponse {
+  final int status;
+  final dynamic body;
+
+  LinkResponse(this.status, this.body);
+}
+
+/// The device id and secret used to talk to the server, obtained by pairing.
+class LinkCredentials {
+  final Uint8List deviceId;
+  final List<int> secret;
+
+  LinkCredentials(String deviceId, this.secret)
+      : deviceId = _uuidBytes(deviceId);
+
+  /// Generates the key pair whose public key is sent as the link key when
+  /// starting a pairing.
+  static Future<SimpleKeyPair> newKeyPair() => _x25519.newKeyPair();
+
+  static Future<String> encodePublicKey(SimpleKeyPair keyPair) async {
+    final pub = await keyPair.extractPublicKey();
+    return base64.encode(pub.bytes);
+  }
+
+  /// Derives the credentials from an approved pairing, given the device id and
+  /// the link key returned by the server.
+  static Future<LinkCredentials> fromPairing(
+    SimpleKeyPair keyPair,
+    String deviceId,
+    String serverKey,
+  ) async {
+    final devicePub = (await keyPair.extractPublicKey()).bytes;
+    final serverPub = base64.decode(serverKey);
+
+    final shared = await _x25519.sharedSecretKey(
+      keyPair: keyPair,
+      remotePublicKey: SimplePublicKey(serverPub, type: KeyPairType.x25519),
+    );
+
+    final secret = await _derive(
+      await shared.extractBytes(),
+      [...utf8.encode("cathode link secret"), ...devicePub, ...serverPub],
+      32,
+    );
+
+    return LinkCredentials(deviceId, secret);
+  }
+}
 
 class LinkClient {
   final http.Client _client;
   final String _host;
   final int _port;
+  final LinkCredentials _credentials;
 
-  LinkClient(String host, int port)
+  LinkClient(String host, int port, LinkCredentials credentials)
       : _client = http.Client(),
         _host = host,
-        _port = port;
+        _port = port,
+        _credentials = credentials;
 
-  Future<Map> send(Map msg) async {
-    final secretKey = SecretKeyData(
-      Uint8List.fromList([1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1]),
-      overwriteWhenDestroyed: true,
-    );
+  /// Calls an operation of the v1 API, named by its operation id.
+  Future<LinkResponse> call(
+    String op, {
+    Map<String, Object?>? params,
+    Object? body,
+  }) async {
+    final (reqKey, reqMacKey) =
+        await _keys(_credentials.secret, "cathode link request");
 
-    var data = mp.serialize(msg);
+    var data = mp.serialize({
+      "ts": DateTime.now().millisecondsSinceEpoch ~/ 1000,
+      "op": op,
+      if (params != null) "params": params,
+      if (body != null) "body": body,
+    });
 
-    final nonce = aesCtr.newNonce();
-    final secretBox = await aesCtr.encrypt(
+    final nonce = _randomBytes(_nonceLength);
+    final secretBox = await _aesCtr.encrypt(
       data,
-      secretKey: secretKey,
+      secretKey: reqKey,
       nonce: nonce,
     );
 
+    final frame = BytesBuilder(copy: false)
+      ..add(_credentials.deviceId)
+      ..add(nonce)
+      ..add(secretBox.cipherText);
+    final mac = await _hmac.calculateMac(frame.toBytes(), secretKey: reqMacKey);
+    frame.add(mac.bytes);
+
     var httpResp = await _client.post(
       Uri(scheme: "https", host: _host, port: _port, path: '/v1'),
-      body: secretBox.concatenation(),
+      body: frame.takeBytes(),
     );
 
-    // TODO: Check status code
+    if (httpResp.statusCode != 200) {
+      throw LinkException(httpResp.statusCode, httpResp.body);
+    }
 
-    final respBox = SecretBox.fromConcatenation(
-      httpResp.bodyBytes,
-      nonceLength: aesCtr.nonceLength,
-      macLength: aesCtr.macAlgorithm.macLength,
+    final resp = httpResp.bodyBytes;
+    if (resp.length < _nonceLength + _macLength) {
+      throw LinkException(httpResp.statusCode, "truncated response");
+    }
+
+    final (respKey, respMacKey) =
+        await _keys(_credentials.secret, "cathode link response");
+
+    final sealed = resp.sublist(0, resp.length - _macLength);
+    final expected = await _hmac.calculateMac(
+      [...nonce, ...sealed],
+      secretKey: respMacKey,
+    );
+    if (expected != Mac(resp.sublist(resp.length - _macLength))) {
+      throw LinkException(httpResp.statusCode, "invalid response mac");
+    }
+
+    final deResp = await _aesCtr.decrypt(
+      SecretBox(
+        sealed.sublist(_nonceLength),
+        nonce: sealed.sublist(0, _nonceLength),
+        mac: Mac.empty,
+      ),
+      secretKey: respKey,
     );
-    final deResp = await aesCtr.decrypt(respBox, secretKey: secretKey);
 
     Map deMsg = mp.deserialize(Uint8List.fromList(deResp));
-    return deMsg;
+    return LinkResponse(deMsg["status"] as int, deMsg["body"]);
+  }
+}
+
+Future<List<int>> _derive(List<int> secret, List<int> info, int length) async {
+  final key = await Hkdf(hmac: _hmac, outputLength: length).deriveKey(
+    secretKey: SecretKey(secret),
+    nonce: const [],
+    info: info,
+  );
+  return key.extractBytes();
+}
+
+/// Derives the cipher and mac key for one direction of the link.
+Future<(SecretKey, SecretKey)> _keys(List<int> secret, String info) async {
+  final key = await _derive(secret, utf8.encode(info), 16 + 32);
+  return (SecretKey(key.sublist(0, 16)), SecretKey(key.sublist(16)));
+}
+
+Uint8List _randomBytes(int length) {
+  final buf = Uint8List(length);
+  for (var i = 0; i < length; i++) {
+    buf[i] = _random.nextInt(256);
+  }
+  return buf;
+}
+
+Uint8List _uuidBytes(String id) {
+  final hex = id.replaceAll("-", "");
+  final buf = Uint8List(16);
+  for (var i = 0; i < 16; i++) {
+    buf[i] = int.parse(hex.substring(i * 2, i * 2 + 2), radix: 16);
   }
+  return buf;
 }